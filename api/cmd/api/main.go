@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/middleware"
 	"github.com/lardira/playtrack/internal/pkg/envutil"
+	"github.com/lardira/playtrack/internal/pkg/mailer"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+	"github.com/lardira/playtrack/internal/pkg/password"
 	"github.com/lardira/playtrack/internal/server"
 )
 
@@ -26,13 +34,66 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	storage := flag.String(
+		"storage",
+		envutil.GetOrDefault("STORAGE", server.StoragePostgres),
+		"postgres, sqlite or memory, memory keeps data in the process until it stops",
+	)
+	flag.Parse()
+
+	// only postgres storage needs a database url
+	var databaseURL string
+	if *storage == server.StoragePostgres {
+		databaseURL = envutil.MustGet("DB_URL")
+	}
+
 	serverErrChan := make(chan error)
 
 	opts := server.Options{
-		Host:        envutil.GetOrDefault("SERVER_HOST", "localhost"),
-		Port:        envutil.GetOrDefault("SERVER_PORT", "8080"),
-		DatabaseURL: envutil.MustGet("DB_URL"),
-		JWTSecret:   envutil.MustGet("JWT_TOKEN_SECRET"),
+		Host:            envutil.GetOrDefault("SERVER_HOST", "localhost"),
+		Port:            envutil.GetOrDefault("SERVER_PORT", "8080"),
+		Storage:         *storage,
+		DatabaseURL:     databaseURL,
+		SQLitePath:      envutil.GetOrDefault("SQLITE_PATH", "./playtrack.db"),
+		JWTSecret:       envutil.GetOrDefault("JWT_TOKEN_SECRET", ""),
+		JWTKeysDir:      envutil.GetOrDefault("JWT_KEYS_DIR", ""),
+		JWTSigningKeyID: envutil.GetOrDefault("JWT_SIGNING_KEY_ID", ""),
+
+		BcryptCost:       envutil.GetIntOrDefault("BCRYPT_COST", password.DefaultCost),
+		AuthRateLimit:    envutil.GetIntOrDefault("AUTH_RATE_LIMIT", 10),
+		TrustedProxies:   envutil.GetListOrDefault("TRUSTED_PROXIES", nil),
+		LoginMaxAttempts: envutil.GetIntOrDefault("LOGIN_MAX_ATTEMPTS", 5),
+		LoginLockout:     envutil.GetDurationOrDefault("LOGIN_LOCKOUT", 15*time.Minute),
+		PlayerCacheTTL:   envutil.GetDurationOrDefault("AUTH_PLAYER_CACHE_TTL", middleware.DefaultPlayerCacheTTL),
+
+		AppURL:     envutil.GetOrDefault("APP_URL", "http://localhost:3000"),
+		MailDriver: envutil.GetOrDefault("MAIL_DRIVER", server.MailDriverFile),
+		MailDir:    envutil.GetOrDefault("MAIL_DIR", "./mail"),
+		SMTP: mailer.SMTPOptions{
+			Host:     envutil.GetOrDefault("SMTP_HOST", "localhost"),
+			Port:     envutil.GetOrDefault("SMTP_PORT", "587"),
+			Username: envutil.GetOrDefault("SMTP_USERNAME", ""),
+			Password: envutil.GetOrDefault("SMTP_PASSWORD", ""),
+			From:     envutil.GetOrDefault("SMTP_FROM", "playtrack@localhost"),
+		},
+
+		APIURL:         envutil.GetOrDefault("API_URL", "http://localhost:8080"),
+		OAuthProviders: oauthProviders(),
+
+		WebhookPollInterval: envutil.GetDurationOrDefault("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		WebhookMaxAttempts:  envutil.GetIntOrDefault("WEBHOOK_MAX_ATTEMPTS", 8),
+
+		EventsNotify: envutil.GetOrDefault("EVENTS_PG_NOTIFY", "0") == "1",
+
+		DiscordPublicKey: envutil.GetOrDefault("DISCORD_PUBLIC_KEY", ""),
+		DiscordAppID:     envutil.GetOrDefault("DISCORD_APP_ID", ""),
+		DiscordBotToken:  envutil.GetOrDefault("DISCORD_BOT_TOKEN", ""),
+
+		Streak: player.StreakRules{
+			BonusAfter:  envutil.GetIntOrDefault("STREAK_BONUS_AFTER", player.DefaultStreakRules.BonusAfter),
+			BonusPoints: envutil.GetIntOrDefault("STREAK_BONUS_POINTS", player.DefaultStreakRules.BonusPoints),
+			DecayAfter:  envutil.GetIntOrDefault("STREAK_DECAY_AFTER", player.DefaultStreakRules.DecayAfter),
+		},
 	}
 	server, err := server.New(ctx, opts)
 	if err != nil {
@@ -55,3 +116,22 @@ func main() {
 		log.Println("kill signal fired")
 	}
 }
+
+// oauthProviders reads OAUTH_PROVIDERS list, each provider is configured
+// with OAUTH_<NAME>_* variables, the kind defaults to the name
+func oauthProviders() map[string]oauth.Config {
+	providers := make(map[string]oauth.Config)
+	for _, name := range envutil.GetListOrDefault("OAUTH_PROVIDERS", nil) {
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		providers[name] = oauth.Config{
+			Kind:         envutil.GetOrDefault(prefix+"KIND", name),
+			ClientID:     envutil.GetOrDefault(prefix+"CLIENT_ID", ""),
+			ClientSecret: envutil.GetOrDefault(prefix+"CLIENT_SECRET", ""),
+			AuthURL:      envutil.GetOrDefault(prefix+"AUTH_URL", ""),
+			TokenURL:     envutil.GetOrDefault(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  envutil.GetOrDefault(prefix+"USERINFO_URL", ""),
+			Scopes:       envutil.GetListOrDefault(prefix+"SCOPES", nil),
+		}
+	}
+	return providers
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lardira/playtrack/internal/backup"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/envutil"
+	"github.com/lardira/playtrack/internal/seed"
+)
+
+const usage = `playtrack manages the data of a playtrack deployment
+
+usage:
+  playtrack backup [-o file] [-exclude-passwords]
+  playtrack restore [-i file]
+  playtrack backfill-achievements
+  playtrack rebuild-ledger [-verify]
+  playtrack seed [-seed n] [-games n] [-players n] [-password p]
+
+DB_URL selects the database, GOOSE_TABLE the migrations table (default %s).
+`
+
+func init() {
+	if envutil.GetOrDefault("LOAD_ENV_FILE", "0") == "1" {
+		if err := envutil.LoadEnvs(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, db.DefaultMigrationsTable)
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "backup":
+		err = runBackup(ctx, args)
+	case "restore":
+		err = runRestore(ctx, args)
+	case "backfill-achievements":
+		err = runBackfillAchievements(ctx)
+	case "rebuild-ledger":
+		err = runRebuildLedger(ctx, args)
+	case "seed":
+		err = runSeed(ctx, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runBackup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("o", "", "archive file, stdout by default")
+	excludePasswords := fs.Bool("exclude-passwords", false, "do not store password hashes of players")
+	fs.Parse(args)
+
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	archive, err := backup.Backup(ctx, pool, backup.Options{
+		MigrationsTable:  envutil.GetOrDefault("GOOSE_TABLE", db.DefaultMigrationsTable),
+		ExcludePasswords: *excludePasswords,
+	})
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := archive.Write(w); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	for _, t := range archive.Manifest.Tables {
+		log.Printf("%s: %d rows", t.Name, t.Rows)
+	}
+	log.Printf("backup of schema version %d is written", archive.Manifest.SchemaVersion)
+	return nil
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("i", "", "archive file, stdin by default")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	archive, err := backup.Read(r)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+	log.Printf(
+		"restoring backup of schema version %d from %v",
+		archive.Manifest.SchemaVersion,
+		archive.Manifest.CreatedAt.Format(time.RFC3339),
+	)
+
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	err = backup.Restore(ctx, pool, archive, backup.Options{
+		MigrationsTable: envutil.GetOrDefault("GOOSE_TABLE", db.DefaultMigrationsTable),
+	})
+	if errors.Is(err, backup.ErrNotEmpty) || errors.Is(err, backup.ErrSchemaNewer) {
+		return fmt.Errorf("restore: %w, restore into an empty database", err)
+	}
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	if archive.Manifest.PasswordsExcluded {
+		log.Println("the backup has no passwords, players have to reset them")
+	}
+	log.Println("restore is done")
+	return nil
+}
+
+// runBackfillAchievements unlocks achievements of history recorded before they existed
+// or imported, no events are published for them
+func runBackfillAchievements(ctx context.Context) error {
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	players, err := player.NewPGRepository(pool).FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("players find: %w", err)
+	}
+	ids := make([]string, 0, len(players))
+	for _, p := range players {
+		ids = append(ids, p.ID)
+	}
+
+	tracker := achievement.NewTracker(
+		achievement.NewPGRepository(pool),
+		player.NewPGPlayedRepository(pool),
+		game.NewPGRepository(pool),
+		nil,
+	)
+	unlocked, err := tracker.Backfill(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+
+	log.Printf("%d achievements unlocked for %d players", unlocked, len(ids))
+	return nil
+}
+
+// runRebuildLedger compares the points ledger with played games and appends correcting
+// entries for players whose ledger does not match, with -verify it only reports mismatches
+func runRebuildLedger(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild-ledger", flag.ExitOnError)
+	verify := fs.Bool("verify", false, "only report mismatches, fail if there are any")
+	fs.Parse(args)
+
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	players, err := player.NewPGRepository(pool).FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("players find: %w", err)
+	}
+	rebuilder := player.NewLedgerRebuilder(
+		player.NewPGPlayedRepository(pool),
+		player.NewPGLedgerRepository(pool),
+		db.NewTxManager(pool),
+	)
+
+	mismatched := 0
+	for _, p := range players {
+		check, err := rebuilder.Rebuild(ctx, &p, *verify)
+		if err != nil {
+			return fmt.Errorf("player %v: %w", p.ID, err)
+		}
+		if len(check.Mismatches) == 0 {
+			continue
+		}
+		mismatched++
+		for _, m := range check.Mismatches {
+			log.Printf("%s: played game %d has %d points, the ledger has %d", p.Username, m.PlayedGameID, m.Expected, m.Actual)
+		}
+		if check.Rebuilt {
+			log.Printf("%s: ledger is corrected from %d played games", p.Username, check.PlayedGames)
+		}
+	}
+
+	if *verify && mismatched > 0 {
+		return fmt.Errorf("ledger does not match played games of %d players", mismatched)
+	}
+	log.Printf("ledger of %d players is checked, %d did not match", len(players), mismatched)
+	return nil
+}
+
+// runSeed fills an empty database with demo data, the same seed gives the same data
+// on the same day
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	seedValue := fs.Uint64("seed", uint64(time.Now().UnixNano()), "seed of generated data, random by default")
+	games := fs.Int("games", seed.DefaultGames, "number of games in the catalog")
+	players := fs.Int("players", seed.DefaultPlayers, "number of players, the first one is the admin")
+	pass := fs.String("password", seed.DefaultPassword, "password of every seeded player")
+	fs.Parse(args)
+
+	if *games < 1 || *players < 1 {
+		return errors.New("seed: games and players must be positive")
+	}
+
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	playedRepository := player.NewPGPlayedRepository(pool)
+	gameRepository := game.NewPGRepository(pool)
+	seeder := seed.NewSeeder(
+		gameRepository,
+		player.NewPGRepository(pool),
+		playedRepository,
+		player.NewPGStreakRepository(pool),
+		player.NewPGLedgerRepository(pool),
+		db.NewTxManager(pool),
+		achievement.NewTracker(achievement.NewPGRepository(pool), playedRepository, gameRepository, nil),
+	)
+
+	log.Printf("seeding with seed %d", *seedValue)
+	res, err := seeder.Seed(ctx, seed.Options{
+		Seed:     *seedValue,
+		Games:    *games,
+		Players:  *players,
+		Password: *pass,
+		Streak: player.StreakRules{
+			BonusAfter:  envutil.GetIntOrDefault("STREAK_BONUS_AFTER", player.DefaultStreakRules.BonusAfter),
+			BonusPoints: envutil.GetIntOrDefault("STREAK_BONUS_POINTS", player.DefaultStreakRules.BonusPoints),
+			DecayAfter:  envutil.GetIntOrDefault("STREAK_DECAY_AFTER", player.DefaultStreakRules.DecayAfter),
+		},
+		Now: time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	if errors.Is(err, seed.ErrNotEmpty) {
+		return fmt.Errorf("seed: %w, seed an empty database", err)
+	}
+	if err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+
+	log.Printf(
+		"%d games, %d players, %d played games and %d achievements are seeded",
+		res.Games, res.Players, res.PlayedGames, res.Achievements,
+	)
+	log.Printf("log in as %s or any seeded player with password %q", seed.AdminUsername, *pass)
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/envutil"
+	"github.com/lardira/playtrack/internal/pkg/password"
+)
+
+const usage = `playtrackctl administers players and data of a playtrack deployment
+
+usage:
+  playtrackctl [-o table|json] <command> [flags]
+
+commands:
+  player list [-username part] [-role admin|player] [-state active|deactivated|banned]
+  player create -username name [-email email] [-admin] [-password password]
+  player set-admin -username name [-admin=false]
+  player reset-password -username name [-password password] [-must-change=false]
+  played-games stuck [-older 720h]
+  played-games close [-older 720h] [-id id] [-status dropped|completed]
+  points recompute [-verify]
+  migrate status
+  migrate up [-to version]
+
+Passwords are read from stdin when -password is not set.
+DB_URL selects the database, GOOSE_TABLE the migrations table (default %s).
+`
+
+func init() {
+	if envutil.GetOrDefault("LOAD_ENV_FILE", "0") == "1" {
+		if err := envutil.LoadEnvs(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func main() {
+	format := flag.String("o", formatTable, "output format, table or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, db.DefaultMigrationsTable)
+	}
+	flag.Parse()
+	if flag.NArg() < 2 || (*format != formatTable && *format != formatJSON) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if cost := envutil.GetIntOrDefault("BCRYPT_COST", 0); cost != 0 {
+		if err := password.SetCost(cost); err != nil {
+			log.Fatalf("bcrypt cost: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.NewPostgres(ctx, envutil.MustGet("DB_URL"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	c := ctl{
+		pool: pool,
+		out:  output{format: *format, w: os.Stdout},
+	}
+
+	group, cmd, args := flag.Arg(0), flag.Arg(1), flag.Args()[2:]
+	switch group + " " + cmd {
+	case "player list":
+		err = c.playerList(ctx, args)
+	case "player create":
+		err = c.playerCreate(ctx, args)
+	case "player set-admin":
+		err = c.playerSetAdmin(ctx, args)
+	case "player reset-password":
+		err = c.playerResetPassword(ctx, args)
+	case "played-games stuck":
+		err = c.playedGamesStuck(ctx, args)
+	case "played-games close":
+		err = c.playedGamesClose(ctx, args)
+	case "points recompute":
+		err = c.pointsRecompute(ctx, args)
+	case "migrate status":
+		err = c.migrateStatus(ctx)
+	case "migrate up":
+		err = c.migrateUp(ctx, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type ctl struct {
+	pool *pgxpool.Pool
+	out  output
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/envutil"
+)
+
+type migrationStatus struct {
+	Version int64 `json:"version"`
+	Latest  int64 `json:"latest"`
+}
+
+func (c *ctl) migrateStatus(ctx context.Context) error {
+	migrator, err := c.migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return c.writeMigrationStatus(ctx, migrator)
+}
+
+func (c *ctl) migrateUp(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate up", flag.ExitOnError)
+	to := fs.Int64("to", 0, "migrate up to the version, the latest by default")
+	fs.Parse(args)
+
+	migrator, err := c.migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if *to != 0 {
+		err = migrator.UpTo(ctx, *to)
+	} else {
+		err = migrator.Up(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	return c.writeMigrationStatus(ctx, migrator)
+}
+
+func (c *ctl) migrator() (*db.Migrator, error) {
+	migrator, err := db.NewMigrator(c.pool, envutil.GetOrDefault("GOOSE_TABLE", db.DefaultMigrationsTable))
+	if err != nil {
+		return nil, fmt.Errorf("migrator: %w", err)
+	}
+	return migrator, nil
+}
+
+func (c *ctl) writeMigrationStatus(ctx context.Context, migrator *db.Migrator) error {
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	status := migrationStatus{Version: version, Latest: migrator.Latest()}
+	return c.out.write(status, []string{"VERSION", "LATEST"}, [][]string{{
+		strconv.FormatInt(status.Version, 10),
+		strconv.FormatInt(status.Latest, 10),
+	}})
+}
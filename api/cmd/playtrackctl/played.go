@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/challenge"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/domain/webhook"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/envutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+const defaultStuckAfter = 30 * 24 * time.Hour
+
+func (c *ctl) playedGamesStuck(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("played-games stuck", flag.ExitOnError)
+	older := fs.Duration("older", defaultStuckAfter, "in progress for longer than")
+	fs.Parse(args)
+
+	stuck, err := player.NewPGPlayedRepository(c.pool).FindStuck(ctx, time.Now().Add(-*older))
+	if err != nil {
+		return fmt.Errorf("played games find: %w", err)
+	}
+	return c.writePlayedGames(stuck)
+}
+
+// playedGamesClose finishes stuck played games the way their players would,
+// so streaks, the ledger, challenges and webhooks see the change
+func (c *ctl) playedGamesClose(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("played-games close", flag.ExitOnError)
+	older := fs.Duration("older", defaultStuckAfter, "in progress for longer than")
+	id := fs.Int("id", 0, "close only this played game")
+	status := fs.String("status", string(player.PlayedGameStatusDropped), "dropped or completed")
+	fs.Parse(args)
+
+	newStatus := player.PlayedGameStatus(*status)
+	if newStatus != player.PlayedGameStatusDropped && newStatus != player.PlayedGameStatusCompleted {
+		return fmt.Errorf("status %q cannot close a played game", *status)
+	}
+
+	playedGameRepository := player.NewPGPlayedRepository(c.pool)
+	stuck, err := playedGameRepository.FindStuck(ctx, time.Now().Add(-*older))
+	if err != nil {
+		return fmt.Errorf("played games find: %w", err)
+	}
+
+	handler := c.playerHandler()
+	closed := make([]player.PlayedGame, 0, len(stuck))
+	for _, pg := range stuck {
+		if *id != 0 && pg.ID != *id {
+			continue
+		}
+
+		// the operator acts as an admin on behalf of the owner
+		ctx := ctxutil.SetPlayer(ctx, ctxutil.CtxPlayer{ID: pg.PlayerID, IsAdmin: true})
+		req := player.RequestUpdatePlayedGame{PlayerID: pg.PlayerID, GameID: pg.ID}
+		req.Body.Status = &newStatus
+		if _, err := handler.UpdatePlayedGame(ctx, &req); err != nil {
+			return fmt.Errorf("played game %d close: %w", pg.ID, err)
+		}
+
+		found, err := playedGameRepository.FindOne(ctx, pg.PlayerID, pg.ID)
+		if err != nil {
+			return fmt.Errorf("played game %d find: %w", pg.ID, err)
+		}
+		closed = append(closed, *found)
+	}
+	if *id != 0 && len(closed) == 0 {
+		return fmt.Errorf("played game %d is not in progress for longer than %v", *id, *older)
+	}
+	return c.writePlayedGames(closed)
+}
+
+// playerHandler is wired as in the api server, events reach webhooks and
+// live streams of the api replicas only with EVENTS_PG_NOTIFY
+func (c *ctl) playerHandler() *player.Handler {
+	gameRepository := game.NewPGRepository(c.pool)
+	playedGameRepository := player.NewPGPlayedRepository(c.pool)
+	ledgerRepository := player.NewPGLedgerRepository(c.pool)
+	txManager := db.NewTxManager(c.pool)
+
+	publishers := event.Publishers{webhook.NewPGRepository(c.pool)}
+	if envutil.GetOrDefault("EVENTS_PG_NOTIFY", "0") == "1" {
+		publishers = append(publishers, event.NewPGNotifier(c.pool))
+	}
+	challengeResolver := challenge.NewResolver(
+		challenge.NewPGRepository(c.pool),
+		playedGameRepository,
+		ledgerRepository,
+		txManager,
+		publishers,
+		0,
+	)
+	achievementTracker := achievement.NewTracker(
+		achievement.NewPGRepository(c.pool),
+		playedGameRepository,
+		gameRepository,
+		publishers,
+	)
+
+	return player.NewHandler(
+		player.NewPGRepository(c.pool),
+		gameRepository,
+		playedGameRepository,
+		backlog.NewPGRepository(c.pool),
+		player.NewPGStreakRepository(c.pool),
+		player.NewPGAdjustmentRepository(c.pool),
+		ledgerRepository,
+		txManager,
+		append(publishers, challengeResolver, achievementTracker),
+		player.Options{Streak: player.StreakRules{
+			BonusAfter:  envutil.GetIntOrDefault("STREAK_BONUS_AFTER", player.DefaultStreakRules.BonusAfter),
+			BonusPoints: envutil.GetIntOrDefault("STREAK_BONUS_POINTS", player.DefaultStreakRules.BonusPoints),
+			DecayAfter:  envutil.GetIntOrDefault("STREAK_DECAY_AFTER", player.DefaultStreakRules.DecayAfter),
+		}},
+	)
+}
+
+func (c *ctl) writePlayedGames(played []player.PlayedGame) error {
+	rows := make([][]string, 0, len(played))
+	for _, pg := range played {
+		rows = append(rows, []string{
+			strconv.Itoa(pg.ID),
+			pg.PlayerID,
+			strconv.Itoa(pg.GameID),
+			string(pg.Status),
+			strconv.Itoa(pg.Points),
+			formatTime(&pg.StartedAt),
+			formatTime(pg.CompletedAt),
+		})
+	}
+	return c.out.write(played, []string{"ID", "PLAYER", "GAME", "STATUS", "POINTS", "STARTED", "COMPLETED"}, rows)
+}
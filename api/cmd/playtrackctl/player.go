@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/admin"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/password"
+)
+
+func (c *ctl) playerList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("player list", flag.ExitOnError)
+	username := fs.String("username", "", "part of the username")
+	role := fs.String("role", "", "admin or player")
+	state := fs.String("state", "", "active, deactivated or banned")
+	fs.Parse(args)
+
+	players, err := player.NewPGRepository(c.pool).FindByFilter(ctx, player.PlayerFilter{
+		Username: *username,
+		Role:     *role,
+		State:    *state,
+	})
+	if err != nil {
+		return fmt.Errorf("players find: %w", err)
+	}
+	return c.writePlayers(players...)
+}
+
+func (c *ctl) playerCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("player create", flag.ExitOnError)
+	username := fs.String("username", "", "username of the player")
+	email := fs.String("email", "", "email of the player, it is trusted as verified")
+	isAdmin := fs.Bool("admin", false, "create an admin")
+	pass := fs.String("password", "", "password, read from stdin when empty")
+	fs.Parse(args)
+
+	plain, err := readPassword(*pass)
+	if err != nil {
+		return err
+	}
+
+	nPlayer := player.Player{
+		Username: *username,
+		Password: plain,
+		IsAdmin:  *isAdmin,
+	}
+	if *email != "" {
+		nPlayer.Email = email
+		nPlayer.EmailVerified = true
+	}
+	nPlayer.Normalize()
+	if err := nPlayer.Valid(); err != nil {
+		return fmt.Errorf("player is not valid: %w", err)
+	}
+	if nPlayer.Password, err = password.Hash(plain); err != nil {
+		return fmt.Errorf("password hash: %w", err)
+	}
+
+	repository := player.NewPGRepository(c.pool)
+	id, err := repository.Insert(ctx, &nPlayer)
+	if err != nil {
+		return fmt.Errorf("player insert: %w", err)
+	}
+	return c.writePlayer(ctx, repository, id)
+}
+
+func (c *ctl) playerSetAdmin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("player set-admin", flag.ExitOnError)
+	username := fs.String("username", "", "username of the player")
+	isAdmin := fs.Bool("admin", true, "promote with true, demote with false")
+	fs.Parse(args)
+
+	repository := player.NewPGRepository(c.pool)
+	found, err := findByUsername(ctx, repository, *username)
+	if err != nil {
+		return err
+	}
+	if _, err := repository.Update(ctx, &player.PlayerUpdate{ID: found.ID, IsAdmin: isAdmin}); err != nil {
+		return fmt.Errorf("player update: %w", err)
+	}
+	return c.writePlayer(ctx, repository, found.ID)
+}
+
+// playerResetPassword sets the password, ends sessions and unlocks the player
+func (c *ctl) playerResetPassword(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("player reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "username of the player")
+	pass := fs.String("password", "", "new password, read from stdin when empty")
+	mustChange := fs.Bool("must-change", true, "require the player to change the password after login")
+	fs.Parse(args)
+
+	repository := player.NewPGRepository(c.pool)
+	found, err := findByUsername(ctx, repository, *username)
+	if err != nil {
+		return err
+	}
+	plain, err := readPassword(*pass)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	nPlayer := player.PlayerUpdate{
+		ID:                 found.ID,
+		Password:           &plain,
+		MustChangePassword: mustChange,
+		TokensValidAfter:   &now,
+	}
+	if err := nPlayer.Valid(); err != nil {
+		return fmt.Errorf("password is not valid: %w", err)
+	}
+	hashed, err := password.Hash(plain)
+	if err != nil {
+		return fmt.Errorf("password hash: %w", err)
+	}
+	nPlayer.Password = &hashed
+
+	if _, err := repository.Update(ctx, &nPlayer); err != nil {
+		return fmt.Errorf("player update: %w", err)
+	}
+	if err := repository.ResetFailedLogins(ctx, found.ID); err != nil {
+		return fmt.Errorf("reset failed logins: %w", err)
+	}
+	return c.writePlayer(ctx, repository, found.ID)
+}
+
+func (c *ctl) writePlayer(ctx context.Context, repository *player.PGRepository, id string) error {
+	found, err := repository.FindOne(ctx, id)
+	if err != nil {
+		return fmt.Errorf("player find: %w", err)
+	}
+	return c.writePlayers(*found)
+}
+
+func (c *ctl) writePlayers(players ...player.Player) error {
+	now := time.Now()
+	items := make([]admin.ManagedPlayer, 0, len(players))
+	rows := make([][]string, 0, len(players))
+	for _, p := range players {
+		item := admin.NewPlayer(&p, now)
+		items = append(items, item)
+
+		state := player.StateFilterActive
+		if item.Suspension != nil {
+			state = string(item.Suspension.Kind) + " until " + formatTime(item.Suspension.Until)
+		}
+		rows = append(rows, []string{
+			p.ID,
+			p.Username,
+			formatOptional(p.Email),
+			strconv.FormatBool(p.IsAdmin),
+			state,
+			formatTime(&p.CreatedAt),
+		})
+	}
+	return c.out.write(items, []string{"ID", "USERNAME", "EMAIL", "ADMIN", "STATE", "CREATED"}, rows)
+}
+
+func findByUsername(ctx context.Context, repository *player.PGRepository, username string) (*player.Player, error) {
+	if username == "" {
+		return nil, errors.New("-username is required")
+	}
+	found, err := repository.FindOneByUsername(ctx, username)
+	if errors.Is(err, player.ErrPlayerNotFound) {
+		return nil, fmt.Errorf("player %q is not found", username)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("player find: %w", err)
+	}
+	return found, nil
+}
+
+// readPassword returns the password of the flag or the first line of stdin,
+// so passwords do not have to be in the shell history
+func readPassword(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+// pointsRecompute checks the points ledger of all players against their played
+// games and corrects mismatching ledgers, with -verify it fails on mismatches instead
+func (c *ctl) pointsRecompute(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("points recompute", flag.ExitOnError)
+	verify := fs.Bool("verify", false, "only report mismatches, fail if there are any")
+	fs.Parse(args)
+
+	players, err := player.NewPGRepository(c.pool).FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("players find: %w", err)
+	}
+	rebuilder := player.NewLedgerRebuilder(
+		player.NewPGPlayedRepository(c.pool),
+		player.NewPGLedgerRepository(c.pool),
+		db.NewTxManager(c.pool),
+	)
+
+	checks := make([]player.LedgerCheck, 0, len(players))
+	rows := make([][]string, 0, len(players))
+	mismatched := 0
+	for _, p := range players {
+		check, err := rebuilder.Rebuild(ctx, &p, *verify)
+		if err != nil {
+			return fmt.Errorf("player %v: %w", p.ID, err)
+		}
+		if len(check.Mismatches) > 0 {
+			mismatched++
+		}
+		checks = append(checks, *check)
+		rows = append(rows, []string{
+			p.Username,
+			strconv.Itoa(check.PlayedGames),
+			strconv.Itoa(len(check.Mismatches)),
+			strconv.FormatBool(check.Rebuilt),
+		})
+	}
+
+	if err := c.out.write(checks, []string{"USERNAME", "PLAYED", "MISMATCHES", "REBUILT"}, rows); err != nil {
+		return err
+	}
+	if *verify && mismatched > 0 {
+		return fmt.Errorf("ledger does not match played games of %d players", mismatched)
+	}
+	return nil
+}
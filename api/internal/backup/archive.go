@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+const (
+	// FormatVersion is increased when the layout of the archive changes
+	FormatVersion = 1
+
+	manifestName = "manifest.json"
+	tablesDir    = "tables"
+)
+
+var (
+	ErrFormatVersion = fmt.Errorf("archive format is not supported, expected version %d", FormatVersion)
+	ErrChecksum      = errors.New("archive checksum mismatch")
+	ErrNoManifest    = errors.New("archive has no manifest")
+)
+
+// Manifest describes an archive, SchemaVersion is the migration version of the database
+// the archive was taken from
+type Manifest struct {
+	FormatVersion     int          `json:"format_version"`
+	SchemaVersion     int64        `json:"schema_version"`
+	CreatedAt         time.Time    `json:"created_at"`
+	PasswordsExcluded bool         `json:"passwords_excluded"`
+	Tables            []TableEntry `json:"tables"`
+}
+
+type TableEntry struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive is a backup of all tables, each row is a json object keyed by column names
+type Archive struct {
+	Manifest Manifest
+	Tables   map[string][]json.RawMessage
+}
+
+// Write writes the archive as gzipped tar with the manifest and a json lines file per table
+func (a *Archive) Write(w io.Writer) error {
+	files := make(map[string][]byte, len(a.Manifest.Tables))
+	manifest := a.Manifest
+	manifest.FormatVersion = FormatVersion
+	manifest.Tables = make([]TableEntry, 0, len(a.Manifest.Tables))
+
+	for _, entry := range a.Manifest.Tables {
+		var buf bytes.Buffer
+		for _, row := range a.Tables[entry.Name] {
+			buf.Write(row)
+			buf.WriteByte('\n')
+		}
+		files[entry.Name] = buf.Bytes()
+		manifest.Tables = append(manifest.Tables, TableEntry{
+			Name:   entry.Name,
+			Rows:   len(a.Tables[entry.Name]),
+			SHA256: checksum(buf.Bytes()),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeFile(tw, manifestName, manifestData, manifest.CreatedAt); err != nil {
+		return err
+	}
+	for _, entry := range manifest.Tables {
+		if err := writeFile(tw, tableFile(entry.Name), files[entry.Name], manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	a.Manifest = manifest
+	return nil
+}
+
+// Read reads an archive and verifies its format version and checksums of tables
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("archive %v: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	manifestData, ok := files[manifestName]
+	if !ok {
+		return nil, ErrNoManifest
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, ErrFormatVersion
+	}
+
+	archive := Archive{
+		Manifest: manifest,
+		Tables:   make(map[string][]json.RawMessage, len(manifest.Tables)),
+	}
+	for _, entry := range manifest.Tables {
+		data := files[tableFile(entry.Name)]
+		if checksum(data) != entry.SHA256 {
+			return nil, fmt.Errorf("table %v: %w", entry.Name, ErrChecksum)
+		}
+
+		rows, err := readRows(data)
+		if err != nil {
+			return nil, fmt.Errorf("table %v: %w", entry.Name, err)
+		}
+		if len(rows) != entry.Rows {
+			return nil, fmt.Errorf("table %v: expected %d rows, got %d", entry.Name, entry.Rows, len(rows))
+		}
+		archive.Tables[entry.Name] = rows
+	}
+	return &archive, nil
+}
+
+func readRows(data []byte) ([]json.RawMessage, error) {
+	rows := make([]json.RawMessage, 0)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, errors.New("row is not valid json")
+		}
+		rows = append(rows, json.RawMessage(bytes.Clone(line)))
+	}
+	return rows, sc.Err()
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func tableFile(name string) string {
+	return path.Join(tablesDir, name+".jsonl")
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func testArchive() *Archive {
+	return &Archive{
+		Manifest: Manifest{
+			SchemaVersion: 14,
+			CreatedAt:     time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			Tables:        []TableEntry{{Name: "game"}, {Name: "player"}, {Name: "played_game"}},
+		},
+		Tables: map[string][]json.RawMessage{
+			"game": {
+				json.RawMessage(`{"id": 1, "title": "Celeste", "points": 3}`),
+				json.RawMessage(`{"id": 2, "title": "Hades", "points": 6}`),
+			},
+			"player": {
+				json.RawMessage(`{"id": "2b0fb1ec-7e6b-4a65-9d26-d25f4b9b49a7", "username": "lardira"}`),
+			},
+			"played_game": {},
+		},
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	archive := testArchive()
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf))
+
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, archive.Manifest, read.Manifest)
+	assert.Equal(t, FormatVersion, read.Manifest.FormatVersion)
+	assert.Equal(t, 2, read.Manifest.Tables[0].Rows)
+	assert.Equal(t, archive.Tables["game"], read.Tables["game"])
+	assert.Equal(t, archive.Tables["player"], read.Tables["player"])
+	assert.Equal(t, 0, len(read.Tables["played_game"]))
+}
+
+func TestRead_Tampered(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testArchive().Write(&buf))
+
+	files := untar(t, buf.Bytes())
+	files["tables/game.jsonl"] = bytes.Replace(files["tables/game.jsonl"], []byte("Celeste"), []byte("Celesta"), 1)
+
+	_, err := Read(bytes.NewReader(retar(t, files)))
+	assert.IsError(t, err, ErrChecksum)
+}
+
+func TestRead_FormatVersion(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testArchive().Write(&buf))
+
+	files := untar(t, buf.Bytes())
+	files[manifestName] = bytes.Replace(files[manifestName], []byte(`"format_version": 1`), []byte(`"format_version": 99`), 1)
+
+	_, err := Read(bytes.NewReader(retar(t, files)))
+	assert.IsError(t, err, ErrFormatVersion)
+}
+
+func TestRead_NoManifest(t *testing.T) {
+	_, err := Read(bytes.NewReader(retar(t, map[string][]byte{"tables/game.jsonl": nil})))
+	assert.IsError(t, err, ErrNoManifest)
+}
+
+func untar(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		assert.NoError(t, err)
+		files[hdr.Name], err = io.ReadAll(tr)
+		assert.NoError(t, err)
+	}
+}
+
+func retar(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		assert.NoError(t, writeFile(tw, name, data, time.Now()))
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
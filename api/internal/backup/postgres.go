@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	restoreBatchSize = 500
+
+	passwordTable = "player"
+)
+
+// Tables are backed up and restored in this order, referenced tables go first.
+// A table missing here fails the backup so new tables are not skipped silently.
+var Tables = []string{
+	"game",
+	"player",
+	"played_game",
+	"played_game_deduplicated",
+	"player_streak",
+	"points_adjustment",
+	"points_ledger",
+	"backlog_item",
+	"challenge",
+	"challenge_participant",
+	"achievement",
+	"auth_token",
+	"identity",
+	"oauth_state",
+	"api_token",
+	"webhook",
+	"webhook_delivery",
+	"discord_link",
+}
+
+var (
+	ErrNotEmpty     = errors.New("database is not empty")
+	ErrSchemaNewer  = errors.New("database schema is newer than the archive")
+	ErrUnknownTable = errors.New("table is not known to backup")
+	ErrArchiveNewer = errors.New("archive schema is newer than migrations of this build")
+)
+
+type Options struct {
+	// MigrationsTable is the goose version table, it is not backed up
+	MigrationsTable string
+	// ExcludePasswords replaces password hashes with empty values, players of
+	// such backup have to reset their passwords
+	ExcludePasswords bool
+}
+
+// Backup reads all tables in a single read only transaction
+func Backup(ctx context.Context, pool *pgxpool.Pool, opts Options) (*Archive, error) {
+	migrator, err := db.NewMigrator(pool, opts.MigrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	defer migrator.Close()
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("schema version: %w", err)
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tables, err := schemaTables(ctx, tx, migrationsTable(opts))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if !slices.Contains(Tables, t) {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownTable, t)
+		}
+	}
+
+	archive := Archive{
+		Manifest: Manifest{
+			SchemaVersion:     version,
+			CreatedAt:         time.Now().UTC(),
+			PasswordsExcluded: opts.ExcludePasswords,
+		},
+		Tables: make(map[string][]json.RawMessage, len(tables)),
+	}
+	for _, t := range Tables {
+		if !slices.Contains(tables, t) {
+			continue
+		}
+		rows, err := dumpTable(ctx, tx, t, opts.ExcludePasswords && t == passwordTable)
+		if err != nil {
+			return nil, fmt.Errorf("table %v: %w", t, err)
+		}
+		archive.Manifest.Tables = append(archive.Manifest.Tables, TableEntry{Name: t})
+		archive.Tables[t] = rows
+	}
+	return &archive, nil
+}
+
+// Restore migrates an empty database to the schema version of the archive, inserts
+// all rows in a single transaction and then applies the rest of migrations
+func Restore(ctx context.Context, pool *pgxpool.Pool, archive *Archive, opts Options) error {
+	for name := range archive.Tables {
+		if !slices.Contains(Tables, name) {
+			return fmt.Errorf("%w: %v", ErrUnknownTable, name)
+		}
+	}
+
+	migrator, err := db.NewMigrator(pool, opts.MigrationsTable)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	if version > archive.Manifest.SchemaVersion {
+		return ErrSchemaNewer
+	}
+	if archive.Manifest.SchemaVersion > migrator.Latest() {
+		return ErrArchiveNewer
+	}
+	if err := migrator.UpTo(ctx, archive.Manifest.SchemaVersion); err != nil {
+		return fmt.Errorf("migrate to %d: %w", archive.Manifest.SchemaVersion, err)
+	}
+
+	err = pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		for _, t := range Tables {
+			rows, ok := archive.Tables[t]
+			if !ok {
+				continue
+			}
+			if err := checkEmpty(ctx, tx, t); err != nil {
+				return err
+			}
+			if err := restoreTable(ctx, tx, t, rows); err != nil {
+				return fmt.Errorf("table %v: %w", t, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	return nil
+}
+
+// schemaTables lists tables of the current schema except the migrations table
+func schemaTables(ctx context.Context, tx pgx.Tx, migrations string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' AND table_name <> $1
+		ORDER BY table_name`,
+		migrations,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func dumpTable(ctx context.Context, tx pgx.Tx, table string, excludePassword bool) ([]json.RawMessage, error) {
+	column := "to_jsonb(t)"
+	if excludePassword {
+		column = `jsonb_set(to_jsonb(t), '{password}', '""')`
+	}
+
+	ident := pgx.Identifier{table}.Sanitize()
+	rows, err := tx.Query(ctx, fmt.Sprintf("SELECT %s FROM %s t ORDER BY t.id", column, ident))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (json.RawMessage, error) {
+		var data []byte
+		err := row.Scan(&data)
+		return json.RawMessage(data), err
+	})
+}
+
+func checkEmpty(ctx context.Context, tx pgx.Tx, table string) error {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", pgx.Identifier{table}.Sanitize())
+	if err := tx.QueryRow(ctx, query).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: table %v has rows", ErrNotEmpty, table)
+	}
+	return nil
+}
+
+// restoreTable lets postgres convert json rows back to the column types and
+// moves the serial sequence past restored ids
+func restoreTable(ctx context.Context, tx pgx.Tx, table string, rows []json.RawMessage) error {
+	ident := pgx.Identifier{table}.Sanitize()
+	insert := fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM jsonb_populate_recordset(NULL::%[1]s, $1::jsonb)", ident)
+
+	for batch := range slices.Chunk(rows, restoreBatchSize) {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, insert, string(data)); err != nil {
+			return err
+		}
+	}
+
+	var sequence *string
+	if err := tx.QueryRow(ctx, "SELECT pg_get_serial_sequence($1, 'id')", table).Scan(&sequence); err != nil {
+		return err
+	}
+	if sequence == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf(
+		"SELECT setval($1::regclass, COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM %s",
+		ident,
+	), *sequence)
+	return err
+}
+
+func migrationsTable(opts Options) string {
+	if opts.MigrationsTable == "" {
+		return db.DefaultMigrationsTable
+	}
+	return opts.MigrationsTable
+}
@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+// testSchema connects to TEST_DB_URL with a fresh schema which is dropped after the test
+func testSchema(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	schema := "backup_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	admin, err := pgx.Connect(t.Context(), dbURL)
+	assert.NoError(t, err)
+	_, err = admin.Exec(t.Context(), "CREATE SCHEMA "+schema)
+	assert.NoError(t, err)
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	assert.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(t.Context(), config)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close(context.Background())
+	})
+	return pool
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	ctx := t.Context()
+	src := testSchema(t)
+	dst := testSchema(t)
+
+	migrator, err := db.NewMigrator(src, "")
+	assert.NoError(t, err)
+	defer migrator.Close()
+	assert.NoError(t, migrator.Up(ctx))
+
+	playerID := uuid.NewString()
+	for _, query := range []string{
+		`INSERT INTO game (points, hours_to_beat, title) VALUES (3, 8, 'Celeste'), (6, 20, 'Hades')`,
+		fmt.Sprintf(`INSERT INTO player (id, username, password, is_admin) VALUES ('%s', 'lardira', 'hash', true)`, playerID),
+		fmt.Sprintf(`INSERT INTO played_game (player_id, game_id, status, points, comment, rating, completed_at, play_time)
+			VALUES ('%s', 1, 'completed', 3, 'fun', 90, NOW(), '20 hours'), ('%s', 2, 'in_progress', 6, NULL, NULL, NULL, NULL)`,
+			playerID, playerID),
+		`INSERT INTO webhook (url, secret, events) VALUES ('https://example.com', 'secret', ARRAY['game.created'])`,
+	} {
+		_, err := src.Exec(ctx, query)
+		assert.NoError(t, err)
+	}
+
+	archive, err := Backup(ctx, src, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), archive.Manifest.SchemaVersion)
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf))
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+
+	assert.NoError(t, Restore(ctx, dst, read, Options{}))
+
+	restored, err := Backup(ctx, dst, Options{})
+	assert.NoError(t, err)
+	for _, entry := range archive.Manifest.Tables {
+		assert.Equal(t, normalize(t, archive.Tables[entry.Name]), normalize(t, restored.Tables[entry.Name]))
+	}
+
+	// sequences continue after restored ids
+	var id int
+	err = dst.QueryRow(ctx, `INSERT INTO game (points, hours_to_beat, title) VALUES (1, 1, 'Next') RETURNING id`).Scan(&id)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	// restoring twice is refused
+	assert.IsError(t, Restore(ctx, dst, read, Options{}), ErrNotEmpty)
+}
+
+func TestBackup_ExcludePasswords(t *testing.T) {
+	ctx := t.Context()
+	src := testSchema(t)
+
+	migrator, err := db.NewMigrator(src, "")
+	assert.NoError(t, err)
+	defer migrator.Close()
+	assert.NoError(t, migrator.Up(ctx))
+
+	_, err = src.Exec(ctx, `INSERT INTO player (id, username, password) VALUES ($1, 'lardira', 'hash')`, uuid.NewString())
+	assert.NoError(t, err)
+
+	archive, err := Backup(ctx, src, Options{ExcludePasswords: true})
+	assert.NoError(t, err)
+	assert.True(t, archive.Manifest.PasswordsExcluded)
+
+	var row map[string]any
+	assert.NoError(t, json.Unmarshal(archive.Tables["player"][0], &row))
+	assert.Equal(t, "", row["password"])
+}
+
+func normalize(t *testing.T, rows []json.RawMessage) []map[string]any {
+	t.Helper()
+
+	out := make([]map[string]any, 0, len(rows))
+	for _, r := range rows {
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(r, &m))
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	// DefaultMigrationsTable matches GOOSE_TABLE of the env template
+	DefaultMigrationsTable = "_goose"
+)
+
+var (
+	//go:embed migrations/*.sql
+	migrationsFS embed.FS
+
+	// sqliteMigrationsFS only has tables of the repositories implemented for sqlite
+	//go:embed migrations_sqlite/*.sql
+	sqliteMigrationsFS embed.FS
+)
+
+// Migrator applies the migrations embedded into the binary, they are the same
+// files the goose cli runs from internal/db/migrations
+type Migrator struct {
+	provider *goose.Provider
+	close    func() error
+}
+
+func NewMigrator(pool *pgxpool.Pool, table string) (*Migrator, error) {
+	db := stdlib.OpenDBFromPool(pool)
+	m, err := newMigrator(goose.DialectPostgres, db, migrationsFS, "migrations", table)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.close = db.Close
+	return m, nil
+}
+
+// NewSQLiteMigrator applies the sqlite migrations from internal/db/migrations_sqlite,
+// closing it leaves sqlDB open
+func NewSQLiteMigrator(sqlDB *sql.DB, table string) (*Migrator, error) {
+	return newMigrator(goose.DialectSQLite3, sqlDB, sqliteMigrationsFS, "migrations_sqlite", table)
+}
+
+func newMigrator(dialect goose.Dialect, db *sql.DB, fsys embed.FS, dir, table string) (*Migrator, error) {
+	if table == "" {
+		table = DefaultMigrationsTable
+	}
+
+	migrations, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(dialect, db, migrations, goose.WithTableName(table))
+	if err != nil {
+		return nil, fmt.Errorf("migrations: %w", err)
+	}
+
+	return &Migrator{
+		provider: provider,
+		close:    func() error { return nil },
+	}, nil
+}
+
+// Version returns the applied version of the schema, 0 for an empty database
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	return m.provider.GetDBVersion(ctx)
+}
+
+// Latest returns the version of the last embedded migration
+func (m *Migrator) Latest() int64 {
+	sources := m.provider.ListSources()
+	if len(sources) == 0 {
+		return 0
+	}
+	return sources[len(sources)-1].Version
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	_, err := m.provider.Up(ctx)
+	return err
+}
+
+func (m *Migrator) UpTo(ctx context.Context, version int64) error {
+	_, err := m.provider.UpTo(ctx, version)
+	return err
+}
+
+// DownTo rolls back migrations newer than version
+func (m *Migrator) DownTo(ctx context.Context, version int64) error {
+	_, err := m.provider.DownTo(ctx, version)
+	return err
+}
+
+func (m *Migrator) Close() error {
+	return m.close()
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+type playedRow struct {
+	ID        int
+	Status    string
+	Points    int
+	Completed bool
+}
+
+// TestSQLiteMigrateDeduplicated covers unfinished games rerolled by the unique
+// index migration, rolling it back restores them
+func TestSQLiteMigrateDeduplicated(t *testing.T) {
+	const before, dedupe = 13, 14
+	ctx := t.Context()
+
+	sqlDB, err := NewSQLite(ctx, filepath.Join(t.TempDir(), "playtrack.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := NewSQLiteMigrator(sqlDB, "")
+	assert.NoError(t, err)
+	defer migrator.Close()
+	assert.NoError(t, migrator.UpTo(ctx, before))
+
+	_, err = sqlDB.ExecContext(ctx, `
+		INSERT INTO game (id, points, title) VALUES (1, 3, 'Celeste');
+		INSERT INTO player (id, username, username_key, password) VALUES ('p', 'lardira', 'lardira', 'hash');
+		INSERT INTO played_game (id, player_id, game_id, points, status, started_at) VALUES
+			(1, 'p', 1, 3, 'added', '2026-01-01 00:00:00.000000'),
+			(2, 'p', 1, 3, 'in_progress', '2026-01-02 00:00:00.000000'),
+			(3, 'p', 1, 3, 'in_progress', '2026-01-03 00:00:00.000000');`)
+	assert.NoError(t, err)
+
+	played := func() []playedRow {
+		t.Helper()
+		rows, err := sqlDB.QueryContext(ctx, "SELECT id, status, points, completed_at IS NOT NULL FROM played_game ORDER BY id")
+		assert.NoError(t, err)
+		defer rows.Close()
+
+		var out []playedRow
+		for rows.Next() {
+			var r playedRow
+			assert.NoError(t, rows.Scan(&r.ID, &r.Status, &r.Points, &r.Completed))
+			out = append(out, r)
+		}
+		assert.NoError(t, rows.Err())
+		return out
+	}
+	original := played()
+
+	assert.NoError(t, migrator.UpTo(ctx, dedupe))
+	assert.Equal(t, []playedRow{
+		{ID: 1, Status: "rerolled", Completed: true},
+		{ID: 2, Status: "rerolled", Completed: true},
+		{ID: 3, Status: "in_progress", Points: 3},
+	}, played())
+
+	assert.NoError(t, migrator.DownTo(ctx, before))
+	assert.Equal(t, original, played())
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE player
+    ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN tokens_valid_after TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE player
+    DROP COLUMN must_change_password,
+    DROP COLUMN tokens_valid_after;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE backlog_item(
+    id SERIAL PRIMARY KEY,
+    player_id UUID NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    game_id INT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    note TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (player_id, game_id)
+);
+CREATE INDEX backlog_item_player_position_idx ON backlog_item(player_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE backlog_item;
+-- +goose StatementEnd
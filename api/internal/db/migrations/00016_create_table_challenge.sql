@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE challenge_status AS ENUM ('pending', 'active', 'finished', 'declined', 'expired', 'cancelled');
+CREATE TYPE challenge_participant_status AS ENUM ('invited', 'accepted', 'declined', 'expired');
+
+CREATE TABLE challenge(
+    id SERIAL PRIMARY KEY,
+    challenger_id UUID NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    game_id INT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL,
+    bonus INT NOT NULL,
+    status challenge_status NOT NULL DEFAULT 'pending',
+    winner_id UUID NULL REFERENCES player(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP NULL
+);
+
+CREATE TABLE challenge_participant(
+    id SERIAL PRIMARY KEY,
+    challenge_id INT NOT NULL REFERENCES challenge(id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    status challenge_participant_status NOT NULL DEFAULT 'invited',
+    played_game_id INT NULL REFERENCES played_game(id) ON DELETE SET NULL,
+    UNIQUE (challenge_id, player_id)
+);
+CREATE INDEX challenge_participant_player_idx ON challenge_participant(player_id);
+CREATE INDEX challenge_participant_played_game_idx ON challenge_participant(played_game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE challenge_participant;
+DROP TABLE challenge;
+DROP TYPE challenge_participant_status;
+DROP TYPE challenge_status;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE player_streak(
+    id SERIAL PRIMARY KEY,
+    player_id UUID NOT NULL UNIQUE REFERENCES player(id) ON DELETE CASCADE,
+    completions INT NOT NULL DEFAULT 0,
+    drops INT NOT NULL DEFAULT 0,
+    penalty INT NOT NULL DEFAULT 0,
+    best_completions INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE player_streak;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE points_adjustment(
+    id SERIAL PRIMARY KEY,
+    played_game_id INT NOT NULL REFERENCES played_game(id) ON DELETE CASCADE,
+    player_id UUID NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    adjusted_by UUID REFERENCES player(id) ON DELETE SET NULL,
+    points_before INT NOT NULL,
+    points INT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE points_adjustment;
+-- +goose StatementEnd
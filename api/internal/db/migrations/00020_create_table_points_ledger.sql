@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE points_ledger_reason AS ENUM (
+    'completion',
+    'streak_bonus',
+    'drop_penalty',
+    'drop_stacking',
+    'adjustment',
+    'challenge_bonus',
+    'import',
+    'history'
+);
+
+CREATE TABLE points_ledger(
+    id SERIAL PRIMARY KEY,
+    player_id UUID NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    played_game_id INT NOT NULL REFERENCES played_game(id) ON DELETE CASCADE,
+    delta INT NOT NULL,
+    reason points_ledger_reason NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX points_ledger_player_idx ON points_ledger(player_id);
+CREATE INDEX points_ledger_played_game_idx ON points_ledger(played_game_id);
+
+-- points of games finished before the ledger
+INSERT INTO points_ledger(player_id, played_game_id, delta, reason, created_at)
+SELECT player_id, id, points, 'history', COALESCE(completed_at, started_at)
+FROM played_game
+WHERE status IN ('completed', 'dropped', 'rerolled') AND points <> 0
+ORDER BY COALESCE(completed_at, started_at), id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE points_ledger;
+DROP TYPE points_ledger_reason;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE player_suspension_kind AS ENUM ('deactivated', 'banned');
+
+ALTER TABLE player
+    ADD COLUMN suspension_kind player_suspension_kind NULL,
+    ADD COLUMN suspension_reason TEXT NULL,
+    ADD COLUMN suspended_at TIMESTAMP NULL,
+    ADD COLUMN suspended_until TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE player
+    DROP COLUMN suspension_kind,
+    DROP COLUMN suspension_reason,
+    DROP COLUMN suspended_at,
+    DROP COLUMN suspended_until;
+
+DROP TYPE player_suspension_kind;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- usernames are stored composed (NFC), the same way the api normalizes them
+UPDATE player
+SET username = normalize(username, NFC)
+WHERE username IS NOT NFC NORMALIZED;
+
+-- players who share a username regardless of the case keep it in order of
+-- registration, the later ones get a part of their id appended
+UPDATE player p
+SET username = p.username || '_' || left(p.id::text, 8)
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY lower(username) ORDER BY created_at, id) AS n
+    FROM player
+) d
+WHERE d.id = p.id AND d.n > 1;
+
+-- an email shared regardless of the case stays with the verified or the oldest player,
+-- the others have to set it again
+UPDATE player p
+SET email = NULL, email_verified = false
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY lower(email) ORDER BY email_verified DESC, created_at, id) AS n
+    FROM player
+    WHERE email IS NOT NULL
+) d
+WHERE d.id = p.id AND d.n > 1;
+
+ALTER TABLE player
+    DROP CONSTRAINT player_email_key;
+
+CREATE UNIQUE INDEX player_username_lower_key ON player (lower(username));
+CREATE UNIQUE INDEX player_email_lower_key ON player (lower(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX player_email_lower_key;
+DROP INDEX player_username_lower_key;
+
+ALTER TABLE player
+    ADD CONSTRAINT player_email_key UNIQUE (email);
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- played games are removed with their player when the account is deleted
+ALTER TABLE played_game
+    DROP CONSTRAINT played_game_player_id_fkey,
+    ADD CONSTRAINT played_game_player_id_fkey
+        FOREIGN KEY (player_id) REFERENCES player(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE played_game
+    DROP CONSTRAINT played_game_player_id_fkey,
+    ADD CONSTRAINT played_game_player_id_fkey
+        FOREIGN KEY (player_id) REFERENCES player(id);
+-- +goose StatementEnd
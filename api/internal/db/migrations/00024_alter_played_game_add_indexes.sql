@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a player has at most one played game that is not finished. Of games left
+-- unfinished together the latest one stays, the others are rerolled with no points.
+-- Rerolled games are kept as they were in played_game_deduplicated, Down restores them.
+CREATE TABLE played_game_deduplicated AS
+SELECT id, status, points, completed_at
+FROM (
+    SELECT id, status, points, completed_at,
+        row_number() OVER (PARTITION BY player_id ORDER BY started_at DESC, id DESC) AS n
+    FROM played_game
+    WHERE status IN ('added', 'in_progress')
+) d
+WHERE d.n > 1;
+
+ALTER TABLE played_game_deduplicated ADD PRIMARY KEY (id);
+
+UPDATE played_game pg
+SET status = 'rerolled', points = 0, completed_at = NOW()
+FROM played_game_deduplicated d
+WHERE d.id = pg.id;
+
+CREATE INDEX played_game_player_idx ON played_game(player_id);
+CREATE INDEX played_game_status_idx ON played_game(status, started_at);
+CREATE UNIQUE INDEX played_game_player_nonterminated_key ON played_game(player_id)
+    WHERE status IN ('added', 'in_progress');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX played_game_player_nonterminated_key;
+DROP INDEX played_game_status_idx;
+DROP INDEX played_game_player_idx;
+
+-- games changed since they were rerolled are left as they are
+UPDATE played_game pg
+SET status = d.status, points = d.points, completed_at = d.completed_at
+FROM played_game_deduplicated d
+WHERE d.id = pg.id AND pg.status = 'rerolled';
+
+DROP TABLE played_game_deduplicated;
+-- +goose StatementEnd
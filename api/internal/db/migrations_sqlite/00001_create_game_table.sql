@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- timestamps are texts in db.SQLiteTimeFormat
+CREATE TABLE game(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    points INT NOT NULL DEFAULT 0,
+    hours_to_beat INT NOT NULL DEFAULT 0,
+    title TEXT NOT NULL UNIQUE,
+    url TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE game;
+-- +goose StatementEnd
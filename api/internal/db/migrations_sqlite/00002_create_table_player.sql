@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- username_key and email_key are lowercased by the repository, sqlite lower()
+-- only knows ascii, they keep usernames and emails unique regardless of the case
+CREATE TABLE player(
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    username_key TEXT NOT NULL UNIQUE,
+    img TEXT NULL,
+    email TEXT NULL,
+    email_key TEXT NULL UNIQUE,
+    password TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    is_admin BOOLEAN NOT NULL DEFAULT false,
+    description TEXT NULL,
+    failed_login_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP NULL,
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    must_change_password BOOLEAN NOT NULL DEFAULT false,
+    tokens_valid_after TIMESTAMP NULL,
+    suspension_kind TEXT NULL CHECK (suspension_kind IN ('deactivated', 'banned')),
+    suspension_reason TEXT NULL,
+    suspended_at TIMESTAMP NULL,
+    suspended_until TIMESTAMP NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE player;
+-- +goose StatementEnd
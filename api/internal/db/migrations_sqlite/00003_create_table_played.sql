@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- status is checked like the played_game_status enum of postgres,
+-- play_time is an interval in microseconds
+CREATE TABLE played_game(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id TEXT REFERENCES player(id) ON DELETE CASCADE,
+    game_id INT REFERENCES game(id),
+    points INT NOT NULL DEFAULT 0,
+    comment TEXT NULL,
+    rating INT NULL,
+    status TEXT NOT NULL DEFAULT 'added'
+        CHECK (status IN ('added', 'in_progress', 'completed', 'dropped', 'rerolled')),
+    play_time INTEGER NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    completed_at TIMESTAMP NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE played_game;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE backlog_item(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    game_id INTEGER NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    note TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    UNIQUE (player_id, game_id)
+);
+CREATE INDEX backlog_item_player_position_idx ON backlog_item(player_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE backlog_item;
+-- +goose StatementEnd
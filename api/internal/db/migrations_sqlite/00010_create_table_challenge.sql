@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE challenge(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    challenger_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    game_id INTEGER NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL,
+    bonus INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'active', 'finished', 'declined', 'expired', 'cancelled')),
+    winner_id TEXT NULL REFERENCES player(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    finished_at TIMESTAMP NULL
+);
+
+CREATE TABLE challenge_participant(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    challenge_id INTEGER NOT NULL REFERENCES challenge(id) ON DELETE CASCADE,
+    player_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'invited'
+        CHECK (status IN ('invited', 'accepted', 'declined', 'expired')),
+    played_game_id INTEGER NULL REFERENCES played_game(id) ON DELETE SET NULL,
+    UNIQUE (challenge_id, player_id)
+);
+CREATE INDEX challenge_participant_player_idx ON challenge_participant(player_id);
+CREATE INDEX challenge_participant_played_game_idx ON challenge_participant(played_game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE challenge_participant;
+DROP TABLE challenge;
+-- +goose StatementEnd
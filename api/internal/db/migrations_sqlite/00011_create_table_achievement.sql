@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE achievement(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    unlocked_at TIMESTAMP NOT NULL,
+    UNIQUE (player_id, code)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE achievement;
+-- +goose StatementEnd
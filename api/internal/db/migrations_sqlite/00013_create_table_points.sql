@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE points_adjustment(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    played_game_id INTEGER NOT NULL REFERENCES played_game(id) ON DELETE CASCADE,
+    player_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    adjusted_by TEXT REFERENCES player(id) ON DELETE SET NULL,
+    points_before INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TABLE points_ledger(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id TEXT NOT NULL REFERENCES player(id) ON DELETE CASCADE,
+    played_game_id INTEGER NOT NULL REFERENCES played_game(id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN (
+        'completion',
+        'streak_bonus',
+        'drop_penalty',
+        'drop_stacking',
+        'adjustment',
+        'challenge_bonus',
+        'import',
+        'history'
+    )),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+CREATE INDEX points_ledger_player_idx ON points_ledger(player_id);
+CREATE INDEX points_ledger_played_game_idx ON points_ledger(played_game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE points_ledger;
+DROP TABLE points_adjustment;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a player has at most one played game that is not finished. Of games left
+-- unfinished together the latest one stays, the others are rerolled with no points.
+-- Rerolled games are kept as they were in played_game_deduplicated, Down restores them.
+CREATE TABLE played_game_deduplicated (
+    id INTEGER PRIMARY KEY,
+    status TEXT NOT NULL,
+    points INT NOT NULL,
+    completed_at TIMESTAMP NULL
+);
+
+INSERT INTO played_game_deduplicated (id, status, points, completed_at)
+SELECT id, status, points, completed_at
+FROM (
+    SELECT id, status, points, completed_at,
+        row_number() OVER (PARTITION BY player_id ORDER BY started_at DESC, id DESC) AS n
+    FROM played_game
+    WHERE status IN ('added', 'in_progress')
+)
+WHERE n > 1;
+
+UPDATE played_game
+SET status = 'rerolled', points = 0, completed_at = strftime('%Y-%m-%d %H:%M:%f000', 'now')
+WHERE id IN (SELECT id FROM played_game_deduplicated);
+
+CREATE INDEX played_game_player_idx ON played_game(player_id);
+CREATE INDEX played_game_status_idx ON played_game(status, started_at);
+CREATE UNIQUE INDEX played_game_player_nonterminated_key ON played_game(player_id)
+    WHERE status IN ('added', 'in_progress');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX played_game_player_nonterminated_key;
+DROP INDEX played_game_status_idx;
+DROP INDEX played_game_player_idx;
+
+-- games changed since they were rerolled are left as they are
+UPDATE played_game
+SET status = d.status, points = d.points, completed_at = d.completed_at
+FROM played_game_deduplicated d
+WHERE d.id = played_game.id AND played_game.status = 'rerolled';
+
+DROP TABLE played_game_deduplicated;
+-- +goose StatementEnd
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteTimeFormat is the fixed width format of timestamps stored by sqlite repositories,
+// texts in it are ordered as the times are and keep microseconds like postgres does
+const SQLiteTimeFormat = "2006-01-02 15:04:05.000000"
+
+// SQLiteQuerier is implemented by both the database and a transaction
+type SQLiteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewSQLite opens the database file at path, creating it if needed. Foreign keys are
+// enforced and transactions take the write lock when they begin, so concurrent
+// transactions wait for each other instead of failing on upgrade.
+func NewSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return sqlDB, nil
+}
+
+// SQLiteConn returns the transaction started by SQLiteTxManager.WithTx if ctx carries one,
+// otherwise the database
+func SQLiteConn(ctx context.Context, sqlDB *sql.DB) SQLiteQuerier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.sqliteTx != nil {
+		return state.sqliteTx
+	}
+	return sqlDB
+}
+
+// SQLiteTime formats t to be stored in a timestamp column
+func SQLiteTime(t time.Time) string {
+	return t.UTC().Format(SQLiteTimeFormat)
+}
+
+// SQLiteTimePtr formats t like SQLiteTime, nil stays nil
+func SQLiteTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := SQLiteTime(*t)
+	return &s
+}
+
+// SQLiteJSON encodes v to be stored in a text column standing for an array or jsonb one
+func SQLiteJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// SQLiteJSONScanner decodes a column written with SQLiteJSON into v
+func SQLiteJSONScanner(v any) sql.Scanner {
+	return jsonScanner{v: v}
+}
+
+type jsonScanner struct {
+	v any
+}
+
+func (s jsonScanner) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), s.v)
+	case []byte:
+		return json.Unmarshal(src, s.v)
+	default:
+		return fmt.Errorf("scan json from %T", src)
+	}
+}
+
+// SQLiteUniqueViolation reports whether err violates the unique index of table.column
+func SQLiteUniqueViolation(err error, table, column string) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return false
+	}
+	return strings.Contains(sqliteErr.Error(), table+"."+column)
+}
+
+type SQLiteTxManager struct {
+	db *sql.DB
+}
+
+func NewSQLiteTxManager(sqlDB *sql.DB) *SQLiteTxManager {
+	return &SQLiteTxManager{
+		db: sqlDB,
+	}
+}
+
+// WithTx runs fn in a transaction which is committed if fn succeeds.
+// Nested calls join the outer transaction.
+func (m *SQLiteTxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.sqliteTx != nil {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	state := txState{sqliteTx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, &state)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is implemented by both the pool and a transaction
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx          pgx.Tx
+	sqliteTx    *sql.Tx
+	afterCommit []func()
+}
+
+// Conn returns the transaction started by TxManager.WithTx if ctx carries one, otherwise the pool.
+// Repositories use it so their queries join the transaction of the caller.
+func Conn(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.tx != nil {
+		return state.tx
+	}
+	return pool
+}
+
+// WithCommitHooks returns ctx collecting AfterCommit functions and a function running them.
+// Transactions of storages other than postgres use it to keep AfterCommit working.
+// Inside another transaction the functions are left to it and the returned one does nothing.
+func WithCommitHooks(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return ctx, func() {}
+	}
+	state := txState{}
+	return context.WithValue(ctx, txKey{}, &state), func() {
+		for _, hook := range state.afterCommit {
+			hook()
+		}
+	}
+}
+
+// AfterCommit runs fn once the transaction of ctx is committed, it is dropped on rollback.
+// Without a transaction fn runs immediately.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{
+		pool: pool,
+	}
+}
+
+// WithTx runs fn in a transaction which is committed if fn succeeds.
+// Nested calls join the outer transaction.
+func (m *TxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	state := txState{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, &state)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
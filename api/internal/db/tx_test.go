@@ -0,0 +1,24 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestAfterCommit_NoTx(t *testing.T) {
+	called := false
+	AfterCommit(t.Context(), func() { called = true })
+	assert.True(t, called)
+}
+
+func TestWithCommitHooks(t *testing.T) {
+	ctx, commit := WithCommitHooks(t.Context())
+
+	called := false
+	AfterCommit(ctx, func() { called = true })
+	assert.False(t, called)
+
+	commit()
+	assert.True(t, called)
+}
@@ -0,0 +1,84 @@
+package account
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const (
+	// DeleteModeAnonymize removes personal data and keeps played games for the standings
+	DeleteModeAnonymize = "anonymize"
+	// DeleteModeErase removes the player with all their data
+	DeleteModeErase = "erase"
+
+	anonymousUsernamePrefix = "deleted-"
+)
+
+// exportTable is a table with data of a player, columns in exclude are secrets
+// which are never exported
+type exportTable struct {
+	name    string
+	owner   string
+	exclude []string
+}
+
+// exportTables are exported in this order, the player goes first. Auth tokens
+// and oauth states are short living secrets and are not exported.
+var exportTables = []exportTable{
+	{name: "player", owner: "id", exclude: []string{"password"}},
+	{name: "played_game", owner: "player_id"},
+	{name: "player_streak", owner: "player_id"},
+	{name: "points_ledger", owner: "player_id"},
+	{name: "points_adjustment", owner: "player_id"},
+	{name: "backlog_item", owner: "player_id"},
+	{name: "challenge", owner: "challenger_id"},
+	{name: "challenge_participant", owner: "player_id"},
+	{name: "achievement", owner: "player_id"},
+	{name: "identity", owner: "player_id"},
+	{name: "api_token", owner: "player_id", exclude: []string{"token_hash"}},
+}
+
+// ExportTable holds rows of a table which belong to the player
+type ExportTable struct {
+	Name string
+	Rows []json.RawMessage
+}
+
+// Removal is the data of the event published when a player leaves
+type Removal struct {
+	Mode string `json:"mode"`
+}
+
+// AnonymousUsername replaces the username of an anonymized player, the whole id
+// is kept so it cannot collide with the unique username of another player
+func AnonymousUsername(playerID string) string {
+	return anonymousUsernamePrefix + playerID
+}
+
+// WriteExport writes every table as a json file of a zip archive
+func WriteExport(w io.Writer, tables []ExportTable, createdAt time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, t := range tables {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     t.Name + ".json",
+			Method:   zip.Deflate,
+			Modified: createdAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows := t.Rows
+		if rows == nil {
+			rows = []json.RawMessage{}
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,150 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+type AccountRepository interface {
+	Anonymize(ctx context.Context, playerID string) error
+	Erase(ctx context.Context, playerID string) error
+	Export(ctx context.Context, playerID string) ([]ExportTable, error)
+}
+
+// PlayerCache is the cache of players used by the auth middleware
+type PlayerCache interface {
+	Invalidate(id string)
+}
+
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+type Handler struct {
+	accountRepository AccountRepository
+	playerCache       PlayerCache
+	tx                Transactor
+	publisher         EventPublisher
+}
+
+func NewHandler(
+	accountRepository AccountRepository,
+	playerCache PlayerCache,
+	tx Transactor,
+	publisher EventPublisher,
+) *Handler {
+	return &Handler{
+		accountRepository: accountRepository,
+		playerCache:       playerCache,
+		tx:                tx,
+		publisher:         publisher,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/players")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"players"}
+		// a leaked api token must not be able to delete the account or read all its data
+		op.Metadata = map[string]any{apiutil.MetadataScope: apiutil.ScopeSession}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "players-delete-one",
+		Method:      http.MethodDelete,
+		Path:        "/{id}",
+		Summary:     "delete player",
+		Description: "anonymize the player keeping played games for the standings, or erase all data of the player",
+	}, h.Delete)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "players-export-one",
+		Method:      http.MethodGet,
+		Path:        "/{id}/export",
+		Summary:     "export player data",
+		Description: "get a zip archive with all data of the player as json files",
+	}, h.Export)
+}
+
+func (h *Handler) Delete(ctx context.Context, i *RequestDelete) (*domain.ResponseID[string], error) {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if i.Mode == DeleteModeErase {
+			err = h.accountRepository.Erase(ctx, i.PlayerID)
+		} else {
+			err = h.accountRepository.Anonymize(ctx, i.PlayerID)
+		}
+		if err != nil {
+			return err
+		}
+		// totals and names on the leaderboard change
+		e := event.NewForPlayer(event.LeaderboardChanged, i.PlayerID, Removal{Mode: i.Mode})
+		return h.publisher.Publish(ctx, e)
+	})
+	if errors.Is(err, ErrPlayerNotFound) {
+		return nil, huma.Error404NotFound("player not found")
+	}
+	if err != nil {
+		log.Printf("player %v delete: %v", i.PlayerID, err)
+		return nil, huma.Error500InternalServerError("delete", err)
+	}
+	h.playerCache.Invalidate(i.PlayerID)
+
+	ctxPlayer, _ := ctxutil.GetPlayer(ctx)
+	log.Printf("player %v deleted with %v by %v", i.PlayerID, i.Mode, ctxPlayer.ID)
+	resp := domain.ResponseID[string]{}
+	resp.Body.ID = i.PlayerID
+	return &resp, nil
+}
+
+func (h *Handler) Export(ctx context.Context, i *RequestExport) (*ResponseExport, error) {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	var tables []ExportTable
+	// tables are read in one transaction so they are consistent with each other
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		tables, err = h.accountRepository.Export(ctx, i.PlayerID)
+		return err
+	})
+	if err != nil {
+		log.Printf("player %v export: %v", i.PlayerID, err)
+		return nil, huma.Error500InternalServerError("export", err)
+	}
+	if len(tables) == 0 || len(tables[0].Rows) == 0 {
+		return nil, huma.Error404NotFound("player not found")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, tables, time.Now()); err != nil {
+		log.Printf("player %v export write: %v", i.PlayerID, err)
+		return nil, huma.Error500InternalServerError("export", err)
+	}
+
+	return &ResponseExport{
+		ContentType:        "application/zip",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="playtrack-%s.zip"`, i.PlayerID),
+		Body:               buf.Bytes(),
+	}, nil
+}
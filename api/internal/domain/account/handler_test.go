@@ -0,0 +1,156 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDelete(t *testing.T) {
+	tcases := []struct {
+		mode   string
+		method string
+	}{
+		{DeleteModeAnonymize, "Anonymize"},
+		{DeleteModeErase, "Erase"},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.mode, func(t *testing.T) {
+			accountRepo := NewMockAccountRepository(t)
+			playerCache := NewMockPlayerCache(t)
+			publisher := NewMockEventPublisher(t)
+			handler := NewHandler(accountRepo, playerCache, passTx(t), publisher)
+
+			playerID := uuid.NewString()
+			ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+			accountRepo.On(tt.method, ctx, playerID).Once().Return(nil)
+			publisher.
+				On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+					return e.Type == event.LeaderboardChanged && e.PlayerID == playerID
+				})).
+				Once().
+				Return(nil)
+			playerCache.On("Invalidate", playerID).Once()
+
+			resp, err := handler.Delete(ctx, &RequestDelete{PlayerID: playerID, Mode: tt.mode})
+			assert.NoError(t, err)
+			assert.Equal(t, playerID, resp.Body.ID)
+		})
+	}
+}
+
+func TestDelete_Rejected(t *testing.T) {
+	tcases := []struct {
+		name     string
+		ctxUser  ctxutil.CtxPlayer
+		found    bool
+		expected int
+	}{
+		{"other player", ctxutil.CtxPlayer{ID: uuid.NewString()}, true, http.StatusForbidden},
+		{"not found", ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true}, false, http.StatusNotFound},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := NewMockAccountRepository(t)
+			handler := NewHandler(accountRepo, NewMockPlayerCache(t), passTx(t), NewMockEventPublisher(t))
+
+			playerID := uuid.NewString()
+			ctx := ctxutil.SetPlayer(t.Context(), tt.ctxUser)
+			if !tt.found {
+				accountRepo.On("Anonymize", ctx, playerID).Once().Return(ErrPlayerNotFound)
+			}
+
+			_, err := handler.Delete(ctx, &RequestDelete{PlayerID: playerID, Mode: DeleteModeAnonymize})
+			assertStatus(t, err, tt.expected)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	accountRepo := NewMockAccountRepository(t)
+	handler := NewHandler(accountRepo, NewMockPlayerCache(t), passTx(t), NewMockEventPublisher(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	accountRepo.On("Export", ctx, playerID).Once().Return([]ExportTable{
+		{Name: "player", Rows: []json.RawMessage{json.RawMessage(`{"id":"` + playerID + `"}`)}},
+		{Name: "played_game"},
+	}, nil)
+
+	resp, err := handler.Export(ctx, &RequestExport{PlayerID: playerID})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/zip", resp.ContentType)
+
+	files := readZip(t, resp.Body)
+	assert.Equal(t, 2, len(files))
+	assert.Contains(t, files["player.json"], playerID)
+	assert.Equal(t, "[]\n", files["played_game.json"])
+}
+
+func TestExport_NotFound(t *testing.T) {
+	accountRepo := NewMockAccountRepository(t)
+	handler := NewHandler(accountRepo, NewMockPlayerCache(t), passTx(t), NewMockEventPublisher(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	accountRepo.On("Export", ctx, playerID).Once().Return([]ExportTable{{Name: "player"}}, nil)
+
+	_, err := handler.Export(ctx, &RequestExport{PlayerID: playerID})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestAnonymousUsername(t *testing.T) {
+	assert.Equal(t, "deleted-3f2b8c1e-0000-4000-8000-000000000000", AnonymousUsername("3f2b8c1e-0000-4000-8000-000000000000"))
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status, statusErr.GetStatus())
+}
+
+func passTx(t *testing.T) *MockTransactor {
+	tx := NewMockTransactor(t)
+	tx.
+		On("WithTx", mock.Anything, mock.Anything).
+		Maybe().
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tx
+}
@@ -0,0 +1,145 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+// ErrPlayerNotFound is returned when there is no player to remove
+var ErrPlayerNotFound = errors.New("player is not found")
+
+// erasedTables hold personal data which is removed when a player is anonymized
+var erasedTables = []string{
+	"auth_token",
+	"oauth_state",
+	"api_token",
+	"identity",
+	"backlog_item",
+}
+
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{
+		pool: pool,
+	}
+}
+
+// Anonymize removes personal data of the player and their credentials, played
+// games stay without comments so standings of other players do not change
+func (r *PGRepository) Anonymize(ctx context.Context, playerID string) error {
+	conn := db.Conn(ctx, r.pool)
+
+	// an empty hash matches no password
+	query, args, err := sq.Update("player").
+		PlaceholderFormat(sq.Dollar).
+		Set("username", AnonymousUsername(playerID)).
+		Set("email", nil).
+		Set("email_verified", false).
+		Set("img", nil).
+		Set("description", nil).
+		Set("password", "").
+		Set("is_admin", false).
+		Set("must_change_password", false).
+		Set("tokens_valid_after", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	tag, err := conn.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPlayerNotFound
+	}
+
+	for _, table := range erasedTables {
+		query, args, err := sq.Delete(table).
+			PlaceholderFormat(sq.Dollar).
+			Where(sq.Eq{"player_id": playerID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	query, args, err = sq.Update("played_game").
+		PlaceholderFormat(sq.Dollar).
+		Set("comment", nil).
+		Where(sq.Eq{"player_id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(ctx, query, args...)
+	return err
+}
+
+// Erase deletes the player, their data is deleted by foreign keys
+func (r *PGRepository) Erase(ctx context.Context, playerID string) error {
+	query, args, err := sq.Delete("player").
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// Export reads rows of the player from every exported table
+func (r *PGRepository) Export(ctx context.Context, playerID string) ([]ExportTable, error) {
+	out := make([]ExportTable, 0, len(exportTables))
+	for _, t := range exportTables {
+		column := "to_jsonb(t)"
+		for _, c := range t.exclude {
+			column += " - '" + c + "'"
+		}
+
+		query, args, err := sq.Select(column).
+			PlaceholderFormat(sq.Dollar).
+			From(pgx.Identifier{t.name}.Sanitize() + " t").
+			Where(sq.Eq{"t." + t.owner: playerID}).
+			OrderBy("t.id").
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, err)
+		}
+		data, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (json.RawMessage, error) {
+			var data []byte
+			err := row.Scan(&data)
+			return json.RawMessage(data), err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, err)
+		}
+		out = append(out, ExportTable{Name: t.name, Rows: data})
+	}
+	return out, nil
+}
@@ -0,0 +1,16 @@
+package account
+
+type RequestDelete struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Mode     string `query:"mode" enum:"anonymize,erase" default:"anonymize" doc:"anonymize keeps played games for the standings, erase deletes them too"`
+}
+
+type RequestExport struct {
+	PlayerID string `path:"id" format:"uuid"`
+}
+
+type ResponseExport struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
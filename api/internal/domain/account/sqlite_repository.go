@@ -0,0 +1,196 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+var (
+	// sqliteKeyColumns are lowercased copies of other columns, they are not exported
+	sqliteKeyColumns = []string{"username_key", "email_key"}
+	// sqliteJSONColumns hold json arrays, they are exported as arrays like postgres ones
+	sqliteJSONColumns = []string{"scopes"}
+)
+
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+// Anonymize removes personal data of the player and their credentials, played
+// games stay without comments so standings of other players do not change
+func (r *SQLiteRepository) Anonymize(ctx context.Context, playerID string) error {
+	conn := db.SQLiteConn(ctx, r.db)
+	username := AnonymousUsername(playerID)
+
+	// an empty hash matches no password
+	query, args, err := sq.Update("player").
+		PlaceholderFormat(sq.Question).
+		Set("username", username).
+		Set("username_key", strings.ToLower(username)).
+		Set("email", nil).
+		Set("email_key", nil).
+		Set("email_verified", false).
+		Set("img", nil).
+		Set("description", nil).
+		Set("password", "").
+		Set("is_admin", false).
+		Set("must_change_password", false).
+		Set("tokens_valid_after", db.SQLiteTime(time.Now())).
+		Where(sq.Eq{"id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPlayerNotFound
+	}
+
+	for _, table := range erasedTables {
+		query, args, err := sq.Delete(table).
+			PlaceholderFormat(sq.Question).
+			Where(sq.Eq{"player_id": playerID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	query, args, err = sq.Update("played_game").
+		PlaceholderFormat(sq.Question).
+		Set("comment", nil).
+		Where(sq.Eq{"player_id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Erase deletes the player, their data is deleted by foreign keys
+func (r *SQLiteRepository) Erase(ctx context.Context, playerID string) error {
+	query, args, err := sq.Delete("player").
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"id": playerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// Export reads rows of the player from every exported table, rows are encoded
+// like postgres does so exports do not depend on the storage
+func (r *SQLiteRepository) Export(ctx context.Context, playerID string) ([]ExportTable, error) {
+	out := make([]ExportTable, 0, len(exportTables))
+	for _, t := range exportTables {
+		data, err := r.export(ctx, t, playerID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, err)
+		}
+		out = append(out, ExportTable{Name: t.name, Rows: data})
+	}
+	return out, nil
+}
+
+func (r *SQLiteRepository) export(ctx context.Context, t exportTable, playerID string) ([]json.RawMessage, error) {
+	query, args, err := sq.Select("*").
+		PlaceholderFormat(sq.Question).
+		From(`"` + t.name + `"`).
+		Where(sq.Eq{t.owner: playerID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]json.RawMessage, 0)
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, c := range columns {
+			name := c.Name()
+			if slices.Contains(t.exclude, name) || slices.Contains(sqliteKeyColumns, name) {
+				continue
+			}
+			row[name] = sqliteExportValue(c, values[i])
+		}
+
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, rows.Err()
+}
+
+// sqliteExportValue converts a value to the one postgres has in the same column
+func sqliteExportValue(c *sql.ColumnType, v any) any {
+	switch v := v.(type) {
+	case int64:
+		switch {
+		case c.DatabaseTypeName() == "BOOLEAN":
+			return v != 0
+		case c.Name() == "play_time":
+			return types.NewDurationString(time.Duration(v) * time.Microsecond)
+		}
+	case string:
+		if slices.Contains(sqliteJSONColumns, c.Name()) {
+			return json.RawMessage(v)
+		}
+	}
+	return v
+}
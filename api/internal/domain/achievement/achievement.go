@@ -0,0 +1,198 @@
+package achievement
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+type Code string
+
+const (
+	CodeFirstCompletion Code = "first_completion"
+	CodeTenCompletions  Code = "ten_completions"
+	CodeFiveInARow      Code = "five_in_a_row"
+	CodeMarathon        Code = "marathon"
+	CodeUnderPar        Code = "under_par"
+)
+
+const (
+	marathonHours = 50
+)
+
+// Definition is a milestone of played game history, Unlock returns the time the history
+// first satisfied it
+type Definition struct {
+	Code        Code
+	Title       string
+	Description string
+	Unlock      func(h *History) (time.Time, bool)
+}
+
+// Definitions are evaluated in order, codes are stored so they must not change
+var Definitions = []Definition{
+	{
+		Code:        CodeFirstCompletion,
+		Title:       "First blood",
+		Description: "Complete a game",
+		Unlock:      nthCompletion(1),
+	},
+	{
+		Code:        CodeTenCompletions,
+		Title:       "Collector",
+		Description: "Complete 10 games",
+		Unlock:      nthCompletion(10),
+	},
+	{
+		Code:        CodeFiveInARow,
+		Title:       "No quitter",
+		Description: "Complete 5 games in a row without a drop",
+		Unlock:      completionStreak(5),
+	},
+	{
+		Code:        CodeMarathon,
+		Title:       "Marathon",
+		Description: "Complete a game of more than 50 hours to beat",
+		Unlock: completedWhere(func(_ player.PlayedGame, g game.Game) bool {
+			return g.HoursToBeat > marathonHours
+		}),
+	},
+	{
+		Code:        CodeUnderPar,
+		Title:       "Under par",
+		Description: "Complete a game faster than its hours to beat",
+		Unlock: completedWhere(func(pg player.PlayedGame, g game.Game) bool {
+			return pg.PlayTime != nil && pg.PlayTime.Duration < time.Duration(g.HoursToBeat)*time.Hour
+		}),
+	},
+}
+
+// Achievement is a definition as seen by a player, UnlockedAt is nil while it is locked
+type Achievement struct {
+	Code        Code       `json:"code"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	UnlockedAt  *time.Time `json:"unlocked_at"`
+}
+
+// Unlock is an achievement unlocked by a player
+type Unlock struct {
+	PlayerID   string
+	Code       Code
+	UnlockedAt time.Time
+}
+
+// Achievements returns all definitions with unlock times of the player
+func Achievements(unlocks []Unlock) []Achievement {
+	out := make([]Achievement, 0, len(Definitions))
+	for _, d := range Definitions {
+		a := Achievement{Code: d.Code, Title: d.Title, Description: d.Description}
+		if i := slices.IndexFunc(unlocks, func(u Unlock) bool { return u.Code == d.Code }); i >= 0 {
+			a.UnlockedAt = &unlocks[i].UnlockedAt
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func (u *Unlock) Achievement() Achievement {
+	for _, d := range Definitions {
+		if d.Code == u.Code {
+			return Achievement{Code: d.Code, Title: d.Title, Description: d.Description, UnlockedAt: &u.UnlockedAt}
+		}
+	}
+	return Achievement{Code: u.Code, UnlockedAt: &u.UnlockedAt}
+}
+
+// History is the finished played games of a player in order of completion, games
+// completed without completion time are ordered by their start
+type History struct {
+	Finished []player.PlayedGame
+	Games    map[int]game.Game
+}
+
+func NewHistory(played []player.PlayedGame, games []game.Game) *History {
+	h := History{
+		Finished: make([]player.PlayedGame, 0, len(played)),
+		Games:    make(map[int]game.Game, len(games)),
+	}
+	for _, g := range games {
+		h.Games[g.ID] = g
+	}
+	for _, pg := range played {
+		if pg.StatusTerminated() {
+			h.Finished = append(h.Finished, pg)
+		}
+	}
+	slices.SortFunc(h.Finished, func(a, b player.PlayedGame) int {
+		return cmp.Or(finishedAt(a).Compare(finishedAt(b)), cmp.Compare(a.ID, b.ID))
+	})
+	return &h
+}
+
+// Evaluate returns achievements the history unlocks
+func Evaluate(playerID string, h *History) []Unlock {
+	out := make([]Unlock, 0)
+	for _, d := range Definitions {
+		if at, ok := d.Unlock(h); ok {
+			out = append(out, Unlock{PlayerID: playerID, Code: d.Code, UnlockedAt: at.UTC()})
+		}
+	}
+	return out
+}
+
+func nthCompletion(n int) func(h *History) (time.Time, bool) {
+	return func(h *History) (time.Time, bool) {
+		count := 0
+		for _, pg := range h.Finished {
+			if pg.Status != player.PlayedGameStatusCompleted {
+				continue
+			}
+			if count++; count == n {
+				return finishedAt(pg), true
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+// completionStreak is unlocked by n completions without a drop in between, rerolls do not
+// break a streak
+func completionStreak(n int) func(h *History) (time.Time, bool) {
+	return func(h *History) (time.Time, bool) {
+		streak := 0
+		for _, pg := range h.Finished {
+			switch pg.Status {
+			case player.PlayedGameStatusDropped:
+				streak = 0
+			case player.PlayedGameStatusCompleted:
+				if streak++; streak == n {
+					return finishedAt(pg), true
+				}
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+func completedWhere(fn func(pg player.PlayedGame, g game.Game) bool) func(h *History) (time.Time, bool) {
+	return func(h *History) (time.Time, bool) {
+		for _, pg := range h.Finished {
+			g, ok := h.Games[pg.GameID]
+			if ok && pg.Status == player.PlayedGameStatusCompleted && fn(pg, g) {
+				return finishedAt(pg), true
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+func finishedAt(pg player.PlayedGame) time.Time {
+	if pg.CompletedAt == nil {
+		return pg.StartedAt
+	}
+	return *pg.CompletedAt
+}
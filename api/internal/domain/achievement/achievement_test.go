@@ -0,0 +1,118 @@
+package achievement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+func TestEvaluate(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	games := []game.Game{
+		{ID: 1, HoursToBeat: 10},
+		{ID: 2, HoursToBeat: 60},
+	}
+
+	// played returns a finished game, the n-th game is finished n days after start
+	played := func(n int, gameID int, status player.PlayedGameStatus) player.PlayedGame {
+		completedAt := start.AddDate(0, 0, n)
+		return player.PlayedGame{ID: n, GameID: gameID, Status: status, CompletedAt: &completedAt}
+	}
+	day := func(n int) time.Time { return start.AddDate(0, 0, n) }
+	fast := types.NewDurationString(8 * time.Hour)
+
+	tcases := []struct {
+		name     string
+		played   []player.PlayedGame
+		expected map[Code]time.Time
+	}{
+		{
+			"nothing finished",
+			[]player.PlayedGame{{ID: 1, GameID: 1, Status: player.PlayedGameStatusInProgress}},
+			map[Code]time.Time{},
+		},
+		{
+			"first completion",
+			[]player.PlayedGame{
+				played(2, 1, player.PlayedGameStatusCompleted),
+				played(1, 1, player.PlayedGameStatusDropped),
+			},
+			map[Code]time.Time{CodeFirstCompletion: day(2)},
+		},
+		{
+			"streak broken by a drop",
+			[]player.PlayedGame{
+				played(1, 1, player.PlayedGameStatusCompleted),
+				played(2, 1, player.PlayedGameStatusCompleted),
+				played(3, 1, player.PlayedGameStatusDropped),
+				played(4, 1, player.PlayedGameStatusCompleted),
+				played(5, 1, player.PlayedGameStatusRerolled),
+				played(6, 1, player.PlayedGameStatusCompleted),
+				played(7, 1, player.PlayedGameStatusCompleted),
+				played(8, 1, player.PlayedGameStatusCompleted),
+				played(9, 1, player.PlayedGameStatusCompleted),
+			},
+			map[Code]time.Time{CodeFirstCompletion: day(1), CodeFiveInARow: day(9)},
+		},
+		{
+			"marathon and under par",
+			func() []player.PlayedGame {
+				short := played(1, 1, player.PlayedGameStatusCompleted)
+				short.PlayTime = &fast
+				return []player.PlayedGame{
+					short,
+					played(2, 2, player.PlayedGameStatusCompleted),
+					played(3, 2, player.PlayedGameStatusDropped),
+				}
+			}(),
+			map[Code]time.Time{CodeFirstCompletion: day(1), CodeUnderPar: day(1), CodeMarathon: day(2)},
+		},
+		{
+			"ten completions",
+			func() []player.PlayedGame {
+				var out []player.PlayedGame
+				for n := 1; n <= 11; n++ {
+					out = append(out, played(n, 1, player.PlayedGameStatusCompleted))
+				}
+				return out
+			}(),
+			map[Code]time.Time{
+				CodeFirstCompletion: day(1),
+				CodeFiveInARow:      day(5),
+				CodeTenCompletions:  day(10),
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			unlocks := Evaluate("p", NewHistory(tc.played, games))
+
+			actual := make(map[Code]time.Time, len(unlocks))
+			for _, u := range unlocks {
+				assert.Equal(t, "p", u.PlayerID)
+				actual[u.Code] = u.UnlockedAt
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAchievements(t *testing.T) {
+	at := time.Now()
+	achievements := Achievements([]Unlock{{Code: CodeMarathon, UnlockedAt: at}})
+
+	assert.Equal(t, len(Definitions), len(achievements))
+	for _, a := range achievements {
+		assert.NotZero(t, a.Title)
+		if a.Code == CodeMarathon {
+			assert.Equal(t, at, *a.UnlockedAt)
+		} else {
+			assert.Zero(t, a.UnlockedAt)
+		}
+	}
+}
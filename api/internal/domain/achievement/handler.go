@@ -0,0 +1,67 @@
+package achievement
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+type AchievementRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]Unlock, error)
+	Insert(ctx context.Context, unlocks []Unlock) ([]Unlock, error)
+}
+
+type PlayedGameRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]player.PlayedGame, error)
+}
+
+type GameRepository interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+type Handler struct {
+	achievementRepository AchievementRepository
+}
+
+func NewHandler(achievementRepository AchievementRepository) *Handler {
+	return &Handler{
+		achievementRepository: achievementRepository,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/players")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"achievements"}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "achievements-get-all",
+		Method:      http.MethodGet,
+		Path:        "/{id}/achievements",
+		Summary:     "get achievements",
+		Description: "get all achievements, unlocked ones have the time of unlock",
+	}, h.GetAll)
+}
+
+func (h *Handler) GetAll(ctx context.Context, i *RequestAchievements) (*domain.ResponseItems[Achievement], error) {
+	unlocks, err := h.achievementRepository.FindAll(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("achievements find all %v: %v", i.PlayerID, err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Achievement]{}
+	resp.Body.Items = Achievements(unlocks)
+	return &resp, nil
+}
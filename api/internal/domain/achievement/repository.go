@@ -0,0 +1,88 @@
+package achievement
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	TableAchievement = "achievement"
+)
+
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGRepository) FindAll(ctx context.Context, playerID string) ([]Unlock, error) {
+	out := make([]Unlock, 0)
+
+	query, args, err := sq.Select("player_id", "code", "unlocked_at").
+		PlaceholderFormat(sq.Dollar).
+		From(TableAchievement).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("unlocked_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u Unlock
+		if err := rows.Scan(&u.PlayerID, &u.Code, &u.UnlockedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// Insert stores unlocks the player does not have yet and returns them
+func (r *PGRepository) Insert(ctx context.Context, unlocks []Unlock) ([]Unlock, error) {
+	out := make([]Unlock, 0)
+	if len(unlocks) == 0 {
+		return out, nil
+	}
+
+	sqlBuild := sq.Insert(TableAchievement).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "code", "unlocked_at").
+		Suffix("ON CONFLICT (player_id, code) DO NOTHING RETURNING player_id, code, unlocked_at")
+	for _, u := range unlocks {
+		sqlBuild = sqlBuild.Values(u.PlayerID, u.Code, u.UnlockedAt.UTC())
+	}
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u Unlock
+		if err := rows.Scan(&u.PlayerID, &u.Code, &u.UnlockedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,5 @@
+package achievement
+
+type RequestAchievements struct {
+	PlayerID string `path:"id" format:"uuid"`
+}
@@ -0,0 +1,72 @@
+package achievement
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context, playerID string) ([]Unlock, error) {
+	query, args, err := sq.Select("player_id", "code", "unlocked_at").
+		PlaceholderFormat(sq.Question).
+		From(TableAchievement).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("unlocked_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, query, args)
+}
+
+// Insert stores unlocks the player does not have yet and returns them
+func (r *SQLiteRepository) Insert(ctx context.Context, unlocks []Unlock) ([]Unlock, error) {
+	if len(unlocks) == 0 {
+		return make([]Unlock, 0), nil
+	}
+
+	sqlBuild := sq.Insert(TableAchievement).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "code", "unlocked_at").
+		Suffix("ON CONFLICT (player_id, code) DO NOTHING RETURNING player_id, code, unlocked_at")
+	for _, u := range unlocks {
+		sqlBuild = sqlBuild.Values(u.PlayerID, u.Code, db.SQLiteTime(u.UnlockedAt))
+	}
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, query, args)
+}
+
+func (r *SQLiteRepository) query(ctx context.Context, query string, args []any) ([]Unlock, error) {
+	out := make([]Unlock, 0)
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u Unlock
+		if err := rows.Scan(&u.PlayerID, &u.Code, &u.UnlockedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,89 @@
+package achievement
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+var (
+	// evaluatingEvents can unlock achievements, imports of played games only publish
+	// leaderboard changes
+	evaluatingEvents = []event.Type{
+		event.PlayedGameCompleted,
+		event.LeaderboardChanged,
+	}
+)
+
+// Tracker unlocks achievements of a player when played games change. It is an event
+// publisher so unlocks are stored in the transaction of the played game.
+type Tracker struct {
+	achievementRepository AchievementRepository
+	playedGameRepository  PlayedGameRepository
+	gameRepository        GameRepository
+	publisher             EventPublisher
+}
+
+func NewTracker(
+	achievementRepository AchievementRepository,
+	playedGameRepository PlayedGameRepository,
+	gameRepository GameRepository,
+	publisher EventPublisher,
+) *Tracker {
+	return &Tracker{
+		achievementRepository: achievementRepository,
+		playedGameRepository:  playedGameRepository,
+		gameRepository:        gameRepository,
+		publisher:             publisher,
+	}
+}
+
+func (t *Tracker) Publish(ctx context.Context, e event.Event) error {
+	if e.PlayerID == "" || !slices.Contains(evaluatingEvents, e.Type) {
+		return nil
+	}
+
+	unlocked, err := t.Evaluate(ctx, e.PlayerID)
+	if err != nil {
+		return fmt.Errorf("achievements of %v: %w", e.PlayerID, err)
+	}
+	for _, u := range unlocked {
+		log.Printf("player %v unlocked achievement %v", u.PlayerID, u.Code)
+		if err := t.publisher.Publish(ctx, event.NewForPlayer(event.AchievementUnlocked, u.PlayerID, u.Achievement())); err != nil {
+			return fmt.Errorf("publish %v: %w", event.AchievementUnlocked, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate stores achievements the history of the player unlocks and returns the new ones
+func (t *Tracker) Evaluate(ctx context.Context, playerID string) ([]Unlock, error) {
+	played, err := t.playedGameRepository.FindAll(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("played games find: %w", err)
+	}
+	games, err := t.gameRepository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("games find: %w", err)
+	}
+
+	unlocks := Evaluate(playerID, NewHistory(played, games))
+	return t.achievementRepository.Insert(ctx, unlocks)
+}
+
+// Backfill evaluates achievements of players without publishing events and returns the
+// number of unlocked achievements
+func (t *Tracker) Backfill(ctx context.Context, playerIDs []string) (int, error) {
+	total := 0
+	for _, id := range playerIDs {
+		unlocked, err := t.Evaluate(ctx, id)
+		if err != nil {
+			return total, fmt.Errorf("player %v: %w", id, err)
+		}
+		total += len(unlocked)
+	}
+	return total, nil
+}
@@ -0,0 +1,87 @@
+package achievement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrackerPublish(t *testing.T) {
+	achievementRepository := NewMockAchievementRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+	tracker := NewTracker(achievementRepository, playedGameRepository, gameRepository, publisher)
+
+	playerID := uuid.NewString()
+	completedAt := time.Now()
+	played := player.PlayedGame{ID: 1, PlayerID: playerID, GameID: 1, Status: player.PlayedGameStatusCompleted, CompletedAt: &completedAt}
+
+	playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{played}, nil)
+	gameRepository.
+		On("FindAll", mock.Anything).
+		Once().
+		Return([]game.Game{{ID: 1, HoursToBeat: 10}}, nil)
+
+	unlock := Unlock{PlayerID: playerID, Code: CodeFirstCompletion, UnlockedAt: completedAt.UTC()}
+	achievementRepository.
+		On("Insert", mock.Anything, []Unlock{unlock}).
+		Once().
+		Return([]Unlock{unlock}, nil)
+	publisher.
+		On("Publish", mock.Anything, mock.MatchedBy(func(e event.Event) bool {
+			a, ok := e.Data.(Achievement)
+			return e.Type == event.AchievementUnlocked && e.PlayerID == playerID && ok && a.Code == CodeFirstCompletion
+		})).
+		Once().
+		Return(nil)
+
+	err := tracker.Publish(t.Context(), event.NewForPlayer(event.PlayedGameCompleted, playerID, &played))
+	assert.NoError(t, err)
+}
+
+func TestTrackerPublish_AlreadyUnlocked(t *testing.T) {
+	achievementRepository := NewMockAchievementRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+	tracker := NewTracker(achievementRepository, playedGameRepository, gameRepository, publisher)
+
+	playerID := uuid.NewString()
+
+	playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{}, nil)
+	gameRepository.
+		On("FindAll", mock.Anything).
+		Once().
+		Return([]game.Game{}, nil)
+	achievementRepository.
+		On("Insert", mock.Anything, []Unlock{}).
+		Once().
+		Return([]Unlock{}, nil)
+	publisher.AssertNotCalled(t, "Publish")
+
+	err := tracker.Publish(t.Context(), event.NewForPlayer(event.LeaderboardChanged, playerID, nil))
+	assert.NoError(t, err)
+}
+
+func TestTrackerPublish_Ignored(t *testing.T) {
+	achievementRepository := NewMockAchievementRepository(t)
+	tracker := NewTracker(achievementRepository, NewMockPlayedGameRepository(t), NewMockGameRepository(t), NewMockEventPublisher(t))
+
+	achievementRepository.AssertNotCalled(t, "Insert")
+
+	assert.NoError(t, tracker.Publish(t.Context(), event.NewForPlayer(event.PlayedGameStarted, uuid.NewString(), nil)))
+	assert.NoError(t, tracker.Publish(t.Context(), event.New(event.GameCreated, nil)))
+}
@@ -0,0 +1,253 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+)
+
+type PlayerRepository interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+	FindByFilter(ctx context.Context, filter player.PlayerFilter) ([]player.Player, error)
+	Update(ctx context.Context, player *player.PlayerUpdate) (string, error)
+	Suspend(ctx context.Context, id string, suspension *player.Suspension) error
+}
+
+// PlayerCache is the cache of players used by the auth middleware
+type PlayerCache interface {
+	Invalidate(id string)
+}
+
+type Handler struct {
+	playerRepository PlayerRepository
+	playerCache      PlayerCache
+	now              func() time.Time
+}
+
+func NewHandler(playerRepository PlayerRepository, playerCache PlayerCache) *Handler {
+	return &Handler{
+		playerRepository: playerRepository,
+		playerCache:      playerCache,
+		now:              time.Now,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/admin/players")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"admin"}
+		// players are managed only from a session, not with api tokens
+		op.Metadata = map[string]any{apiutil.MetadataScope: apiutil.ScopeSession}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-get-players",
+		Method:      http.MethodGet,
+		Path:        "/",
+		Summary:     "get players",
+		Description: "get players with their suspensions filtered by username, role and state, admin only",
+	}, h.GetPlayers)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-set-admin",
+		Method:      http.MethodPut,
+		Path:        "/{id}/admin",
+		Summary:     "set admin",
+		Description: "promote a player to admin or demote an admin, admin only",
+	}, h.SetAdmin)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-deactivate-player",
+		Method:      http.MethodPost,
+		Path:        "/{id}/deactivate",
+		Summary:     "deactivate player",
+		Description: "deactivate a player with a reason and an optional expiry and log the player out, admin only",
+	}, h.Deactivate)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-ban-player",
+		Method:      http.MethodPost,
+		Path:        "/{id}/ban",
+		Summary:     "ban player",
+		Description: "ban a player with a reason and an optional expiry and log the player out, admin only",
+	}, h.Ban)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-lift-suspension",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/suspension",
+		Summary:     "lift suspension",
+		Description: "reactivate a deactivated or banned player, admin only",
+	}, h.LiftSuspension)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "admin-logout-player",
+		Method:      http.MethodPost,
+		Path:        "/{id}/logout",
+		Summary:     "log player out",
+		Description: "revoke all sessions and api tokens of a player, admin only",
+	}, h.Logout)
+}
+
+func (h *Handler) GetPlayers(ctx context.Context, i *RequestPlayers) (*domain.ResponseItems[ManagedPlayer], error) {
+	if _, ok := checkAdmin(ctx); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	players, err := h.playerRepository.FindByFilter(ctx, player.PlayerFilter{
+		Username: i.Username,
+		Role:     i.Role,
+		State:    i.State,
+	})
+	if err != nil {
+		log.Printf("admin find players: %v", err)
+		return nil, huma.Error500InternalServerError("find", err)
+	}
+
+	now := h.now()
+	resp := domain.ResponseItems[ManagedPlayer]{}
+	resp.Body.Items = make([]ManagedPlayer, 0, len(players))
+	for _, p := range players {
+		resp.Body.Items = append(resp.Body.Items, NewPlayer(&p, now))
+	}
+	return &resp, nil
+}
+
+func (h *Handler) SetAdmin(ctx context.Context, i *RequestSetAdmin) (*domain.ResponseItem[ManagedPlayer], error) {
+	ctxPlayer, ok := checkAdmin(ctx)
+	if !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+	// an admin must not be able to lock themselves out
+	if ctxPlayer.ID == i.ID && !i.Body.IsAdmin {
+		return nil, huma.Error400BadRequest("admin cannot demote themselves")
+	}
+
+	if _, err := h.playerRepository.Update(ctx, &player.PlayerUpdate{ID: i.ID, IsAdmin: &i.Body.IsAdmin}); err != nil {
+		log.Printf("admin set admin %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "update")
+	}
+	h.playerCache.Invalidate(i.ID)
+
+	return h.player(ctx, i.ID)
+}
+
+func (h *Handler) Deactivate(ctx context.Context, i *RequestSuspend) (*domain.ResponseItem[ManagedPlayer], error) {
+	return h.suspend(ctx, i, player.SuspensionDeactivated)
+}
+
+func (h *Handler) Ban(ctx context.Context, i *RequestSuspend) (*domain.ResponseItem[ManagedPlayer], error) {
+	return h.suspend(ctx, i, player.SuspensionBanned)
+}
+
+func (h *Handler) suspend(
+	ctx context.Context,
+	i *RequestSuspend,
+	kind player.SuspensionKind,
+) (*domain.ResponseItem[ManagedPlayer], error) {
+	ctxPlayer, ok := checkAdmin(ctx)
+	if !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+	if ctxPlayer.ID == i.ID {
+		return nil, huma.Error400BadRequest("admin cannot suspend themselves")
+	}
+
+	now := h.now()
+	if i.Body.Until != nil && !i.Body.Until.After(now) {
+		return nil, huma.Error400BadRequest("until must be in the future")
+	}
+
+	suspension := player.Suspension{
+		Kind:   kind,
+		Reason: i.Body.Reason,
+		At:     now,
+		Until:  i.Body.Until,
+	}
+	if err := h.playerRepository.Suspend(ctx, i.ID, &suspension); err != nil {
+		log.Printf("admin suspend %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "suspend")
+	}
+	// sessions end with the suspension, they are not restored when it is lifted
+	if err := h.revokeTokens(ctx, i.ID, now); err != nil {
+		return nil, err
+	}
+
+	return h.player(ctx, i.ID)
+}
+
+func (h *Handler) LiftSuspension(ctx context.Context, i *RequestPlayer) (*domain.ResponseItem[ManagedPlayer], error) {
+	if _, ok := checkAdmin(ctx); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if err := h.playerRepository.Suspend(ctx, i.ID, nil); err != nil {
+		log.Printf("admin lift suspension %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "lift suspension")
+	}
+	h.playerCache.Invalidate(i.ID)
+
+	return h.player(ctx, i.ID)
+}
+
+func (h *Handler) Logout(ctx context.Context, i *RequestPlayer) (*struct{}, error) {
+	if _, ok := checkAdmin(ctx); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if err := h.revokeTokens(ctx, i.ID, h.now()); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *Handler) revokeTokens(ctx context.Context, id string, now time.Time) error {
+	if _, err := h.playerRepository.Update(ctx, &player.PlayerUpdate{ID: id, TokensValidAfter: &now}); err != nil {
+		log.Printf("admin revoke tokens %v: %v", id, err)
+		return notFoundOr500(err, "revoke tokens")
+	}
+	h.playerCache.Invalidate(id)
+	return nil
+}
+
+func (h *Handler) player(ctx context.Context, id string) (*domain.ResponseItem[ManagedPlayer], error) {
+	found, err := h.playerRepository.FindOne(ctx, id)
+	if err != nil {
+		log.Printf("admin find player %v: %v", id, err)
+		return nil, notFoundOr500(err, "find")
+	}
+
+	item := NewPlayer(found, h.now())
+	resp := domain.ResponseItem[ManagedPlayer]{}
+	resp.Body.Item = &item
+	return &resp, nil
+}
+
+// NewPlayer shows only suspensions which are still active
+func NewPlayer(p *player.Player, now time.Time) ManagedPlayer {
+	out := ManagedPlayer{Player: *p, MustChangePassword: p.MustChangePassword}
+	if p.Suspended(now) {
+		out.Suspension = p.Suspension
+	}
+	return out
+}
+
+func notFoundOr500(err error, msg string) error {
+	if errors.Is(err, player.ErrPlayerNotFound) {
+		return huma.Error404NotFound("player not found")
+	}
+	return huma.Error500InternalServerError(msg, err)
+}
+
+func checkAdmin(ctx context.Context) (ctxutil.CtxPlayer, bool) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	return ctxPlayer, ok && ctxPlayer.IsAdmin
+}
@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBan(t *testing.T) {
+	playerRepo := NewMockPlayerRepository(t)
+	playerCache := NewMockPlayerCache(t)
+	handler := NewHandler(playerRepo, playerCache)
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	adminID := uuid.NewString()
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: adminID, IsAdmin: true})
+	until := now.Add(24 * time.Hour)
+
+	req := RequestSuspend{ID: playerID}
+	req.Body.Reason = "cheating"
+	req.Body.Until = &until
+
+	suspension := &player.Suspension{Kind: player.SuspensionBanned, Reason: "cheating", At: now, Until: &until}
+	playerRepo.On("Suspend", ctx, playerID, suspension).Once().Return(nil)
+	playerRepo.
+		On("Update", ctx, mock.MatchedBy(func(u *player.PlayerUpdate) bool {
+			return u.ID == playerID && u.TokensValidAfter != nil && u.TokensValidAfter.Equal(now)
+		})).
+		Once().
+		Return(playerID, nil)
+	playerCache.On("Invalidate", playerID).Once()
+	playerRepo.On("FindOne", ctx, playerID).Once().Return(&player.Player{ID: playerID, Suspension: suspension}, nil)
+
+	resp, err := handler.Ban(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, suspension, resp.Body.Item.Suspension)
+}
+
+func TestSuspend_Rejected(t *testing.T) {
+	adminID := uuid.NewString()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	tcases := []struct {
+		name     string
+		ctxUser  ctxutil.CtxPlayer
+		id       string
+		until    *time.Time
+		expected int
+	}{
+		{"not admin", ctxutil.CtxPlayer{ID: uuid.NewString()}, uuid.NewString(), nil, http.StatusForbidden},
+		{"themselves", ctxutil.CtxPlayer{ID: adminID, IsAdmin: true}, adminID, nil, http.StatusBadRequest},
+		{"until in the past", ctxutil.CtxPlayer{ID: adminID, IsAdmin: true}, uuid.NewString(), &past, http.StatusBadRequest},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(NewMockPlayerRepository(t), NewMockPlayerCache(t))
+			handler.now = func() time.Time { return now }
+			ctx := ctxutil.SetPlayer(t.Context(), tc.ctxUser)
+
+			req := RequestSuspend{ID: tc.id}
+			req.Body.Reason = "spam"
+			req.Body.Until = tc.until
+
+			_, err := handler.Deactivate(ctx, &req)
+			assertStatus(t, err, tc.expected)
+		})
+	}
+}
+
+func TestSetAdmin(t *testing.T) {
+	playerRepo := NewMockPlayerRepository(t)
+	playerCache := NewMockPlayerCache(t)
+	handler := NewHandler(playerRepo, playerCache)
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	req := RequestSetAdmin{ID: playerID}
+	req.Body.IsAdmin = true
+
+	playerRepo.
+		On("Update", ctx, mock.MatchedBy(func(u *player.PlayerUpdate) bool {
+			return u.ID == playerID && u.IsAdmin != nil && *u.IsAdmin
+		})).
+		Once().
+		Return(playerID, nil)
+	playerCache.On("Invalidate", playerID).Once()
+	playerRepo.On("FindOne", ctx, playerID).Once().Return(&player.Player{ID: playerID, IsAdmin: true}, nil)
+
+	resp, err := handler.SetAdmin(ctx, &req)
+	assert.NoError(t, err)
+	assert.True(t, resp.Body.Item.IsAdmin)
+}
+
+func TestSetAdmin_DemoteThemselves(t *testing.T) {
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockPlayerCache(t))
+
+	adminID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: adminID, IsAdmin: true})
+
+	_, err := handler.SetAdmin(ctx, &RequestSetAdmin{ID: adminID})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestGetPlayers_ExpiredSuspensionHidden(t *testing.T) {
+	playerRepo := NewMockPlayerRepository(t)
+	handler := NewHandler(playerRepo, NewMockPlayerCache(t))
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+	expired := now.Add(-time.Minute)
+	banned := &player.Suspension{Kind: player.SuspensionBanned, Reason: "spam", At: now.Add(-time.Hour)}
+
+	filter := player.PlayerFilter{Username: "bo", Role: player.RoleFilterPlayer}
+	playerRepo.On("FindByFilter", ctx, filter).Once().Return([]player.Player{
+		{ID: "1", Username: "bob", Suspension: banned},
+		{ID: "2", Username: "bobby", Suspension: &player.Suspension{Kind: player.SuspensionDeactivated, At: expired.Add(-time.Hour), Until: &expired}},
+	}, nil)
+
+	resp, err := handler.GetPlayers(ctx, &RequestPlayers{Username: "bo", Role: player.RoleFilterPlayer})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resp.Body.Items))
+	assert.True(t, resp.Body.Items[0].Suspension == banned)
+	assert.True(t, resp.Body.Items[1].Suspension == nil)
+}
+
+func TestGetPlayers_MustChangePassword(t *testing.T) {
+	playerRepo := NewMockPlayerRepository(t)
+	handler := NewHandler(playerRepo, NewMockPlayerCache(t))
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+	p := player.Player{ID: uuid.NewString(), Username: "bob", MustChangePassword: true}
+	playerRepo.On("FindByFilter", ctx, player.PlayerFilter{}).Once().Return([]player.Player{p}, nil)
+
+	resp, err := handler.GetPlayers(ctx, &RequestPlayers{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Body.Items))
+
+	managed, err := json.Marshal(resp.Body.Items[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(managed), `"must_change_password":true`)
+
+	public, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(public), "must_change_password")
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status, statusErr.GetStatus())
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+type RequestPlayers struct {
+	Username string `query:"username" maxLength:"64"`
+	Role     string `query:"role" enum:"admin,player"`
+	State    string `query:"state" enum:"active,deactivated,banned"`
+}
+
+type RequestPlayer struct {
+	ID string `path:"id" format:"uuid"`
+}
+
+type RequestSetAdmin struct {
+	ID   string `path:"id" format:"uuid"`
+	Body struct {
+		IsAdmin bool `json:"is_admin"`
+	}
+}
+
+type RequestSuspend struct {
+	ID   string `path:"id" format:"uuid"`
+	Body struct {
+		Reason string `json:"reason" minLength:"1" maxLength:"256"`
+		// Until is omitted for suspensions lifted only by an admin
+		Until *time.Time `json:"until" required:"false"`
+	}
+}
+
+// ManagedPlayer is a player with the fields only admins see, it has its own
+// name as api schemas are named after types
+type ManagedPlayer struct {
+	player.Player
+	MustChangePassword bool               `json:"must_change_password"`
+	Suspension         *player.Suspension `json:"suspension"`
+}
@@ -0,0 +1,117 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+)
+
+const (
+	// Prefix distinguishes api tokens from jwt in the Authorization header
+	Prefix = "pt_"
+
+	tokenBytes     = 32
+	displayedChars = 8
+)
+
+var (
+	ErrTokenNotFound = errors.New("api token is not found")
+	ErrNoName        = errors.New("name is empty")
+	ErrNoScopes      = errors.New("at least one scope is required")
+	ErrInvalidScope  = fmt.Errorf("scope must be one of %v", GrantableScopes)
+	ErrExpired       = errors.New("expiration is in the past")
+)
+
+var (
+	GrantableScopes = []string{
+		apiutil.ScopeRead,
+		apiutil.ScopePlayedGamesWrite,
+		apiutil.ScopeAdmin,
+	}
+)
+
+// Token is a long-lived player token for scripts and bots. Only hash of the token is stored,
+// the prefix helps the player to recognize it.
+type Token struct {
+	ID         int       `json:"id"`
+	PlayerID   string    `json:"player_id" format:"uuid"`
+	Name       string    `json:"name"`
+	Scopes     []string  `json:"scopes"`
+	Prefix     string    `json:"prefix"`
+	Hash       string    `json:"-"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// New generates a token and returns it with the plain value which is shown to the player once
+func New(playerID, name string, scopes []string, expiresAt time.Time) (*Token, string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	plain := Prefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return &Token{
+		PlayerID:  playerID,
+		Name:      name,
+		Scopes:    scopes,
+		Prefix:    plain[:len(Prefix)+displayedChars],
+		Hash:      Hash(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+func Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *Token) Valid(now time.Time) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return ErrNoName
+	}
+	if len(t.Scopes) == 0 {
+		return ErrNoScopes
+	}
+	for _, s := range t.Scopes {
+		if !slices.Contains(GrantableScopes, s) {
+			return ErrInvalidScope
+		}
+	}
+	if !t.ExpiresAt.IsZero() && !t.ExpiresAt.After(now) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Expired reports whether the token is expired, a token without expiration never expires
+func (t *Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !t.ExpiresAt.After(now)
+}
+
+// Allows reports whether the token grants the required scope. Admin grants everything
+// but a session, write grants read.
+func (t *Token) Allows(required string) bool {
+	switch required {
+	case apiutil.ScopeRead:
+		return len(t.Scopes) > 0
+	case apiutil.ScopePlayedGamesWrite:
+		return t.HasScope(apiutil.ScopePlayedGamesWrite) || t.HasScope(apiutil.ScopeAdmin)
+	case apiutil.ScopeAdmin:
+		return t.HasScope(apiutil.ScopeAdmin)
+	}
+	return false
+}
+
+func (t *Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
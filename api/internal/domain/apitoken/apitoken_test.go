@@ -0,0 +1,90 @@
+package apitoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+)
+
+func TestNew(t *testing.T) {
+	token, plain, err := New(uuid.NewString(), "bot", []string{apiutil.ScopeRead}, time.Time{})
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plain, Prefix))
+	assert.True(t, strings.HasPrefix(plain, token.Prefix))
+	assert.Equal(t, Hash(plain), token.Hash)
+	assert.NotEqual(t, plain, token.Hash)
+}
+
+func TestTokenValid(t *testing.T) {
+	now := time.Now()
+
+	tcases := []struct {
+		name  string
+		token Token
+		err   error
+	}{
+		{
+			"valid",
+			Token{Name: "bot", Scopes: []string{apiutil.ScopeRead, apiutil.ScopePlayedGamesWrite}},
+			nil,
+		},
+		{
+			"no name",
+			Token{Name: " ", Scopes: []string{apiutil.ScopeRead}},
+			ErrNoName,
+		},
+		{
+			"no scopes",
+			Token{Name: "bot"},
+			ErrNoScopes,
+		},
+		{
+			"session scope",
+			Token{Name: "bot", Scopes: []string{apiutil.ScopeSession}},
+			ErrInvalidScope,
+		},
+		{
+			"expired",
+			Token{Name: "bot", Scopes: []string{apiutil.ScopeRead}, ExpiresAt: now.Add(-time.Hour)},
+			ErrExpired,
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.IsError(t, tt.token.Valid(now), tt.err)
+		})
+	}
+}
+
+func TestTokenExpired(t *testing.T) {
+	now := time.Now()
+	token := Token{}
+	assert.False(t, token.Expired(now))
+
+	token.ExpiresAt = now.Add(time.Minute)
+	assert.False(t, token.Expired(now))
+	assert.True(t, token.Expired(now.Add(time.Hour)))
+}
+
+func TestTokenAllows(t *testing.T) {
+	read := Token{Scopes: []string{apiutil.ScopeRead}}
+	assert.True(t, read.Allows(apiutil.ScopeRead))
+	assert.False(t, read.Allows(apiutil.ScopePlayedGamesWrite))
+	assert.False(t, read.Allows(apiutil.ScopeAdmin))
+
+	write := Token{Scopes: []string{apiutil.ScopePlayedGamesWrite}}
+	assert.True(t, write.Allows(apiutil.ScopeRead))
+	assert.True(t, write.Allows(apiutil.ScopePlayedGamesWrite))
+	assert.False(t, write.Allows(apiutil.ScopeAdmin))
+
+	admin := Token{Scopes: []string{apiutil.ScopeAdmin}}
+	assert.True(t, admin.Allows(apiutil.ScopePlayedGamesWrite))
+	assert.True(t, admin.Allows(apiutil.ScopeAdmin))
+	assert.False(t, admin.Allows(apiutil.ScopeSession))
+}
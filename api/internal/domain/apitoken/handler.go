@@ -0,0 +1,148 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+)
+
+type TokenRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]Token, error)
+	Insert(ctx context.Context, token *Token) (int, error)
+	Delete(ctx context.Context, playerID string, id int) error
+}
+
+type PlayerRepository interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+}
+
+type Handler struct {
+	tokenRepository  TokenRepository
+	playerRepository PlayerRepository
+}
+
+func NewHandler(tokenRepository TokenRepository, playerRepository PlayerRepository) *Handler {
+	return &Handler{
+		tokenRepository:  tokenRepository,
+		playerRepository: playerRepository,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/players")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"tokens"}
+		// a leaked api token must not be able to create or list other tokens
+		op.Metadata = map[string]any{apiutil.MetadataScope: apiutil.ScopeSession}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "tokens-get-all",
+		Method:      http.MethodGet,
+		Path:        "/{id}/tokens",
+		Summary:     "get all api tokens",
+		Description: "get api tokens of a player",
+	}, h.GetAll)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "tokens-create-one",
+		Method:      http.MethodPost,
+		Path:        "/{id}/tokens",
+		Summary:     "create api token",
+		Description: "create an api token for scripts and bots, the token is returned only once",
+	}, h.Create)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "tokens-delete-one",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/tokens/{tokenID}",
+		Summary:     "revoke api token",
+		Description: "revoke an api token",
+	}, h.Delete)
+}
+
+func (h *Handler) GetAll(ctx context.Context, i *RequestPlayerTokens) (*domain.ResponseItems[Token], error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	tokens, err := h.tokenRepository.FindAll(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("api tokens find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Token]{}
+	resp.Body.Items = tokens
+	return &resp, nil
+}
+
+func (h *Handler) Create(ctx context.Context, i *RequestCreateToken) (*ResponseCreatedToken, error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	found, err := h.playerRepository.FindOne(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("api token create find player %v: %v", i.PlayerID, err)
+		return nil, huma.Error404NotFound("player not found")
+	}
+
+	var expiresAt time.Time
+	if i.Body.ExpiresAt != nil {
+		expiresAt = *i.Body.ExpiresAt
+	}
+
+	token, plain, err := New(found.ID, i.Body.Name, i.Body.Scopes, expiresAt)
+	if err != nil {
+		log.Printf("api token new: %v", err)
+		return nil, huma.Error500InternalServerError("could not create token")
+	}
+	if err := token.Valid(time.Now()); err != nil {
+		log.Printf("api token not valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+	if token.HasScope(apiutil.ScopeAdmin) && !found.IsAdmin {
+		return nil, huma.Error403Forbidden("admin scope requires an admin player")
+	}
+
+	id, err := h.tokenRepository.Insert(ctx, token)
+	if err != nil {
+		log.Printf("api token insert: %v", err)
+		return nil, huma.Error500InternalServerError("create", err)
+	}
+	token.ID = id
+
+	log.Printf("api token %v created for player %v", id, found.ID)
+	resp := ResponseCreatedToken{}
+	resp.Body.Item = token
+	resp.Body.Token = plain
+	return &resp, nil
+}
+
+func (h *Handler) Delete(ctx context.Context, i *RequestDeleteToken) (*domain.ResponseID[int], error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if err := h.tokenRepository.Delete(ctx, i.PlayerID, i.TokenID); err != nil {
+		log.Printf("api token delete %v: %v", i.TokenID, err)
+		if errors.Is(err, ErrTokenNotFound) {
+			return nil, huma.Error404NotFound("token not found")
+		}
+		return nil, huma.Error500InternalServerError("delete", err)
+	}
+
+	log.Printf("api token %v of player %v revoked", i.TokenID, i.PlayerID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.TokenID
+	return &resp, nil
+}
@@ -0,0 +1,118 @@
+package apitoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAll(t *testing.T) {
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(tokenRepository, NewMockPlayerRepository(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	tokens := []Token{{ID: 1, PlayerID: playerID, Name: "bot", Scopes: []string{apiutil.ScopeRead}}}
+
+	tokenRepository.
+		On("FindAll", ctx, playerID).
+		Once().
+		Return(tokens, nil)
+
+	resp, err := handler.GetAll(ctx, &RequestPlayerTokens{PlayerID: playerID})
+	assert.NoError(t, err)
+	assert.Equal(t, tokens, resp.Body.Items)
+}
+
+func TestGetAll_OtherPlayer(t *testing.T) {
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(tokenRepository, NewMockPlayerRepository(t))
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	tokenRepository.AssertNotCalled(t, "FindAll")
+
+	_, err := handler.GetAll(ctx, &RequestPlayerTokens{PlayerID: uuid.NewString()})
+	assert.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	tokenRepository := NewMockTokenRepository(t)
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(tokenRepository, playerRepository)
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	req := RequestCreateToken{PlayerID: playerID}
+	req.Body.Name = "discord bot"
+	req.Body.Scopes = []string{apiutil.ScopeRead, apiutil.ScopePlayedGamesWrite}
+	req.Body.ExpiresAt = &expiresAt
+
+	playerRepository.
+		On("FindOne", ctx, playerID).
+		Once().
+		Return(&player.Player{ID: playerID}, nil)
+
+	var inserted *Token
+	tokenRepository.
+		On("Insert", ctx, mock.MatchedBy(func(token *Token) bool {
+			inserted = token
+			return token.PlayerID == playerID && token.Name == req.Body.Name
+		})).
+		Once().
+		Return(7, nil)
+
+	resp, err := handler.Create(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, resp.Body.Item.ID)
+	assert.Equal(t, Hash(resp.Body.Token), inserted.Hash)
+	assert.Equal(t, req.Body.Scopes, inserted.Scopes)
+	assert.True(t, expiresAt.Equal(inserted.ExpiresAt))
+}
+
+func TestCreate_AdminScope_NotAdmin(t *testing.T) {
+	tokenRepository := NewMockTokenRepository(t)
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(tokenRepository, playerRepository)
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	req := RequestCreateToken{PlayerID: playerID}
+	req.Body.Name = "script"
+	req.Body.Scopes = []string{apiutil.ScopeAdmin}
+
+	playerRepository.
+		On("FindOne", ctx, playerID).
+		Once().
+		Return(&player.Player{ID: playerID}, nil)
+
+	tokenRepository.AssertNotCalled(t, "Insert")
+
+	_, err := handler.Create(ctx, &req)
+	assert.Error(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(tokenRepository, NewMockPlayerRepository(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	tokenRepository.
+		On("Delete", ctx, playerID, 3).
+		Once().
+		Return(ErrTokenNotFound)
+
+	_, err := handler.Delete(ctx, &RequestDeleteToken{PlayerID: playerID, TokenID: 3})
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	TableAPIToken = "api_token"
+)
+
+const (
+	tokenColumns string = `id, player_id, name, scopes, token_prefix, token_hash,
+	expires_at, last_used_at, created_at`
+)
+
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGRepository) FindAll(ctx context.Context, playerID string) ([]Token, error) {
+	out := make([]Token, 0)
+
+	sqlBuild := sq.Select(tokenColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableAPIToken).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := tokenFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, nil
+}
+
+func (r *PGRepository) FindOneByHash(ctx context.Context, hash string) (*Token, error) {
+	sqlBuild := sq.Select(tokenColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableAPIToken).
+		Where(sq.Eq{"token_hash": hash})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := tokenFromRow(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *PGRepository) Insert(ctx context.Context, token *Token) (int, error) {
+	var id int
+
+	var expiresAt *time.Time
+	if !token.ExpiresAt.IsZero() {
+		e := token.ExpiresAt.UTC()
+		expiresAt = &e
+	}
+
+	sqlBuild := sq.Insert(TableAPIToken).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "name", "scopes", "token_prefix", "token_hash", "expires_at").
+		Values(token.PlayerID, token.Name, token.Scopes, token.Prefix, token.Hash, expiresAt).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Delete revokes the token of the player
+func (r *PGRepository) Delete(ctx context.Context, playerID string, id int) error {
+	sqlBuild := sq.Delete(TableAPIToken).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"id": id, "player_id": playerID})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *PGRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	sqlBuild := sq.Update(TableAPIToken).
+		PlaceholderFormat(sq.Dollar).
+		Set("last_used_at", at.UTC()).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, query, args...)
+	return err
+}
+
+func tokenFromRow(row pgx.Row) (*Token, error) {
+	var t Token
+	var expiresAt, lastUsedAt *time.Time
+	err := row.Scan(
+		&t.ID,
+		&t.PlayerID,
+		&t.Name,
+		&t.Scopes,
+		&t.Prefix,
+		&t.Hash,
+		&expiresAt,
+		&lastUsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil {
+		t.ExpiresAt = *expiresAt
+	}
+	if lastUsedAt != nil {
+		t.LastUsedAt = *lastUsedAt
+	}
+	return &t, nil
+}
@@ -0,0 +1,29 @@
+package apitoken
+
+import "time"
+
+type RequestPlayerTokens struct {
+	PlayerID string `path:"id" format:"uuid"`
+}
+
+type RequestCreateToken struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Body     struct {
+		Name      string     `json:"name" minLength:"1" maxLength:"64"`
+		Scopes    []string   `json:"scopes" minItems:"1" uniqueItems:"true" enum:"read,played_games:write,admin"`
+		ExpiresAt *time.Time `json:"expires_at" required:"false"`
+	}
+}
+
+type RequestDeleteToken struct {
+	PlayerID string `path:"id" format:"uuid"`
+	TokenID  int    `path:"tokenID"`
+}
+
+type ResponseCreatedToken struct {
+	Body struct {
+		Item *Token `json:"item"`
+		// Token is shown only once
+		Token string `json:"token" readOnly:"true"`
+	}
+}
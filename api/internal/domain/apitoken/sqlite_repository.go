@@ -0,0 +1,169 @@
+package apitoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+// SQLiteRepository stores tokens in sqlite, scopes are kept as a json array
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context, playerID string) ([]Token, error) {
+	out := make([]Token, 0)
+
+	sqlBuild := sq.Select(tokenColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableAPIToken).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := sqliteTokenFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) FindOneByHash(ctx context.Context, hash string) (*Token, error) {
+	sqlBuild := sq.Select(tokenColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableAPIToken).
+		Where(sq.Eq{"token_hash": hash})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := sqliteTokenFromRow(db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *SQLiteRepository) Insert(ctx context.Context, token *Token) (int, error) {
+	var id int
+
+	var expiresAt *string
+	if !token.ExpiresAt.IsZero() {
+		e := db.SQLiteTime(token.ExpiresAt)
+		expiresAt = &e
+	}
+	scopes, err := db.SQLiteJSON(token.Scopes)
+	if err != nil {
+		return id, err
+	}
+
+	sqlBuild := sq.Insert(TableAPIToken).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "name", "scopes", "token_prefix", "token_hash", "expires_at").
+		Values(token.PlayerID, token.Name, scopes, token.Prefix, token.Hash, expiresAt).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Delete revokes the token of the player
+func (r *SQLiteRepository) Delete(ctx context.Context, playerID string, id int) error {
+	sqlBuild := sq.Delete(TableAPIToken).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"id": id, "player_id": playerID})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	sqlBuild := sq.Update(TableAPIToken).
+		PlaceholderFormat(sq.Question).
+		Set("last_used_at", db.SQLiteTime(at)).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func sqliteTokenFromRow(row pgx.Row) (*Token, error) {
+	var t Token
+	var expiresAt, lastUsedAt *time.Time
+	err := row.Scan(
+		&t.ID,
+		&t.PlayerID,
+		&t.Name,
+		db.SQLiteJSONScanner(&t.Scopes),
+		&t.Prefix,
+		&t.Hash,
+		&expiresAt,
+		&lastUsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil {
+		t.ExpiresAt = *expiresAt
+	}
+	if lastUsedAt != nil {
+		t.LastUsedAt = *lastUsedAt
+	}
+	return &t, nil
+}
@@ -2,40 +2,146 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/db"
 	"github.com/lardira/playtrack/internal/domain"
 	"github.com/lardira/playtrack/internal/domain/player"
 	"github.com/lardira/playtrack/internal/middleware"
 	"github.com/lardira/playtrack/internal/pkg/apiutil"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
+	"github.com/lardira/playtrack/internal/pkg/mailer"
 	"github.com/lardira/playtrack/internal/pkg/password"
+	"github.com/lardira/playtrack/internal/pkg/ratelimit"
 )
 
 const (
 	defaultExpiration = 10 * 24 * time.Hour
+
+	defaultLoginMaxAttempts = 5
+	defaultLoginLockout     = 15 * time.Minute
+	defaultRateLimit        = 10
+	defaultRateLimitPer     = time.Minute
+
+	defaultResetTokenTTL  = 1 * time.Hour
+	defaultVerifyTokenTTL = 48 * time.Hour
+
+	jwksCacheControl = "public, max-age=300"
 )
 
 type PlayerRepository interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
 	FindOneByUsername(ctx context.Context, username string) (*player.Player, error)
+	FindOneByEmail(ctx context.Context, email string) (*player.Player, error)
 	Update(ctx context.Context, player *player.PlayerUpdate) (string, error)
 	Insert(context.Context, *player.Player) (string, error)
+	RegisterFailedLogin(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, id string) error
+}
+
+// PlayerCache is the cache of players used by the auth middleware, password changes
+// invalidate it so revoked tokens and the must change password flag apply at once
+type PlayerCache interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+	Invalidate(id string)
+}
+
+type TokenRepository interface {
+	Insert(ctx context.Context, token *Token) (int, error)
+	Consume(ctx context.Context, purpose TokenPurpose, hash string) (*Token, error)
+}
+
+type Options struct {
+	// LoginMaxAttempts is a number of failed logins in a row before the player is locked
+	LoginMaxAttempts int
+	LoginLockout     time.Duration
+
+	// RateLimit is a number of login/register requests allowed per RateLimitPer
+	// for a single ip and for a single username
+	RateLimit    int
+	RateLimitPer time.Duration
+	// TrustedProxies may set the client ip in X-Real-IP and X-Forwarded-For,
+	// without them the ip limit uses the address of the connection
+	TrustedProxies []netip.Prefix
+
+	// AppURL is a public url of the web app used in links sent by email
+	AppURL         string
+	ResetTokenTTL  time.Duration
+	VerifyTokenTTL time.Duration
 }
 
 type Handler struct {
-	secret           string
+	keys             *keyset.KeySet
 	playerRepository PlayerRepository
+	tokenRepository  TokenRepository
+	playerCache      PlayerCache
+	mailer           mailer.Mailer
+
+	appURL         string
+	resetTokenTTL  time.Duration
+	verifyTokenTTL time.Duration
+
+	loginMaxAttempts int
+	loginLockout     time.Duration
+	ipLimiter        *ratelimit.Limiter
+	clientIP         func(huma.Context) string
+	usernameLimiter  *ratelimit.Limiter
+	authorize        func(ctx huma.Context, next func(huma.Context))
 }
 
-func NewHandler(secret string, playerRepository PlayerRepository) *Handler {
+func NewHandler(
+	keys *keyset.KeySet,
+	playerRepository PlayerRepository,
+	tokenRepository TokenRepository,
+	playerCache PlayerCache,
+	mailer mailer.Mailer,
+	opts Options,
+) *Handler {
+	if opts.LoginMaxAttempts <= 0 {
+		opts.LoginMaxAttempts = defaultLoginMaxAttempts
+	}
+	if opts.LoginLockout <= 0 {
+		opts.LoginLockout = defaultLoginLockout
+	}
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = defaultRateLimit
+	}
+	if opts.RateLimitPer <= 0 {
+		opts.RateLimitPer = defaultRateLimitPer
+	}
+	if opts.ResetTokenTTL <= 0 {
+		opts.ResetTokenTTL = defaultResetTokenTTL
+	}
+	if opts.VerifyTokenTTL <= 0 {
+		opts.VerifyTokenTTL = defaultVerifyTokenTTL
+	}
+
 	return &Handler{
-		secret:           secret,
+		keys:             keys,
 		playerRepository: playerRepository,
+		tokenRepository:  tokenRepository,
+		playerCache:      playerCache,
+		mailer:           mailer,
+		appURL:           strings.TrimSuffix(opts.AppURL, "/"),
+		resetTokenTTL:    opts.ResetTokenTTL,
+		verifyTokenTTL:   opts.VerifyTokenTTL,
+		loginMaxAttempts: opts.LoginMaxAttempts,
+		loginLockout:     opts.LoginLockout,
+		ipLimiter:        ratelimit.New(opts.RateLimit, opts.RateLimitPer),
+		clientIP:         middleware.ClientIP(opts.TrustedProxies),
+		usernameLimiter:  ratelimit.New(opts.RateLimit, opts.RateLimitPer),
+		// account operations are not available with api tokens
+		authorize: middleware.Authorize(keys, playerCache, nil),
 	}
 }
 
@@ -51,6 +157,7 @@ func (h *Handler) Register(api huma.API) {
 		Path:        "/register",
 		Summary:     "register player",
 		Description: "register a new player (auth and player entity will be created)",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.ipLimiter, h.clientIP)},
 	}, h.RegisterPlayer)
 
 	huma.Register(grp, huma.Operation{
@@ -59,29 +166,121 @@ func (h *Handler) Register(api huma.API) {
 		Path:        "/login",
 		Summary:     "login",
 		Description: "login a player in order to get a jwt token",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.ipLimiter, h.clientIP)},
 	}, h.Login)
 
 	huma.Register(grp, huma.Operation{
-		OperationID: "set-password",
+		OperationID: "change-password",
+		Method:      http.MethodPatch,
+		Path:        "/change-password",
+		Summary:     "change password",
+		Description: "change password of the current player, the old password is required",
+		Security:    apiutil.OperationSecurity,
+		Metadata:    map[string]any{middleware.MetadataAllowMustChangePassword: true},
+		Middlewares: huma.Middlewares{h.authorize},
+	}, h.ChangePassword)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "reset-player-password",
 		Method:      http.MethodPatch,
-		Path:        "/set-password",
-		Summary:     "set new password",
-		Description: "set new password for a player secured",
+		Path:        "/players/{id}/reset-password",
+		Summary:     "reset player password",
+		Description: "set a temporary password for a player (admin only), it must be changed on the next login",
+		Security:    apiutil.OperationSecurity,
+		Middlewares: huma.Middlewares{h.authorize},
+	}, h.ResetPlayerPassword)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "forgot-password",
+		Method:      http.MethodPost,
+		Path:        "/forgot-password",
+		Summary:     "forgot password",
+		Description: "send a password reset link to the player email",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.ipLimiter, h.clientIP)},
+	}, h.ForgotPassword)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "reset-password",
+		Method:      http.MethodPost,
+		Path:        "/reset-password",
+		Summary:     "reset password",
+		Description: "set new password using a token from the password reset email",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.ipLimiter, h.clientIP)},
+	}, h.ResetPassword)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "send-verification-email",
+		Method:      http.MethodPost,
+		Path:        "/send-verification",
+		Summary:     "send verification email",
+		Description: "send an email verification link to the player email",
 		Security:    apiutil.OperationSecurity,
-		Middlewares: huma.Middlewares{middleware.Authorize(h.secret)},
-	}, h.SetPassword)
+		Middlewares: huma.Middlewares{
+			h.authorize,
+			middleware.RateLimit(h.ipLimiter, h.clientIP),
+		},
+	}, h.SendVerification)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "verify-email",
+		Method:      http.MethodPost,
+		Path:        "/verify-email",
+		Summary:     "verify email",
+		Description: "verify the player email using a token from the verification email",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.ipLimiter, h.clientIP)},
+	}, h.VerifyEmail)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-jwks",
+		Method:      http.MethodGet,
+		Path:        "/.well-known/jwks.json",
+		Summary:     "get jwks",
+		Description: "get public keys to verify jwt tokens, a key is selected by the kid token header",
+		Tags:        []string{"auth"},
+	}, h.GetJWKS)
 }
 
 func (h *Handler) Login(ctx context.Context, i *RequestLoginPlayer) (*ResponseLoginPlayer, error) {
-	found, err := h.playerRepository.FindOneByUsername(ctx, i.Body.Username)
+	if !h.usernameLimiter.Allow(strings.ToLower(i.Body.Username)) {
+		log.Printf("login rate limit for %v", i.Body.Username)
+		return nil, huma.Error429TooManyRequests("too many login attempts, try again later")
+	}
+
+	found, err := h.findByLogin(ctx, i.Body.Username)
 	if err != nil {
 		log.Printf("login player find one: %v", err)
 		return nil, huma.Error401Unauthorized("username or password is incorrect")
 	}
-	if !password.CompareHash(i.Body.Password, found.Password) {
-		log.Printf("login compare hash: %v", err)
+	// a locked account answers like a wrong password and the hash is compared anyway,
+	// so neither the answer nor its time tells the lock or a guessed password
+	matches := password.CompareHash(i.Body.Password, found.Password)
+	if found.Locked(time.Now()) {
+		log.Printf("login player %v is locked until %v", found.ID, found.LockedUntil)
+		return nil, huma.Error401Unauthorized("username or password is incorrect")
+	}
+	if !matches {
+		log.Printf("login compare hash: player %v", found.ID)
+
+		lockedUntil, err := h.playerRepository.RegisterFailedLogin(ctx, found.ID, h.loginMaxAttempts, h.loginLockout)
+		if err != nil {
+			log.Printf("login register failed: %v", err)
+		} else if lockedUntil != nil && lockedUntil.After(time.Now()) {
+			log.Printf("player %v locked until %v", found.ID, lockedUntil)
+		}
 		return nil, huma.Error401Unauthorized("username or password is incorrect")
 	}
+	// suspension is told only to who knows the password
+	if found.Suspended(time.Now()) {
+		log.Printf("login player %v is suspended", found.ID)
+		return nil, huma.Error403Forbidden(suspendedMessage(found.Suspension))
+	}
+
+	if found.FailedLoginAttempts > 0 || !found.LockedUntil.IsZero() {
+		if err := h.playerRepository.ResetFailedLogins(ctx, found.ID); err != nil {
+			log.Printf("login reset failed: %v", err)
+		}
+	}
+	h.rehashPassword(ctx, found, i.Body.Password)
 
 	token, err := h.issueToken(found)
 	if err != nil {
@@ -91,6 +290,7 @@ func (h *Handler) Login(ctx context.Context, i *RequestLoginPlayer) (*ResponseLo
 
 	resp := ResponseLoginPlayer{}
 	resp.Body.Token = token
+	resp.Body.MustChangePassword = found.MustChangePassword
 	return &resp, nil
 }
 
@@ -98,12 +298,18 @@ func (h *Handler) RegisterPlayer(
 	ctx context.Context,
 	i *RequestRegisterCreatePlayer,
 ) (*domain.ResponseID[string], error) {
+	if !h.usernameLimiter.Allow(strings.ToLower(i.Body.Username)) {
+		log.Printf("register rate limit for %v", i.Body.Username)
+		return nil, huma.Error429TooManyRequests("too many attempts, try again later")
+	}
+
 	nPlayer := player.Player{
 		Username: i.Body.Username,
 		Img:      i.Body.Img,
 		Email:    i.Body.Email,
 		Password: i.Body.Password,
 	}
+	nPlayer.Normalize()
 	if err := nPlayer.Valid(); err != nil {
 		log.Printf("register player not valid: %v", err)
 		return nil, huma.Error400BadRequest("entity is not valid", err)
@@ -117,65 +323,377 @@ func (h *Handler) RegisterPlayer(
 	nPlayer.Password = hashedPassword
 
 	id, err := h.playerRepository.Insert(ctx, &nPlayer)
+	if errors.Is(err, player.ErrUsernameTaken) || errors.Is(err, player.ErrEmailTaken) {
+		log.Printf("register insert player: %v", err)
+		return nil, huma.Error409Conflict(err.Error())
+	}
 	if err != nil {
 		log.Printf("register insert player: %v", err)
 		return nil, huma.Error500InternalServerError("create", err)
 	}
 
 	log.Printf("player %v created", id)
+
+	if nPlayer.Email != nil {
+		nPlayer.ID = id
+		if err := h.sendVerification(ctx, &nPlayer); err != nil {
+			log.Printf("register send verification %v: %v", id, err)
+		}
+	}
+
 	resp := domain.ResponseID[string]{}
 	resp.Body.ID = id
 	return &resp, nil
 }
 
-func (h *Handler) SetPassword(
+// ChangePassword changes password of the current player, the old password is required.
+// Tokens issued before are invalidated so a new one is returned.
+func (h *Handler) ChangePassword(
 	ctx context.Context,
-	i *RequestSetPassword,
-) (*domain.ResponseID[string], error) {
+	i *RequestChangePassword,
+) (*ResponseLoginPlayer, error) {
 	ctxPlr, ok := ctxutil.GetPlayer(ctx)
 	if !ok {
 		return nil, huma.Error401Unauthorized("player id is invalid")
 	}
 
-	found, err := h.playerRepository.FindOneByUsername(ctx, i.Body.Username)
+	found, err := h.playerRepository.FindOne(ctx, ctxPlr.ID)
 	if err != nil {
-		log.Printf("find one by username %v: %v", i.Body.Username, err)
+		log.Printf("change pass find one %v: %v", ctxPlr.ID, err)
 		return nil, huma.Error401Unauthorized("player not found")
 	}
-	if !ctxPlr.IsAdmin && (found.ID != ctxPlr.ID) {
-		log.Printf("player %v access to %v", ctxPlr, found.ID)
+	if !password.CompareHash(i.Body.OldPassword, found.Password) {
+		log.Printf("change pass compare hash: player %v", found.ID)
+
+		if _, err := h.playerRepository.RegisterFailedLogin(ctx, found.ID, h.loginMaxAttempts, h.loginLockout); err != nil {
+			log.Printf("change pass register failed: %v", err)
+		}
+		return nil, huma.Error403Forbidden("old password is incorrect")
+	}
+	if i.Body.OldPassword == i.Body.NewPassword {
+		return nil, huma.Error400BadRequest("new password must differ from the old one")
+	}
+
+	mustChange := false
+	now := time.Now().UTC()
+	nPlayer := player.PlayerUpdate{
+		ID:                 found.ID,
+		Password:           &i.Body.NewPassword,
+		MustChangePassword: &mustChange,
+		TokensValidAfter:   &now,
+	}
+	if err := nPlayer.Valid(); err != nil {
+		log.Printf("change pass not valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	hashedPassword, err := password.Hash(i.Body.NewPassword)
+	if err != nil {
+		log.Printf("change pass hash: %v", err)
+		return nil, huma.Error500InternalServerError("could not update player")
+	}
+	nPlayer.Password = &hashedPassword
+
+	id, err := h.playerRepository.Update(ctx, &nPlayer)
+	if err != nil {
+		log.Printf("change pass player update: %v", err)
+		return nil, huma.Error500InternalServerError("update", err)
+	}
+	h.invalidate(ctx, id)
+	log.Printf("player %v updated (pass)", id)
+
+	found.MustChangePassword = false
+	token, err := h.issueToken(found)
+	if err != nil {
+		log.Printf("change pass issue token: %v", err)
+		return nil, huma.Error500InternalServerError("could not issue token", err)
+	}
+
+	resp := ResponseLoginPlayer{}
+	resp.Body.Token = token
+	return &resp, nil
+}
+
+// ResetPlayerPassword sets a temporary password for a player by an admin.
+// The player must change it on the next login, all the player tokens are invalidated.
+func (h *Handler) ResetPlayerPassword(
+	ctx context.Context,
+	i *RequestResetPlayerPassword,
+) (*domain.ResponseID[string], error) {
+	ctxPlr, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+	if !ctxPlr.IsAdmin {
+		log.Printf("player %v reset pass of %v", ctxPlr.ID, i.PlayerID)
 		return nil, huma.Error403Forbidden("player cannot access this entity")
 	}
 
+	found, err := h.playerRepository.FindOne(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("reset player pass find one %v: %v", i.PlayerID, err)
+		return nil, huma.Error404NotFound("player not found")
+	}
+
+	mustChange := true
+	now := time.Now().UTC()
+	nPlayer := player.PlayerUpdate{
+		ID:                 found.ID,
+		Password:           &i.Body.Password,
+		MustChangePassword: &mustChange,
+		TokensValidAfter:   &now,
+	}
+	if err := nPlayer.Valid(); err != nil {
+		log.Printf("reset player pass not valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	hashedPassword, err := password.Hash(i.Body.Password)
+	if err != nil {
+		log.Printf("reset player pass hash: %v", err)
+		return nil, huma.Error500InternalServerError("could not update player")
+	}
+	nPlayer.Password = &hashedPassword
+
+	id, err := h.playerRepository.Update(ctx, &nPlayer)
+	if err != nil {
+		log.Printf("reset player pass update: %v", err)
+		return nil, huma.Error500InternalServerError("update", err)
+	}
+
+	if err := h.playerRepository.ResetFailedLogins(ctx, id); err != nil {
+		log.Printf("reset player pass reset failed logins: %v", err)
+	}
+	h.invalidate(ctx, id)
+
+	log.Printf("player %v password reset by %v", id, ctxPlr.ID)
+	resp := domain.ResponseID[string]{}
+	resp.Body.ID = id
+	return &resp, nil
+}
+
+func (h *Handler) ForgotPassword(
+	ctx context.Context,
+	i *RequestForgotPassword,
+) (*domain.ResponseMessage, error) {
+	resp := domain.ResponseMessage{}
+	resp.Body.Message = "if the email is registered, a password reset link has been sent"
+
+	// the response is the same for unknown emails to not disclose registered ones
+	found, err := h.playerRepository.FindOneByEmail(ctx, i.Body.Email)
+	if err != nil {
+		log.Printf("forgot password find by email: %v", err)
+		return &resp, nil
+	}
+
+	// failures are only logged, an error would disclose that the email is registered
+	token, plain, err := NewToken(found.ID, TokenPurposePasswordReset, h.resetTokenTTL)
+	if err != nil {
+		log.Printf("forgot password new token: %v", err)
+		return &resp, nil
+	}
+	if _, err := h.tokenRepository.Insert(ctx, token); err != nil {
+		log.Printf("forgot password token insert: %v", err)
+		return &resp, nil
+	}
+
+	msg := mailer.Message{
+		To:      *found.Email,
+		Subject: "playtrack password reset",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nuse the link below to set a new password:\n%s/reset-password?token=%s\n\n"+
+				"The link expires in %v. If you did not request a password reset, ignore this email.\n",
+			found.Username, h.appURL, plain, h.resetTokenTTL,
+		),
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("forgot password send: %v", err)
+		return &resp, nil
+	}
+
+	log.Printf("player %v password reset requested", found.ID)
+	return &resp, nil
+}
+
+func (h *Handler) ResetPassword(
+	ctx context.Context,
+	i *RequestResetPassword,
+) (*domain.ResponseID[string], error) {
 	nPlayer := player.PlayerUpdate{
-		ID:       found.ID,
-		Username: &found.Username,
 		Password: &i.Body.Password,
 	}
 	if err := nPlayer.Valid(); err != nil {
-		log.Printf("set pass not valid: %v", err)
+		log.Printf("reset pass not valid: %v", err)
 		return nil, huma.Error400BadRequest("entity is not valid", err)
 	}
 
+	token, err := h.tokenRepository.Consume(ctx, TokenPurposePasswordReset, HashToken(i.Body.Token))
+	if err != nil {
+		log.Printf("reset pass consume token: %v", err)
+		return nil, huma.Error400BadRequest("token is invalid or expired")
+	}
+
 	hashedPassword, err := password.Hash(i.Body.Password)
 	if err != nil {
-		log.Printf("set pass hash: %v", err)
+		log.Printf("reset pass hash: %v", err)
 		return nil, huma.Error500InternalServerError("could not update player")
 	}
+	mustChange := false
+	now := time.Now().UTC()
+	nPlayer.ID = token.PlayerID
 	nPlayer.Password = &hashedPassword
+	nPlayer.MustChangePassword = &mustChange
+	nPlayer.TokensValidAfter = &now
 
 	id, err := h.playerRepository.Update(ctx, &nPlayer)
 	if err != nil {
-		log.Printf("set pass player update: %v", err)
-		return nil, huma.Error500InternalServerError("create", err)
+		log.Printf("reset pass player update: %v", err)
+		return nil, huma.Error500InternalServerError("update", err)
 	}
 
-	log.Printf("player %v updated (pass)", id)
+	if err := h.playerRepository.ResetFailedLogins(ctx, id); err != nil {
+		log.Printf("reset pass reset failed logins: %v", err)
+	}
+	h.invalidate(ctx, id)
+
+	log.Printf("player %v updated (pass reset)", id)
+	resp := domain.ResponseID[string]{}
+	resp.Body.ID = id
+	return &resp, nil
+}
+
+func (h *Handler) SendVerification(
+	ctx context.Context,
+	i *struct{},
+) (*domain.ResponseMessage, error) {
+	ctxPlr, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	found, err := h.playerRepository.FindOne(ctx, ctxPlr.ID)
+	if err != nil {
+		log.Printf("send verification find one %v: %v", ctxPlr.ID, err)
+		return nil, huma.Error401Unauthorized("player not found")
+	}
+	if found.EmailVerified {
+		return nil, huma.Error409Conflict("email is already verified")
+	}
+	if found.Email == nil {
+		return nil, huma.Error400BadRequest("player has no email")
+	}
+
+	if err := h.sendVerification(ctx, found); err != nil {
+		log.Printf("send verification %v: %v", found.ID, err)
+		return nil, huma.Error500InternalServerError("could not send email")
+	}
+
+	resp := domain.ResponseMessage{}
+	resp.Body.Message = "verification link has been sent"
+	return &resp, nil
+}
+
+func (h *Handler) VerifyEmail(
+	ctx context.Context,
+	i *RequestVerifyEmail,
+) (*domain.ResponseID[string], error) {
+	token, err := h.tokenRepository.Consume(ctx, TokenPurposeEmailVerify, HashToken(i.Body.Token))
+	if err != nil {
+		log.Printf("verify email consume token: %v", err)
+		return nil, huma.Error400BadRequest("token is invalid or expired")
+	}
+
+	verified := true
+	id, err := h.playerRepository.Update(ctx, &player.PlayerUpdate{
+		ID:            token.PlayerID,
+		EmailVerified: &verified,
+	})
+	if err != nil {
+		log.Printf("verify email player update: %v", err)
+		return nil, huma.Error500InternalServerError("update", err)
+	}
+
+	log.Printf("player %v email verified", id)
 	resp := domain.ResponseID[string]{}
 	resp.Body.ID = id
 	return &resp, nil
 }
 
+func (h *Handler) GetJWKS(ctx context.Context, i *struct{}) (*ResponseJWKS, error) {
+	resp := ResponseJWKS{}
+	resp.CacheControl = jwksCacheControl
+	resp.Body = h.keys.JWKS()
+	return &resp, nil
+}
+
+func (h *Handler) sendVerification(ctx context.Context, p *player.Player) error {
+	token, plain, err := NewToken(p.ID, TokenPurposeEmailVerify, h.verifyTokenTTL)
+	if err != nil {
+		return err
+	}
+	if _, err := h.tokenRepository.Insert(ctx, token); err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mailer.Message{
+		To:      *p.Email,
+		Subject: "playtrack email verification",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nconfirm your email using the link below:\n%s/verify-email?token=%s\n\n"+
+				"The link expires in %v.\n",
+			p.Username, h.appURL, plain, h.verifyTokenTTL,
+		),
+	})
+}
+
+// rehashPassword updates the password hash if it was created with an outdated cost
+func (h *Handler) rehashPassword(ctx context.Context, p *player.Player, plain string) {
+	if !password.NeedsRehash(p.Password) {
+		return
+	}
+
+	hashedPassword, err := password.Hash(plain)
+	if err != nil {
+		log.Printf("rehash pass hash: %v", err)
+		return
+	}
+
+	_, err = h.playerRepository.Update(ctx, &player.PlayerUpdate{
+		ID:       p.ID,
+		Password: &hashedPassword,
+	})
+	if err != nil {
+		log.Printf("rehash player %v update: %v", p.ID, err)
+		return
+	}
+	log.Printf("player %v password rehashed", p.ID)
+}
+
+// invalidate drops the cached player once the update is committed
+func (h *Handler) invalidate(ctx context.Context, playerID string) {
+	db.AfterCommit(ctx, func() { h.playerCache.Invalidate(playerID) })
+}
+
+// findByLogin finds the player by the email or the username, usernames cannot contain @
+func (h *Handler) findByLogin(ctx context.Context, login string) (*player.Player, error) {
+	if strings.Contains(login, "@") {
+		return h.playerRepository.FindOneByEmail(ctx, login)
+	}
+	return h.playerRepository.FindOneByUsername(ctx, login)
+}
+
+// suspendedMessage tells the player why the account is disabled and until when
+func suspendedMessage(s *player.Suspension) string {
+	msg := fmt.Sprintf("account is %s", s.Kind)
+	if s.Until != nil {
+		msg += " until " + s.Until.UTC().Format(time.RFC3339)
+	}
+	if s.Reason != "" {
+		msg += ": " + s.Reason
+	}
+	return msg
+}
+
 func (h *Handler) issueToken(p *player.Player) (string, error) {
 	now := time.Now()
 	audience := []string{apiutil.RolePlayer}
@@ -193,6 +711,5 @@ func (h *Handler) issueToken(p *player.Player) (string, error) {
 		Audience:  audience,
 	}
 
-	token := jwt.NewWithClaims(apiutil.DefaultSigningMethod, claims)
-	return token.SignedString([]byte(h.secret))
+	return h.keys.Sign(claims)
 }
@@ -1,34 +1,54 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/lardira/playtrack/internal/domain/player"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
+	"github.com/lardira/playtrack/internal/pkg/mailer"
 	"github.com/lardira/playtrack/internal/pkg/password"
 	"github.com/lardira/playtrack/internal/pkg/testutil"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
-const (
-	testSecret = "test"
+var (
+	testKeys = newTestKeys()
 )
 
+func newTestKeys() *keyset.KeySet {
+	key, err := keyset.GenerateEd25519("test")
+	if err != nil {
+		panic(err)
+	}
+	keys, err := keyset.New(key)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
 func TestNewHandler(t *testing.T) {
-	got := NewHandler(testSecret, NewMockPlayerRepository(t))
+	got := NewHandler(testKeys, NewMockPlayerRepository(t), NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
 	assert.NotEqual(t, nil, got)
 
-	assert.Equal(t, testSecret, got.secret)
+	assert.Equal(t, testKeys, got.keys)
 	assert.NotEqual(t, nil, got.playerRepository)
 }
 
 func TestLogin(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
-	handler := NewHandler(testSecret, playerRepository)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
 
 	playerUsername := "test"
 	playerPassword := "test"
@@ -63,7 +83,7 @@ func TestLogin(t *testing.T) {
 		if exp.Before(time.Now()) {
 			return nil, err
 		}
-		return []byte(testSecret), nil
+		return testKeys.Keyfunc(t)
 	})
 	assert.NoError(t, err)
 	assert.NotEqual(t, nil, parsedToken)
@@ -73,9 +93,218 @@ func TestLogin(t *testing.T) {
 	assert.Equal(t, testPlayer.ID, sub)
 }
 
+func TestLogin_WrongPassword(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	playerPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(playerPassword)
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Password: hash,
+	}
+
+	loginRequest := RequestLoginPlayer{}
+	loginRequest.Body.Username = testPlayer.Username
+	loginRequest.Body.Password = playerPassword + "x"
+
+	playerRepository.
+		On("FindOneByUsername", mock.Anything, testPlayer.Username).
+		Once().
+		Return(&testPlayer, nil)
+
+	playerRepository.
+		On("RegisterFailedLogin", mock.Anything, testPlayer.ID, defaultLoginMaxAttempts, defaultLoginLockout).
+		Once().
+		Return(nil, nil)
+
+	resp, err := handler.Login(t.Context(), &loginRequest)
+	assert.Error(t, err)
+	assert.Equal(t, nil, resp)
+}
+
+func TestLogin_Locked(t *testing.T) {
+	playerPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(playerPassword)
+
+	// the right and a wrong password get the same answer
+	for name, attempt := range map[string]string{"right": playerPassword, "wrong": playerPassword + "x"} {
+		t.Run(name, func(t *testing.T) {
+			playerRepository := NewMockPlayerRepository(t)
+			handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+			testPlayer := player.Player{
+				ID:          uuid.NewString(),
+				Username:    testutil.Faker().Username(),
+				Password:    hash,
+				LockedUntil: time.Now().Add(time.Minute),
+			}
+
+			loginRequest := RequestLoginPlayer{}
+			loginRequest.Body.Username = testPlayer.Username
+			loginRequest.Body.Password = attempt
+
+			playerRepository.
+				On("FindOneByUsername", mock.Anything, testPlayer.Username).
+				Once().
+				Return(&testPlayer, nil)
+
+			playerRepository.AssertNotCalled(t, "RegisterFailedLogin")
+
+			_, err := handler.Login(t.Context(), &loginRequest)
+			assert.Error(t, err)
+
+			var statusErr huma.StatusError
+			assert.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, http.StatusUnauthorized, statusErr.GetStatus())
+			assert.Equal(t, "username or password is incorrect", statusErr.Error())
+		})
+	}
+}
+
+func TestLogin_Suspended(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	playerPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(playerPassword)
+	until := time.Date(2099, 1, 2, 3, 4, 5, 0, time.UTC)
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Password: hash,
+		Suspension: &player.Suspension{
+			Kind:   player.SuspensionBanned,
+			Reason: "cheating",
+			At:     time.Now(),
+			Until:  &until,
+		},
+	}
+
+	loginRequest := RequestLoginPlayer{}
+	loginRequest.Body.Username = testPlayer.Username
+	loginRequest.Body.Password = playerPassword
+
+	playerRepository.
+		On("FindOneByUsername", mock.Anything, testPlayer.Username).
+		Once().
+		Return(&testPlayer, nil)
+
+	_, err := handler.Login(t.Context(), &loginRequest)
+	assert.Error(t, err)
+
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusForbidden, statusErr.GetStatus())
+	assert.Contains(t, err.Error(), "account is banned until 2099-01-02T03:04:05Z: cheating")
+}
+
+func TestLogin_ResetFailedAndRehash(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	playerPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := bcrypt.GenerateFromPassword([]byte(playerPassword), bcrypt.MinCost)
+	testPlayer := player.Player{
+		ID:                  uuid.NewString(),
+		Username:            testutil.Faker().Username(),
+		Password:            string(hash),
+		FailedLoginAttempts: 2,
+	}
+
+	loginRequest := RequestLoginPlayer{}
+	loginRequest.Body.Username = testPlayer.Username
+	loginRequest.Body.Password = playerPassword
+
+	var rehashed *player.PlayerUpdate
+
+	playerRepository.
+		On("FindOneByUsername", mock.Anything, testPlayer.Username).
+		Once().
+		Return(&testPlayer, nil)
+
+	playerRepository.
+		On("ResetFailedLogins", mock.Anything, testPlayer.ID).
+		Once().
+		Return(nil)
+
+	playerRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(p *player.PlayerUpdate) bool {
+			if p == nil || p.Password == nil || p.ID != testPlayer.ID {
+				return false
+			}
+			rehashed = p
+			return true
+		})).
+		Once().
+		Return(testPlayer.ID, nil)
+
+	resp, err := handler.Login(t.Context(), &loginRequest)
+	assert.NoError(t, err)
+	assert.NotZero(t, resp.Body.Token)
+
+	assert.True(t, password.CompareHash(playerPassword, *rehashed.Password))
+	assert.False(t, password.NeedsRehash(*rehashed.Password))
+}
+
+func TestLogin_ByEmail(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	playerPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(playerPassword)
+	email := testutil.Faker().Email()
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Email:    &email,
+		Password: hash,
+	}
+
+	loginRequest := RequestLoginPlayer{}
+	loginRequest.Body.Username = email
+	loginRequest.Body.Password = playerPassword
+
+	playerRepository.
+		On("FindOneByEmail", mock.Anything, email).
+		Once().
+		Return(&testPlayer, nil)
+	playerRepository.AssertNotCalled(t, "FindOneByUsername")
+
+	resp, err := handler.Login(t.Context(), &loginRequest)
+	assert.NoError(t, err)
+	assert.NotZero(t, resp.Body.Token)
+}
+
+func TestLogin_RateLimit(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{RateLimit: 1})
+
+	username := testutil.Faker().Username()
+	loginRequest := RequestLoginPlayer{}
+	loginRequest.Body.Username = username
+	loginRequest.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+
+	playerRepository.
+		On("FindOneByUsername", mock.Anything, username).
+		Once().
+		Return(nil, errors.New("not found"))
+
+	_, err := handler.Login(t.Context(), &loginRequest)
+	assert.Error(t, err)
+
+	_, err = handler.Login(t.Context(), &loginRequest)
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.GetStatus())
+}
+
 func TestRegister(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
-	handler := NewHandler(testSecret, playerRepository)
+	tokenRepository := NewMockTokenRepository(t)
+	mail := mailer.NewMemory()
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mail, Options{})
 
 	newID := uuid.NewString()
 	email := testutil.Faker().Email()
@@ -100,96 +329,168 @@ func TestRegister(t *testing.T) {
 		Once().
 		Return(newID, nil)
 
+	tokenRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(tk *Token) bool {
+			return tk.PlayerID == newID && tk.Purpose == TokenPurposeEmailVerify
+		})).
+		Once().
+		Return(1, nil)
+
 	resp, err := handler.RegisterPlayer(t.Context(), &req)
 	assert.NoError(t, err)
 	assert.Equal(t, newID, resp.Body.ID)
 
 	assert.True(t, password.CompareHash(req.Body.Password, constructedPlayer.Password))
+
+	messages := mail.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, email, messages[0].To)
+}
+
+func TestRegister_Taken(t *testing.T) {
+	tcases := []struct {
+		name string
+		err  error
+	}{
+		{"username", player.ErrUsernameTaken},
+		{"email", player.ErrEmailTaken},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			playerRepository := NewMockPlayerRepository(t)
+			handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+			req := RequestRegisterCreatePlayer{}
+			req.Body.Username = " Alex "
+			req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+
+			playerRepository.
+				On("Insert", mock.Anything, mock.MatchedBy(func(p *player.Player) bool {
+					return p.Username == "Alex"
+				})).
+				Once().
+				Return("", tt.err)
+
+			_, err := handler.RegisterPlayer(t.Context(), &req)
+			var statusErr huma.StatusError
+			assert.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, http.StatusConflict, statusErr.GetStatus())
+		})
+	}
 }
 
-func TestSetPassword(t *testing.T) {
+func TestChangePassword(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
-	handler := NewHandler(testSecret, playerRepository)
+	playerCache := NewMockPlayerCache(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), playerCache, mailer.NewMemory(), Options{})
 
-	playerID := uuid.NewString()
-	username := testutil.Faker().Username()
-	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	oldPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(oldPassword)
+	testPlayer := player.Player{
+		ID:                 uuid.NewString(),
+		Username:           testutil.Faker().Username(),
+		Password:           hash,
+		MustChangePassword: true,
+	}
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: testPlayer.ID})
 
-	req := RequestSetPassword{}
-	req.Body.Username = username
-	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	req := RequestChangePassword{}
+	req.Body.OldPassword = oldPassword
+	req.Body.NewPassword = oldPassword + "new"
 
 	var constructedPlayer *player.PlayerUpdate
 
 	playerRepository.
-		On("FindOneByUsername", ctx, username).
+		On("FindOne", ctx, testPlayer.ID).
 		Once().
-		Return(&player.Player{ID: playerID, Username: username}, nil)
+		Return(&testPlayer, nil)
 
 	playerRepository.
 		On(
 			"Update",
 			ctx,
 			mock.MatchedBy(func(p *player.PlayerUpdate) bool {
-				if p == nil || p.Password == nil || p.ID == "" {
+				if p == nil || p.Password == nil || p.ID != testPlayer.ID {
 					return false
 				}
 				constructedPlayer = p
 				return true
 			})).
 		Once().
-		Return(playerID, nil)
+		Return(testPlayer.ID, nil)
 
-	resp, err := handler.SetPassword(ctx, &req)
+	playerCache.
+		On("Invalidate", testPlayer.ID).
+		Once().
+		Return()
+
+	resp, err := handler.ChangePassword(ctx, &req)
 	assert.NoError(t, err)
-	assert.Equal(t, playerID, resp.Body.ID)
+	assert.NotZero(t, resp.Body.Token)
+	assert.False(t, resp.Body.MustChangePassword)
 
-	assert.True(t, password.CompareHash(req.Body.Password, *constructedPlayer.Password))
+	assert.True(t, password.CompareHash(req.Body.NewPassword, *constructedPlayer.Password))
+	assert.False(t, *constructedPlayer.MustChangePassword)
+	assert.NotEqual(t, nil, constructedPlayer.TokensValidAfter)
 }
 
-func TestSetPassword_DifferentPlayer(t *testing.T) {
+func TestChangePassword_WrongOldPassword(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
-	handler := NewHandler(testSecret, playerRepository)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
 
-	newID := uuid.NewString()
-	diffID := uuid.NewString()
-	username := testutil.Faker().Username()
-	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: newID})
+	oldPassword := testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	hash, _ := password.Hash(oldPassword)
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Password: hash,
+	}
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: testPlayer.ID})
 
-	req := RequestSetPassword{}
-	req.Body.Username = username
-	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+	req := RequestChangePassword{}
+	req.Body.OldPassword = oldPassword + "wrong"
+	req.Body.NewPassword = oldPassword + "new"
+
+	playerRepository.
+		On("FindOne", ctx, testPlayer.ID).
+		Once().
+		Return(&testPlayer, nil)
 
 	playerRepository.
-		On("FindOneByUsername", ctx, username).
+		On("RegisterFailedLogin", ctx, testPlayer.ID, defaultLoginMaxAttempts, defaultLoginLockout).
 		Once().
-		Return(&player.Player{ID: diffID, Username: username}, nil)
+		Return(nil, nil)
 
 	playerRepository.AssertNotCalled(t, "Update")
 
-	_, err := handler.SetPassword(ctx, &req)
+	_, err := handler.ChangePassword(ctx, &req)
 	assert.Error(t, err)
+
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusForbidden, statusErr.GetStatus())
 }
 
-func TestSetPassword_DifferentPlayer_AsAdmin(t *testing.T) {
+func TestResetPlayerPassword_AsAdmin(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
-	handler := NewHandler(testSecret, playerRepository)
+	playerCache := NewMockPlayerCache(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), playerCache, mailer.NewMemory(), Options{})
 
 	adminID := uuid.NewString()
 	diffID := uuid.NewString()
-	username := testutil.Faker().Username()
 	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: adminID, IsAdmin: true})
 
-	req := RequestSetPassword{}
-	req.Body.Username = username
+	req := RequestResetPlayerPassword{}
+	req.PlayerID = diffID
 	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
 
 	var constructedPlayer *player.PlayerUpdate
 
 	playerRepository.
-		On("FindOneByUsername", ctx, username).
+		On("FindOne", ctx, diffID).
 		Once().
-		Return(&player.Player{ID: diffID, Username: username}, nil)
+		Return(&player.Player{ID: diffID, Username: testutil.Faker().Username()}, nil)
 
 	playerRepository.
 		On(
@@ -205,22 +506,292 @@ func TestSetPassword_DifferentPlayer_AsAdmin(t *testing.T) {
 		Once().
 		Return(diffID, nil)
 
-	resp, err := handler.SetPassword(ctx, &req)
+	playerRepository.
+		On("ResetFailedLogins", ctx, diffID).
+		Once().
+		Return(nil)
+
+	playerCache.
+		On("Invalidate", diffID).
+		Once().
+		Return()
+
+	resp, err := handler.ResetPlayerPassword(ctx, &req)
 	assert.NoError(t, err)
 	assert.Equal(t, diffID, resp.Body.ID)
 
 	assert.Equal(t, diffID, constructedPlayer.ID)
+	assert.True(t, *constructedPlayer.MustChangePassword)
+	assert.NotEqual(t, nil, constructedPlayer.TokensValidAfter)
+	assert.True(t, password.CompareHash(req.Body.Password, *constructedPlayer.Password))
+}
+
+func TestResetPlayerPassword_NotAdmin(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	handler := NewHandler(testKeys, playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	req := RequestResetPlayerPassword{}
+	req.PlayerID = uuid.NewString()
+	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+
+	playerRepository.AssertNotCalled(t, "FindOne")
+	playerRepository.AssertNotCalled(t, "Update")
+
+	_, err := handler.ResetPlayerPassword(ctx, &req)
+	assert.Error(t, err)
+}
+
+func TestForgotPassword(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	mail := mailer.NewMemory()
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mail, Options{AppURL: "http://app/"})
+
+	email := testutil.Faker().Email()
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Email:    &email,
+	}
+
+	var inserted *Token
+
+	playerRepository.
+		On("FindOneByEmail", mock.Anything, email).
+		Once().
+		Return(&testPlayer, nil)
+
+	tokenRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(tk *Token) bool {
+			if tk.PlayerID != testPlayer.ID || tk.Purpose != TokenPurposePasswordReset {
+				return false
+			}
+			inserted = tk
+			return true
+		})).
+		Once().
+		Return(1, nil)
+
+	req := RequestForgotPassword{}
+	req.Body.Email = email
+
+	resp, err := handler.ForgotPassword(t.Context(), &req)
+	assert.NoError(t, err)
+	assert.NotZero(t, resp.Body.Message)
+
+	messages := mail.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, email, messages[0].To)
+
+	_, plain, ok := strings.Cut(messages[0].Body, "http://app/reset-password?token=")
+	assert.True(t, ok)
+	plain, _, _ = strings.Cut(plain, "\n")
+	assert.Equal(t, inserted.Hash, HashToken(plain))
+}
+
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	mail := mailer.NewMemory()
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mail, Options{})
+
+	email := testutil.Faker().Email()
+
+	playerRepository.
+		On("FindOneByEmail", mock.Anything, email).
+		Once().
+		Return(nil, errors.New("not found"))
+
+	tokenRepository.AssertNotCalled(t, "Insert")
+
+	req := RequestForgotPassword{}
+	req.Body.Email = email
+
+	resp, err := handler.ForgotPassword(t.Context(), &req)
+	assert.NoError(t, err)
+	assert.NotZero(t, resp.Body.Message)
+	assert.Zero(t, mail.Messages())
+}
+
+type failingMailer struct{}
+
+func (failingMailer) Send(context.Context, mailer.Message) error {
+	return errors.New("smtp unavailable")
+}
+
+func TestForgotPassword_SendFailed(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), failingMailer{}, Options{})
+
+	email := testutil.Faker().Email()
+	testPlayer := player.Player{
+		ID:       uuid.NewString(),
+		Username: testutil.Faker().Username(),
+		Email:    &email,
+	}
+
+	playerRepository.
+		On("FindOneByEmail", mock.Anything, email).
+		Once().
+		Return(&testPlayer, nil)
+
+	tokenRepository.
+		On("Insert", mock.Anything, mock.Anything).
+		Once().
+		Return(1, nil)
+
+	req := RequestForgotPassword{}
+	req.Body.Email = email
+
+	resp, err := handler.ForgotPassword(t.Context(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "if the email is registered, a password reset link has been sent", resp.Body.Message)
+}
+
+func TestResetPassword(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	playerCache := NewMockPlayerCache(t)
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, playerCache, mailer.NewMemory(), Options{})
+
+	playerID := uuid.NewString()
+	token, plain, err := NewToken(playerID, TokenPurposePasswordReset, time.Hour)
+	assert.NoError(t, err)
+
+	req := RequestResetPassword{}
+	req.Body.Token = plain
+	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+
+	var constructedPlayer *player.PlayerUpdate
+
+	tokenRepository.
+		On("Consume", mock.Anything, TokenPurposePasswordReset, token.Hash).
+		Once().
+		Return(token, nil)
+
+	playerRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(p *player.PlayerUpdate) bool {
+			if p == nil || p.Password == nil || p.ID != playerID {
+				return false
+			}
+			constructedPlayer = p
+			return true
+		})).
+		Once().
+		Return(playerID, nil)
+
+	playerRepository.
+		On("ResetFailedLogins", mock.Anything, playerID).
+		Once().
+		Return(nil)
+
+	playerCache.
+		On("Invalidate", playerID).
+		Once().
+		Return()
+
+	resp, err := handler.ResetPassword(t.Context(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, playerID, resp.Body.ID)
 
 	assert.True(t, password.CompareHash(req.Body.Password, *constructedPlayer.Password))
 }
 
+func TestResetPassword_InvalidToken(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	req := RequestResetPassword{}
+	req.Body.Token = testutil.Faker().LetterN(43)
+	req.Body.Password = testutil.Faker().Password(true, true, true, true, false, player.MinPasswordLength)
+
+	tokenRepository.
+		On("Consume", mock.Anything, TokenPurposePasswordReset, HashToken(req.Body.Token)).
+		Once().
+		Return(nil, ErrTokenNotFound)
+
+	playerRepository.AssertNotCalled(t, "Update")
+
+	_, err := handler.ResetPassword(t.Context(), &req)
+	assert.Error(t, err)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	playerID := uuid.NewString()
+	token, plain, err := NewToken(playerID, TokenPurposeEmailVerify, time.Hour)
+	assert.NoError(t, err)
+
+	tokenRepository.
+		On("Consume", mock.Anything, TokenPurposeEmailVerify, token.Hash).
+		Once().
+		Return(token, nil)
+
+	playerRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(p *player.PlayerUpdate) bool {
+			return p.ID == playerID && p.EmailVerified != nil && *p.EmailVerified
+		})).
+		Once().
+		Return(playerID, nil)
+
+	req := RequestVerifyEmail{}
+	req.Body.Token = plain
+
+	resp, err := handler.VerifyEmail(t.Context(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, playerID, resp.Body.ID)
+}
+
+func TestSendVerification_AlreadyVerified(t *testing.T) {
+	playerRepository := NewMockPlayerRepository(t)
+	tokenRepository := NewMockTokenRepository(t)
+	handler := NewHandler(testKeys, playerRepository, tokenRepository, NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	email := testutil.Faker().Email()
+	testPlayer := player.Player{
+		ID:            uuid.NewString(),
+		Username:      testutil.Faker().Username(),
+		Email:         &email,
+		EmailVerified: true,
+	}
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: testPlayer.ID})
+
+	playerRepository.
+		On("FindOne", ctx, testPlayer.ID).
+		Once().
+		Return(&testPlayer, nil)
+
+	tokenRepository.AssertNotCalled(t, "Insert")
+
+	_, err := handler.SendVerification(ctx, nil)
+	assert.Error(t, err)
+}
+
 func TestIssueToken(t *testing.T) {
 	var p player.Player
 	testutil.Faker().Struct(&p)
 
-	handler := NewHandler(testSecret, NewMockPlayerRepository(t))
+	handler := NewHandler(testKeys, NewMockPlayerRepository(t), NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
 
 	token, err := handler.issueToken(&p)
 	assert.NoError(t, err)
 	assert.NotZero(t, token)
 }
+
+func TestGetJWKS(t *testing.T) {
+	handler := NewHandler(testKeys, NewMockPlayerRepository(t), NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{})
+
+	resp, err := handler.GetJWKS(t.Context(), &struct{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Body.Keys))
+	assert.Equal(t, "test", resp.Body.Keys[0].Kid)
+	assert.Equal(t, "EdDSA", resp.Body.Keys[0].Alg)
+	assert.NotZero(t, resp.CacheControl)
+}
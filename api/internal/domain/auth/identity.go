@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdentityNotFound   = errors.New("identity is not found")
+	ErrIdentityLinked     = errors.New("identity is linked to another player")
+	ErrOAuthStateNotFound = errors.New("oauth state is not found, expired or already used")
+)
+
+// Identity links a player to an account of an external identity provider
+type Identity struct {
+	ID        int       `json:"id"`
+	PlayerID  string    `json:"player_id" format:"uuid"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OAuthState is a pending sign in with a provider. It is created when the player is
+// redirected to the provider and consumed in the callback. Only hash of the state is stored.
+type OAuthState struct {
+	ID       int
+	Provider string
+	Hash     string
+	Verifier string
+	// PlayerID is set when an identity is linked to an existing player
+	PlayerID  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// NewOAuthState generates a state and returns it with the plain value passed to the provider
+func NewOAuthState(provider, verifier, playerID string, ttl time.Duration) (*OAuthState, string, error) {
+	plain, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &OAuthState{
+		Provider:  provider,
+		Hash:      HashToken(plain),
+		Verifier:  verifier,
+		PlayerID:  playerID,
+		ExpiresAt: time.Now().Add(ttl),
+	}, plain, nil
+}
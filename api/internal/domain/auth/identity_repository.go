@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	TableIdentity   = "identity"
+	TableOAuthState = "oauth_state"
+
+	pgUniqueViolation = "23505"
+)
+
+const (
+	identityColumns   string = "id, player_id, provider, subject, email, created_at"
+	oauthStateColumns string = "id, provider, state_hash, code_verifier, player_id, expires_at, created_at"
+)
+
+type PGIdentityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGIdentityRepository(pool *pgxpool.Pool) *PGIdentityRepository {
+	return &PGIdentityRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGIdentityRepository) FindOne(ctx context.Context, provider, subject string) (*Identity, error) {
+	sqlBuild := sq.Select(identityColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableIdentity).
+		Where(sq.Eq{"provider": provider, "subject": subject})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := identityFromRow(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *PGIdentityRepository) FindAllByPlayer(ctx context.Context, playerID string) ([]Identity, error) {
+	out := make([]Identity, 0)
+
+	sqlBuild := sq.Select(identityColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableIdentity).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		identity, err := identityFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *identity)
+	}
+	return out, nil
+}
+
+// Insert links the identity to the player, ErrIdentityLinked is returned when
+// the identity or the provider is already linked
+func (r *PGIdentityRepository) Insert(ctx context.Context, identity *Identity) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableIdentity).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "provider", "subject", "email").
+		Values(identity.PlayerID, identity.Provider, identity.Subject, identity.Email).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return id, ErrIdentityLinked
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *PGIdentityRepository) Delete(ctx context.Context, playerID, provider string) error {
+	sqlBuild := sq.Delete(TableIdentity).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"player_id": playerID, "provider": provider})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+type PGOAuthStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGOAuthStateRepository(pool *pgxpool.Pool) *PGOAuthStateRepository {
+	return &PGOAuthStateRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGOAuthStateRepository) Insert(ctx context.Context, state *OAuthState) (int, error) {
+	var id int
+
+	var playerID *string
+	if state.PlayerID != "" {
+		playerID = &state.PlayerID
+	}
+
+	sqlBuild := sq.Insert(TableOAuthState).
+		PlaceholderFormat(sq.Dollar).
+		Columns("provider", "state_hash", "code_verifier", "player_id", "expires_at").
+		Values(state.Provider, state.Hash, state.Verifier, playerID, state.ExpiresAt.UTC()).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the state as used and returns it, the state is valid only once
+func (r *PGOAuthStateRepository) Consume(ctx context.Context, provider, hash string) (*OAuthState, error) {
+	now := time.Now().UTC()
+
+	query, args, err := sq.Update(TableOAuthState).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", now).
+		Where(sq.Eq{"provider": provider, "state_hash": hash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING " + oauthStateColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oauthStateFromRow(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOAuthStateNotFound
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+func identityFromRow(row pgx.Row) (*Identity, error) {
+	var i Identity
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func oauthStateFromRow(row pgx.Row) (*OAuthState, error) {
+	var s OAuthState
+	var playerID *string
+	err := row.Scan(
+		&s.ID,
+		&s.Provider,
+		&s.Hash,
+		&s.Verifier,
+		&playerID,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if playerID != nil {
+		s.PlayerID = *playerID
+	}
+	return &s, nil
+}
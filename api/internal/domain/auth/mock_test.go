@@ -6,8 +6,11 @@ package auth
 
 import (
 	"context"
+	"net/url"
+	"time"
 
 	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -38,6 +41,142 @@ func (_m *MockPlayerRepository) EXPECT() *MockPlayerRepository_Expecter {
 	return &MockPlayerRepository_Expecter{mock: &_m.Mock}
 }
 
+// FindOne provides a mock function for the type MockPlayerRepository
+func (_mock *MockPlayerRepository) FindOne(ctx context.Context, id string) (*player.Player, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOne")
+	}
+
+	var r0 *player.Player
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*player.Player, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *player.Player); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*player.Player)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPlayerRepository_FindOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindOne'
+type MockPlayerRepository_FindOne_Call struct {
+	*mock.Call
+}
+
+// FindOne is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockPlayerRepository_Expecter) FindOne(ctx interface{}, id interface{}) *MockPlayerRepository_FindOne_Call {
+	return &MockPlayerRepository_FindOne_Call{Call: _e.mock.On("FindOne", ctx, id)}
+}
+
+func (_c *MockPlayerRepository_FindOne_Call) Run(run func(ctx context.Context, id string)) *MockPlayerRepository_FindOne_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerRepository_FindOne_Call) Return(player1 *player.Player, err error) *MockPlayerRepository_FindOne_Call {
+	_c.Call.Return(player1, err)
+	return _c
+}
+
+func (_c *MockPlayerRepository_FindOne_Call) RunAndReturn(run func(ctx context.Context, id string) (*player.Player, error)) *MockPlayerRepository_FindOne_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindOneByEmail provides a mock function for the type MockPlayerRepository
+func (_mock *MockPlayerRepository) FindOneByEmail(ctx context.Context, email string) (*player.Player, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOneByEmail")
+	}
+
+	var r0 *player.Player
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*player.Player, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *player.Player); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*player.Player)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPlayerRepository_FindOneByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindOneByEmail'
+type MockPlayerRepository_FindOneByEmail_Call struct {
+	*mock.Call
+}
+
+// FindOneByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockPlayerRepository_Expecter) FindOneByEmail(ctx interface{}, email interface{}) *MockPlayerRepository_FindOneByEmail_Call {
+	return &MockPlayerRepository_FindOneByEmail_Call{Call: _e.mock.On("FindOneByEmail", ctx, email)}
+}
+
+func (_c *MockPlayerRepository_FindOneByEmail_Call) Run(run func(ctx context.Context, email string)) *MockPlayerRepository_FindOneByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerRepository_FindOneByEmail_Call) Return(player1 *player.Player, err error) *MockPlayerRepository_FindOneByEmail_Call {
+	_c.Call.Return(player1, err)
+	return _c
+}
+
+func (_c *MockPlayerRepository_FindOneByEmail_Call) RunAndReturn(run func(ctx context.Context, email string) (*player.Player, error)) *MockPlayerRepository_FindOneByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindOneByUsername provides a mock function for the type MockPlayerRepository
 func (_mock *MockPlayerRepository) FindOneByUsername(ctx context.Context, username string) (*player.Player, error) {
 	ret := _mock.Called(ctx, username)
@@ -172,6 +311,143 @@ func (_c *MockPlayerRepository_Insert_Call) RunAndReturn(run func(context1 conte
 	return _c
 }
 
+// RegisterFailedLogin provides a mock function for the type MockPlayerRepository
+func (_mock *MockPlayerRepository) RegisterFailedLogin(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (*time.Time, error) {
+	ret := _mock.Called(ctx, id, maxAttempts, lockout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterFailedLogin")
+	}
+
+	var r0 *time.Time
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) (*time.Time, error)); ok {
+		return returnFunc(ctx, id, maxAttempts, lockout)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) *time.Time); ok {
+		r0 = returnFunc(ctx, id, maxAttempts, lockout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, time.Duration) error); ok {
+		r1 = returnFunc(ctx, id, maxAttempts, lockout)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPlayerRepository_RegisterFailedLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterFailedLogin'
+type MockPlayerRepository_RegisterFailedLogin_Call struct {
+	*mock.Call
+}
+
+// RegisterFailedLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - maxAttempts int
+//   - lockout time.Duration
+func (_e *MockPlayerRepository_Expecter) RegisterFailedLogin(ctx interface{}, id interface{}, maxAttempts interface{}, lockout interface{}) *MockPlayerRepository_RegisterFailedLogin_Call {
+	return &MockPlayerRepository_RegisterFailedLogin_Call{Call: _e.mock.On("RegisterFailedLogin", ctx, id, maxAttempts, lockout)}
+}
+
+func (_c *MockPlayerRepository_RegisterFailedLogin_Call) Run(run func(ctx context.Context, id string, maxAttempts int, lockout time.Duration)) *MockPlayerRepository_RegisterFailedLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 time.Duration
+		if args[3] != nil {
+			arg3 = args[3].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerRepository_RegisterFailedLogin_Call) Return(time1 *time.Time, err error) *MockPlayerRepository_RegisterFailedLogin_Call {
+	_c.Call.Return(time1, err)
+	return _c
+}
+
+func (_c *MockPlayerRepository_RegisterFailedLogin_Call) RunAndReturn(run func(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (*time.Time, error)) *MockPlayerRepository_RegisterFailedLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetFailedLogins provides a mock function for the type MockPlayerRepository
+func (_mock *MockPlayerRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetFailedLogins")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPlayerRepository_ResetFailedLogins_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetFailedLogins'
+type MockPlayerRepository_ResetFailedLogins_Call struct {
+	*mock.Call
+}
+
+// ResetFailedLogins is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockPlayerRepository_Expecter) ResetFailedLogins(ctx interface{}, id interface{}) *MockPlayerRepository_ResetFailedLogins_Call {
+	return &MockPlayerRepository_ResetFailedLogins_Call{Call: _e.mock.On("ResetFailedLogins", ctx, id)}
+}
+
+func (_c *MockPlayerRepository_ResetFailedLogins_Call) Run(run func(ctx context.Context, id string)) *MockPlayerRepository_ResetFailedLogins_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerRepository_ResetFailedLogins_Call) Return(err error) *MockPlayerRepository_ResetFailedLogins_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPlayerRepository_ResetFailedLogins_Call) RunAndReturn(run func(ctx context.Context, id string) error) *MockPlayerRepository_ResetFailedLogins_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function for the type MockPlayerRepository
 func (_mock *MockPlayerRepository) Update(ctx context.Context, player1 *player.PlayerUpdate) (string, error) {
 	ret := _mock.Called(ctx, player1)
@@ -237,3 +513,940 @@ func (_c *MockPlayerRepository_Update_Call) RunAndReturn(run func(ctx context.Co
 	_c.Call.Return(run)
 	return _c
 }
+
+// NewMockTokenRepository creates a new instance of MockTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTokenRepository {
+	mock := &MockTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTokenRepository is an autogenerated mock type for the TokenRepository type
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+type MockTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTokenRepository) EXPECT() *MockTokenRepository_Expecter {
+	return &MockTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// Consume provides a mock function for the type MockTokenRepository
+func (_mock *MockTokenRepository) Consume(ctx context.Context, purpose TokenPurpose, hash string) (*Token, error) {
+	ret := _mock.Called(ctx, purpose, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Consume")
+	}
+
+	var r0 *Token
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, TokenPurpose, string) (*Token, error)); ok {
+		return returnFunc(ctx, purpose, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, TokenPurpose, string) *Token); ok {
+		r0 = returnFunc(ctx, purpose, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Token)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, TokenPurpose, string) error); ok {
+		r1 = returnFunc(ctx, purpose, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenRepository_Consume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Consume'
+type MockTokenRepository_Consume_Call struct {
+	*mock.Call
+}
+
+// Consume is a helper method to define mock.On call
+//   - ctx context.Context
+//   - purpose TokenPurpose
+//   - hash string
+func (_e *MockTokenRepository_Expecter) Consume(ctx interface{}, purpose interface{}, hash interface{}) *MockTokenRepository_Consume_Call {
+	return &MockTokenRepository_Consume_Call{Call: _e.mock.On("Consume", ctx, purpose, hash)}
+}
+
+func (_c *MockTokenRepository_Consume_Call) Run(run func(ctx context.Context, purpose TokenPurpose, hash string)) *MockTokenRepository_Consume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 TokenPurpose
+		if args[1] != nil {
+			arg1 = args[1].(TokenPurpose)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenRepository_Consume_Call) Return(token *Token, err error) *MockTokenRepository_Consume_Call {
+	_c.Call.Return(token, err)
+	return _c
+}
+
+func (_c *MockTokenRepository_Consume_Call) RunAndReturn(run func(ctx context.Context, purpose TokenPurpose, hash string) (*Token, error)) *MockTokenRepository_Consume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockTokenRepository
+func (_mock *MockTokenRepository) Insert(ctx context.Context, token *Token) (int, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Token) (int, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Token) int); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Token) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockTokenRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token *Token
+func (_e *MockTokenRepository_Expecter) Insert(ctx interface{}, token interface{}) *MockTokenRepository_Insert_Call {
+	return &MockTokenRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, token)}
+}
+
+func (_c *MockTokenRepository_Insert_Call) Run(run func(ctx context.Context, token *Token)) *MockTokenRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Token
+		if args[1] != nil {
+			arg1 = args[1].(*Token)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenRepository_Insert_Call) Return(n int, err error) *MockTokenRepository_Insert_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockTokenRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, token *Token) (int, error)) *MockTokenRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockIdentityRepository creates a new instance of MockIdentityRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIdentityRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIdentityRepository {
+	mock := &MockIdentityRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockIdentityRepository is an autogenerated mock type for the IdentityRepository type
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+type MockIdentityRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockIdentityRepository) EXPECT() *MockIdentityRepository_Expecter {
+	return &MockIdentityRepository_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function for the type MockIdentityRepository
+func (_mock *MockIdentityRepository) Delete(ctx context.Context, playerID string, provider string) error {
+	ret := _mock.Called(ctx, playerID, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, playerID, provider)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdentityRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockIdentityRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+//   - provider string
+func (_e *MockIdentityRepository_Expecter) Delete(ctx interface{}, playerID interface{}, provider interface{}) *MockIdentityRepository_Delete_Call {
+	return &MockIdentityRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, playerID, provider)}
+}
+
+func (_c *MockIdentityRepository_Delete_Call) Run(run func(ctx context.Context, playerID string, provider string)) *MockIdentityRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdentityRepository_Delete_Call) Return(err error) *MockIdentityRepository_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdentityRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, playerID string, provider string) error) *MockIdentityRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAllByPlayer provides a mock function for the type MockIdentityRepository
+func (_mock *MockIdentityRepository) FindAllByPlayer(ctx context.Context, playerID string) ([]Identity, error) {
+	ret := _mock.Called(ctx, playerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByPlayer")
+	}
+
+	var r0 []Identity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]Identity, error)); ok {
+		return returnFunc(ctx, playerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []Identity); ok {
+		r0 = returnFunc(ctx, playerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Identity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, playerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdentityRepository_FindAllByPlayer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAllByPlayer'
+type MockIdentityRepository_FindAllByPlayer_Call struct {
+	*mock.Call
+}
+
+// FindAllByPlayer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+func (_e *MockIdentityRepository_Expecter) FindAllByPlayer(ctx interface{}, playerID interface{}) *MockIdentityRepository_FindAllByPlayer_Call {
+	return &MockIdentityRepository_FindAllByPlayer_Call{Call: _e.mock.On("FindAllByPlayer", ctx, playerID)}
+}
+
+func (_c *MockIdentityRepository_FindAllByPlayer_Call) Run(run func(ctx context.Context, playerID string)) *MockIdentityRepository_FindAllByPlayer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdentityRepository_FindAllByPlayer_Call) Return(identitys []Identity, err error) *MockIdentityRepository_FindAllByPlayer_Call {
+	_c.Call.Return(identitys, err)
+	return _c
+}
+
+func (_c *MockIdentityRepository_FindAllByPlayer_Call) RunAndReturn(run func(ctx context.Context, playerID string) ([]Identity, error)) *MockIdentityRepository_FindAllByPlayer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindOne provides a mock function for the type MockIdentityRepository
+func (_mock *MockIdentityRepository) FindOne(ctx context.Context, provider string, subject string) (*Identity, error) {
+	ret := _mock.Called(ctx, provider, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOne")
+	}
+
+	var r0 *Identity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*Identity, error)); ok {
+		return returnFunc(ctx, provider, subject)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *Identity); ok {
+		r0 = returnFunc(ctx, provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Identity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdentityRepository_FindOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindOne'
+type MockIdentityRepository_FindOne_Call struct {
+	*mock.Call
+}
+
+// FindOne is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider string
+//   - subject string
+func (_e *MockIdentityRepository_Expecter) FindOne(ctx interface{}, provider interface{}, subject interface{}) *MockIdentityRepository_FindOne_Call {
+	return &MockIdentityRepository_FindOne_Call{Call: _e.mock.On("FindOne", ctx, provider, subject)}
+}
+
+func (_c *MockIdentityRepository_FindOne_Call) Run(run func(ctx context.Context, provider string, subject string)) *MockIdentityRepository_FindOne_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdentityRepository_FindOne_Call) Return(identity *Identity, err error) *MockIdentityRepository_FindOne_Call {
+	_c.Call.Return(identity, err)
+	return _c
+}
+
+func (_c *MockIdentityRepository_FindOne_Call) RunAndReturn(run func(ctx context.Context, provider string, subject string) (*Identity, error)) *MockIdentityRepository_FindOne_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockIdentityRepository
+func (_mock *MockIdentityRepository) Insert(ctx context.Context, identity *Identity) (int, error) {
+	ret := _mock.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Identity) (int, error)); ok {
+		return returnFunc(ctx, identity)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Identity) int); ok {
+		r0 = returnFunc(ctx, identity)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Identity) error); ok {
+		r1 = returnFunc(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdentityRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockIdentityRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - identity *Identity
+func (_e *MockIdentityRepository_Expecter) Insert(ctx interface{}, identity interface{}) *MockIdentityRepository_Insert_Call {
+	return &MockIdentityRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, identity)}
+}
+
+func (_c *MockIdentityRepository_Insert_Call) Run(run func(ctx context.Context, identity *Identity)) *MockIdentityRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Identity
+		if args[1] != nil {
+			arg1 = args[1].(*Identity)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdentityRepository_Insert_Call) Return(n int, err error) *MockIdentityRepository_Insert_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockIdentityRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, identity *Identity) (int, error)) *MockIdentityRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOAuthStateRepository creates a new instance of MockOAuthStateRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOAuthStateRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOAuthStateRepository {
+	mock := &MockOAuthStateRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOAuthStateRepository is an autogenerated mock type for the OAuthStateRepository type
+type MockOAuthStateRepository struct {
+	mock.Mock
+}
+
+type MockOAuthStateRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOAuthStateRepository) EXPECT() *MockOAuthStateRepository_Expecter {
+	return &MockOAuthStateRepository_Expecter{mock: &_m.Mock}
+}
+
+// Consume provides a mock function for the type MockOAuthStateRepository
+func (_mock *MockOAuthStateRepository) Consume(ctx context.Context, provider string, hash string) (*OAuthState, error) {
+	ret := _mock.Called(ctx, provider, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Consume")
+	}
+
+	var r0 *OAuthState
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*OAuthState, error)); ok {
+		return returnFunc(ctx, provider, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *OAuthState); ok {
+		r0 = returnFunc(ctx, provider, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthState)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOAuthStateRepository_Consume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Consume'
+type MockOAuthStateRepository_Consume_Call struct {
+	*mock.Call
+}
+
+// Consume is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider string
+//   - hash string
+func (_e *MockOAuthStateRepository_Expecter) Consume(ctx interface{}, provider interface{}, hash interface{}) *MockOAuthStateRepository_Consume_Call {
+	return &MockOAuthStateRepository_Consume_Call{Call: _e.mock.On("Consume", ctx, provider, hash)}
+}
+
+func (_c *MockOAuthStateRepository_Consume_Call) Run(run func(ctx context.Context, provider string, hash string)) *MockOAuthStateRepository_Consume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOAuthStateRepository_Consume_Call) Return(oAuthState *OAuthState, err error) *MockOAuthStateRepository_Consume_Call {
+	_c.Call.Return(oAuthState, err)
+	return _c
+}
+
+func (_c *MockOAuthStateRepository_Consume_Call) RunAndReturn(run func(ctx context.Context, provider string, hash string) (*OAuthState, error)) *MockOAuthStateRepository_Consume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockOAuthStateRepository
+func (_mock *MockOAuthStateRepository) Insert(ctx context.Context, state *OAuthState) (int, error) {
+	ret := _mock.Called(ctx, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthState) (int, error)); ok {
+		return returnFunc(ctx, state)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthState) int); ok {
+		r0 = returnFunc(ctx, state)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *OAuthState) error); ok {
+		r1 = returnFunc(ctx, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOAuthStateRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockOAuthStateRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - state *OAuthState
+func (_e *MockOAuthStateRepository_Expecter) Insert(ctx interface{}, state interface{}) *MockOAuthStateRepository_Insert_Call {
+	return &MockOAuthStateRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, state)}
+}
+
+func (_c *MockOAuthStateRepository_Insert_Call) Run(run func(ctx context.Context, state *OAuthState)) *MockOAuthStateRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *OAuthState
+		if args[1] != nil {
+			arg1 = args[1].(*OAuthState)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOAuthStateRepository_Insert_Call) Return(n int, err error) *MockOAuthStateRepository_Insert_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockOAuthStateRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, state *OAuthState) (int, error)) *MockOAuthStateRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOAuthProvider creates a new instance of MockOAuthProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOAuthProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOAuthProvider {
+	mock := &MockOAuthProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOAuthProvider is an autogenerated mock type for the OAuthProvider type
+type MockOAuthProvider struct {
+	mock.Mock
+}
+
+type MockOAuthProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOAuthProvider) EXPECT() *MockOAuthProvider_Expecter {
+	return &MockOAuthProvider_Expecter{mock: &_m.Mock}
+}
+
+// AuthCodeURL provides a mock function for the type MockOAuthProvider
+func (_mock *MockOAuthProvider) AuthCodeURL(state string, verifier string, redirectURL string) string {
+	ret := _mock.Called(state, verifier, redirectURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthCodeURL")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func(string, string, string) string); ok {
+		r0 = returnFunc(state, verifier, redirectURL)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// MockOAuthProvider_AuthCodeURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthCodeURL'
+type MockOAuthProvider_AuthCodeURL_Call struct {
+	*mock.Call
+}
+
+// AuthCodeURL is a helper method to define mock.On call
+//   - state string
+//   - verifier string
+//   - redirectURL string
+func (_e *MockOAuthProvider_Expecter) AuthCodeURL(state interface{}, verifier interface{}, redirectURL interface{}) *MockOAuthProvider_AuthCodeURL_Call {
+	return &MockOAuthProvider_AuthCodeURL_Call{Call: _e.mock.On("AuthCodeURL", state, verifier, redirectURL)}
+}
+
+func (_c *MockOAuthProvider_AuthCodeURL_Call) Run(run func(state string, verifier string, redirectURL string)) *MockOAuthProvider_AuthCodeURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOAuthProvider_AuthCodeURL_Call) Return(s string) *MockOAuthProvider_AuthCodeURL_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *MockOAuthProvider_AuthCodeURL_Call) RunAndReturn(run func(state string, verifier string, redirectURL string) string) *MockOAuthProvider_AuthCodeURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Identify provides a mock function for the type MockOAuthProvider
+func (_mock *MockOAuthProvider) Identify(ctx context.Context, params url.Values, verifier string, redirectURL string) (*oauth.User, error) {
+	ret := _mock.Called(ctx, params, verifier, redirectURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Identify")
+	}
+
+	var r0 *oauth.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, url.Values, string, string) (*oauth.User, error)); ok {
+		return returnFunc(ctx, params, verifier, redirectURL)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, url.Values, string, string) *oauth.User); ok {
+		r0 = returnFunc(ctx, params, verifier, redirectURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*oauth.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, url.Values, string, string) error); ok {
+		r1 = returnFunc(ctx, params, verifier, redirectURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOAuthProvider_Identify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Identify'
+type MockOAuthProvider_Identify_Call struct {
+	*mock.Call
+}
+
+// Identify is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params url.Values
+//   - verifier string
+//   - redirectURL string
+func (_e *MockOAuthProvider_Expecter) Identify(ctx interface{}, params interface{}, verifier interface{}, redirectURL interface{}) *MockOAuthProvider_Identify_Call {
+	return &MockOAuthProvider_Identify_Call{Call: _e.mock.On("Identify", ctx, params, verifier, redirectURL)}
+}
+
+func (_c *MockOAuthProvider_Identify_Call) Run(run func(ctx context.Context, params url.Values, verifier string, redirectURL string)) *MockOAuthProvider_Identify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 url.Values
+		if args[1] != nil {
+			arg1 = args[1].(url.Values)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOAuthProvider_Identify_Call) Return(user *oauth.User, err error) *MockOAuthProvider_Identify_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockOAuthProvider_Identify_Call) RunAndReturn(run func(ctx context.Context, params url.Values, verifier string, redirectURL string) (*oauth.User, error)) *MockOAuthProvider_Identify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPlayerCache creates a new instance of MockPlayerCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPlayerCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPlayerCache {
+	mock := &MockPlayerCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPlayerCache is an autogenerated mock type for the PlayerCache type
+type MockPlayerCache struct {
+	mock.Mock
+}
+
+type MockPlayerCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPlayerCache) EXPECT() *MockPlayerCache_Expecter {
+	return &MockPlayerCache_Expecter{mock: &_m.Mock}
+}
+
+// FindOne provides a mock function for the type MockPlayerCache
+func (_mock *MockPlayerCache) FindOne(ctx context.Context, id string) (*player.Player, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOne")
+	}
+
+	var r0 *player.Player
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*player.Player, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *player.Player); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*player.Player)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPlayerCache_FindOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindOne'
+type MockPlayerCache_FindOne_Call struct {
+	*mock.Call
+}
+
+// FindOne is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockPlayerCache_Expecter) FindOne(ctx interface{}, id interface{}) *MockPlayerCache_FindOne_Call {
+	return &MockPlayerCache_FindOne_Call{Call: _e.mock.On("FindOne", ctx, id)}
+}
+
+func (_c *MockPlayerCache_FindOne_Call) Run(run func(ctx context.Context, id string)) *MockPlayerCache_FindOne_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerCache_FindOne_Call) Return(player1 *player.Player, err error) *MockPlayerCache_FindOne_Call {
+	_c.Call.Return(player1, err)
+	return _c
+}
+
+func (_c *MockPlayerCache_FindOne_Call) RunAndReturn(run func(ctx context.Context, id string) (*player.Player, error)) *MockPlayerCache_FindOne_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Invalidate provides a mock function for the type MockPlayerCache
+func (_mock *MockPlayerCache) Invalidate(id string) {
+	_mock.Called(id)
+	return
+}
+
+// MockPlayerCache_Invalidate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Invalidate'
+type MockPlayerCache_Invalidate_Call struct {
+	*mock.Call
+}
+
+// Invalidate is a helper method to define mock.On call
+//   - id string
+func (_e *MockPlayerCache_Expecter) Invalidate(id interface{}) *MockPlayerCache_Invalidate_Call {
+	return &MockPlayerCache_Invalidate_Call{Call: _e.mock.On("Invalidate", id)}
+}
+
+func (_c *MockPlayerCache_Invalidate_Call) Run(run func(id string)) *MockPlayerCache_Invalidate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayerCache_Invalidate_Call) Return() *MockPlayerCache_Invalidate_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockPlayerCache_Invalidate_Call) RunAndReturn(run func(id string)) *MockPlayerCache_Invalidate_Call {
+	_c.Run(run)
+	return _c
+}
@@ -0,0 +1,437 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/middleware"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+	"github.com/lardira/playtrack/internal/pkg/password"
+)
+
+const (
+	defaultOAuthStateTTL = 10 * time.Minute
+
+	maxUsernameLength   = 32
+	usernameAttempts    = 5
+	oauthCallbackPath   = "/oauth/callback"
+	oauthErrorSignIn    = "could not sign in with the provider"
+	oauthErrorEmailUsed = "email is used by another player, sign in and link the provider in the profile"
+)
+
+var (
+	errEmailUsed = errors.New("email is used by another player")
+)
+
+type OAuthProvider interface {
+	AuthCodeURL(state, verifier, redirectURL string) string
+	Identify(ctx context.Context, params url.Values, verifier, redirectURL string) (*oauth.User, error)
+}
+
+type IdentityRepository interface {
+	FindOne(ctx context.Context, provider, subject string) (*Identity, error)
+	FindAllByPlayer(ctx context.Context, playerID string) ([]Identity, error)
+	Insert(ctx context.Context, identity *Identity) (int, error)
+	Delete(ctx context.Context, playerID, provider string) error
+}
+
+type OAuthStateRepository interface {
+	Insert(ctx context.Context, state *OAuthState) (int, error)
+	Consume(ctx context.Context, provider, hash string) (*OAuthState, error)
+}
+
+type OAuthOptions struct {
+	// RedirectURL is a public url of the oauth endpoints,
+	// a provider redirects back to <RedirectURL>/<provider>/callback
+	RedirectURL string
+	StateTTL    time.Duration
+}
+
+// OAuthHandler signs players in with external identity providers.
+// The sign in ends with a redirect to the web app with the same token as login has.
+type OAuthHandler struct {
+	auth                 *Handler
+	identityRepository   IdentityRepository
+	oauthStateRepository OAuthStateRepository
+	providers            map[string]OAuthProvider
+
+	redirectURL string
+	stateTTL    time.Duration
+}
+
+func NewOAuthHandler(
+	auth *Handler,
+	identityRepository IdentityRepository,
+	oauthStateRepository OAuthStateRepository,
+	providers map[string]OAuthProvider,
+	opts OAuthOptions,
+) *OAuthHandler {
+	if opts.StateTTL <= 0 {
+		opts.StateTTL = defaultOAuthStateTTL
+	}
+
+	return &OAuthHandler{
+		auth:                 auth,
+		identityRepository:   identityRepository,
+		oauthStateRepository: oauthStateRepository,
+		providers:            providers,
+		redirectURL:          strings.TrimSuffix(opts.RedirectURL, "/"),
+		stateTTL:             opts.StateTTL,
+	}
+}
+
+func (h *OAuthHandler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/auth")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"auth"}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "oauth-start",
+		Method:      http.MethodGet,
+		Path:        "/oauth/{provider}",
+		Summary:     "sign in with provider",
+		Description: "redirect to the identity provider to sign in",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.auth.ipLimiter, h.auth.clientIP)},
+	}, h.Start)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "oauth-callback",
+		Method:      http.MethodGet,
+		Path:        "/oauth/{provider}/callback",
+		Summary:     "provider callback",
+		Description: "callback of the identity provider, redirects to the web app with a jwt token",
+		Middlewares: huma.Middlewares{middleware.RateLimit(h.auth.ipLimiter, h.auth.clientIP)},
+	}, h.Callback)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "oauth-link",
+		Method:      http.MethodPost,
+		Path:        "/oauth/{provider}/link",
+		Summary:     "link provider",
+		Description: "get a provider url to link the identity to the current player",
+		Security:    apiutil.OperationSecurity,
+		Middlewares: huma.Middlewares{h.auth.authorize},
+	}, h.Link)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "oauth-unlink",
+		Method:      http.MethodDelete,
+		Path:        "/oauth/{provider}/link",
+		Summary:     "unlink provider",
+		Description: "remove the provider identity of the current player",
+		Security:    apiutil.OperationSecurity,
+		Middlewares: huma.Middlewares{h.auth.authorize},
+	}, h.Unlink)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-identities",
+		Method:      http.MethodGet,
+		Path:        "/identities",
+		Summary:     "get identities",
+		Description: "get provider identities linked to the current player",
+		Security:    apiutil.OperationSecurity,
+		Middlewares: huma.Middlewares{h.auth.authorize},
+	}, h.GetIdentities)
+}
+
+func (h *OAuthHandler) Start(ctx context.Context, i *RequestOAuthProvider) (*ResponseRedirect, error) {
+	authURL, err := h.authCodeURL(ctx, i.Provider, "")
+	if err != nil {
+		return nil, err
+	}
+	return redirect(authURL), nil
+}
+
+func (h *OAuthHandler) Link(ctx context.Context, i *RequestOAuthProvider) (*ResponseOAuthURL, error) {
+	ctxPlr, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	authURL, err := h.authCodeURL(ctx, i.Provider, ctxPlr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := ResponseOAuthURL{}
+	resp.Body.URL = authURL
+	return &resp, nil
+}
+
+func (h *OAuthHandler) Unlink(ctx context.Context, i *RequestOAuthProvider) (*domain.ResponseMessage, error) {
+	ctxPlr, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	if err := h.identityRepository.Delete(ctx, ctxPlr.ID, i.Provider); err != nil {
+		log.Printf("oauth unlink %v %v: %v", ctxPlr.ID, i.Provider, err)
+		if errors.Is(err, ErrIdentityNotFound) {
+			return nil, huma.Error404NotFound("identity not found")
+		}
+		return nil, huma.Error500InternalServerError("could not unlink")
+	}
+
+	log.Printf("player %v unlinked %v", ctxPlr.ID, i.Provider)
+	resp := domain.ResponseMessage{}
+	resp.Body.Message = "provider has been unlinked"
+	return &resp, nil
+}
+
+func (h *OAuthHandler) GetIdentities(ctx context.Context, i *struct{}) (*domain.ResponseItems[Identity], error) {
+	ctxPlr, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	identities, err := h.identityRepository.FindAllByPlayer(ctx, ctxPlr.ID)
+	if err != nil {
+		log.Printf("get identities %v: %v", ctxPlr.ID, err)
+		return nil, huma.Error500InternalServerError("could not get identities")
+	}
+
+	resp := domain.ResponseItems[Identity]{}
+	resp.Body.Items = identities
+	return &resp, nil
+}
+
+// Callback finishes the sign in and redirects to the web app. The token or an error
+// is passed in the url fragment so it is not sent to any server.
+func (h *OAuthHandler) Callback(ctx context.Context, i *RequestOAuthCallback) (*ResponseRedirect, error) {
+	provider, ok := h.providers[i.Provider]
+	if !ok {
+		return nil, huma.Error404NotFound("provider not found")
+	}
+
+	state, err := h.oauthStateRepository.Consume(ctx, i.Provider, HashToken(i.Params.Get("state")))
+	if err != nil {
+		log.Printf("oauth callback %v consume state: %v", i.Provider, err)
+		return h.appRedirect(url.Values{"error": {"sign in has expired, try again"}}), nil
+	}
+
+	user, err := provider.Identify(ctx, i.Params, state.Verifier, h.callbackURL(i.Provider))
+	if err != nil {
+		log.Printf("oauth callback %v identify: %v", i.Provider, err)
+		return h.appRedirect(url.Values{"error": {oauthErrorSignIn}}), nil
+	}
+
+	if state.PlayerID != "" {
+		if err := h.link(ctx, state.PlayerID, i.Provider, user); err != nil {
+			log.Printf("oauth callback %v link %v: %v", i.Provider, state.PlayerID, err)
+			msg := "could not link the provider"
+			if errors.Is(err, ErrIdentityLinked) {
+				msg = "the provider account is already linked"
+			}
+			return h.appRedirect(url.Values{"error": {msg}}), nil
+		}
+		log.Printf("player %v linked %v", state.PlayerID, i.Provider)
+		return h.appRedirect(url.Values{"linked": {i.Provider}}), nil
+	}
+
+	found, err := h.playerForIdentity(ctx, i.Provider, user)
+	if err != nil {
+		log.Printf("oauth callback %v player: %v", i.Provider, err)
+		msg := oauthErrorSignIn
+		if errors.Is(err, errEmailUsed) {
+			msg = oauthErrorEmailUsed
+		}
+		return h.appRedirect(url.Values{"error": {msg}}), nil
+	}
+	if found.Locked(time.Now()) {
+		log.Printf("oauth callback player %v is locked until %v", found.ID, found.LockedUntil)
+		return h.appRedirect(url.Values{"error": {"account is temporarily locked, try again later"}}), nil
+	}
+	if found.Suspended(time.Now()) {
+		log.Printf("oauth callback player %v is suspended", found.ID)
+		return h.appRedirect(url.Values{"error": {suspendedMessage(found.Suspension)}}), nil
+	}
+
+	token, err := h.auth.issueToken(found)
+	if err != nil {
+		log.Printf("oauth callback issue token: %v", err)
+		return nil, huma.Error500InternalServerError("could not issue token", err)
+	}
+
+	log.Printf("player %v signed in with %v", found.ID, i.Provider)
+	return h.appRedirect(url.Values{"token": {token}}), nil
+}
+
+// playerForIdentity returns the player linked to the identity. A player with the same
+// verified email is linked, otherwise a new player is created.
+func (h *OAuthHandler) playerForIdentity(
+	ctx context.Context,
+	provider string,
+	user *oauth.User,
+) (*player.Player, error) {
+	identity, err := h.identityRepository.FindOne(ctx, provider, user.Subject)
+	if err == nil {
+		return h.auth.playerRepository.FindOne(ctx, identity.PlayerID)
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	var found *player.Player
+	if user.Email != "" && user.EmailVerified {
+		if existing, err := h.auth.playerRepository.FindOneByEmail(ctx, user.Email); err == nil {
+			// only a verified email proves both accounts belong to the same person
+			if !existing.EmailVerified {
+				return nil, errEmailUsed
+			}
+			found = existing
+		}
+	}
+	if found == nil {
+		found, err = h.createPlayer(ctx, provider, user)
+		if err != nil {
+			return nil, fmt.Errorf("create player: %w", err)
+		}
+	}
+
+	if err := h.link(ctx, found.ID, provider, user); err != nil {
+		return nil, fmt.Errorf("link: %w", err)
+	}
+	return found, nil
+}
+
+func (h *OAuthHandler) link(ctx context.Context, playerID, provider string, user *oauth.User) error {
+	identity := Identity{
+		PlayerID: playerID,
+		Provider: provider,
+		Subject:  user.Subject,
+	}
+	if user.Email != "" {
+		identity.Email = &user.Email
+	}
+
+	_, err := h.identityRepository.Insert(ctx, &identity)
+	return err
+}
+
+func (h *OAuthHandler) createPlayer(ctx context.Context, provider string, user *oauth.User) (*player.Player, error) {
+	username, err := h.availableUsername(ctx, provider, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// the player signs in with the provider, a password can be set with forgot password
+	plain, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := password.Hash(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	nPlayer := player.Player{
+		Username: username,
+		Password: hashedPassword,
+	}
+	if user.Email != "" && user.EmailVerified {
+		nPlayer.Email = &user.Email
+		nPlayer.EmailVerified = true
+	}
+	if user.Img != "" {
+		nPlayer.Img = &user.Img
+	}
+	if err := nPlayer.Valid(); err != nil {
+		return nil, err
+	}
+
+	id, err := h.auth.playerRepository.Insert(ctx, &nPlayer)
+	if err != nil {
+		return nil, err
+	}
+	nPlayer.ID = id
+
+	log.Printf("player %v created with %v", id, provider)
+	return &nPlayer, nil
+}
+
+// availableUsername derives a free username from the provider one
+func (h *OAuthHandler) availableUsername(ctx context.Context, provider string, user *oauth.User) (string, error) {
+	base := sanitizeUsername(user.Username)
+	if len(base) < player.MinUsernameLength {
+		base = sanitizeUsername(provider + "_" + user.Subject)
+	}
+
+	username := base
+	for range usernameAttempts {
+		// reserved names get a suffix like taken ones
+		if !player.UsernameReserved(username) {
+			if _, err := h.auth.playerRepository.FindOneByUsername(ctx, username); err != nil {
+				return username, nil
+			}
+		}
+		suffix := fmt.Sprintf("_%04d", rand.IntN(10000))
+		username = truncate(base, maxUsernameLength-len(suffix)) + suffix
+	}
+	return "", fmt.Errorf("no available username for %q", base)
+}
+
+func (h *OAuthHandler) authCodeURL(ctx context.Context, provider, playerID string) (string, error) {
+	p, ok := h.providers[provider]
+	if !ok {
+		return "", huma.Error404NotFound("provider not found")
+	}
+
+	verifier := oauth.GenerateVerifier()
+	state, plain, err := NewOAuthState(provider, verifier, playerID, h.stateTTL)
+	if err != nil {
+		log.Printf("oauth new state: %v", err)
+		return "", huma.Error500InternalServerError("could not start sign in")
+	}
+	if _, err := h.oauthStateRepository.Insert(ctx, state); err != nil {
+		log.Printf("oauth state insert: %v", err)
+		return "", huma.Error500InternalServerError("could not start sign in")
+	}
+
+	return p.AuthCodeURL(plain, verifier, h.callbackURL(provider)), nil
+}
+
+func (h *OAuthHandler) callbackURL(provider string) string {
+	return h.redirectURL + "/" + url.PathEscape(provider) + "/callback"
+}
+
+func (h *OAuthHandler) appRedirect(fragment url.Values) *ResponseRedirect {
+	return redirect(h.auth.appURL + oauthCallbackPath + "#" + fragment.Encode())
+}
+
+func redirect(location string) *ResponseRedirect {
+	return &ResponseRedirect{
+		Status:   http.StatusFound,
+		Location: location,
+	}
+}
+
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.') {
+			b.WriteRune(r)
+		}
+	}
+	return truncate(b.String(), maxUsernameLength)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/mailer"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testProvider    = "fake"
+	testAppURL      = "http://localhost:3000"
+	testRedirectURL = "http://localhost:8080/pub/auth/oauth"
+)
+
+type oauthTest struct {
+	playerRepository     *MockPlayerRepository
+	identityRepository   *MockIdentityRepository
+	oauthStateRepository *MockOAuthStateRepository
+	provider             *MockOAuthProvider
+	handler              *OAuthHandler
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	ot := oauthTest{
+		playerRepository:     NewMockPlayerRepository(t),
+		identityRepository:   NewMockIdentityRepository(t),
+		oauthStateRepository: NewMockOAuthStateRepository(t),
+		provider:             NewMockOAuthProvider(t),
+	}
+
+	authHandler := NewHandler(testKeys, ot.playerRepository, NewMockTokenRepository(t), NewMockPlayerCache(t), mailer.NewMemory(), Options{
+		AppURL: testAppURL,
+	})
+	ot.handler = NewOAuthHandler(
+		authHandler,
+		ot.identityRepository,
+		ot.oauthStateRepository,
+		map[string]OAuthProvider{testProvider: ot.provider},
+		OAuthOptions{RedirectURL: testRedirectURL},
+	)
+	return &ot
+}
+
+// callback returns a callback request and expects the state to be consumed
+func (ot *oauthTest) callback(t *testing.T, playerID string) (*RequestOAuthCallback, *oauth.User) {
+	plain := testutil.Faker().UUID()
+	state := OAuthState{
+		Provider: testProvider,
+		Hash:     HashToken(plain),
+		Verifier: oauth.GenerateVerifier(),
+		PlayerID: playerID,
+	}
+	user := oauth.User{
+		Subject:       testutil.Faker().UUID(),
+		Username:      testutil.Faker().Username(),
+		Email:         testutil.Faker().Email(),
+		EmailVerified: true,
+	}
+
+	req := RequestOAuthCallback{
+		Provider: testProvider,
+		Params:   url.Values{"state": {plain}, "code": {"code"}},
+	}
+
+	ot.oauthStateRepository.
+		On("Consume", mock.Anything, testProvider, state.Hash).
+		Once().
+		Return(&state, nil)
+
+	ot.provider.
+		On("Identify", mock.Anything, req.Params, state.Verifier, testRedirectURL+"/"+testProvider+"/callback").
+		Once().
+		Return(&user, nil)
+
+	return &req, &user
+}
+
+func fragment(t *testing.T, location string) url.Values {
+	before, frag, ok := strings.Cut(location, "#")
+	assert.True(t, ok)
+	assert.Equal(t, testAppURL+oauthCallbackPath, before)
+
+	values, err := url.ParseQuery(frag)
+	assert.NoError(t, err)
+	return values
+}
+
+func tokenSubject(t *testing.T, token string) string {
+	parsed, err := jwt.Parse(token, testKeys.Keyfunc)
+	assert.NoError(t, err)
+
+	sub, err := parsed.Claims.GetSubject()
+	assert.NoError(t, err)
+	return sub
+}
+
+func TestOAuthStart(t *testing.T) {
+	ot := newOAuthTest(t)
+
+	var state *OAuthState
+	ot.oauthStateRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(s *OAuthState) bool {
+			state = s
+			return s.Provider == testProvider && s.Verifier != "" && s.PlayerID == ""
+		})).
+		Once().
+		Return(1, nil)
+
+	authURL := "http://idp.local/authorize"
+	ot.provider.
+		On("AuthCodeURL", mock.Anything, mock.Anything, testRedirectURL+"/"+testProvider+"/callback").
+		Once().
+		Run(func(args mock.Arguments) {
+			assert.Equal(t, state.Hash, HashToken(args.String(0)))
+			assert.Equal(t, state.Verifier, args.String(1))
+		}).
+		Return(authURL)
+
+	resp, err := ot.handler.Start(t.Context(), &RequestOAuthProvider{Provider: testProvider})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.Status)
+	assert.Equal(t, authURL, resp.Location)
+}
+
+func TestOAuthStart_UnknownProvider(t *testing.T) {
+	ot := newOAuthTest(t)
+
+	ot.oauthStateRepository.AssertNotCalled(t, "Insert")
+
+	_, err := ot.handler.Start(t.Context(), &RequestOAuthProvider{Provider: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestOAuthCallback_ExistingIdentity(t *testing.T) {
+	ot := newOAuthTest(t)
+	req, user := ot.callback(t, "")
+
+	playerID := uuid.NewString()
+	ot.identityRepository.
+		On("FindOne", mock.Anything, testProvider, user.Subject).
+		Once().
+		Return(&Identity{PlayerID: playerID, Provider: testProvider, Subject: user.Subject}, nil)
+
+	ot.playerRepository.
+		On("FindOne", mock.Anything, playerID).
+		Once().
+		Return(&player.Player{ID: playerID}, nil)
+
+	resp, err := ot.handler.Callback(t.Context(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.Status)
+
+	values := fragment(t, resp.Location)
+	assert.Equal(t, playerID, tokenSubject(t, values.Get("token")))
+}
+
+func TestOAuthCallback_NewPlayer(t *testing.T) {
+	ot := newOAuthTest(t)
+	req, user := ot.callback(t, "")
+
+	playerID := uuid.NewString()
+	// faker usernames may have spaces which are dropped
+	username := sanitizeUsername(user.Username)
+	ot.identityRepository.
+		On("FindOne", mock.Anything, testProvider, user.Subject).
+		Once().
+		Return(nil, ErrIdentityNotFound)
+
+	ot.playerRepository.
+		On("FindOneByEmail", mock.Anything, user.Email).
+		Once().
+		Return(nil, ErrIdentityNotFound)
+
+	ot.playerRepository.
+		On("FindOneByUsername", mock.Anything, username).
+		Once().
+		Return(nil, ErrIdentityNotFound)
+
+	ot.playerRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(p *player.Player) bool {
+			return p.Username == username && p.Password != "" &&
+				p.Email != nil && *p.Email == user.Email && p.EmailVerified
+		})).
+		Once().
+		Return(playerID, nil)
+
+	ot.identityRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(i *Identity) bool {
+			return i.PlayerID == playerID && i.Provider == testProvider && i.Subject == user.Subject
+		})).
+		Once().
+		Return(1, nil)
+
+	resp, err := ot.handler.Callback(t.Context(), req)
+	assert.NoError(t, err)
+
+	values := fragment(t, resp.Location)
+	assert.Equal(t, playerID, tokenSubject(t, values.Get("token")))
+}
+
+func TestOAuthCallback_EmailOfUnverifiedPlayer(t *testing.T) {
+	ot := newOAuthTest(t)
+	req, user := ot.callback(t, "")
+
+	ot.identityRepository.
+		On("FindOne", mock.Anything, testProvider, user.Subject).
+		Once().
+		Return(nil, ErrIdentityNotFound)
+
+	ot.playerRepository.
+		On("FindOneByEmail", mock.Anything, user.Email).
+		Once().
+		Return(&player.Player{ID: uuid.NewString(), Email: &user.Email}, nil)
+
+	ot.playerRepository.AssertNotCalled(t, "Insert")
+	ot.identityRepository.AssertNotCalled(t, "Insert")
+
+	resp, err := ot.handler.Callback(t.Context(), req)
+	assert.NoError(t, err)
+
+	values := fragment(t, resp.Location)
+	assert.Zero(t, values.Get("token"))
+	assert.Equal(t, oauthErrorEmailUsed, values.Get("error"))
+}
+
+func TestOAuthCallback_Link(t *testing.T) {
+	ot := newOAuthTest(t)
+	playerID := uuid.NewString()
+	req, user := ot.callback(t, playerID)
+
+	ot.identityRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(i *Identity) bool {
+			return i.PlayerID == playerID && i.Subject == user.Subject
+		})).
+		Once().
+		Return(1, nil)
+
+	resp, err := ot.handler.Callback(t.Context(), req)
+	assert.NoError(t, err)
+
+	values := fragment(t, resp.Location)
+	assert.Zero(t, values.Get("token"))
+	assert.Equal(t, testProvider, values.Get("linked"))
+}
+
+func TestOAuthCallback_InvalidState(t *testing.T) {
+	ot := newOAuthTest(t)
+
+	req := RequestOAuthCallback{
+		Provider: testProvider,
+		Params:   url.Values{"state": {testutil.Faker().UUID()}},
+	}
+
+	ot.oauthStateRepository.
+		On("Consume", mock.Anything, testProvider, HashToken(req.Params.Get("state"))).
+		Once().
+		Return(nil, ErrOAuthStateNotFound)
+
+	ot.provider.AssertNotCalled(t, "Identify")
+
+	resp, err := ot.handler.Callback(t.Context(), &req)
+	assert.NoError(t, err)
+
+	values := fragment(t, resp.Location)
+	assert.Zero(t, values.Get("token"))
+	assert.NotZero(t, values.Get("error"))
+}
+
+func TestOAuthLink(t *testing.T) {
+	ot := newOAuthTest(t)
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	ot.oauthStateRepository.
+		On("Insert", ctx, mock.MatchedBy(func(s *OAuthState) bool {
+			return s.PlayerID == playerID
+		})).
+		Once().
+		Return(1, nil)
+
+	ot.provider.
+		On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything).
+		Once().
+		Return("http://idp.local/authorize")
+
+	resp, err := ot.handler.Link(ctx, &RequestOAuthProvider{Provider: testProvider})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://idp.local/authorize", resp.Body.URL)
+}
+
+func TestSanitizeUsername(t *testing.T) {
+	assert.Equal(t, "player.one_2", sanitizeUsername("player.one_2"))
+	assert.Equal(t, "plyer", sanitizeUsername("pläyer 🎮"))
+	assert.Equal(t, maxUsernameLength, len(sanitizeUsername(strings.Repeat("a", 64))))
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	TableAuthToken = "auth_token"
+)
+
+const (
+	tokenColumns string = "id, player_id, purpose, token_hash, expires_at, created_at"
+)
+
+type PGTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGTokenRepository(pool *pgxpool.Pool) *PGTokenRepository {
+	return &PGTokenRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGTokenRepository) Insert(ctx context.Context, token *Token) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableAuthToken).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "purpose", "token_hash", "expires_at").
+		Values(token.PlayerID, token.Purpose, token.Hash, token.ExpiresAt).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the token as used and returns it. Other unused tokens of the player
+// with the same purpose are invalidated as well.
+func (r *PGTokenRepository) Consume(ctx context.Context, purpose TokenPurpose, hash string) (*Token, error) {
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query, args, err := sq.Update(TableAuthToken).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", now).
+		Where(sq.Eq{"purpose": purpose, "token_hash": hash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING " + tokenColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := tokenFromRow(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	query, args, err = sq.Update(TableAuthToken).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", now).
+		Where(sq.Eq{"player_id": token.PlayerID, "purpose": purpose, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func tokenFromRow(row pgx.Row) (*Token, error) {
+	var t Token
+	err := row.Scan(
+		&t.ID,
+		&t.PlayerID,
+		&t.Purpose,
+		&t.Hash,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -1,5 +1,12 @@
 package auth
 
+import (
+	"net/url"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
+)
+
 type RequestRegisterCreatePlayer struct {
 	Body struct {
 		Username string  `json:"username" minLength:"4" maxLength:"32"`
@@ -9,22 +16,82 @@ type RequestRegisterCreatePlayer struct {
 	}
 }
 
-type RequestSetPassword struct {
+type RequestChangePassword struct {
 	Body struct {
-		Username string `json:"username" minLength:"4" maxLength:"32"`
+		OldPassword string `json:"old_password" minLength:"1" maxLength:"32"`
+		NewPassword string `json:"new_password" minLength:"8" maxLength:"32"`
+	}
+}
+
+type RequestResetPlayerPassword struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Body     struct {
 		Password string `json:"password" minLength:"8" maxLength:"32"`
 	}
 }
 
 type RequestLoginPlayer struct {
 	Body struct {
-		Username string `json:"username" minLength:"4" maxLength:"32"`
+		// Username is the username or the email of the player
+		Username string `json:"username" minLength:"4" maxLength:"254" doc:"username or email"`
 		Password string `json:"password" minLength:"8" maxLength:"32"`
 	}
 }
 
 type ResponseLoginPlayer struct {
 	Body struct {
-		Token string `json:"token" readOnly:"true"`
+		Token              string `json:"token" readOnly:"true"`
+		MustChangePassword bool   `json:"must_change_password" readOnly:"true"`
+	}
+}
+
+type RequestForgotPassword struct {
+	Body struct {
+		Email string `json:"email" format:"email"`
+	}
+}
+
+type RequestResetPassword struct {
+	Body struct {
+		Token    string `json:"token" minLength:"1"`
+		Password string `json:"password" minLength:"8" maxLength:"32"`
+	}
+}
+
+type RequestVerifyEmail struct {
+	Body struct {
+		Token string `json:"token" minLength:"1"`
+	}
+}
+
+type RequestOAuthProvider struct {
+	Provider string `path:"provider" minLength:"1"`
+}
+
+type RequestOAuthCallback struct {
+	Provider string `path:"provider" minLength:"1"`
+	// Params are all query params, their set depends on the provider
+	Params url.Values
+}
+
+func (r *RequestOAuthCallback) Resolve(ctx huma.Context) []error {
+	u := ctx.URL()
+	r.Params = u.Query()
+	return nil
+}
+
+type ResponseRedirect struct {
+	Status   int
+	Location string `header:"Location"`
+}
+
+type ResponseOAuthURL struct {
+	Body struct {
+		URL string `json:"url" format:"uri"`
 	}
 }
+
+type ResponseJWKS struct {
+	CacheControl string `header:"Cache-Control"`
+	Body         keyset.JWKS
+}
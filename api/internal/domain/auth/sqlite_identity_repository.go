@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteIdentityRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIdentityRepository(sqlDB *sql.DB) *SQLiteIdentityRepository {
+	return &SQLiteIdentityRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteIdentityRepository) FindOne(ctx context.Context, provider, subject string) (*Identity, error) {
+	sqlBuild := sq.Select(identityColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableIdentity).
+		Where(sq.Eq{"provider": provider, "subject": subject})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := identityFromRow(db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *SQLiteIdentityRepository) FindAllByPlayer(ctx context.Context, playerID string) ([]Identity, error) {
+	out := make([]Identity, 0)
+
+	sqlBuild := sq.Select(identityColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableIdentity).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		identity, err := identityFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *identity)
+	}
+	return out, rows.Err()
+}
+
+// Insert links the identity to the player, ErrIdentityLinked is returned when
+// the identity or the provider is already linked
+func (r *SQLiteIdentityRepository) Insert(ctx context.Context, identity *Identity) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableIdentity).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "provider", "subject", "email").
+		Values(identity.PlayerID, identity.Provider, identity.Subject, identity.Email).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		if db.SQLiteUniqueViolation(err, TableIdentity, "provider") {
+			return id, ErrIdentityLinked
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *SQLiteIdentityRepository) Delete(ctx context.Context, playerID, provider string) error {
+	sqlBuild := sq.Delete(TableIdentity).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"player_id": playerID, "provider": provider})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+type SQLiteOAuthStateRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteOAuthStateRepository(sqlDB *sql.DB) *SQLiteOAuthStateRepository {
+	return &SQLiteOAuthStateRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteOAuthStateRepository) Insert(ctx context.Context, state *OAuthState) (int, error) {
+	var id int
+
+	var playerID *string
+	if state.PlayerID != "" {
+		playerID = &state.PlayerID
+	}
+
+	sqlBuild := sq.Insert(TableOAuthState).
+		PlaceholderFormat(sq.Question).
+		Columns("provider", "state_hash", "code_verifier", "player_id", "expires_at").
+		Values(state.Provider, state.Hash, state.Verifier, playerID, db.SQLiteTime(state.ExpiresAt)).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the state as used and returns it, the state is valid only once
+func (r *SQLiteOAuthStateRepository) Consume(ctx context.Context, provider, hash string) (*OAuthState, error) {
+	now := db.SQLiteTime(time.Now())
+
+	query, args, err := sq.Update(TableOAuthState).
+		PlaceholderFormat(sq.Question).
+		Set("used_at", now).
+		Where(sq.Eq{"provider": provider, "state_hash": hash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING " + oauthStateColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oauthStateFromRow(db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOAuthStateNotFound
+		}
+		return nil, err
+	}
+	return state, nil
+}
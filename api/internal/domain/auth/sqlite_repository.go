@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteTokenRepository struct {
+	db *sql.DB
+	tx *db.SQLiteTxManager
+}
+
+func NewSQLiteTokenRepository(sqlDB *sql.DB) *SQLiteTokenRepository {
+	return &SQLiteTokenRepository{
+		db: sqlDB,
+		tx: db.NewSQLiteTxManager(sqlDB),
+	}
+}
+
+func (r *SQLiteTokenRepository) Insert(ctx context.Context, token *Token) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableAuthToken).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "purpose", "token_hash", "expires_at").
+		Values(token.PlayerID, token.Purpose, token.Hash, db.SQLiteTime(token.ExpiresAt)).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the token as used and returns it. Other unused tokens of the player
+// with the same purpose are invalidated as well.
+func (r *SQLiteTokenRepository) Consume(ctx context.Context, purpose TokenPurpose, hash string) (*Token, error) {
+	now := db.SQLiteTime(time.Now())
+
+	var token *Token
+	err := r.tx.WithTx(ctx, func(ctx context.Context) error {
+		conn := db.SQLiteConn(ctx, r.db)
+
+		query, args, err := sq.Update(TableAuthToken).
+			PlaceholderFormat(sq.Question).
+			Set("used_at", now).
+			Where(sq.Eq{"purpose": purpose, "token_hash": hash, "used_at": nil}).
+			Where(sq.Gt{"expires_at": now}).
+			Suffix("RETURNING " + tokenColumns).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		token, err = tokenFromRow(conn.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTokenNotFound
+			}
+			return err
+		}
+
+		query, args, err = sq.Update(TableAuthToken).
+			PlaceholderFormat(sq.Question).
+			Set("used_at", now).
+			Where(sq.Eq{"player_id": token.PlayerID, "purpose": purpose, "used_at": nil}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const (
+	tokenBytes = 32
+)
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	TokenPurposeEmailVerify   TokenPurpose = "email_verify"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token is not found, expired or already used")
+)
+
+// Token is a single-use expiring token sent to a player by email.
+// Only hash of the token is stored.
+type Token struct {
+	ID        int
+	PlayerID  string
+	Purpose   TokenPurpose
+	Hash      string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// NewToken generates a token and returns it with the plain value which must be sent to the player
+func NewToken(playerID string, purpose TokenPurpose, ttl time.Duration) (*Token, string, error) {
+	plain, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Token{
+		PlayerID:  playerID,
+		Purpose:   purpose,
+		Hash:      HashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}, plain, nil
+}
+
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestNewToken(t *testing.T) {
+	playerID := uuid.NewString()
+	ttl := time.Hour
+
+	token, plain, err := NewToken(playerID, TokenPurposePasswordReset, ttl)
+	assert.NoError(t, err)
+	assert.NotZero(t, plain)
+
+	assert.Equal(t, playerID, token.PlayerID)
+	assert.Equal(t, TokenPurposePasswordReset, token.Purpose)
+	assert.Equal(t, HashToken(plain), token.Hash)
+	assert.NotEqual(t, plain, token.Hash)
+	assert.True(t, token.ExpiresAt.After(time.Now().Add(ttl-time.Minute)))
+
+	_, other, err := NewToken(playerID, TokenPurposePasswordReset, ttl)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plain, other)
+}
@@ -0,0 +1,34 @@
+package backlog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	MaxNoteLength = 256
+)
+
+var (
+	ErrItemNotFound = errors.New("backlog item is not found")
+	ErrItemExists   = errors.New("game is already in the backlog")
+	ErrNoteLength   = fmt.Errorf("note must not be longer than %d symbols", MaxNoteLength)
+)
+
+// Item is a game a player wants to play next, items are ordered by position starting from 0
+type Item struct {
+	ID        int       `json:"id"`
+	PlayerID  string    `json:"player_id"`
+	GameID    int       `json:"game_id"`
+	Position  int       `json:"position"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (i *Item) Valid() error {
+	if i.Note != nil && len([]rune(*i.Note)) > MaxNoteLength {
+		return ErrNoteLength
+	}
+	return nil
+}
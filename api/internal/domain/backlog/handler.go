@@ -0,0 +1,209 @@
+package backlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+)
+
+type ItemRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]Item, error)
+	FindOne(ctx context.Context, playerID string, id int) (*Item, error)
+	Insert(ctx context.Context, item *Item) (int, error)
+	UpdateNote(ctx context.Context, playerID string, id int, note *string) error
+	Move(ctx context.Context, playerID string, id int, position int) error
+	Delete(ctx context.Context, playerID string, id int) error
+}
+
+type GameRepository interface {
+	FindOne(ctx context.Context, id int) (*game.Game, error)
+}
+
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Handler struct {
+	itemRepository ItemRepository
+	gameRepository GameRepository
+	tx             Transactor
+}
+
+func NewHandler(itemRepository ItemRepository, gameRepository GameRepository, tx Transactor) *Handler {
+	return &Handler{
+		itemRepository: itemRepository,
+		gameRepository: gameRepository,
+		tx:             tx,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/players")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"backlog"}
+	})
+	write := map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite}
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "backlog-get-all",
+		Method:      http.MethodGet,
+		Path:        "/{id}/backlog",
+		Summary:     "get backlog",
+		Description: "get games a player wants to play next in order",
+	}, h.GetAll)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "backlog-add-one",
+		Method:      http.MethodPost,
+		Path:        "/{id}/backlog",
+		Summary:     "add game to backlog",
+		Description: "add a game to the end of the backlog or at the position",
+		Metadata:    write,
+	}, h.Add)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "backlog-update-one",
+		Method:      http.MethodPatch,
+		Path:        "/{id}/backlog/{itemID}",
+		Summary:     "update backlog item",
+		Description: "change the note of an item or move it to another position",
+		Metadata:    write,
+	}, h.Update)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "backlog-delete-one",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/backlog/{itemID}",
+		Summary:     "remove game from backlog",
+		Description: "remove a game from the backlog",
+		Metadata:    write,
+	}, h.Delete)
+}
+
+func (h *Handler) GetAll(ctx context.Context, i *RequestBacklog) (*domain.ResponseItems[Item], error) {
+	items, err := h.itemRepository.FindAll(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("backlog find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Item]{}
+	resp.Body.Items = items
+	return &resp, nil
+}
+
+func (h *Handler) Add(ctx context.Context, i *RequestAddItem) (*domain.ResponseID[int], error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if _, err := h.gameRepository.FindOne(ctx, i.Body.GameID); err != nil {
+		log.Printf("backlog game find %v: %v", i.Body.GameID, err)
+		return nil, huma.Error400BadRequest("game is not found")
+	}
+
+	item := Item{
+		PlayerID: i.PlayerID,
+		GameID:   i.Body.GameID,
+		Note:     i.Body.Note,
+	}
+	if err := item.Valid(); err != nil {
+		log.Printf("backlog item valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	var id int
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = h.itemRepository.Insert(ctx, &item)
+		if err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+		if i.Body.Position != nil {
+			return h.itemRepository.Move(ctx, i.PlayerID, id, *i.Body.Position)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("backlog add: %v", err)
+		if errors.Is(err, ErrItemExists) {
+			return nil, huma.Error409Conflict("game is already in the backlog")
+		}
+		return nil, huma.Error500InternalServerError("add", err)
+	}
+
+	log.Printf("backlog item %v added for player %v", id, i.PlayerID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = id
+	return &resp, nil
+}
+
+func (h *Handler) Update(ctx context.Context, i *RequestUpdateItem) (*domain.ResponseID[int], error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	item := Item{Note: i.Body.Note}
+	if err := item.Valid(); err != nil {
+		log.Printf("backlog item valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := h.itemRepository.FindOne(ctx, i.PlayerID, i.ItemID); err != nil {
+			return err
+		}
+		if i.Body.Note != nil {
+			if err := h.itemRepository.UpdateNote(ctx, i.PlayerID, i.ItemID, i.Body.Note); err != nil {
+				return fmt.Errorf("update note: %w", err)
+			}
+		}
+		if i.Body.Position != nil {
+			if err := h.itemRepository.Move(ctx, i.PlayerID, i.ItemID, *i.Body.Position); err != nil {
+				return fmt.Errorf("move: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("backlog update %v: %v", i.ItemID, err)
+		if errors.Is(err, ErrItemNotFound) {
+			return nil, huma.Error404NotFound("backlog item not found")
+		}
+		return nil, huma.Error500InternalServerError("update", err)
+	}
+
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.ItemID
+	return &resp, nil
+}
+
+func (h *Handler) Delete(ctx context.Context, i *RequestDeleteItem) (*domain.ResponseID[int], error) {
+	if !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		return h.itemRepository.Delete(ctx, i.PlayerID, i.ItemID)
+	})
+	if err != nil {
+		log.Printf("backlog delete %v: %v", i.ItemID, err)
+		if errors.Is(err, ErrItemNotFound) {
+			return nil, huma.Error404NotFound("backlog item not found")
+		}
+		return nil, huma.Error500InternalServerError("delete", err)
+	}
+
+	log.Printf("backlog item %v of player %v removed", i.ItemID, i.PlayerID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.ItemID
+	return &resp, nil
+}
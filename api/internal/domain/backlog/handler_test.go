@@ -0,0 +1,214 @@
+package backlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAll(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	handler := NewHandler(itemRepository, NewMockGameRepository(t), passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+	items := []Item{{ID: 1, PlayerID: playerID, GameID: 3}, {ID: 2, PlayerID: playerID, GameID: 1, Position: 1}}
+
+	itemRepository.
+		On("FindAll", ctx, playerID).
+		Once().
+		Return(items, nil)
+
+	resp, err := handler.GetAll(ctx, &RequestBacklog{PlayerID: playerID})
+	assert.NoError(t, err)
+	assert.Equal(t, items, resp.Body.Items)
+}
+
+func TestAdd(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	handler := NewHandler(itemRepository, gameRepository, passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	note := "after the dlc is out"
+
+	req := RequestAddItem{PlayerID: playerID}
+	req.Body.GameID = 3
+	req.Body.Note = &note
+
+	gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	itemRepository.
+		On("Insert", ctx, mock.MatchedBy(func(item *Item) bool {
+			return item.PlayerID == playerID && item.GameID == 3 && *item.Note == note
+		})).
+		Once().
+		Return(5, nil)
+	itemRepository.AssertNotCalled(t, "Move")
+
+	resp, err := handler.Add(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, resp.Body.ID)
+}
+
+func TestAdd_Position(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	handler := NewHandler(itemRepository, gameRepository, passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	position := 0
+
+	req := RequestAddItem{PlayerID: playerID}
+	req.Body.GameID = 3
+	req.Body.Position = &position
+
+	gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	itemRepository.
+		On("Insert", ctx, mock.Anything).
+		Once().
+		Return(5, nil)
+	itemRepository.
+		On("Move", ctx, playerID, 5, 0).
+		Once().
+		Return(nil)
+
+	_, err := handler.Add(ctx, &req)
+	assert.NoError(t, err)
+}
+
+func TestAdd_Exists(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	handler := NewHandler(itemRepository, gameRepository, passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	req := RequestAddItem{PlayerID: playerID}
+	req.Body.GameID = 3
+
+	gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	itemRepository.
+		On("Insert", ctx, mock.Anything).
+		Once().
+		Return(0, ErrItemExists)
+
+	_, err := handler.Add(ctx, &req)
+	assertStatus(t, err, 409)
+}
+
+func TestAdd_OtherPlayer(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	handler := NewHandler(itemRepository, gameRepository, passTx(t))
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	req := RequestAddItem{PlayerID: uuid.NewString()}
+	req.Body.GameID = 3
+
+	itemRepository.AssertNotCalled(t, "Insert")
+
+	_, err := handler.Add(ctx, &req)
+	assertStatus(t, err, 403)
+}
+
+func TestUpdate(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	handler := NewHandler(itemRepository, NewMockGameRepository(t), passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	note := "co-op with friends"
+	position := 2
+
+	req := RequestUpdateItem{PlayerID: playerID, ItemID: 5}
+	req.Body.Note = &note
+	req.Body.Position = &position
+
+	itemRepository.
+		On("FindOne", ctx, playerID, 5).
+		Once().
+		Return(&Item{ID: 5, PlayerID: playerID}, nil)
+	itemRepository.
+		On("UpdateNote", ctx, playerID, 5, &note).
+		Once().
+		Return(nil)
+	itemRepository.
+		On("Move", ctx, playerID, 5, 2).
+		Once().
+		Return(nil)
+
+	resp, err := handler.Update(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, resp.Body.ID)
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	handler := NewHandler(itemRepository, NewMockGameRepository(t), passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	itemRepository.
+		On("FindOne", ctx, playerID, 5).
+		Once().
+		Return(nil, ErrItemNotFound)
+
+	_, err := handler.Update(ctx, &RequestUpdateItem{PlayerID: playerID, ItemID: 5})
+	assertStatus(t, err, 404)
+}
+
+func TestDelete(t *testing.T) {
+	itemRepository := NewMockItemRepository(t)
+	handler := NewHandler(itemRepository, NewMockGameRepository(t), passTx(t))
+
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	itemRepository.
+		On("Delete", ctx, playerID, 5).
+		Once().
+		Return(nil)
+
+	resp, err := handler.Delete(ctx, &RequestDeleteItem{PlayerID: playerID, ItemID: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, resp.Body.ID)
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status, statusErr.GetStatus())
+}
+
+func passTx(t *testing.T) *MockTransactor {
+	tx := NewMockTransactor(t)
+	tx.
+		On("WithTx", mock.Anything, mock.Anything).
+		Maybe().
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tx
+}
@@ -0,0 +1,254 @@
+package backlog
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	TableBacklogItem = "backlog_item"
+
+	pgUniqueViolation = "23505"
+)
+
+const (
+	itemColumns string = "id, player_id, game_id, position, note, created_at"
+)
+
+// PGRepository keeps positions of a player dense, callers run changes of
+// positions in a transaction
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGRepository) FindAll(ctx context.Context, playerID string) ([]Item, error) {
+	out := make([]Item, 0)
+
+	sqlBuild := sq.Select(itemColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("position", "id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := itemFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	return out, rows.Err()
+}
+
+func (r *PGRepository) FindOne(ctx context.Context, playerID string, id int) (*Item, error) {
+	sqlBuild := sq.Select(itemColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": playerID, "id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := itemFromRow(db.Conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Insert appends the item to the end of the backlog
+func (r *PGRepository) Insert(ctx context.Context, item *Item) (int, error) {
+	var id int
+
+	position := sq.Select("COALESCE(MAX(position) + 1, 0)").
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": item.PlayerID})
+
+	sqlBuild := sq.Insert(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "game_id", "position", "note").
+		Values(item.PlayerID, item.GameID, sq.Expr("(?)", position), item.Note).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return id, ErrItemExists
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *PGRepository) UpdateNote(ctx context.Context, playerID string, id int, note *string) error {
+	query, args, err := sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Set("note", note).
+		Where(sq.Eq{"player_id": playerID, "id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// Move places the item at position shifting items in between, position is
+// clamped to the size of the backlog
+func (r *PGRepository) Move(ctx context.Context, playerID string, id int, position int) error {
+	conn := db.Conn(ctx, r.pool)
+
+	item, err := r.FindOne(ctx, playerID, id)
+	if err != nil {
+		return err
+	}
+
+	var last int
+	err = conn.QueryRow(ctx,
+		"SELECT MAX(position) FROM "+TableBacklogItem+" WHERE player_id = $1",
+		playerID,
+	).Scan(&last)
+	if err != nil {
+		return err
+	}
+	position = max(0, min(position, last))
+	if position == item.Position {
+		return nil
+	}
+
+	shift := sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"player_id": playerID})
+	if position < item.Position {
+		shift = shift.Set("position", sq.Expr("position + 1")).
+			Where(sq.GtOrEq{"position": position}).
+			Where(sq.Lt{"position": item.Position})
+	} else {
+		shift = shift.Set("position", sq.Expr("position - 1")).
+			Where(sq.Gt{"position": item.Position}).
+			Where(sq.LtOrEq{"position": position})
+	}
+
+	query, args, err := shift.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	query, args, err = sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Set("position", position).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *PGRepository) Delete(ctx context.Context, playerID string, id int) error {
+	return r.delete(ctx, playerID, sq.Eq{"player_id": playerID, "id": id})
+}
+
+// DeleteByGame removes the game from the backlog of the player if it is there
+func (r *PGRepository) DeleteByGame(ctx context.Context, playerID string, gameID int) error {
+	err := r.delete(ctx, playerID, sq.Eq{"player_id": playerID, "game_id": gameID})
+	if errors.Is(err, ErrItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// delete removes an item and closes the gap in positions
+func (r *PGRepository) delete(ctx context.Context, playerID string, where sq.Eq) error {
+	conn := db.Conn(ctx, r.pool)
+
+	query, args, err := sq.Delete(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Where(where).
+		Suffix("RETURNING position").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	var position int
+	if err := conn.QueryRow(ctx, query, args...).Scan(&position); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		return err
+	}
+
+	query, args, err = sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Dollar).
+		Set("position", sq.Expr("position - 1")).
+		Where(sq.Eq{"player_id": playerID}).
+		Where(sq.Gt{"position": position}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(ctx, query, args...)
+	return err
+}
+
+func itemFromRow(row pgx.Row) (*Item, error) {
+	var i Item
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.GameID,
+		&i.Position,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
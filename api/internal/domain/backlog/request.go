@@ -0,0 +1,29 @@
+package backlog
+
+type RequestBacklog struct {
+	PlayerID string `path:"id" format:"uuid"`
+}
+
+type RequestAddItem struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Body     struct {
+		GameID int     `json:"game_id"`
+		Note   *string `json:"note" maxLength:"256" required:"false"`
+		// Position inserts the item at the position instead of the end
+		Position *int `json:"position" minimum:"0" required:"false"`
+	}
+}
+
+type RequestUpdateItem struct {
+	PlayerID string `path:"id" format:"uuid"`
+	ItemID   int    `path:"itemID"`
+	Body     struct {
+		Note     *string `json:"note" maxLength:"256" required:"false"`
+		Position *int    `json:"position" minimum:"0" required:"false"`
+	}
+}
+
+type RequestDeleteItem struct {
+	PlayerID string `path:"id" format:"uuid"`
+	ItemID   int    `path:"itemID"`
+}
@@ -0,0 +1,226 @@
+package backlog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+// SQLiteRepository keeps positions of a player dense like PGRepository does
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context, playerID string) ([]Item, error) {
+	out := make([]Item, 0)
+
+	sqlBuild := sq.Select(itemColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("position", "id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := itemFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) FindOne(ctx context.Context, playerID string, id int) (*Item, error) {
+	sqlBuild := sq.Select(itemColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": playerID, "id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := itemFromRow(db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Insert appends the item to the end of the backlog
+func (r *SQLiteRepository) Insert(ctx context.Context, item *Item) (int, error) {
+	var id int
+
+	position := sq.Select("COALESCE(MAX(position) + 1, 0)").
+		From(TableBacklogItem).
+		Where(sq.Eq{"player_id": item.PlayerID})
+
+	sqlBuild := sq.Insert(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "game_id", "position", "note").
+		Values(item.PlayerID, item.GameID, sq.Expr("(?)", position), item.Note).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		if db.SQLiteUniqueViolation(err, TableBacklogItem, "game_id") {
+			return id, ErrItemExists
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepository) UpdateNote(ctx context.Context, playerID string, id int, note *string) error {
+	query, args, err := sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Set("note", note).
+		Where(sq.Eq{"player_id": playerID, "id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// Move places the item at position shifting items in between, position is
+// clamped to the size of the backlog
+func (r *SQLiteRepository) Move(ctx context.Context, playerID string, id int, position int) error {
+	conn := db.SQLiteConn(ctx, r.db)
+
+	item, err := r.FindOne(ctx, playerID, id)
+	if err != nil {
+		return err
+	}
+
+	var last int
+	err = conn.QueryRowContext(ctx,
+		"SELECT MAX(position) FROM "+TableBacklogItem+" WHERE player_id = ?",
+		playerID,
+	).Scan(&last)
+	if err != nil {
+		return err
+	}
+	position = max(0, min(position, last))
+	if position == item.Position {
+		return nil
+	}
+
+	shift := sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"player_id": playerID})
+	if position < item.Position {
+		shift = shift.Set("position", sq.Expr("position + 1")).
+			Where(sq.GtOrEq{"position": position}).
+			Where(sq.Lt{"position": item.Position})
+	} else {
+		shift = shift.Set("position", sq.Expr("position - 1")).
+			Where(sq.Gt{"position": item.Position}).
+			Where(sq.LtOrEq{"position": position})
+	}
+
+	query, args, err := shift.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	query, args, err = sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Set("position", position).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, playerID string, id int) error {
+	return r.delete(ctx, playerID, sq.Eq{"player_id": playerID, "id": id})
+}
+
+// DeleteByGame removes the game from the backlog of the player if it is there
+func (r *SQLiteRepository) DeleteByGame(ctx context.Context, playerID string, gameID int) error {
+	err := r.delete(ctx, playerID, sq.Eq{"player_id": playerID, "game_id": gameID})
+	if errors.Is(err, ErrItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// delete removes an item and closes the gap in positions
+func (r *SQLiteRepository) delete(ctx context.Context, playerID string, where sq.Eq) error {
+	conn := db.SQLiteConn(ctx, r.db)
+
+	query, args, err := sq.Delete(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Where(where).
+		Suffix("RETURNING position").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	var position int
+	if err := conn.QueryRowContext(ctx, query, args...).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		return err
+	}
+
+	query, args, err = sq.Update(TableBacklogItem).
+		PlaceholderFormat(sq.Question).
+		Set("position", sq.Expr("position - 1")).
+		Where(sq.Eq{"player_id": playerID}).
+		Where(sq.Gt{"position": position}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,237 @@
+package challenge
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+const (
+	// WinnerBonus is added to the points of the played game of the winner
+	WinnerBonus = 2
+	// AcceptTTL is how long invited players can accept a challenge
+	AcceptTTL = 72 * time.Hour
+
+	MaxOpponents = 10
+)
+
+type Mode string
+
+const (
+	// ModeFirstCompleted is won by the first participant to complete the game
+	ModeFirstCompleted Mode = "first_completed"
+	// ModePlayTime is won by the lowest play time once every participant finished
+	ModePlayTime Mode = "play_time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusActive    Status = "active"
+	StatusFinished  Status = "finished"
+	StatusDeclined  Status = "declined"
+	StatusExpired   Status = "expired"
+	StatusCancelled Status = "cancelled"
+)
+
+type ParticipantStatus string
+
+const (
+	ParticipantInvited  ParticipantStatus = "invited"
+	ParticipantAccepted ParticipantStatus = "accepted"
+	ParticipantDeclined ParticipantStatus = "declined"
+	ParticipantExpired  ParticipantStatus = "expired"
+)
+
+var (
+	ErrChallengeNotFound   = errors.New("challenge is not found")
+	ErrParticipantNotFound = errors.New("player is not invited to the challenge")
+	ErrNoOpponents         = fmt.Errorf("challenge must have from 1 to %d opponents", MaxOpponents)
+	ErrSelfChallenge       = errors.New("player cannot challenge themselves")
+	ErrDuplicateOpponent   = errors.New("opponents must be unique")
+	ErrInvalidMode         = fmt.Errorf("mode must be one of %v", modes)
+	ErrNotInvited          = errors.New("player has already answered the challenge")
+	ErrChallengeClosed     = errors.New("challenge is not open")
+)
+
+var (
+	modes = []Mode{ModeFirstCompleted, ModePlayTime}
+
+	openStatus = []Status{StatusPending, StatusActive}
+
+	validChallengeStatuses = map[Status][]Status{
+		StatusPending:   {StatusActive, StatusDeclined, StatusExpired, StatusCancelled},
+		StatusActive:    {StatusFinished},
+		StatusFinished:  {},
+		StatusDeclined:  {},
+		StatusExpired:   {},
+		StatusCancelled: {},
+	}
+)
+
+// Challenge is a game several players race to beat, the challenger accepts it on creation
+type Challenge struct {
+	ID           int           `json:"id"`
+	ChallengerID string        `json:"challenger_id"`
+	GameID       int           `json:"game_id"`
+	Mode         Mode          `json:"mode"`
+	Bonus        int           `json:"bonus"`
+	Status       Status        `json:"status"`
+	WinnerID     *string       `json:"winner_id"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	FinishedAt   *time.Time    `json:"finished_at"`
+	Participants []Participant `json:"participants"`
+}
+
+type Participant struct {
+	PlayerID     string            `json:"player_id"`
+	Status       ParticipantStatus `json:"status"`
+	PlayedGameID *int              `json:"played_game_id"`
+}
+
+func (c *Challenge) Valid() error {
+	if !slices.Contains(modes, c.Mode) {
+		return ErrInvalidMode
+	}
+
+	opponents := 0
+	seen := make(map[string]bool, len(c.Participants))
+	for _, p := range c.Participants {
+		if seen[p.PlayerID] {
+			if p.PlayerID == c.ChallengerID {
+				return ErrSelfChallenge
+			}
+			return ErrDuplicateOpponent
+		}
+		seen[p.PlayerID] = true
+		if p.PlayerID != c.ChallengerID {
+			opponents++
+		}
+	}
+	if opponents < 1 || opponents > MaxOpponents {
+		return ErrNoOpponents
+	}
+	return nil
+}
+
+func (c *Challenge) Open() bool {
+	return slices.Contains(openStatus, c.Status)
+}
+
+func (c *Challenge) StatusNextValid(next Status) error {
+	nextMp := validChallengeStatuses[c.Status]
+	if ok := slices.Contains(nextMp, next); !ok {
+		return fmt.Errorf("next status is not in possible: %v", nextMp)
+	}
+	return nil
+}
+
+// Participant returns the participant of the player or nil
+func (c *Challenge) Participant(playerID string) *Participant {
+	for i := range c.Participants {
+		if c.Participants[i].PlayerID == playerID {
+			return &c.Participants[i]
+		}
+	}
+	return nil
+}
+
+// Decide returns the status of an open challenge given played games of accepted participants
+// keyed by played game id, winner is set for a finished challenge if anyone completed the game.
+//
+// Rules:
+//   - nobody but the challenger accepted and nobody is invited anymore: expired or declined
+//   - the challenger finished before anybody accepted: cancelled
+//   - first_completed: the earliest completion wins as soon as there is one
+//   - play_time: once every accepted participant finished and nobody is invited anymore
+//     the lowest play time wins, completions without play time rank after by completion time
+func (c *Challenge) Decide(games map[int]player.PlayedGame) (Status, *player.PlayedGame) {
+	var (
+		accepted  []player.PlayedGame
+		opponents int
+		invited   bool
+		expired   bool
+	)
+	for _, p := range c.Participants {
+		switch p.Status {
+		case ParticipantInvited:
+			invited = true
+		case ParticipantExpired:
+			expired = true
+		case ParticipantAccepted:
+			if p.PlayerID != c.ChallengerID {
+				opponents++
+			}
+			if p.PlayedGameID != nil {
+				if g, ok := games[*p.PlayedGameID]; ok {
+					accepted = append(accepted, g)
+				}
+			}
+		}
+	}
+
+	terminated := !slices.ContainsFunc(accepted, func(g player.PlayedGame) bool {
+		return !g.StatusTerminated()
+	})
+
+	if opponents == 0 {
+		switch {
+		case invited && terminated:
+			return StatusCancelled, nil
+		case invited:
+			return c.Status, nil
+		case expired:
+			return StatusExpired, nil
+		default:
+			return StatusDeclined, nil
+		}
+	}
+
+	completed := slices.DeleteFunc(slices.Clone(accepted), func(g player.PlayedGame) bool {
+		return g.Status != player.PlayedGameStatusCompleted
+	})
+	slices.SortStableFunc(completed, func(a, b player.PlayedGame) int {
+		if c.Mode == ModePlayTime {
+			switch {
+			case a.PlayTime != nil && b.PlayTime == nil:
+				return -1
+			case a.PlayTime == nil && b.PlayTime != nil:
+				return 1
+			case a.PlayTime != nil && a.PlayTime.Duration != b.PlayTime.Duration:
+				return cmp.Compare(a.PlayTime.Duration, b.PlayTime.Duration)
+			}
+		}
+		return completedAt(a).Compare(completedAt(b))
+	})
+
+	if c.Mode == ModeFirstCompleted && len(completed) > 0 {
+		return StatusFinished, &completed[0]
+	}
+	if !terminated || invited {
+		return StatusActive, nil
+	}
+	if len(completed) == 0 {
+		return StatusFinished, nil
+	}
+	return StatusFinished, &completed[0]
+}
+
+func completedAt(g player.PlayedGame) time.Time {
+	if g.CompletedAt == nil {
+		return time.Time{}
+	}
+	return *g.CompletedAt
+}
+
+type ChallengeUpdate struct {
+	ID         int
+	Status     *Status
+	WinnerID   *string
+	FinishedAt *time.Time
+}
@@ -0,0 +1,182 @@
+package challenge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+func TestChallengeValid(t *testing.T) {
+	challenger := Participant{PlayerID: "a", Status: ParticipantAccepted}
+
+	tcases := []struct {
+		name         string
+		mode         Mode
+		participants []Participant
+		err          error
+	}{
+		{"valid", ModeFirstCompleted, []Participant{challenger, {PlayerID: "b"}}, nil},
+		{"no opponents", ModePlayTime, []Participant{challenger}, ErrNoOpponents},
+		{"self", ModePlayTime, []Participant{challenger, {PlayerID: "a"}}, ErrSelfChallenge},
+		{"duplicate", ModePlayTime, []Participant{challenger, {PlayerID: "b"}, {PlayerID: "b"}}, ErrDuplicateOpponent},
+		{"mode", Mode("fastest"), []Participant{challenger, {PlayerID: "b"}}, ErrInvalidMode},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Challenge{ChallengerID: "a", Mode: tc.mode, Participants: tc.participants}
+			assert.Equal(t, tc.err, c.Valid())
+		})
+	}
+}
+
+func TestChallengeDecide(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	fast := types.NewDurationString(10 * time.Hour)
+	slow := types.NewDurationString(20 * time.Hour)
+
+	played := func(id int, status player.PlayedGameStatus, completedAt *time.Time, playTime *types.DurationString) player.PlayedGame {
+		return player.PlayedGame{
+			ID:          id,
+			PlayerID:    string(rune('a' + id - 1)),
+			Status:      status,
+			CompletedAt: completedAt,
+			PlayTime:    playTime,
+		}
+	}
+	participant := func(id int, status ParticipantStatus) Participant {
+		p := Participant{PlayerID: string(rune('a' + id - 1)), Status: status}
+		if status == ParticipantAccepted {
+			p.PlayedGameID = &id
+		}
+		return p
+	}
+
+	tcases := []struct {
+		name         string
+		mode         Mode
+		status       Status
+		participants []Participant
+		games        []player.PlayedGame
+		next         Status
+		winner       int
+	}{
+		{
+			"waiting for opponents",
+			ModeFirstCompleted,
+			StatusPending,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantInvited)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusInProgress, nil, nil)},
+			StatusPending,
+			0,
+		},
+		{
+			"challenger finished alone",
+			ModeFirstCompleted,
+			StatusPending,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantInvited)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusCompleted, &now, nil)},
+			StatusCancelled,
+			0,
+		},
+		{
+			"everyone declined",
+			ModeFirstCompleted,
+			StatusPending,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantDeclined)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusInProgress, nil, nil)},
+			StatusDeclined,
+			0,
+		},
+		{
+			"invitations expired",
+			ModePlayTime,
+			StatusPending,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantDeclined), participant(3, ParticipantExpired)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusInProgress, nil, nil)},
+			StatusExpired,
+			0,
+		},
+		{
+			"opponent accepted",
+			ModeFirstCompleted,
+			StatusPending,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantAccepted)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusAdded, nil, nil), played(2, player.PlayedGameStatusAdded, nil, nil)},
+			StatusActive,
+			0,
+		},
+		{
+			"first completed wins",
+			ModeFirstCompleted,
+			StatusActive,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantAccepted), participant(3, ParticipantInvited)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusInProgress, nil, nil), played(2, player.PlayedGameStatusCompleted, &now, nil)},
+			StatusFinished,
+			2,
+		},
+		{
+			"play time waits for everyone",
+			ModePlayTime,
+			StatusActive,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantAccepted)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusInProgress, nil, nil), played(2, player.PlayedGameStatusCompleted, &now, &fast)},
+			StatusActive,
+			0,
+		},
+		{
+			"lowest play time wins",
+			ModePlayTime,
+			StatusActive,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantAccepted), participant(3, ParticipantAccepted)},
+			[]player.PlayedGame{
+				played(1, player.PlayedGameStatusCompleted, &earlier, &slow),
+				played(2, player.PlayedGameStatusCompleted, &now, &fast),
+				played(3, player.PlayedGameStatusCompleted, &earlier, nil),
+			},
+			StatusFinished,
+			2,
+		},
+		{
+			"nobody completed",
+			ModePlayTime,
+			StatusActive,
+			[]Participant{participant(1, ParticipantAccepted), participant(2, ParticipantAccepted)},
+			[]player.PlayedGame{played(1, player.PlayedGameStatusDropped, &now, nil), played(2, player.PlayedGameStatusRerolled, &now, nil)},
+			StatusFinished,
+			0,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Challenge{ChallengerID: "a", Mode: tc.mode, Status: tc.status, Participants: tc.participants}
+			games := make(map[int]player.PlayedGame)
+			for _, g := range tc.games {
+				games[g.ID] = g
+			}
+
+			next, winner := c.Decide(games)
+			assert.Equal(t, tc.next, next)
+			if tc.winner == 0 {
+				assert.Zero(t, winner)
+			} else {
+				assert.NotZero(t, winner)
+				assert.Equal(t, tc.winner, winner.ID)
+			}
+		})
+	}
+}
+
+func TestChallengeStatusNextValid(t *testing.T) {
+	c := Challenge{Status: StatusPending}
+	assert.NoError(t, c.StatusNextValid(StatusActive))
+	assert.Error(t, c.StatusNextValid(StatusFinished))
+
+	c.Status = StatusFinished
+	assert.Error(t, c.StatusNextValid(StatusActive))
+}
@@ -0,0 +1,340 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+type ChallengeRepository interface {
+	FindAll(ctx context.Context, playerID string, all bool) ([]Challenge, error)
+	FindOne(ctx context.Context, id int) (*Challenge, error)
+	FindOpenByPlayedGame(ctx context.Context, playedGameID int) (*Challenge, error)
+	FindDue(ctx context.Context, now time.Time) ([]Challenge, error)
+	Insert(ctx context.Context, c *Challenge) (int, error)
+	Update(ctx context.Context, c *ChallengeUpdate) (int, error)
+	UpdateParticipant(ctx context.Context, challengeID int, p *Participant) error
+	ExpireInvitations(ctx context.Context, challengeID int) error
+}
+
+type PlayerRepository interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+}
+
+type GameRepository interface {
+	FindOne(ctx context.Context, id int) (*game.Game, error)
+}
+
+type PlayedGameRepository interface {
+	FindOne(ctx context.Context, playerID string, id int) (*player.PlayedGame, error)
+	Update(ctx context.Context, game *player.PlayedGameUpdate) (int, error)
+}
+
+type LedgerRepository interface {
+	Insert(ctx context.Context, entries []player.LedgerEntry) error
+}
+
+// PlayedGameHandler creates played games of participants with the rules of the http api
+type PlayedGameHandler interface {
+	CreatePlayedGame(ctx context.Context, i *player.RequestCreatePlayedGame) (*domain.ResponseID[int], error)
+}
+
+type Settler interface {
+	Settle(ctx context.Context, c *Challenge) error
+}
+
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+type Handler struct {
+	challengeRepository ChallengeRepository
+	playerRepository    PlayerRepository
+	gameRepository      GameRepository
+	playedGames         PlayedGameHandler
+	settler             Settler
+	tx                  Transactor
+}
+
+func NewHandler(
+	challengeRepository ChallengeRepository,
+	playerRepository PlayerRepository,
+	gameRepository GameRepository,
+	playedGames PlayedGameHandler,
+	settler Settler,
+	tx Transactor,
+) *Handler {
+	return &Handler{
+		challengeRepository: challengeRepository,
+		playerRepository:    playerRepository,
+		gameRepository:      gameRepository,
+		playedGames:         playedGames,
+		settler:             settler,
+		tx:                  tx,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	players := huma.NewGroup(api, "/players")
+	players.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"challenges"}
+	})
+
+	huma.Register(players, huma.Operation{
+		OperationID: "challenges-get-all",
+		Method:      http.MethodGet,
+		Path:        "/{id}/challenges",
+		Summary:     "get challenges of player",
+		Description: "get pending and active challenges the player participates in, all of them with all",
+	}, h.GetAll)
+
+	grp := huma.NewGroup(api, "/challenges")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"challenges"}
+	})
+	write := map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite}
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "challenges-get-one",
+		Method:      http.MethodGet,
+		Path:        "/{id}",
+		Summary:     "get challenge",
+		Description: "get a challenge with its participants",
+	}, h.GetOne)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "challenges-create-one",
+		Method:      http.MethodPost,
+		Path:        "/",
+		Summary:     "challenge players",
+		Description: fmt.Sprintf(
+			"challenge players to beat a game, a played game is added for the challenger. "+
+				"Opponents can accept within %v, the winner gets %d bonus points",
+			AcceptTTL, WinnerBonus,
+		),
+		Metadata: write,
+	}, h.Create)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "challenges-accept",
+		Method:      http.MethodPost,
+		Path:        "/{id}/accept",
+		Summary:     "accept challenge",
+		Description: "accept an invitation to a challenge, a played game of the challenge is added",
+		Metadata:    write,
+	}, h.Accept)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "challenges-decline",
+		Method:      http.MethodPost,
+		Path:        "/{id}/decline",
+		Summary:     "decline challenge",
+		Description: "decline an invitation to a challenge",
+		Metadata:    write,
+	}, h.Decline)
+}
+
+func (h *Handler) GetAll(ctx context.Context, i *RequestPlayerChallenges) (*domain.ResponseItems[Challenge], error) {
+	challenges, err := h.challengeRepository.FindAll(ctx, i.PlayerID, i.All)
+	if err != nil {
+		log.Printf("challenges find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Challenge]{}
+	resp.Body.Items = challenges
+	return &resp, nil
+}
+
+func (h *Handler) GetOne(ctx context.Context, i *RequestChallenge) (*domain.ResponseItem[Challenge], error) {
+	c, err := h.challengeRepository.FindOne(ctx, i.ID)
+	if err != nil {
+		log.Printf("challenge find one %v: %v", i.ID, err)
+		if errors.Is(err, ErrChallengeNotFound) {
+			return nil, huma.Error404NotFound("challenge not found")
+		}
+		return nil, huma.Error500InternalServerError("find", err)
+	}
+
+	resp := domain.ResponseItem[Challenge]{}
+	resp.Body.Item = c
+	return &resp, nil
+}
+
+func (h *Handler) Create(ctx context.Context, i *RequestCreateChallenge) (*domain.ResponseID[int], error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if _, err := h.gameRepository.FindOne(ctx, i.Body.GameID); err != nil {
+		log.Printf("challenge game find %v: %v", i.Body.GameID, err)
+		return nil, huma.Error400BadRequest("game is not found")
+	}
+
+	c := Challenge{
+		ChallengerID: ctxPlayer.ID,
+		GameID:       i.Body.GameID,
+		Mode:         i.Body.Mode,
+		Bonus:        WinnerBonus,
+		Status:       StatusPending,
+		ExpiresAt:    time.Now().Add(AcceptTTL),
+		Participants: []Participant{{PlayerID: ctxPlayer.ID, Status: ParticipantAccepted}},
+	}
+	for _, id := range i.Body.OpponentIDs {
+		c.Participants = append(c.Participants, Participant{PlayerID: id, Status: ParticipantInvited})
+	}
+	if err := c.Valid(); err != nil {
+		log.Printf("challenge valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	for _, id := range i.Body.OpponentIDs {
+		if _, err := h.playerRepository.FindOne(ctx, id); err != nil {
+			log.Printf("challenge opponent find %v: %v", id, err)
+			return nil, huma.Error400BadRequest(fmt.Sprintf("player %v is not found", id))
+		}
+	}
+
+	var id int
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		played, err := h.playedGames.CreatePlayedGame(ctx, playedGameRequest(ctxPlayer.ID, c.GameID))
+		if err != nil {
+			return err
+		}
+		c.Participants[0].PlayedGameID = &played.Body.ID
+
+		id, err = h.challengeRepository.Insert(ctx, &c)
+		if err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, challengeError("challenge create", err)
+	}
+
+	log.Printf("challenge %v created by %v", id, ctxPlayer.ID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = id
+	return &resp, nil
+}
+
+func (h *Handler) Accept(ctx context.Context, i *RequestChallenge) (*domain.ResponseID[int], error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		c, p, err := h.invitation(ctx, i.ID, ctxPlayer.ID)
+		if err != nil {
+			return err
+		}
+
+		played, err := h.playedGames.CreatePlayedGame(ctx, playedGameRequest(ctxPlayer.ID, c.GameID))
+		if err != nil {
+			return err
+		}
+		p.Status = ParticipantAccepted
+		p.PlayedGameID = &played.Body.ID
+		if err := h.challengeRepository.UpdateParticipant(ctx, c.ID, p); err != nil {
+			return fmt.Errorf("participant update: %w", err)
+		}
+		return h.settler.Settle(ctx, c)
+	})
+	if err != nil {
+		return nil, challengeError("challenge accept", err)
+	}
+
+	log.Printf("challenge %v accepted by %v", i.ID, ctxPlayer.ID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.ID
+	return &resp, nil
+}
+
+func (h *Handler) Decline(ctx context.Context, i *RequestChallenge) (*domain.ResponseID[int], error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		c, p, err := h.invitation(ctx, i.ID, ctxPlayer.ID)
+		if err != nil {
+			return err
+		}
+
+		p.Status = ParticipantDeclined
+		if err := h.challengeRepository.UpdateParticipant(ctx, c.ID, p); err != nil {
+			return fmt.Errorf("participant update: %w", err)
+		}
+		return h.settler.Settle(ctx, c)
+	})
+	if err != nil {
+		return nil, challengeError("challenge decline", err)
+	}
+
+	log.Printf("challenge %v declined by %v", i.ID, ctxPlayer.ID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.ID
+	return &resp, nil
+}
+
+// invitation returns the open challenge and the participant of the player who has not answered yet
+func (h *Handler) invitation(ctx context.Context, id int, playerID string) (*Challenge, *Participant, error) {
+	c, err := h.challengeRepository.FindOne(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !c.Open() || !time.Now().Before(c.ExpiresAt) {
+		return nil, nil, ErrChallengeClosed
+	}
+
+	p := c.Participant(playerID)
+	if p == nil {
+		return nil, nil, ErrParticipantNotFound
+	}
+	if p.Status != ParticipantInvited {
+		return nil, nil, ErrNotInvited
+	}
+	return c, p, nil
+}
+
+// challengeError maps errors of challenge changes to api errors, errors of played games
+// created for participants are returned as they are
+func challengeError(op string, err error) error {
+	log.Printf("%v: %v", op, err)
+
+	var statusErr huma.StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr
+	case errors.Is(err, ErrChallengeNotFound), errors.Is(err, ErrParticipantNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, ErrChallengeClosed), errors.Is(err, ErrNotInvited):
+		return huma.Error409Conflict(err.Error())
+	}
+	return huma.Error500InternalServerError(op, err)
+}
+
+func playedGameRequest(playerID string, gameID int) *player.RequestCreatePlayedGame {
+	req := player.RequestCreatePlayedGame{PlayerID: playerID}
+	req.Body.GameID = gameID
+	return &req
+}
@@ -0,0 +1,281 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/stretchr/testify/mock"
+)
+
+type testHandler struct {
+	*Handler
+	challengeRepository *MockChallengeRepository
+	playerRepository    *MockPlayerRepository
+	gameRepository      *MockGameRepository
+	playedGames         *MockPlayedGameHandler
+	settler             *MockSettler
+}
+
+func newTestHandler(t *testing.T) *testHandler {
+	h := testHandler{
+		challengeRepository: NewMockChallengeRepository(t),
+		playerRepository:    NewMockPlayerRepository(t),
+		gameRepository:      NewMockGameRepository(t),
+		playedGames:         NewMockPlayedGameHandler(t),
+		settler:             NewMockSettler(t),
+	}
+	h.Handler = NewHandler(
+		h.challengeRepository,
+		h.playerRepository,
+		h.gameRepository,
+		h.playedGames,
+		h.settler,
+		passTx(t),
+	)
+	return &h
+}
+
+func (h *testHandler) createsPlayedGame(playerID string, gameID int, id int) {
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = id
+	h.playedGames.
+		On("CreatePlayedGame", mock.Anything, mock.MatchedBy(func(r *player.RequestCreatePlayedGame) bool {
+			return r.PlayerID == playerID && r.Body.GameID == gameID
+		})).
+		Once().
+		Return(&resp, nil)
+}
+
+func TestCreate(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: challengerID})
+
+	req := RequestCreateChallenge{}
+	req.Body.GameID = 3
+	req.Body.OpponentIDs = []string{opponentID}
+	req.Body.Mode = ModePlayTime
+
+	h.gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	h.playerRepository.
+		On("FindOne", ctx, opponentID).
+		Once().
+		Return(&player.Player{ID: opponentID}, nil)
+	h.createsPlayedGame(challengerID, 3, 11)
+
+	var inserted *Challenge
+	h.challengeRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(c *Challenge) bool {
+			inserted = c
+			return true
+		})).
+		Once().
+		Return(4, nil)
+
+	resp, err := h.Create(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Body.ID)
+
+	assert.Equal(t, StatusPending, inserted.Status)
+	assert.Equal(t, WinnerBonus, inserted.Bonus)
+	assert.True(t, inserted.ExpiresAt.After(time.Now()))
+	assert.Equal(t, 2, len(inserted.Participants))
+	assert.Equal(t, ParticipantAccepted, inserted.Participants[0].Status)
+	assert.Equal(t, 11, *inserted.Participants[0].PlayedGameID)
+	assert.Equal(t, Participant{PlayerID: opponentID, Status: ParticipantInvited}, inserted.Participants[1])
+}
+
+func TestCreate_Self(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: challengerID})
+
+	req := RequestCreateChallenge{}
+	req.Body.GameID = 3
+	req.Body.OpponentIDs = []string{challengerID}
+	req.Body.Mode = ModeFirstCompleted
+
+	h.gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	h.challengeRepository.AssertNotCalled(t, "Insert")
+
+	_, err := h.Create(ctx, &req)
+	assertStatus(t, err, 400)
+}
+
+func TestCreate_PlayedGameRejected(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: challengerID})
+
+	req := RequestCreateChallenge{}
+	req.Body.GameID = 3
+	req.Body.OpponentIDs = []string{opponentID}
+	req.Body.Mode = ModeFirstCompleted
+
+	h.gameRepository.
+		On("FindOne", ctx, 3).
+		Once().
+		Return(&game.Game{ID: 3}, nil)
+	h.playerRepository.
+		On("FindOne", ctx, opponentID).
+		Once().
+		Return(&player.Player{ID: opponentID}, nil)
+	h.playedGames.
+		On("CreatePlayedGame", mock.Anything, mock.Anything).
+		Once().
+		Return(nil, huma.Error409Conflict("player has game in nonterminated status: 1"))
+	h.challengeRepository.AssertNotCalled(t, "Insert")
+
+	_, err := h.Create(ctx, &req)
+	assertStatus(t, err, 409)
+}
+
+func TestAccept(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: opponentID})
+
+	h.challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(openChallenge(challengerID, opponentID), nil)
+	h.createsPlayedGame(opponentID, 3, 12)
+	h.challengeRepository.
+		On("UpdateParticipant", mock.Anything, 4, mock.MatchedBy(func(p *Participant) bool {
+			return p.PlayerID == opponentID && p.Status == ParticipantAccepted && *p.PlayedGameID == 12
+		})).
+		Once().
+		Return(nil)
+	h.settler.
+		On("Settle", mock.Anything, mock.MatchedBy(func(c *Challenge) bool { return c.ID == 4 })).
+		Once().
+		Return(nil)
+
+	resp, err := h.Accept(ctx, &RequestChallenge{ID: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Body.ID)
+}
+
+func TestAccept_Expired(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: opponentID})
+
+	c := openChallenge(challengerID, opponentID)
+	c.ExpiresAt = time.Now().Add(-time.Minute)
+	h.challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(c, nil)
+	h.playedGames.AssertNotCalled(t, "CreatePlayedGame")
+
+	_, err := h.Accept(ctx, &RequestChallenge{ID: 4})
+	assertStatus(t, err, 409)
+}
+
+func TestAccept_NotInvited(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	h.challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(openChallenge(challengerID, uuid.NewString()), nil)
+
+	_, err := h.Accept(ctx, &RequestChallenge{ID: 4})
+	assertStatus(t, err, 404)
+}
+
+func TestDecline(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: opponentID})
+
+	h.challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(openChallenge(challengerID, opponentID), nil)
+	h.challengeRepository.
+		On("UpdateParticipant", mock.Anything, 4, mock.MatchedBy(func(p *Participant) bool {
+			return p.PlayerID == opponentID && p.Status == ParticipantDeclined && p.PlayedGameID == nil
+		})).
+		Once().
+		Return(nil)
+	h.settler.
+		On("Settle", mock.Anything, mock.Anything).
+		Once().
+		Return(nil)
+
+	_, err := h.Decline(ctx, &RequestChallenge{ID: 4})
+	assert.NoError(t, err)
+}
+
+func TestDecline_Answered(t *testing.T) {
+	h := newTestHandler(t)
+	challengerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: challengerID})
+
+	h.challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(openChallenge(challengerID, uuid.NewString()), nil)
+
+	_, err := h.Decline(ctx, &RequestChallenge{ID: 4})
+	assertStatus(t, err, 409)
+}
+
+func openChallenge(challengerID, opponentID string) *Challenge {
+	playedGameID := 11
+	return &Challenge{
+		ID:           4,
+		ChallengerID: challengerID,
+		GameID:       3,
+		Mode:         ModeFirstCompleted,
+		Bonus:        WinnerBonus,
+		Status:       StatusPending,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		Participants: []Participant{
+			{PlayerID: challengerID, Status: ParticipantAccepted, PlayedGameID: &playedGameID},
+			{PlayerID: opponentID, Status: ParticipantInvited},
+		},
+	}
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, status, statusErr.GetStatus())
+}
+
+func passTx(t *testing.T) *MockTransactor {
+	tx := NewMockTransactor(t)
+	tx.
+		On("WithTx", mock.Anything, mock.Anything).
+		Maybe().
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tx
+}
@@ -0,0 +1,19 @@
+package challenge
+
+type RequestPlayerChallenges struct {
+	PlayerID string `path:"id" format:"uuid"`
+	// All includes finished, declined, expired and cancelled challenges
+	All bool `query:"all"`
+}
+
+type RequestChallenge struct {
+	ID int `path:"id"`
+}
+
+type RequestCreateChallenge struct {
+	Body struct {
+		GameID      int      `json:"game_id"`
+		OpponentIDs []string `json:"opponent_ids" minItems:"1" maxItems:"10" uniqueItems:"true"`
+		Mode        Mode     `json:"mode" enum:"first_completed,play_time"`
+	}
+}
@@ -0,0 +1,199 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+const (
+	defaultExpireInterval = time.Minute
+)
+
+var (
+	// resolvingEvents are events of played games which can decide a challenge
+	resolvingEvents = []event.Type{
+		event.PlayedGameCompleted,
+		event.PlayedGameDropped,
+		event.PlayedGameRerolled,
+	}
+)
+
+// Resolver decides challenges when linked played games finish and expires unanswered
+// invitations. It is an event publisher so it runs in the transaction of the played game.
+type Resolver struct {
+	challengeRepository  ChallengeRepository
+	playedGameRepository PlayedGameRepository
+	ledgerRepository     LedgerRepository
+	tx                   Transactor
+	publisher            EventPublisher
+	interval             time.Duration
+}
+
+func NewResolver(
+	challengeRepository ChallengeRepository,
+	playedGameRepository PlayedGameRepository,
+	ledgerRepository LedgerRepository,
+	tx Transactor,
+	publisher EventPublisher,
+	interval time.Duration,
+) *Resolver {
+	if interval == 0 {
+		interval = defaultExpireInterval
+	}
+	return &Resolver{
+		challengeRepository:  challengeRepository,
+		playedGameRepository: playedGameRepository,
+		ledgerRepository:     ledgerRepository,
+		tx:                   tx,
+		publisher:            publisher,
+		interval:             interval,
+	}
+}
+
+// Publish settles the challenge of a finished played game
+func (r *Resolver) Publish(ctx context.Context, e event.Event) error {
+	if !slices.Contains(resolvingEvents, e.Type) {
+		return nil
+	}
+	played, ok := e.Data.(*player.PlayedGame)
+	if !ok {
+		return nil
+	}
+
+	c, err := r.challengeRepository.FindOpenByPlayedGame(ctx, played.ID)
+	if errors.Is(err, ErrChallengeNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("challenge find: %w", err)
+	}
+	return r.Settle(ctx, c)
+}
+
+// Run expires invitations of due challenges until ctx is done
+func (r *Resolver) Run(ctx context.Context) {
+	ticker := time.Tick(r.interval)
+
+	for {
+		select {
+		case <-ticker:
+			if err := r.expire(ctx, time.Now()); err != nil {
+				log.Printf("challenge expire: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Resolver) expire(ctx context.Context, now time.Time) error {
+	due, err := r.challengeRepository.FindDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range due {
+		err := r.tx.WithTx(ctx, func(ctx context.Context) error {
+			if err := r.challengeRepository.ExpireInvitations(ctx, c.ID); err != nil {
+				return err
+			}
+			for i := range c.Participants {
+				if c.Participants[i].Status == ParticipantInvited {
+					c.Participants[i].Status = ParticipantExpired
+				}
+			}
+			return r.Settle(ctx, &c)
+		})
+		if err != nil {
+			return fmt.Errorf("challenge %v: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// Settle moves an open challenge to the status decided by played games of its participants
+// and gives the bonus to the winner
+func (r *Resolver) Settle(ctx context.Context, c *Challenge) error {
+	games := make(map[int]player.PlayedGame)
+	for _, p := range c.Participants {
+		if p.Status != ParticipantAccepted || p.PlayedGameID == nil {
+			continue
+		}
+		played, err := r.playedGameRepository.FindOne(ctx, p.PlayerID, *p.PlayedGameID)
+		if errors.Is(err, player.ErrPlayedGameNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("played game find: %w", err)
+		}
+		games[played.ID] = *played
+	}
+
+	status, winner := c.Decide(games)
+	if status == c.Status {
+		return nil
+	}
+	if err := c.StatusNextValid(status); err != nil {
+		return fmt.Errorf("challenge %v: %w", c.ID, err)
+	}
+
+	now := time.Now()
+	update := ChallengeUpdate{ID: c.ID, Status: &status}
+	if status == StatusFinished {
+		update.FinishedAt = &now
+	}
+	if winner != nil {
+		update.WinnerID = &winner.PlayerID
+		points := winner.Points + c.Bonus
+		if _, err := r.playedGameRepository.Update(ctx, &player.PlayedGameUpdate{
+			ID:     winner.ID,
+			Points: &points,
+		}); err != nil {
+			return fmt.Errorf("winner points update: %w", err)
+		}
+		bonus := player.LedgerEntry{
+			PlayerID:     winner.PlayerID,
+			PlayedGameID: winner.ID,
+			Delta:        c.Bonus,
+			Reason:       player.LedgerReasonChallengeBonus,
+		}
+		if err := r.ledgerRepository.Insert(ctx, []player.LedgerEntry{bonus}); err != nil {
+			return fmt.Errorf("winner ledger insert: %w", err)
+		}
+	}
+	if _, err := r.challengeRepository.Update(ctx, &update); err != nil {
+		return fmt.Errorf("challenge update: %w", err)
+	}
+	log.Printf("challenge %v is %v", c.ID, status)
+
+	if status != StatusFinished {
+		return nil
+	}
+	finished, err := r.challengeRepository.FindOne(ctx, c.ID)
+	if err != nil {
+		return fmt.Errorf("find for event: %w", err)
+	}
+	e := event.New(event.ChallengeFinished, finished)
+	if winner != nil {
+		e = event.NewForPlayer(event.ChallengeFinished, winner.PlayerID, finished)
+
+		played, err := r.playedGameRepository.FindOne(ctx, winner.PlayerID, winner.ID)
+		if err != nil {
+			return fmt.Errorf("find for event: %w", err)
+		}
+		if err := r.publisher.Publish(ctx, event.NewForPlayer(event.LeaderboardChanged, winner.PlayerID, played)); err != nil {
+			return fmt.Errorf("publish %v: %w", event.LeaderboardChanged, err)
+		}
+	}
+	if err := r.publisher.Publish(ctx, e); err != nil {
+		return fmt.Errorf("publish %v: %w", e.Type, err)
+	}
+	return nil
+}
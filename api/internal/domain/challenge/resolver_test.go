@@ -0,0 +1,180 @@
+package challenge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolverPublish_Winner(t *testing.T) {
+	challengeRepository := NewMockChallengeRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	publisher := NewMockEventPublisher(t)
+	resolver := NewResolver(challengeRepository, playedGameRepository, ledgerRepository, passTx(t), publisher, 0)
+
+	challengerID := uuid.NewString()
+	opponentID := uuid.NewString()
+	c := openChallenge(challengerID, opponentID)
+	c.Status = StatusActive
+	opponentGameID := 12
+	c.Participants[1] = Participant{PlayerID: opponentID, Status: ParticipantAccepted, PlayedGameID: &opponentGameID}
+
+	now := time.Now()
+	completed := player.PlayedGame{ID: 12, PlayerID: opponentID, Points: 3, Status: player.PlayedGameStatusCompleted, CompletedAt: &now}
+
+	challengeRepository.
+		On("FindOpenByPlayedGame", mock.Anything, 12).
+		Once().
+		Return(c, nil)
+	playedGameRepository.
+		On("FindOne", mock.Anything, challengerID, 11).
+		Once().
+		Return(&player.PlayedGame{ID: 11, PlayerID: challengerID, Status: player.PlayedGameStatusInProgress}, nil)
+	playedGameRepository.
+		On("FindOne", mock.Anything, opponentID, 12).
+		Return(&completed, nil)
+	playedGameRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(u *player.PlayedGameUpdate) bool {
+			return u.ID == 12 && *u.Points == 3+WinnerBonus
+		})).
+		Once().
+		Return(12, nil)
+	ledgerRepository.
+		On("Insert", mock.Anything, []player.LedgerEntry{{
+			PlayerID:     opponentID,
+			PlayedGameID: 12,
+			Delta:        WinnerBonus,
+			Reason:       player.LedgerReasonChallengeBonus,
+		}}).
+		Once().
+		Return(nil)
+	challengeRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(u *ChallengeUpdate) bool {
+			return *u.Status == StatusFinished && *u.WinnerID == opponentID && u.FinishedAt != nil
+		})).
+		Once().
+		Return(4, nil)
+	challengeRepository.
+		On("FindOne", mock.Anything, 4).
+		Once().
+		Return(c, nil)
+	publisher.
+		On("Publish", mock.Anything, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.LeaderboardChanged && e.PlayerID == opponentID
+		})).
+		Once().
+		Return(nil)
+	publisher.
+		On("Publish", mock.Anything, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.ChallengeFinished && e.PlayerID == opponentID
+		})).
+		Once().
+		Return(nil)
+
+	err := resolver.Publish(t.Context(), event.NewForPlayer(event.PlayedGameCompleted, opponentID, &completed))
+	assert.NoError(t, err)
+}
+
+func TestResolverPublish_Undecided(t *testing.T) {
+	challengeRepository := NewMockChallengeRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	resolver := NewResolver(challengeRepository, playedGameRepository, NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), 0)
+
+	challengerID := uuid.NewString()
+	c := openChallenge(challengerID, uuid.NewString())
+	dropped := player.PlayedGame{ID: 11, PlayerID: challengerID, Status: player.PlayedGameStatusInProgress}
+
+	challengeRepository.
+		On("FindOpenByPlayedGame", mock.Anything, 11).
+		Once().
+		Return(c, nil)
+	playedGameRepository.
+		On("FindOne", mock.Anything, challengerID, 11).
+		Once().
+		Return(&dropped, nil)
+	challengeRepository.AssertNotCalled(t, "Update")
+
+	err := resolver.Publish(t.Context(), event.NewForPlayer(event.PlayedGameDropped, challengerID, &dropped))
+	assert.NoError(t, err)
+}
+
+// TestResolverPublish_PlayedGameGone covers a participant whose played game was erased
+func TestResolverPublish_PlayedGameGone(t *testing.T) {
+	challengeRepository := NewMockChallengeRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	resolver := NewResolver(challengeRepository, playedGameRepository, NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), 0)
+
+	challengerID := uuid.NewString()
+	c := openChallenge(challengerID, uuid.NewString())
+	played := player.PlayedGame{ID: 11, PlayerID: challengerID, Status: player.PlayedGameStatusCompleted}
+
+	challengeRepository.
+		On("FindOpenByPlayedGame", mock.Anything, 11).
+		Once().
+		Return(c, nil)
+	playedGameRepository.
+		On("FindOne", mock.Anything, challengerID, 11).
+		Once().
+		Return(nil, player.ErrPlayedGameNotFound)
+	// the game is not played anymore, the invitation has nothing to wait for
+	challengeRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(u *ChallengeUpdate) bool {
+			return *u.Status == StatusCancelled && u.WinnerID == nil
+		})).
+		Once().
+		Return(4, nil)
+
+	err := resolver.Publish(t.Context(), event.NewForPlayer(event.PlayedGameCompleted, challengerID, &played))
+	assert.NoError(t, err)
+}
+
+func TestResolverPublish_NotChallenge(t *testing.T) {
+	challengeRepository := NewMockChallengeRepository(t)
+	resolver := NewResolver(challengeRepository, NewMockPlayedGameRepository(t), NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), 0)
+
+	played := player.PlayedGame{ID: 20, Status: player.PlayedGameStatusCompleted}
+	challengeRepository.
+		On("FindOpenByPlayedGame", mock.Anything, 20).
+		Once().
+		Return(nil, ErrChallengeNotFound)
+
+	assert.NoError(t, resolver.Publish(t.Context(), event.New(event.PlayedGameCompleted, &played)))
+	assert.NoError(t, resolver.Publish(t.Context(), event.New(event.PlayedGameAdded, &played)))
+}
+
+func TestResolverExpire(t *testing.T) {
+	challengeRepository := NewMockChallengeRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	resolver := NewResolver(challengeRepository, playedGameRepository, NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), 0)
+
+	challengerID := uuid.NewString()
+	c := openChallenge(challengerID, uuid.NewString())
+	now := time.Now()
+
+	challengeRepository.
+		On("FindDue", mock.Anything, now).
+		Once().
+		Return([]Challenge{*c}, nil)
+	challengeRepository.
+		On("ExpireInvitations", mock.Anything, 4).
+		Once().
+		Return(nil)
+	playedGameRepository.
+		On("FindOne", mock.Anything, challengerID, 11).
+		Once().
+		Return(&player.PlayedGame{ID: 11, PlayerID: challengerID, Status: player.PlayedGameStatusInProgress}, nil)
+	challengeRepository.
+		On("Update", mock.Anything, mock.MatchedBy(func(u *ChallengeUpdate) bool {
+			return *u.Status == StatusExpired && u.WinnerID == nil
+		})).
+		Once().
+		Return(4, nil)
+
+	assert.NoError(t, resolver.expire(t.Context(), now))
+}
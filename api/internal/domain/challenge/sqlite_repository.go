@@ -0,0 +1,248 @@
+package challenge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+// FindAll returns challenges the player participates in, only open ones unless all is set
+func (r *SQLiteRepository) FindAll(ctx context.Context, playerID string, all bool) ([]Challenge, error) {
+	sqlBuild := sq.Select(challengeColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableChallenge).
+		Where(sq.Expr(
+			"id IN (SELECT challenge_id FROM "+TableParticipant+" WHERE player_id = ?)",
+			playerID,
+		)).
+		OrderBy("created_at DESC", "id DESC")
+	if !all {
+		sqlBuild = sqlBuild.Where(sq.Eq{"status": openStatus})
+	}
+	return r.find(ctx, sqlBuild)
+}
+
+func (r *SQLiteRepository) FindOne(ctx context.Context, id int) (*Challenge, error) {
+	sqlBuild := sq.Select(challengeColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableChallenge).
+		Where(sq.Eq{"id": id})
+	return r.findOne(ctx, sqlBuild)
+}
+
+// FindOpenByPlayedGame returns the open challenge the played game is linked to
+func (r *SQLiteRepository) FindOpenByPlayedGame(ctx context.Context, playedGameID int) (*Challenge, error) {
+	sqlBuild := sq.Select(challengeColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableChallenge).
+		Where(sq.Expr(
+			"id IN (SELECT challenge_id FROM "+TableParticipant+" WHERE played_game_id = ?)",
+			playedGameID,
+		)).
+		Where(sq.Eq{"status": openStatus})
+	return r.findOne(ctx, sqlBuild)
+}
+
+// FindDue returns open challenges past their expiration which still have invited players
+func (r *SQLiteRepository) FindDue(ctx context.Context, now time.Time) ([]Challenge, error) {
+	sqlBuild := sq.Select(challengeColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableChallenge).
+		Where(sq.Eq{"status": openStatus}).
+		Where(sq.LtOrEq{"expires_at": db.SQLiteTime(now)}).
+		Where(sq.Expr(
+			"id IN (SELECT challenge_id FROM "+TableParticipant+" WHERE status = ?)",
+			ParticipantInvited,
+		)).
+		OrderBy("id")
+	return r.find(ctx, sqlBuild)
+}
+
+// Insert inserts the challenge with its participants
+func (r *SQLiteRepository) Insert(ctx context.Context, c *Challenge) (int, error) {
+	var id int
+	conn := db.SQLiteConn(ctx, r.db)
+
+	query, args, err := sq.Insert(TableChallenge).
+		PlaceholderFormat(sq.Question).
+		Columns("challenger_id", "game_id", "mode", "bonus", "status", "expires_at").
+		Values(c.ChallengerID, c.GameID, c.Mode, c.Bonus, c.Status, db.SQLiteTime(c.ExpiresAt)).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return id, err
+	}
+	if err := conn.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return id, err
+	}
+
+	sqlBuild := sq.Insert(TableParticipant).
+		PlaceholderFormat(sq.Question).
+		Columns(participantColumns)
+	for _, p := range c.Participants {
+		sqlBuild = sqlBuild.Values(id, p.PlayerID, p.Status, p.PlayedGameID)
+	}
+	query, args, err = sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+	_, err = conn.ExecContext(ctx, query, args...)
+	return id, err
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, c *ChallengeUpdate) (int, error) {
+	var id int
+
+	sqlBuild := sq.Update(TableChallenge).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"id": c.ID}).
+		Suffix("RETURNING id")
+	if c.Status != nil {
+		sqlBuild = sqlBuild.Set("status", *c.Status)
+	}
+	if c.WinnerID != nil {
+		sqlBuild = sqlBuild.Set("winner_id", *c.WinnerID)
+	}
+	if c.FinishedAt != nil {
+		sqlBuild = sqlBuild.Set("finished_at", db.SQLiteTime(*c.FinishedAt))
+	}
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+	if err := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return id, ErrChallengeNotFound
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepository) UpdateParticipant(ctx context.Context, challengeID int, p *Participant) error {
+	query, args, err := sq.Update(TableParticipant).
+		PlaceholderFormat(sq.Question).
+		Set("status", p.Status).
+		Set("played_game_id", p.PlayedGameID).
+		Where(sq.Eq{"challenge_id": challengeID, "player_id": p.PlayerID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrParticipantNotFound
+	}
+	return nil
+}
+
+// ExpireInvitations expires invitations of the challenge nobody answered
+func (r *SQLiteRepository) ExpireInvitations(ctx context.Context, challengeID int) error {
+	query, args, err := sq.Update(TableParticipant).
+		PlaceholderFormat(sq.Question).
+		Set("status", ParticipantExpired).
+		Where(sq.Eq{"challenge_id": challengeID, "status": ParticipantInvited}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *SQLiteRepository) findOne(ctx context.Context, sqlBuild sq.SelectBuilder) (*Challenge, error) {
+	challenges, err := r.find(ctx, sqlBuild.Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(challenges) == 0 {
+		return nil, ErrChallengeNotFound
+	}
+	return &challenges[0], nil
+}
+
+// find selects challenges and loads their participants
+func (r *SQLiteRepository) find(ctx context.Context, sqlBuild sq.SelectBuilder) ([]Challenge, error) {
+	conn := db.SQLiteConn(ctx, r.db)
+	out := make([]Challenge, 0)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int)
+	for rows.Next() {
+		c, err := challengeFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		c.Participants = make([]Participant, 0)
+		index[c.ID] = len(out)
+		out = append(out, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	ids := make([]int, 0, len(out))
+	for _, c := range out {
+		ids = append(ids, c.ID)
+	}
+	query, args, err = sq.Select(participantColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableParticipant).
+		Where(sq.Eq{"challenge_id": ids}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	pRows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer pRows.Close()
+
+	for pRows.Next() {
+		var (
+			challengeID int
+			p           Participant
+		)
+		if err := pRows.Scan(&challengeID, &p.PlayerID, &p.Status, &p.PlayedGameID); err != nil {
+			return nil, err
+		}
+		c := &out[index[challengeID]]
+		c.Participants = append(c.Participants, p)
+	}
+	return out, pRows.Err()
+}
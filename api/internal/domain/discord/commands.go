@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	DefaultAPIURL = "https://discord.com/api/v10"
+)
+
+const (
+	CommandRoll        = "roll"
+	CommandDone        = "done"
+	CommandDrop        = "drop"
+	CommandReroll      = "reroll"
+	CommandLeaderboard = "leaderboard"
+	CommandLink        = "link"
+
+	optionRating  = "rating"
+	optionBacklog = "backlog"
+)
+
+type OptionType int
+
+const (
+	OptionTypeInteger OptionType = 4
+	OptionTypeBoolean OptionType = 5
+)
+
+// Command is a slash command definition registered in discord
+type Command struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Options     []CommandOptionDef `json:"options,omitempty"`
+}
+
+type CommandOptionDef struct {
+	Type        OptionType `json:"type"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Required    bool       `json:"required,omitempty"`
+	MinValue    *int       `json:"min_value,omitempty"`
+	MaxValue    *int       `json:"max_value,omitempty"`
+}
+
+var (
+	minRating = 1
+	maxRating = 100
+
+	Commands = []Command{
+		{
+			Name:        CommandRoll,
+			Description: "Roll a random game you have not played yet",
+			Options: []CommandOptionDef{{
+				Type:        OptionTypeBoolean,
+				Name:        optionBacklog,
+				Description: "Roll one of the games of your backlog",
+			}},
+		},
+		{
+			Name:        CommandDone,
+			Description: "Complete your current game",
+			Options: []CommandOptionDef{{
+				Type:        OptionTypeInteger,
+				Name:        optionRating,
+				Description: "Your rating of the game",
+				MinValue:    &minRating,
+				MaxValue:    &maxRating,
+			}},
+		},
+		{Name: CommandDrop, Description: "Drop your current game"},
+		{Name: CommandReroll, Description: "Reroll your current game"},
+		{Name: CommandLeaderboard, Description: "Show the leaderboard"},
+		{Name: CommandLink, Description: "Link your discord account to playtrack"},
+	}
+)
+
+// RegisterCommands overwrites the global commands of the application
+func RegisterCommands(ctx context.Context, client *http.Client, apiURL, appID, botToken string) error {
+	body, err := json.Marshal(Commands)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/applications/%s/commands", apiURL, appID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+botToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("register commands: status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
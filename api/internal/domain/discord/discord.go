@@ -0,0 +1,219 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/auth"
+)
+
+type InteractionType int
+
+const (
+	InteractionTypePing               InteractionType = 1
+	InteractionTypeApplicationCommand InteractionType = 2
+)
+
+type ResponseType int
+
+const (
+	ResponseTypePong                     ResponseType = 1
+	ResponseTypeChannelMessageWithSource ResponseType = 4
+)
+
+const (
+	// MessageFlagEphemeral shows the message only to the user who invoked the command
+	MessageFlagEphemeral = 1 << 6
+)
+
+const (
+	HeaderSignature = "X-Signature-Ed25519"
+	HeaderTimestamp = "X-Signature-Timestamp"
+)
+
+const (
+	colorInfo    = 0x5865f2
+	colorSuccess = 0x22c55e
+	colorWarning = 0xf97316
+	colorDanger  = 0xef4444
+)
+
+var (
+	ErrLinkNotFound  = errors.New("link code is not found, expired or already used")
+	ErrInvalidKey    = errors.New("public key must be a hex encoded ed25519 key")
+	ErrInvalidOption = errors.New("option has invalid value")
+)
+
+// Interaction is a request sent by discord when a user invokes a command
+type Interaction struct {
+	ID     string          `json:"id"`
+	Type   InteractionType `json:"type"`
+	Data   *CommandData    `json:"data"`
+	Member *Member         `json:"member"`
+	User   *User           `json:"user"`
+}
+
+// Invoker returns the user who invoked the command, in guilds it is sent as a member
+func (i *Interaction) Invoker() *User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+type CommandData struct {
+	Name    string          `json:"name"`
+	Options []CommandOption `json:"options"`
+}
+
+// IntOption returns the value of an integer option, ok is false when the option is not set
+func (d *CommandData) IntOption(name string) (int, bool, error) {
+	for _, o := range d.Options {
+		if o.Name != name {
+			continue
+		}
+		var v int
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return 0, false, ErrInvalidOption
+		}
+		return v, true, nil
+	}
+	return 0, false, nil
+}
+
+// BoolOption returns the value of a boolean option, it is false when the option is not set
+func (d *CommandData) BoolOption(name string) (bool, error) {
+	for _, o := range d.Options {
+		if o.Name != name {
+			continue
+		}
+		var v bool
+		if err := json.Unmarshal(o.Value, &v); err != nil {
+			return false, ErrInvalidOption
+		}
+		return v, nil
+	}
+	return false, nil
+}
+
+type CommandOption struct {
+	Name  string          `json:"name"`
+	Type  OptionType      `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type Member struct {
+	User *User `json:"user"`
+}
+
+type User struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+}
+
+// Name returns the display name of the user
+func (u *User) Name() string {
+	if u.GlobalName != "" {
+		return u.GlobalName
+	}
+	return u.Username
+}
+
+type InteractionResponse struct {
+	Type ResponseType  `json:"type"`
+	Data *ResponseData `json:"data,omitempty"`
+}
+
+type ResponseData struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+	Flags   int     `json:"flags,omitempty"`
+}
+
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+}
+
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Link is a pending link of a discord account, the player confirms it in the web app
+type Link struct {
+	ID              int
+	DiscordUserID   string
+	DiscordUsername string
+	Hash            string
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+}
+
+// NewLink generates a link code and returns it with the plain value shown to the user
+func NewLink(user *User, ttl time.Duration) (*Link, string) {
+	plain := rand.Text()
+	return &Link{
+		DiscordUserID:   user.ID,
+		DiscordUsername: user.Name(),
+		Hash:            auth.HashToken(plain),
+		ExpiresAt:       time.Now().Add(ttl),
+	}, plain
+}
+
+// ParsePublicKey parses the hex encoded public key of the discord application
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// Verify checks the signature discord sends with every interaction
+func Verify(publicKey ed25519.PublicKey, signature, timestamp string, body []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+
+	msg := make([]byte, 0, len(timestamp)+len(body))
+	msg = append(msg, timestamp...)
+	msg = append(msg, body...)
+	return ed25519.Verify(publicKey, msg, sig)
+}
+
+func pong() *InteractionResponse {
+	return &InteractionResponse{Type: ResponseTypePong}
+}
+
+func message(embeds ...Embed) *InteractionResponse {
+	return &InteractionResponse{
+		Type: ResponseTypeChannelMessageWithSource,
+		Data: &ResponseData{Embeds: embeds},
+	}
+}
+
+// ephemeral answers only to the user, it is used for errors and private links
+func ephemeral(format string, args ...any) *InteractionResponse {
+	return &InteractionResponse{
+		Type: ResponseTypeChannelMessageWithSource,
+		Data: &ResponseData{
+			Content: fmt.Sprintf(format, args...),
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
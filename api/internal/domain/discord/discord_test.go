@@ -0,0 +1,96 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	body := []byte(`{"type":1}`)
+	sig := hex.EncodeToString(ed25519.Sign(privateKey, append([]byte("1700000000"), body...)))
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp string
+		body      []byte
+		want      bool
+	}{
+		{name: "valid", signature: sig, timestamp: "1700000000", body: body, want: true},
+		{name: "other timestamp", signature: sig, timestamp: "1700000001", body: body},
+		{name: "other body", signature: sig, timestamp: "1700000000", body: []byte(`{"type":2}`)},
+		{name: "not hex", signature: "zz", timestamp: "1700000000", body: body},
+		{name: "empty", timestamp: "1700000000", body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Verify(publicKey, tt.signature, tt.timestamp, tt.body))
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	parsed, err := ParsePublicKey(hex.EncodeToString(publicKey))
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, parsed)
+
+	_, err = ParsePublicKey("abcd")
+	assert.IsError(t, err, ErrInvalidKey)
+}
+
+func TestIntOption(t *testing.T) {
+	data := CommandData{Options: []CommandOption{
+		{Name: "rating", Type: OptionTypeInteger, Value: json.RawMessage("87")},
+		{Name: "broken", Type: OptionTypeInteger, Value: json.RawMessage(`"x"`)},
+	}}
+
+	v, ok, err := data.IntOption("rating")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 87, v)
+
+	_, ok, err = data.IntOption("missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = data.IntOption("broken")
+	assert.IsError(t, err, ErrInvalidOption)
+}
+
+func TestRegisterCommands(t *testing.T) {
+	var got []Command
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/applications/99/commands", r.URL.Path)
+		assert.Equal(t, "Bot secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	err := RegisterCommands(t.Context(), srv.Client(), srv.URL, "99", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, len(Commands), len(got))
+}
+
+func TestRegisterCommands_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := RegisterCommands(t.Context(), srv.Client(), srv.URL, "99", "secret")
+	assert.Error(t, err)
+}
@@ -0,0 +1,425 @@
+package discord
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+)
+
+const (
+	defaultLinkTTL = 15 * time.Minute
+
+	leaderboardSize = 10
+)
+
+// PlayedGameHandler is the domain logic of played games shared with the http api
+type PlayedGameHandler interface {
+	CreatePlayedGame(ctx context.Context, i *player.RequestCreatePlayedGame) (*domain.ResponseID[int], error)
+	UpdatePlayedGame(ctx context.Context, i *player.RequestUpdatePlayedGame) (*domain.ResponseID[int], error)
+}
+
+type LedgerRepository interface {
+	Leaderboard(ctx context.Context) ([]player.LeaderboardPlayer, error)
+}
+
+type PlayedGameRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]player.PlayedGame, error)
+}
+
+type GameRepository interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
+	FindOne(ctx context.Context, id int) (*game.Game, error)
+}
+
+type BacklogRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]backlog.Item, error)
+}
+
+type IdentityRepository interface {
+	FindOne(ctx context.Context, provider, subject string) (*auth.Identity, error)
+	Insert(ctx context.Context, identity *auth.Identity) (int, error)
+}
+
+type LinkRepository interface {
+	Insert(ctx context.Context, link *Link) (int, error)
+	Consume(ctx context.Context, hash string) (*Link, error)
+}
+
+// PlayerCache finds players the same way the auth middleware does
+type PlayerCache interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+}
+
+type Options struct {
+	PublicKey ed25519.PublicKey
+	// AppURL is a public url of the web app where links are confirmed
+	AppURL  string
+	LinkTTL time.Duration
+}
+
+type Handler struct {
+	playedGames          PlayedGameHandler
+	ledgerRepository     LedgerRepository
+	playedGameRepository PlayedGameRepository
+	gameRepository       GameRepository
+	backlogRepository    BacklogRepository
+	identityRepository   IdentityRepository
+	linkRepository       LinkRepository
+	playerCache          PlayerCache
+	opts                 Options
+}
+
+func NewHandler(
+	playedGames PlayedGameHandler,
+	ledgerRepository LedgerRepository,
+	playedGameRepository PlayedGameRepository,
+	gameRepository GameRepository,
+	backlogRepository BacklogRepository,
+	identityRepository IdentityRepository,
+	linkRepository LinkRepository,
+	playerCache PlayerCache,
+	opts Options,
+) *Handler {
+	if opts.LinkTTL == 0 {
+		opts.LinkTTL = defaultLinkTTL
+	}
+	return &Handler{
+		playedGames:          playedGames,
+		ledgerRepository:     ledgerRepository,
+		playedGameRepository: playedGameRepository,
+		gameRepository:       gameRepository,
+		backlogRepository:    backlogRepository,
+		identityRepository:   identityRepository,
+		linkRepository:       linkRepository,
+		playerCache:          playerCache,
+		opts:                 opts,
+	}
+}
+
+// Register registers operations of authorized players
+func (h *Handler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "discord-confirm-link",
+		Method:      http.MethodPost,
+		Path:        "/discord/link",
+		Summary:     "link discord account",
+		Description: "link the discord account which requested the code with /link to the player",
+		Tags:        []string{"discord"},
+	}, h.ConfirmLink)
+}
+
+// RegisterInteractions registers the endpoint called by discord, requests are authenticated by signature
+func (h *Handler) RegisterInteractions(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "discord-interactions",
+		Method:      http.MethodPost,
+		Path:        "/discord/interactions",
+		Summary:     "discord interactions",
+		Description: "handle discord slash commands",
+		Tags:        []string{"discord"},
+	}, h.Interactions)
+}
+
+func (h *Handler) Interactions(ctx context.Context, i *RequestInteraction) (*ResponseInteraction, error) {
+	if !Verify(h.opts.PublicKey, i.Signature, i.Timestamp, i.RawBody) {
+		return nil, huma.Error401Unauthorized("invalid request signature")
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(i.RawBody, &interaction); err != nil {
+		log.Printf("discord interaction decode: %v", err)
+		return nil, huma.Error400BadRequest("invalid interaction", err)
+	}
+
+	resp := ResponseInteraction{}
+	switch interaction.Type {
+	case InteractionTypePing:
+		resp.Body = pong()
+	case InteractionTypeApplicationCommand:
+		resp.Body = h.command(ctx, &interaction)
+	default:
+		return nil, huma.Error400BadRequest(fmt.Sprintf("unsupported interaction type %d", interaction.Type))
+	}
+	return &resp, nil
+}
+
+func (h *Handler) ConfirmLink(ctx context.Context, i *RequestConfirmLink) (*domain.ResponseMessage, error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	link, err := h.linkRepository.Consume(ctx, auth.HashToken(i.Body.Code))
+	if err != nil {
+		log.Printf("discord link consume: %v", err)
+		if errors.Is(err, ErrLinkNotFound) {
+			return nil, huma.Error400BadRequest("link code is invalid or expired")
+		}
+		return nil, huma.Error500InternalServerError("link", err)
+	}
+
+	_, err = h.identityRepository.Insert(ctx, &auth.Identity{
+		PlayerID: ctxPlayer.ID,
+		Provider: oauth.KindDiscord,
+		Subject:  link.DiscordUserID,
+	})
+	if err != nil {
+		log.Printf("discord link identity insert: %v", err)
+		if errors.Is(err, auth.ErrIdentityLinked) {
+			return nil, huma.Error409Conflict("discord account is already linked")
+		}
+		return nil, huma.Error500InternalServerError("link", err)
+	}
+
+	log.Printf("discord user %v linked to player %v", link.DiscordUserID, ctxPlayer.ID)
+	resp := domain.ResponseMessage{}
+	resp.Body.Message = fmt.Sprintf("discord account %s is linked", link.DiscordUsername)
+	return &resp, nil
+}
+
+// command runs a slash command, failures are answered to the user instead of returning an error
+// so discord shows the reason
+func (h *Handler) command(ctx context.Context, interaction *Interaction) *InteractionResponse {
+	user := interaction.Invoker()
+	if user == nil || interaction.Data == nil {
+		return ephemeral("Could not recognize the command.")
+	}
+
+	switch interaction.Data.Name {
+	case CommandLink:
+		return h.link(ctx, user)
+	case CommandLeaderboard:
+		return h.leaderboard(ctx)
+	}
+
+	identity, err := h.identityRepository.FindOne(ctx, oauth.KindDiscord, user.ID)
+	if err != nil {
+		if errors.Is(err, auth.ErrIdentityNotFound) {
+			return ephemeral("Your discord account is not linked, use `/%s` first.", CommandLink)
+		}
+		log.Printf("discord identity find %v: %v", user.ID, err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	// commands are checked like http requests of the player
+	found, err := h.playerCache.FindOne(ctx, identity.PlayerID)
+	if err != nil {
+		log.Printf("discord player find %v: %v", identity.PlayerID, err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+	if found.Suspended(time.Now()) {
+		return ephemeral("Your playtrack account is suspended.")
+	}
+	if found.MustChangePassword {
+		return ephemeral("You must change your password in playtrack first.")
+	}
+	ctx = ctxutil.SetPlayer(ctx, ctxutil.CtxPlayer{ID: found.ID, IsAdmin: found.IsAdmin})
+
+	switch interaction.Data.Name {
+	case CommandRoll:
+		fromBacklog, err := interaction.Data.BoolOption(optionBacklog)
+		if err != nil {
+			return ephemeral("Backlog must be true or false.")
+		}
+		return h.roll(ctx, user, identity.PlayerID, fromBacklog)
+	case CommandDone:
+		rating, ok, err := interaction.Data.IntOption(optionRating)
+		if err != nil {
+			return ephemeral("Rating must be a number.")
+		}
+		var ratingPtr *int
+		if ok {
+			ratingPtr = &rating
+		}
+		return h.finish(ctx, user, identity.PlayerID, player.PlayedGameStatusCompleted, ratingPtr)
+	case CommandDrop:
+		return h.finish(ctx, user, identity.PlayerID, player.PlayedGameStatusDropped, nil)
+	case CommandReroll:
+		return h.finish(ctx, user, identity.PlayerID, player.PlayedGameStatusRerolled, nil)
+	}
+	return ephemeral("Unknown command `/%s`.", interaction.Data.Name)
+}
+
+func (h *Handler) link(ctx context.Context, user *User) *InteractionResponse {
+	_, err := h.identityRepository.FindOne(ctx, oauth.KindDiscord, user.ID)
+	if err == nil {
+		return ephemeral("Your discord account is already linked.")
+	}
+	if !errors.Is(err, auth.ErrIdentityNotFound) {
+		log.Printf("discord identity find %v: %v", user.ID, err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	link, code := NewLink(user, h.opts.LinkTTL)
+	if _, err := h.linkRepository.Insert(ctx, link); err != nil {
+		log.Printf("discord link insert: %v", err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	linkURL := strings.TrimSuffix(h.opts.AppURL, "/") + "/discord/link?code=" + url.QueryEscape(code)
+	return ephemeral(
+		"Open %s while signed in to playtrack to link your account. The link expires in %v.",
+		linkURL,
+		h.opts.LinkTTL,
+	)
+}
+
+// roll picks a random game the player has not played, with fromBacklog it picks
+// one of the backlog games whether they were played or not
+func (h *Handler) roll(ctx context.Context, user *User, playerID string, fromBacklog bool) *InteractionResponse {
+	games, err := h.gameRepository.FindAll(ctx)
+	if err != nil {
+		log.Printf("discord roll games find: %v", err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	var candidates []game.Game
+	if fromBacklog {
+		items, err := h.backlogRepository.FindAll(ctx, playerID)
+		if err != nil {
+			log.Printf("discord roll backlog find %v: %v", playerID, err)
+			return ephemeral("Something went wrong, try again later.")
+		}
+		candidates = slices.DeleteFunc(games, func(g game.Game) bool {
+			return !slices.ContainsFunc(items, func(i backlog.Item) bool { return i.GameID == g.ID })
+		})
+		if len(candidates) == 0 {
+			return ephemeral("Your backlog is empty, add games to it in playtrack first.")
+		}
+	} else {
+		played, err := h.playedGameRepository.FindAll(ctx, playerID)
+		if err != nil {
+			log.Printf("discord roll played games find %v: %v", playerID, err)
+			return ephemeral("Something went wrong, try again later.")
+		}
+		candidates = slices.DeleteFunc(games, func(g game.Game) bool {
+			return slices.ContainsFunc(played, func(p player.PlayedGame) bool { return p.GameID == g.ID })
+		})
+		if len(candidates) == 0 {
+			return ephemeral("You have played every game of the catalog, add more games first.")
+		}
+	}
+	rolled := candidates[rand.IntN(len(candidates))]
+
+	req := player.RequestCreatePlayedGame{PlayerID: playerID}
+	req.Body.GameID = rolled.ID
+	if _, err := h.playedGames.CreatePlayedGame(ctx, &req); err != nil {
+		return ephemeral("Could not roll a game: %s", errorMessage(err))
+	}
+
+	fields := []EmbedField{
+		{Name: "Points", Value: fmt.Sprint(rolled.Points), Inline: true},
+		{Name: "Hours to beat", Value: fmt.Sprint(rolled.HoursToBeat), Inline: true},
+	}
+	embed := Embed{
+		Title:       fmt.Sprintf("🎲 %s rolled %s", user.Name(), rolled.Title),
+		Description: "Good luck!",
+		Color:       colorInfo,
+		Fields:      fields,
+	}
+	if rolled.URL != nil {
+		embed.URL = *rolled.URL
+	}
+	return message(embed)
+}
+
+// finish moves the current game of the player to a terminated status
+func (h *Handler) finish(
+	ctx context.Context,
+	user *User,
+	playerID string,
+	status player.PlayedGameStatus,
+	rating *int,
+) *InteractionResponse {
+	played, err := h.playedGameRepository.FindAll(ctx, playerID)
+	if err != nil {
+		log.Printf("discord played games find %v: %v", playerID, err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	idx := slices.IndexFunc(played, func(p player.PlayedGame) bool { return !p.StatusTerminated() })
+	if idx < 0 {
+		return ephemeral("You have no game in progress, use `/%s` to get one.", CommandRoll)
+	}
+	current := played[idx]
+
+	req := player.RequestUpdatePlayedGame{PlayerID: playerID, GameID: current.ID}
+	req.Body.Status = &status
+	req.Body.Rating = rating
+	if _, err := h.playedGames.UpdatePlayedGame(ctx, &req); err != nil {
+		return ephemeral("Could not update the game: %s", errorMessage(err))
+	}
+
+	title := fmt.Sprintf("game #%d", current.GameID)
+	if g, err := h.gameRepository.FindOne(ctx, current.GameID); err == nil {
+		title = g.Title
+	}
+
+	embed := Embed{Fields: []EmbedField{}}
+	switch status {
+	case player.PlayedGameStatusCompleted:
+		embed.Title = fmt.Sprintf("✅ %s completed %s", user.Name(), title)
+		embed.Color = colorSuccess
+		if rating != nil {
+			embed.Fields = append(embed.Fields, EmbedField{Name: "Rating", Value: fmt.Sprintf("%d/%d", *rating, maxRating), Inline: true})
+		}
+	case player.PlayedGameStatusDropped:
+		embed.Title = fmt.Sprintf("💀 %s dropped %s", user.Name(), title)
+		embed.Color = colorDanger
+	case player.PlayedGameStatusRerolled:
+		embed.Title = fmt.Sprintf("🔁 %s rerolled %s", user.Name(), title)
+		embed.Description = fmt.Sprintf("Use `/%s` to get the next game.", CommandRoll)
+		embed.Color = colorWarning
+	}
+	return message(embed)
+}
+
+func (h *Handler) leaderboard(ctx context.Context) *InteractionResponse {
+	rows, err := h.ledgerRepository.Leaderboard(ctx)
+	if err != nil {
+		log.Printf("discord leaderboard find: %v", err)
+		return ephemeral("Something went wrong, try again later.")
+	}
+
+	var sb strings.Builder
+	for i, row := range rows[:min(len(rows), leaderboardSize)] {
+		fmt.Fprintf(&sb, "**%d. %s** — %d pts (✅ %d, 💀 %d)\n", i+1, row.Username, row.Total, row.Completed, row.Dropped)
+	}
+	if len(rows) == 0 {
+		sb.WriteString("No players yet.")
+	}
+
+	return message(Embed{
+		Title:       "🏆 Leaderboard",
+		Description: sb.String(),
+		Color:       colorInfo,
+	})
+}
+
+// errorMessage returns the message of a domain error which is safe to show to the user
+func errorMessage(err error) string {
+	var statusErr huma.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Error()
+	}
+	log.Printf("discord command: %v", err)
+	return "something went wrong"
+}
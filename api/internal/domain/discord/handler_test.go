@@ -0,0 +1,413 @@
+package discord
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	linkedUserID = "5001"
+	guestUserID  = "5002"
+)
+
+type testHandler struct {
+	*Handler
+	privateKey           ed25519.PrivateKey
+	playedGames          *MockPlayedGameHandler
+	ledgerRepository     *MockLedgerRepository
+	playedGameRepository *MockPlayedGameRepository
+	gameRepository       *MockGameRepository
+	backlogRepository    *MockBacklogRepository
+	identityRepository   *MockIdentityRepository
+	linkRepository       *MockLinkRepository
+	playerCache          *MockPlayerCache
+}
+
+func newTestHandler(t *testing.T) *testHandler {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	h := testHandler{
+		privateKey:           privateKey,
+		playedGames:          NewMockPlayedGameHandler(t),
+		ledgerRepository:     NewMockLedgerRepository(t),
+		playedGameRepository: NewMockPlayedGameRepository(t),
+		gameRepository:       NewMockGameRepository(t),
+		backlogRepository:    NewMockBacklogRepository(t),
+		identityRepository:   NewMockIdentityRepository(t),
+		linkRepository:       NewMockLinkRepository(t),
+		playerCache:          NewMockPlayerCache(t),
+	}
+	h.Handler = NewHandler(
+		h.playedGames,
+		h.ledgerRepository,
+		h.playedGameRepository,
+		h.gameRepository,
+		h.backlogRepository,
+		h.identityRepository,
+		h.linkRepository,
+		h.playerCache,
+		Options{PublicKey: publicKey, AppURL: "https://playtrack.example/"},
+	)
+	return &h
+}
+
+// signedRequest loads an interaction fixture and signs it the way discord does
+func (h *testHandler) signedRequest(t *testing.T, fixture string) *RequestInteraction {
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := ed25519.Sign(h.privateKey, append([]byte(timestamp), body...))
+
+	return &RequestInteraction{
+		Signature: hex.EncodeToString(sig),
+		Timestamp: timestamp,
+		RawBody:   body,
+	}
+}
+
+func (h *testHandler) linked(playerID string) {
+	h.linkedPlayer(&player.Player{ID: playerID})
+}
+
+func (h *testHandler) linkedPlayer(p *player.Player) {
+	h.identityRepository.
+		On("FindOne", mock.Anything, oauth.KindDiscord, linkedUserID).
+		Once().
+		Return(&auth.Identity{PlayerID: p.ID, Provider: oauth.KindDiscord, Subject: linkedUserID}, nil)
+
+	h.playerCache.
+		On("FindOne", mock.Anything, p.ID).
+		Once().
+		Return(p, nil)
+}
+
+func TestInteractions_Ping(t *testing.T) {
+	h := newTestHandler(t)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "ping.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseTypePong, resp.Body.Type)
+}
+
+func TestInteractions_InvalidSignature(t *testing.T) {
+	h := newTestHandler(t)
+
+	req := h.signedRequest(t, "ping.json")
+	req.Timestamp += "1"
+
+	_, err := h.Interactions(t.Context(), req)
+	assert.Error(t, err)
+
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, 401, statusErr.GetStatus())
+}
+
+func TestInteractions_Roll(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{{ID: 1, GameID: 1, Status: player.PlayedGameStatusCompleted}}, nil)
+
+	h.gameRepository.
+		On("FindAll", mock.Anything).
+		Once().
+		Return([]game.Game{{ID: 1, Title: "Played"}, {ID: 2, Title: "Hollow Knight", Points: 4, HoursToBeat: 27}}, nil)
+
+	h.playedGames.
+		On("CreatePlayedGame", mock.MatchedBy(func(ctx context.Context) bool {
+			p, ok := ctxutil.GetPlayer(ctx)
+			return ok && p.ID == playerID
+		}), mock.MatchedBy(func(r *player.RequestCreatePlayedGame) bool {
+			return r.PlayerID == playerID && r.Body.GameID == 2
+		})).
+		Once().
+		Return(&domain.ResponseID[int]{}, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "roll.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseTypeChannelMessageWithSource, resp.Body.Type)
+	assert.Equal(t, 1, len(resp.Body.Data.Embeds))
+	assert.Equal(t, "🎲 Lardira rolled Hollow Knight", resp.Body.Data.Embeds[0].Title)
+	assert.Equal(t, 0, resp.Body.Data.Flags)
+}
+
+func TestInteractions_RollBacklog(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.backlogRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]backlog.Item{{ID: 1, PlayerID: playerID, GameID: 1}}, nil)
+
+	h.gameRepository.
+		On("FindAll", mock.Anything).
+		Once().
+		Return([]game.Game{{ID: 1, Title: "Celeste", Points: 2, HoursToBeat: 10}, {ID: 2, Title: "Hollow Knight"}}, nil)
+
+	h.playedGames.
+		On("CreatePlayedGame", mock.Anything, mock.MatchedBy(func(r *player.RequestCreatePlayedGame) bool {
+			return r.PlayerID == playerID && r.Body.GameID == 1
+		})).
+		Once().
+		Return(&domain.ResponseID[int]{}, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "roll_backlog.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "🎲 Lardira rolled Celeste", resp.Body.Data.Embeds[0].Title)
+}
+
+func TestInteractions_RollEmptyBacklog(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.backlogRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]backlog.Item{}, nil)
+
+	h.gameRepository.
+		On("FindAll", mock.Anything).
+		Once().
+		Return([]game.Game{{ID: 1, Title: "Celeste"}}, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "roll_backlog.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+}
+
+func TestInteractions_Done(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{
+			{ID: 1, GameID: 1, Status: player.PlayedGameStatusDropped},
+			{ID: 2, GameID: 3, Status: player.PlayedGameStatusInProgress},
+		}, nil)
+
+	h.playedGames.
+		On("UpdatePlayedGame", mock.Anything, mock.MatchedBy(func(r *player.RequestUpdatePlayedGame) bool {
+			return r.PlayerID == playerID &&
+				r.GameID == 2 &&
+				*r.Body.Status == player.PlayedGameStatusCompleted &&
+				*r.Body.Rating == 87
+		})).
+		Once().
+		Return(&domain.ResponseID[int]{}, nil)
+
+	h.gameRepository.
+		On("FindOne", mock.Anything, 3).
+		Once().
+		Return(&game.Game{ID: 3, Title: "Celeste"}, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "done.json"))
+	assert.NoError(t, err)
+	embed := resp.Body.Data.Embeds[0]
+	assert.Equal(t, "✅ Lardira completed Celeste", embed.Title)
+	assert.Equal(t, []EmbedField{{Name: "Rating", Value: "87/100", Inline: true}}, embed.Fields)
+}
+
+func TestInteractions_Drop_NoCurrentGame(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{{ID: 1, GameID: 1, Status: player.PlayedGameStatusCompleted}}, nil)
+
+	h.playedGames.AssertNotCalled(t, "UpdatePlayedGame")
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "drop.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+	assert.Contains(t, resp.Body.Data.Content, "no game in progress")
+}
+
+func TestInteractions_Drop_DomainError(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	h.linked(playerID)
+
+	h.playedGameRepository.
+		On("FindAll", mock.Anything, playerID).
+		Once().
+		Return([]player.PlayedGame{{ID: 1, GameID: 1, Status: player.PlayedGameStatusInProgress}}, nil)
+
+	h.playedGames.
+		On("UpdatePlayedGame", mock.Anything, mock.Anything).
+		Once().
+		Return(nil, huma.Error400BadRequest("game cannot be dropped"))
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "drop.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+	assert.Equal(t, "Could not update the game: game cannot be dropped", resp.Body.Data.Content)
+}
+
+func TestInteractions_NotLinked(t *testing.T) {
+	h := newTestHandler(t)
+
+	h.identityRepository.
+		On("FindOne", mock.Anything, oauth.KindDiscord, linkedUserID).
+		Once().
+		Return(nil, auth.ErrIdentityNotFound)
+
+	h.playedGameRepository.AssertNotCalled(t, "FindAll")
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "roll.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+	assert.Contains(t, resp.Body.Data.Content, "/link")
+}
+
+func TestInteractions_Suspended(t *testing.T) {
+	h := newTestHandler(t)
+	h.linkedPlayer(&player.Player{
+		ID:         uuid.NewString(),
+		Suspension: &player.Suspension{Kind: player.SuspensionBanned, At: time.Now().Add(-time.Hour)},
+	})
+
+	h.playedGameRepository.AssertNotCalled(t, "FindAll")
+	h.playedGames.AssertNotCalled(t, "UpdatePlayedGame")
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "drop.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+	assert.Equal(t, "Your playtrack account is suspended.", resp.Body.Data.Content)
+}
+
+func TestInteractions_MustChangePassword(t *testing.T) {
+	h := newTestHandler(t)
+	h.linkedPlayer(&player.Player{ID: uuid.NewString(), MustChangePassword: true})
+
+	h.gameRepository.AssertNotCalled(t, "FindAll")
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "roll.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+	assert.Equal(t, "You must change your password in playtrack first.", resp.Body.Data.Content)
+}
+
+func TestInteractions_Leaderboard(t *testing.T) {
+	h := newTestHandler(t)
+
+	h.ledgerRepository.
+		On("Leaderboard", mock.Anything).
+		Once().
+		Return([]player.LeaderboardPlayer{
+			{Username: "first", Total: 3, Completed: 1, Dropped: 1},
+			{Username: "second", Total: 3, Completed: 1},
+		}, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "leaderboard.json"))
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(resp.Body.Data.Embeds[0].Description), "\n")
+	assert.Equal(t, []string{
+		"**1. first** — 3 pts (✅ 1, 💀 1)",
+		"**2. second** — 3 pts (✅ 1, 💀 0)",
+	}, lines)
+}
+
+func TestInteractions_Link(t *testing.T) {
+	h := newTestHandler(t)
+
+	h.identityRepository.
+		On("FindOne", mock.Anything, oauth.KindDiscord, guestUserID).
+		Once().
+		Return(nil, auth.ErrIdentityNotFound)
+
+	var inserted *Link
+	h.linkRepository.
+		On("Insert", mock.Anything, mock.MatchedBy(func(l *Link) bool {
+			inserted = l
+			return l.DiscordUserID == guestUserID && l.DiscordUsername == "guest"
+		})).
+		Once().
+		Return(1, nil)
+
+	resp, err := h.Interactions(t.Context(), h.signedRequest(t, "link.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, MessageFlagEphemeral, resp.Body.Data.Flags)
+
+	_, code, ok := strings.Cut(resp.Body.Data.Content, "https://playtrack.example/discord/link?code=")
+	assert.True(t, ok)
+	code, _, _ = strings.Cut(code, " ")
+	assert.Equal(t, inserted.Hash, auth.HashToken(code))
+}
+
+func TestConfirmLink(t *testing.T) {
+	h := newTestHandler(t)
+	playerID := uuid.NewString()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+
+	req := RequestConfirmLink{}
+	req.Body.Code = "code"
+
+	h.linkRepository.
+		On("Consume", ctx, auth.HashToken(req.Body.Code)).
+		Once().
+		Return(&Link{DiscordUserID: guestUserID, DiscordUsername: "guest"}, nil)
+
+	h.identityRepository.
+		On("Insert", ctx, &auth.Identity{PlayerID: playerID, Provider: oauth.KindDiscord, Subject: guestUserID}).
+		Once().
+		Return(1, nil)
+
+	resp, err := h.ConfirmLink(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "discord account guest is linked", resp.Body.Message)
+}
+
+func TestConfirmLink_InvalidCode(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	req := RequestConfirmLink{}
+	req.Body.Code = "code"
+
+	h.linkRepository.
+		On("Consume", ctx, auth.HashToken(req.Body.Code)).
+		Once().
+		Return(nil, ErrLinkNotFound)
+
+	h.identityRepository.AssertNotCalled(t, "Insert")
+
+	_, err := h.ConfirmLink(ctx, &req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	TableDiscordLink = "discord_link"
+)
+
+const (
+	linkColumns string = "id, discord_user_id, discord_username, code_hash, expires_at, created_at"
+)
+
+type PGLinkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGLinkRepository(pool *pgxpool.Pool) *PGLinkRepository {
+	return &PGLinkRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGLinkRepository) Insert(ctx context.Context, link *Link) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableDiscordLink).
+		PlaceholderFormat(sq.Dollar).
+		Columns("discord_user_id", "discord_username", "code_hash", "expires_at").
+		Values(link.DiscordUserID, link.DiscordUsername, link.Hash, link.ExpiresAt.UTC()).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the link as used and returns it, the link is valid only once
+func (r *PGLinkRepository) Consume(ctx context.Context, hash string) (*Link, error) {
+	now := time.Now().UTC()
+
+	query, args, err := sq.Update(TableDiscordLink).
+		PlaceholderFormat(sq.Dollar).
+		Set("used_at", now).
+		Where(sq.Eq{"code_hash": hash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING " + linkColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var l Link
+	err = r.pool.QueryRow(ctx, query, args...).Scan(
+		&l.ID,
+		&l.DiscordUserID,
+		&l.DiscordUsername,
+		&l.Hash,
+		&l.ExpiresAt,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+	return &l, nil
+}
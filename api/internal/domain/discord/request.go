@@ -0,0 +1,18 @@
+package discord
+
+type RequestInteraction struct {
+	Signature string `header:"X-Signature-Ed25519"`
+	Timestamp string `header:"X-Signature-Timestamp"`
+	// RawBody is verified before the interaction is decoded
+	RawBody []byte
+}
+
+type ResponseInteraction struct {
+	Body *InteractionResponse
+}
+
+type RequestConfirmLink struct {
+	Body struct {
+		Code string `json:"code" minLength:"1"`
+	}
+}
@@ -0,0 +1,75 @@
+package discord
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteLinkRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLinkRepository(sqlDB *sql.DB) *SQLiteLinkRepository {
+	return &SQLiteLinkRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteLinkRepository) Insert(ctx context.Context, link *Link) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableDiscordLink).
+		PlaceholderFormat(sq.Question).
+		Columns("discord_user_id", "discord_username", "code_hash", "expires_at").
+		Values(link.DiscordUserID, link.DiscordUsername, link.Hash, db.SQLiteTime(link.ExpiresAt)).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// Consume marks the link as used and returns it, the link is valid only once
+func (r *SQLiteLinkRepository) Consume(ctx context.Context, hash string) (*Link, error) {
+	now := db.SQLiteTime(time.Now())
+
+	query, args, err := sq.Update(TableDiscordLink).
+		PlaceholderFormat(sq.Question).
+		Set("used_at", now).
+		Where(sq.Eq{"code_hash": hash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING " + linkColumns).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var l Link
+	err = db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&l.ID,
+		&l.DiscordUserID,
+		&l.DiscordUsername,
+		&l.Hash,
+		&l.ExpiresAt,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+	return &l, nil
+}
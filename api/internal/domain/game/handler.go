@@ -2,11 +2,14 @@ package game
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/pkg/event"
 )
 
 type GameRepository interface {
@@ -15,13 +18,25 @@ type GameRepository interface {
 	Insert(context.Context, *Game) (int, error)
 }
 
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
 type Handler struct {
 	gameRepository GameRepository
+	tx             Transactor
+	publisher      EventPublisher
 }
 
-func NewHandler(gameRepository GameRepository) *Handler {
+func NewHandler(gameRepository GameRepository, tx Transactor, publisher EventPublisher) *Handler {
 	return &Handler{
 		gameRepository: gameRepository,
+		tx:             tx,
+		publisher:      publisher,
 	}
 }
 
@@ -98,9 +113,24 @@ func (h *Handler) Create(
 		return nil, huma.Error400BadRequest("game is not valid", err)
 	}
 
-	id, err := h.gameRepository.Insert(ctx, &nGame)
+	var id int
+	err := h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = h.gameRepository.Insert(ctx, &nGame)
+		if err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+		created, err := h.gameRepository.FindOne(ctx, id)
+		if err != nil {
+			return fmt.Errorf("find for event: %w", err)
+		}
+		return h.publisher.Publish(ctx, event.New(event.GameCreated, created))
+	})
+	if errors.Is(err, ErrFoundByTitle) {
+		return nil, huma.Error409Conflict("game with this title already exists", err)
+	}
 	if err != nil {
-		log.Printf("game insert: %v", err)
+		log.Printf("game create: %v", err)
 		return nil, huma.Error500InternalServerError("create", err)
 	}
 
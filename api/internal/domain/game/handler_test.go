@@ -1,17 +1,22 @@
 package game
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/pkg/event"
 	"github.com/lardira/playtrack/internal/pkg/testutil"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAll(t *testing.T) {
 	gameRepository := NewMockGameRepository(t)
-	handler := NewHandler(gameRepository)
+	publisher := NewMockEventPublisher(t)
+	handler := NewHandler(gameRepository, passTx(t), publisher)
 
 	games := make([]Game, 2)
 	testutil.Faker().Struct(&games[0])
@@ -29,7 +34,8 @@ func TestGetAll(t *testing.T) {
 
 func TestGetOne(t *testing.T) {
 	gameRepository := NewMockGameRepository(t)
-	handler := NewHandler(gameRepository)
+	publisher := NewMockEventPublisher(t)
+	handler := NewHandler(gameRepository, passTx(t), publisher)
 
 	var game Game
 	testutil.Faker().Struct(&game)
@@ -53,7 +59,8 @@ func TestGetOne(t *testing.T) {
 
 func TestGetOne_NotFound(t *testing.T) {
 	gameRepository := NewMockGameRepository(t)
-	handler := NewHandler(gameRepository)
+	publisher := NewMockEventPublisher(t)
+	handler := NewHandler(gameRepository, passTx(t), publisher)
 
 	var game Game
 	testutil.Faker().Struct(&game)
@@ -76,7 +83,8 @@ func TestGetOne_NotFound(t *testing.T) {
 
 func TestGetCreate(t *testing.T) {
 	gameRepository := NewMockGameRepository(t)
-	handler := NewHandler(gameRepository)
+	publisher := NewMockEventPublisher(t)
+	handler := NewHandler(gameRepository, passTx(t), publisher)
 
 	newID := testutil.Faker().Int()
 	hoursToBeat := 2
@@ -87,6 +95,19 @@ func TestGetCreate(t *testing.T) {
 		Once().
 		Return(newID, nil)
 
+	created := Game{ID: newID, Points: 1, HoursToBeat: hoursToBeat}
+	gameRepository.
+		On("FindOne", t.Context(), newID).
+		Once().
+		Return(&created, nil)
+
+	publisher.
+		On("Publish", t.Context(), mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.GameCreated && e.Data == &created
+		})).
+		Once().
+		Return(nil)
+
 	var req RequestCreateGame
 	req.Body.HoursToBeat = hoursToBeat
 	req.Body.Title = testutil.Faker().MovieName()
@@ -96,3 +117,34 @@ func TestGetCreate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, newID, resp.Body.ID)
 }
+
+func TestGetCreate_TitleTaken(t *testing.T) {
+	gameRepository := NewMockGameRepository(t)
+	handler := NewHandler(gameRepository, passTx(t), NewMockEventPublisher(t))
+
+	gameRepository.
+		On("Insert", t.Context(), mock.AnythingOfType("*game.Game")).
+		Once().
+		Return(0, ErrFoundByTitle)
+
+	var req RequestCreateGame
+	req.Body.HoursToBeat = 2
+	req.Body.Title = testutil.Faker().MovieName()
+
+	_, err := handler.Create(t.Context(), &req)
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusConflict, statusErr.GetStatus())
+}
+
+// passTx returns a transactor which runs the function in the given context
+func passTx(t *testing.T) *MockTransactor {
+	tx := NewMockTransactor(t)
+	tx.
+		On("WithTx", mock.Anything, mock.Anything).
+		Maybe().
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tx
+}
@@ -6,11 +6,15 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
 )
 
 const (
 	TableGame = "game"
+
+	pgUniqueViolation = "23505"
 )
 
 const (
@@ -19,6 +23,7 @@ const (
 
 var (
 	ErrFoundByTitle = errors.New("title is not unique")
+	ErrGameNotFound = errors.New("game is not found")
 )
 
 type PGRepository struct {
@@ -36,13 +41,14 @@ func (r *PGRepository) FindAll(ctx context.Context) ([]Game, error) {
 
 	sqlBuild := sq.Select(gameColumns).
 		PlaceholderFormat(sq.Dollar).
-		From(TableGame)
+		From(TableGame).
+		OrderBy("id")
 
 	query, args, err := sqlBuild.ToSql()
 	if err != nil {
 		return nil, err
 	}
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,20 +75,19 @@ func (r *PGRepository) FindOne(ctx context.Context, id int) (*Game, error) {
 		return nil, err
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	g, err := gameFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGameNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return g, nil
 }
 
+// FindByTitle returns ErrGameNotFound when there is no game with the exact title
 func (r *PGRepository) FindByTitle(ctx context.Context, title string) (*Game, error) {
-	query := `SELECT 
-				id, points, hours_to_beat, title, url, created_at  
-			FROM game 
-			WHERE title=$1`
-
 	sqlBuild := sq.Select(gameColumns).
 		PlaceholderFormat(sq.Dollar).
 		From(TableGame).
@@ -93,14 +98,18 @@ func (r *PGRepository) FindByTitle(ctx context.Context, title string) (*Game, er
 		return nil, err
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	g, err := gameFromRow(row)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
 		return nil, err
 	}
 	return g, nil
 }
 
+// Insert returns ErrFoundByTitle when there is a game with the same title
 func (r *PGRepository) Insert(ctx context.Context, game *Game) (int, error) {
 	var id int
 
@@ -115,8 +124,12 @@ func (r *PGRepository) Insert(ctx context.Context, game *Game) (int, error) {
 		return id, err
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	if err := row.Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return id, ErrFoundByTitle
+		}
 		return id, err
 	}
 	return id, nil
@@ -0,0 +1,120 @@
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+// SQLiteRepository stores games in sqlite, missing games are reported with
+// the same errors as PGRepository does
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context) ([]Game, error) {
+	out := make([]Game, 0)
+
+	sqlBuild := sq.Select(gameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableGame).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		g, err := gameFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *g)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) FindOne(ctx context.Context, id int) (*Game, error) {
+	sqlBuild := sq.Select(gameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableGame).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	g, err := gameFromRow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// FindByTitle returns ErrGameNotFound when there is no game with the exact title
+func (r *SQLiteRepository) FindByTitle(ctx context.Context, title string) (*Game, error) {
+	sqlBuild := sq.Select(gameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableGame).
+		Where(sq.Eq{"title": title})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	g, err := gameFromRow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Insert returns ErrFoundByTitle when there is a game with the same title
+func (r *SQLiteRepository) Insert(ctx context.Context, game *Game) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableGame).
+		PlaceholderFormat(sq.Question).
+		Columns("points", "hours_to_beat", "title", "url").
+		Values(game.Points, game.HoursToBeat, game.Title, game.URL).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		if db.SQLiteUniqueViolation(err, TableGame, "title") {
+			return id, ErrFoundByTitle
+		}
+		return id, err
+	}
+	return id, nil
+}
@@ -0,0 +1,39 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxAdjustmentReasonLength = 256
+
+var (
+	ErrAdjustmentNoReason  = errors.New("reason is required")
+	ErrAdjustmentReasonLen = fmt.Errorf("reason must not be more than %d symbols", MaxAdjustmentReasonLength)
+)
+
+// Adjustment is a change of points of a played game made by an admin,
+// other points are derived by the server from games and status transitions
+type Adjustment struct {
+	ID           int    `json:"id"`
+	PlayedGameID int    `json:"played_game_id"`
+	PlayerID     string `json:"player_id"`
+	// AdjustedBy is the admin who adjusted points, nil when the admin is deleted
+	AdjustedBy   *string   `json:"adjusted_by"`
+	PointsBefore int       `json:"points_before"`
+	Points       int       `json:"points"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (a *Adjustment) Valid() error {
+	if strings.TrimSpace(a.Reason) == "" {
+		return ErrAdjustmentNoReason
+	}
+	if len(a.Reason) > MaxAdjustmentReasonLength {
+		return ErrAdjustmentReasonLen
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package player
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	TablePointsAdjustment = "points_adjustment"
+
+	adjustmentColumns string = "id, played_game_id, player_id, adjusted_by, points_before, points, reason, created_at"
+)
+
+type PGAdjustmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGAdjustmentRepository(pool *pgxpool.Pool) *PGAdjustmentRepository {
+	return &PGAdjustmentRepository{
+		pool: pool,
+	}
+}
+
+// FindAll returns adjustments of the played game, the latest first
+func (r *PGAdjustmentRepository) FindAll(ctx context.Context, playedGameID int) ([]Adjustment, error) {
+	query, args, err := sq.Select(adjustmentColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePointsAdjustment).
+		Where(sq.Eq{"played_game_id": playedGameID}).
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Adjustment, 0)
+	for rows.Next() {
+		var a Adjustment
+		err := rows.Scan(
+			&a.ID,
+			&a.PlayedGameID,
+			&a.PlayerID,
+			&a.AdjustedBy,
+			&a.PointsBefore,
+			&a.Points,
+			&a.Reason,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *PGAdjustmentRepository) Insert(ctx context.Context, a *Adjustment) (int, error) {
+	var id int
+
+	query, args, err := sq.Insert(TablePointsAdjustment).
+		PlaceholderFormat(sq.Dollar).
+		Columns("played_game_id", "player_id", "adjusted_by", "points_before", "points", "reason").
+		Values(a.PlayedGameID, a.PlayerID, a.AdjustedBy, a.PointsBefore, a.Points, a.Reason).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	if err := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
@@ -1,19 +1,26 @@
 package player
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/domain/backlog"
 	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/apiutil"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
 )
 
+var errImportRejected = errors.New("import has invalid rows")
+
 type PlayerRepository interface {
 	FindAll(context.Context) ([]Player, error)
 	FindOne(ctx context.Context, id string) (*Player, error)
@@ -24,30 +31,92 @@ type PlayerRepository interface {
 type PlayedGameRepository interface {
 	FindAll(ctx context.Context, playerID string) ([]PlayedGame, error)
 	FindOne(ctx context.Context, playerID string, id int) (*PlayedGame, error)
-	FindLastNotReroll(ctx context.Context, playerID string) (*PlayedGame, error)
+	FindNonterminated(ctx context.Context, playerID string) (*PlayedGame, error)
 	Insert(ctx context.Context, player *PlayedGame) (int, error)
+	Import(ctx context.Context, game *PlayedGame) (int, error)
 	Update(ctx context.Context, game *PlayedGameUpdate) (int, error)
 }
 
 type GameRepository interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
 	FindOne(ctx context.Context, id int) (*game.Game, error)
+	FindByTitle(ctx context.Context, title string) (*game.Game, error)
+	Insert(ctx context.Context, game *game.Game) (int, error)
+}
+
+type BacklogRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]backlog.Item, error)
+	DeleteByGame(ctx context.Context, playerID string, gameID int) error
+}
+
+type StreakRepository interface {
+	FindOne(ctx context.Context, playerID string) (*Streak, error)
+	Save(ctx context.Context, s *Streak) error
+}
+
+type AdjustmentRepository interface {
+	FindAll(ctx context.Context, playedGameID int) ([]Adjustment, error)
+	Insert(ctx context.Context, a *Adjustment) (int, error)
+}
+
+type LedgerRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error)
+	Insert(ctx context.Context, entries []LedgerEntry) error
+	Leaderboard(ctx context.Context) ([]LeaderboardPlayer, error)
+}
+
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+type Options struct {
+	// Streak rules, DefaultStreakRules when empty
+	Streak StreakRules
 }
 
 type Handler struct {
 	playerRepository     PlayerRepository
 	playedGameRepository PlayedGameRepository
 	gameRepository       GameRepository
+	backlogRepository    BacklogRepository
+	streakRepository     StreakRepository
+	adjustmentRepository AdjustmentRepository
+	ledgerRepository     LedgerRepository
+	tx                   Transactor
+	publisher            EventPublisher
+	opts                 Options
 }
 
 func NewHandler(
 	playerRepository PlayerRepository,
 	gameRepository GameRepository,
 	playedGameRepository PlayedGameRepository,
+	backlogRepository BacklogRepository,
+	streakRepository StreakRepository,
+	adjustmentRepository AdjustmentRepository,
+	ledgerRepository LedgerRepository,
+	tx Transactor,
+	publisher EventPublisher,
+	opts Options,
 ) *Handler {
+	if opts.Streak == (StreakRules{}) {
+		opts.Streak = DefaultStreakRules
+	}
 	return &Handler{
 		playerRepository:     playerRepository,
 		playedGameRepository: playedGameRepository,
 		gameRepository:       gameRepository,
+		backlogRepository:    backlogRepository,
+		streakRepository:     streakRepository,
+		adjustmentRepository: adjustmentRepository,
+		ledgerRepository:     ledgerRepository,
+		tx:                   tx,
+		publisher:            publisher,
+		opts:                 opts,
 	}
 }
 
@@ -65,6 +134,14 @@ func (h *Handler) Register(api huma.API) {
 		Description: "get all players",
 	}, h.GetAll)
 
+	huma.Register(grp, huma.Operation{
+		OperationID: "players-leaderboard",
+		Method:      http.MethodGet,
+		Path:        "/leaderboard",
+		Summary:     "get leaderboard",
+		Description: "get totals of all players from the points ledger, the best first",
+	}, h.Leaderboard)
+
 	huma.Register(grp, huma.Operation{
 		OperationID: "players-get-one",
 		Method:      http.MethodGet,
@@ -89,6 +166,42 @@ func (h *Handler) Register(api huma.API) {
 		Description: "get all played games",
 	}, h.GetAllPlayedGames)
 
+	huma.Register(grp, huma.Operation{
+		OperationID: "players-get-streak",
+		Method:      http.MethodGet,
+		Path:        "/{id}/streak",
+		Summary:     "get streak",
+		Description: "get completion and drop streaks of a player, penalty is the level of the next drop penalty",
+	}, h.GetStreak)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "players-get-ledger",
+		Method:      http.MethodGet,
+		Path:        "/{id}/ledger",
+		Summary:     "get points ledger",
+		Description: "get changes of points of a player with reasons in order they were made and the total",
+	}, h.GetLedger)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "played-games-export",
+		Method:      http.MethodGet,
+		Path:        "/{id}/played-games/export",
+		Summary:     "export played games",
+		Description: "export history of played games as csv or json",
+	}, h.ExportPlayedGames)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "played-games-import",
+		Method:      http.MethodPost,
+		Path:        "/{id}/played-games/import",
+		Summary:     "import played games",
+		Description: "import finished played games as csv or json, games are matched by title. " +
+			"Missing games are created for admins when hours_to_beat is set. " +
+			"Points of rows are kept only for admins, games imported by players get no points. " +
+			"Nothing is imported if any row is invalid, errors are reported per row.",
+		Metadata: map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite},
+	}, h.ImportPlayedGames)
+
 	huma.Register(grp, huma.Operation{
 		OperationID: "played-games-get-one",
 		Method:      http.MethodGet,
@@ -102,7 +215,8 @@ func (h *Handler) Register(api huma.API) {
 		Method:      http.MethodPost,
 		Path:        "/{id}/played-games",
 		Summary:     "create played game",
-		Description: "create a new played game",
+		Description: "create a new played game, the game is removed from the backlog of the player",
+		Metadata:    map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite},
 	}, h.CreatePlayedGame)
 
 	huma.Register(grp, huma.Operation{
@@ -110,8 +224,25 @@ func (h *Handler) Register(api huma.API) {
 		Method:      http.MethodPatch,
 		Path:        "/{id}/played-games/{gameID}",
 		Summary:     "update played game",
-		Description: "update a played game",
+		Description: "update a played game, points are derived by the server from the game and status",
+		Metadata:    map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite},
 	}, h.UpdatePlayedGame)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "played-games-get-adjustments",
+		Method:      http.MethodGet,
+		Path:        "/{id}/played-games/{gameID}/adjustments",
+		Summary:     "get points adjustments",
+		Description: "get admin adjustments of points of a played game, the latest first",
+	}, h.GetAdjustments)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "played-games-adjust-points",
+		Method:      http.MethodPost,
+		Path:        "/{id}/played-games/{gameID}/adjustments",
+		Summary:     "adjust points",
+		Description: "set points of a played game, only for admins. The reason and the admin are recorded.",
+	}, h.AdjustPoints)
 }
 
 func (h *Handler) GetAll(ctx context.Context, i *struct{}) (*domain.ResponseItems[Player], error) {
@@ -144,7 +275,7 @@ func (h *Handler) Update(
 	ctx context.Context,
 	i *RequestUpdatePlayer,
 ) (*domain.ResponseID[string], error) {
-	if ok := checkAuthorizedFor(ctx, i.PlayerID); !ok {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
 		return nil, huma.Error403Forbidden("player cannot access this entity")
 	}
 
@@ -155,12 +286,17 @@ func (h *Handler) Update(
 		Email:       i.Body.Email,
 		Description: i.Body.Description,
 	}
+	nPlayer.Normalize()
 	if err := nPlayer.Valid(); err != nil {
 		log.Printf("player valid: %v", err)
 		return nil, huma.Error400BadRequest("entity is not valid", err)
 	}
 
 	id, err := h.playerRepository.Update(ctx, &nPlayer)
+	if errors.Is(err, ErrUsernameTaken) || errors.Is(err, ErrEmailTaken) {
+		log.Printf("player update: %v", err)
+		return nil, huma.Error409Conflict(err.Error())
+	}
 	if err != nil {
 		log.Printf("player update: %v", err)
 		return nil, huma.Error500InternalServerError("update", err)
@@ -172,6 +308,49 @@ func (h *Handler) Update(
 	return &resp, nil
 }
 
+func (h *Handler) GetStreak(ctx context.Context, i *struct {
+	ID string `path:"id" format:"uuid"`
+}) (*domain.ResponseItem[Streak], error) {
+	streak, err := h.streak(ctx, i.ID)
+	if err != nil {
+		log.Printf("streak find %v: %v", i.ID, err)
+		return nil, huma.Error500InternalServerError("find", err)
+	}
+
+	resp := domain.ResponseItem[Streak]{}
+	resp.Body.Item = streak
+	return &resp, nil
+}
+
+func (h *Handler) Leaderboard(ctx context.Context, i *struct{}) (*domain.ResponseItems[LeaderboardPlayer], error) {
+	leaderboard, err := h.ledgerRepository.Leaderboard(ctx)
+	if err != nil {
+		log.Printf("leaderboard find: %v", err)
+		return nil, huma.Error500InternalServerError("find", err)
+	}
+
+	resp := domain.ResponseItems[LeaderboardPlayer]{}
+	resp.Body.Items = leaderboard
+	return &resp, nil
+}
+
+func (h *Handler) GetLedger(ctx context.Context, i *struct {
+	ID string `path:"id" format:"uuid"`
+}) (*ResponseLedger, error) {
+	entries, err := h.ledgerRepository.FindAll(ctx, i.ID)
+	if err != nil {
+		log.Printf("ledger find all %v: %v", i.ID, err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := ResponseLedger{}
+	resp.Body.Items = entries
+	for _, e := range entries {
+		resp.Body.Total += e.Delta
+	}
+	return &resp, nil
+}
+
 func (h *Handler) GetAllPlayedGames(ctx context.Context, i *struct {
 	ID string `path:"id" format:"uuid"`
 }) (*domain.ResponseItems[PlayedGame], error) {
@@ -205,11 +384,27 @@ func (h *Handler) CreatePlayedGame(
 	ctx context.Context,
 	i *RequestCreatePlayedGame,
 ) (*domain.ResponseID[int], error) {
-	if ok := checkAuthorizedFor(ctx, i.PlayerID); !ok {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
 		return nil, huma.Error403Forbidden("player cannot access this entity")
 	}
 
-	game, err := h.gameRepository.FindOne(ctx, i.Body.GameID)
+	gameID := i.Body.GameID
+	if gameID == 0 && i.Body.FromBacklog {
+		items, err := h.backlogRepository.FindAll(ctx, i.PlayerID)
+		if err != nil {
+			log.Printf("backlog find all: %v", err)
+			return nil, huma.Error500InternalServerError("backlog find", err)
+		}
+		if len(items) == 0 {
+			return nil, huma.Error400BadRequest("backlog is empty")
+		}
+		gameID = items[0].GameID
+	}
+	if gameID == 0 {
+		return nil, huma.Error400BadRequest("game_id or from_backlog is required")
+	}
+
+	game, err := h.gameRepository.FindOne(ctx, gameID)
 	if err != nil {
 		log.Printf("game find one: %v", err)
 		return nil, huma.Error400BadRequest("game find", err)
@@ -217,7 +412,7 @@ func (h *Handler) CreatePlayedGame(
 
 	nPlayed := PlayedGame{
 		PlayerID: i.PlayerID,
-		GameID:   i.Body.GameID,
+		GameID:   gameID,
 		Points:   game.Points,
 	}
 
@@ -231,9 +426,24 @@ func (h *Handler) CreatePlayedGame(
 		return nil, err
 	}
 
-	id, err := h.playedGameRepository.Insert(ctx, &nPlayed)
+	var id int
+	err = h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = h.playedGameRepository.Insert(ctx, &nPlayed)
+		if err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+		if err := h.backlogRepository.DeleteByGame(ctx, i.PlayerID, gameID); err != nil {
+			return fmt.Errorf("backlog delete: %w", err)
+		}
+		return h.publishPlayedGame(ctx, i.PlayerID, id, event.PlayedGameAdded, event.LeaderboardChanged)
+	})
+	// another game was added since the check
+	if errors.Is(err, ErrNonterminatedExists) {
+		return nil, huma.Error409Conflict(err.Error())
+	}
 	if err != nil {
-		log.Printf("played game insert: %v", err)
+		log.Printf("played game create: %v", err)
 		return nil, huma.Error500InternalServerError("create", err)
 	}
 
@@ -247,13 +457,19 @@ func (h *Handler) UpdatePlayedGame(
 	ctx context.Context,
 	i *RequestUpdatePlayedGame,
 ) (*domain.ResponseID[int], error) {
-	if ok := checkAuthorizedFor(ctx, i.PlayerID); !ok {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
 		return nil, huma.Error403Forbidden("player cannot access this entity")
 	}
 
+	if i.Body.Points != nil {
+		if ctxPlayer, _ := ctxutil.GetPlayer(ctx); !ctxPlayer.IsAdmin {
+			return nil, huma.Error403Forbidden("points are set by the server")
+		}
+		return nil, huma.Error400BadRequest("points are changed with an adjustment")
+	}
+
 	nGame := PlayedGameUpdate{
 		ID:          i.GameID,
-		Points:      i.Body.Points,
 		Comment:     i.Body.Comment,
 		Rating:      i.Body.Rating,
 		Status:      i.Body.Status,
@@ -278,38 +494,58 @@ func (h *Handler) UpdatePlayedGame(
 			return nil, huma.Error400BadRequest("entity is not valid", err)
 		}
 
-		newPoints := 0
-		now := time.Now()
+		// the status is checked again by the update, another request may change it first
+		nGame.FromStatus = &playedGame.Status
 
+		now := time.Now()
 		switch newStatus {
 		case PlayedGameStatusDropped:
-			newPoints = -1
-
-			prevGame, err := h.playedGameRepository.FindLastNotReroll(ctx, i.PlayerID)
-			if err != nil && !errors.Is(err, ErrPlayedGameNotFound) {
-				log.Printf("last played game find: %v", err)
-				return nil, huma.Error400BadRequest("game played find", err)
-			}
-
-			// consecutive drops are stacked
-			if err == nil && prevGame.Status == PlayedGameStatusDropped {
-				newPoints = prevGame.Points - 1
-			}
-
-			nGame.Points = &newPoints
+			// points of a drop are the penalty of the streak
 			if nGame.CompletedAt == nil {
 				nGame.CompletedAt = &now
 			}
 
 		case PlayedGameStatusRerolled:
-			nGame.Points = &newPoints
+			noPoints := 0
+			nGame.Points = &noPoints
 			if nGame.CompletedAt == nil {
 				nGame.CompletedAt = &now
 			}
 		}
 	}
 
-	id, err := h.playedGameRepository.Update(ctx, &nGame)
+	var id int
+	err = h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var entries []LedgerEntry
+		if nGame.Status != nil {
+			var err error
+			if entries, err = h.applyStreak(ctx, playedGame, &nGame); err != nil {
+				return fmt.Errorf("streak: %w", err)
+			}
+		}
+
+		var err error
+		id, err = h.playedGameRepository.Update(ctx, &nGame)
+		if err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+		if err := h.appendLedger(ctx, entries); err != nil {
+			return fmt.Errorf("ledger insert: %w", err)
+		}
+		var events []event.Type
+		if nGame.Status != nil {
+			if t, ok := statusEvents[*nGame.Status]; ok {
+				events = append(events, t)
+			}
+		}
+		if nGame.Status != nil {
+			events = append(events, event.LeaderboardChanged)
+		}
+		return h.publishPlayedGame(ctx, i.PlayerID, id, events...)
+	})
+	if errors.Is(err, ErrPlayedGameStatusChanged) {
+		return nil, huma.Error409Conflict(err.Error())
+	}
 	if err != nil {
 		log.Printf("played game update: %v", err)
 		return nil, huma.Error500InternalServerError("update", err)
@@ -321,32 +557,386 @@ func (h *Handler) UpdatePlayedGame(
 	return &resp, nil
 }
 
-func (h *Handler) containsNonterminatedPlayed(ctx context.Context, playerID string) error {
-	allPlayed, err := h.playedGameRepository.FindAll(ctx, playerID)
+func (h *Handler) GetAdjustments(
+	ctx context.Context,
+	i *RequestAdjustments,
+) (*domain.ResponseItems[Adjustment], error) {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if _, err := h.playedGameRepository.FindOne(ctx, i.PlayerID, i.GameID); err != nil {
+		log.Printf("played find one: %v", err)
+		return nil, huma.Error404NotFound("entity is not found", err)
+	}
+
+	adjustments, err := h.adjustmentRepository.FindAll(ctx, i.GameID)
 	if err != nil {
-		return huma.Error400BadRequest("find played games", err)
+		log.Printf("adjustments find all %v: %v", i.GameID, err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Adjustment]{}
+	resp.Body.Items = adjustments
+	return &resp, nil
+}
+
+func (h *Handler) AdjustPoints(
+	ctx context.Context,
+	i *RequestAdjustPoints,
+) (*domain.ResponseID[int], error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok || !ctxPlayer.IsAdmin {
+		return nil, huma.Error403Forbidden("only admins can adjust points")
+	}
+
+	playedGame, err := h.playedGameRepository.FindOne(ctx, i.PlayerID, i.GameID)
+	if err != nil {
+		log.Printf("played find one: %v", err)
+		return nil, huma.Error404NotFound("entity is not found", err)
 	}
 
-	for _, p := range allPlayed {
-		if !p.StatusTerminated() {
-			return huma.Error400BadRequest(
-				fmt.Sprintf("player has game in nonterminated status: %v", p.ID),
-			)
+	adjustment := Adjustment{
+		PlayedGameID: playedGame.ID,
+		PlayerID:     playedGame.PlayerID,
+		AdjustedBy:   &ctxPlayer.ID,
+		PointsBefore: playedGame.Points,
+		Points:       i.Body.Points,
+		Reason:       strings.TrimSpace(i.Body.Reason),
+	}
+	if err := adjustment.Valid(); err != nil {
+		log.Printf("adjustment valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	var id int
+	err = h.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if _, err = h.playedGameRepository.Update(ctx, &PlayedGameUpdate{ID: playedGame.ID, Points: &adjustment.Points}); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+		if id, err = h.adjustmentRepository.Insert(ctx, &adjustment); err != nil {
+			return fmt.Errorf("insert: %w", err)
 		}
+		// points of unfinished games get into the ledger when they are finished
+		if playedGame.StatusTerminated() {
+			entries := ledgerEntries(newLedgerEntry(playedGame, adjustment.Points-adjustment.PointsBefore, LedgerReasonAdjustment))
+			if err := h.appendLedger(ctx, entries); err != nil {
+				return fmt.Errorf("ledger insert: %w", err)
+			}
+		}
+		return h.publishPlayedGame(ctx, i.PlayerID, playedGame.ID, event.LeaderboardChanged)
+	})
+	if err != nil {
+		log.Printf("played game %v adjust: %v", playedGame.ID, err)
+		return nil, huma.Error500InternalServerError("adjust", err)
 	}
-	return nil
+
+	log.Printf("played game %v points adjusted by %v: %d -> %d", playedGame.ID, ctxPlayer.ID, adjustment.PointsBefore, adjustment.Points)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = id
+	return &resp, nil
 }
 
-func checkAuthorizedFor(ctx context.Context, playerID string) bool {
+func (h *Handler) ExportPlayedGames(
+	ctx context.Context,
+	i *RequestExportPlayedGames,
+) (*ResponseExportPlayedGames, error) {
+	if ok := ctxutil.AuthorizedFor(ctx, i.PlayerID); !ok {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	played, err := h.playedGameRepository.FindAll(ctx, i.PlayerID)
+	if err != nil {
+		log.Printf("played games find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+	games, err := h.gameRepository.FindAll(ctx)
+	if err != nil {
+		log.Printf("games find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	gamesByID := make(map[int]*game.Game, len(games))
+	for idx := range games {
+		gamesByID[games[idx].ID] = &games[idx]
+	}
+
+	rows := make([]HistoryRow, 0, len(played))
+	for idx := range played {
+		g, ok := gamesByID[played[idx].GameID]
+		if !ok {
+			log.Printf("played game %v export: game %v is not found", played[idx].ID, played[idx].GameID)
+			return nil, huma.Error500InternalServerError("game is not found")
+		}
+		rows = append(rows, NewHistoryRow(&played[idx], g))
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeHistory(&buf, i.Format, rows); err != nil {
+		log.Printf("played games export: %v", err)
+		return nil, huma.Error500InternalServerError("export", err)
+	}
+
+	resp := ResponseExportPlayedGames{
+		ContentType:        "application/json",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="played-games-%s.%s"`, i.PlayerID, i.Format),
+		Body:               buf.Bytes(),
+	}
+	if i.Format == HistoryFormatCSV {
+		resp.ContentType = "text/csv; charset=utf-8"
+	}
+	return &resp, nil
+}
+
+func (h *Handler) ImportPlayedGames(
+	ctx context.Context,
+	i *RequestImportPlayedGames,
+) (*ResponseImportPlayedGames, error) {
 	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok || !ctxutil.AuthorizedFor(ctx, i.PlayerID) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	rows, rowErrs, err := DecodeHistory(i.RawBody, i.Format)
+	if err != nil {
+		log.Printf("played games import decode: %v", err)
+		return nil, huma.Error400BadRequest("history is not valid", err)
+	}
+
+	resp := ResponseImportPlayedGames{}
+	resp.Body.CreatedGames = make([]string, 0)
+
+	err = h.tx.WithTx(ctx, func(ctx context.Context) error {
+		resolved := make(map[string]*game.Game)
+		decodeFailed := make(map[int]bool, len(rowErrs))
+		for _, e := range rowErrs {
+			decodeFailed[e.Row] = true
+		}
+
+		for idx, row := range rows {
+			n := idx + 1
+			if decodeFailed[n] {
+				continue
+			}
+
+			g, err := h.importRow(ctx, ctxPlayer, i.PlayerID, &row, resolved, &resp.Body.CreatedGames)
+			if err != nil {
+				var statusErr huma.StatusError
+				if errors.As(err, &statusErr) {
+					return err
+				}
+				rowErrs = append(rowErrs, &HistoryRowError{Row: n, Err: err})
+				continue
+			}
+			resolved[row.Title] = g
+		}
+
+		// the whole import is rolled back, rows are reported after the transaction
+		if len(rowErrs) > 0 {
+			return errImportRejected
+		}
+
+		resp.Body.Imported = len(rows)
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := h.replayStreak(ctx, i.PlayerID); err != nil {
+			return fmt.Errorf("streak: %w", err)
+		}
+		return h.publisher.Publish(ctx, event.NewForPlayer(event.LeaderboardChanged, i.PlayerID, resp.Body))
+	})
+	if errors.Is(err, errImportRejected) {
+		details := make([]error, 0, len(rowErrs))
+		for _, e := range rowErrs {
+			details = append(details, &huma.ErrorDetail{
+				Message:  e.Err.Error(),
+				Location: fmt.Sprintf("body[%d]", e.Row),
+			})
+		}
+		return nil, huma.Error422UnprocessableEntity("history rows are not valid", details...)
+	}
+	if err != nil {
+		log.Printf("played games import: %v", err)
+		var statusErr huma.StatusError
+		if errors.As(err, &statusErr) {
+			return nil, err
+		}
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+
+	log.Printf("played games imported for %v: %d", i.PlayerID, resp.Body.Imported)
+	return &resp, nil
+}
+
+// importRow resolves the game of the row and inserts it, returned plain errors are
+// problems of the row and huma errors fail the whole import
+func (h *Handler) importRow(
+	ctx context.Context,
+	ctxPlayer ctxutil.CtxPlayer,
+	playerID string,
+	row *HistoryRow,
+	resolved map[string]*game.Game,
+	created *[]string,
+) (*game.Game, error) {
+	row.Title = strings.TrimSpace(row.Title)
+	// points are earned by status transitions here, games imported by players were not
+	// played here and get no points, admins restore them as they are
+	if !ctxPlayer.IsAdmin {
+		noPoints := 0
+		row.Points = &noPoints
+	}
+	if err := row.Valid(); err != nil {
+		return nil, err
+	}
+
+	g, ok := resolved[row.Title]
 	if !ok {
-		return false
+		var err error
+		g, err = h.gameRepository.FindByTitle(ctx, row.Title)
+		if errors.Is(err, game.ErrGameNotFound) {
+			g, err = h.createImportedGame(ctx, ctxPlayer, row)
+			if err == nil {
+				*created = append(*created, g.Title)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	played := row.PlayedGame(playerID, g)
+	if err := played.Valid(); err != nil {
+		return nil, err
+	}
+	id, err := h.playedGameRepository.Import(ctx, &played)
+	if err != nil {
+		log.Printf("played game import insert: %v", err)
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+	played.ID = id
+	if err := h.appendLedger(ctx, ledgerEntries(newLedgerEntry(&played, played.Points, LedgerReasonImport))); err != nil {
+		log.Printf("played game import ledger: %v", err)
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+	return g, nil
+}
+
+func (h *Handler) createImportedGame(ctx context.Context, ctxPlayer ctxutil.CtxPlayer, row *HistoryRow) (*game.Game, error) {
+	if !ctxPlayer.IsAdmin || row.HoursToBeat == nil {
+		return nil, fmt.Errorf("game %q is not found", row.Title)
 	}
 
-	if ctxPlayer.IsAdmin {
-		return true
-	} else if ctxPlayer.ID != playerID {
-		return false
+	nGame := game.Game{
+		Title:       row.Title,
+		HoursToBeat: *row.HoursToBeat,
+		URL:         row.URL,
+	}
+	nGame.CalculatePoints()
+	if err := nGame.Valid(); err != nil {
+		return nil, fmt.Errorf("game %q: %w", row.Title, err)
+	}
+
+	id, err := h.gameRepository.Insert(ctx, &nGame)
+	if err != nil {
+		log.Printf("imported game insert: %v", err)
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+	g, err := h.gameRepository.FindOne(ctx, id)
+	if err != nil {
+		log.Printf("imported game find: %v", err)
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+	if err := h.publisher.Publish(ctx, event.New(event.GameCreated, g)); err != nil {
+		log.Printf("imported game publish: %v", err)
+		return nil, huma.Error500InternalServerError("import", err)
+	}
+	return g, nil
+}
+
+// applyStreak moves the streak of the player by the new status of the played game,
+// a drop costs the penalty of the streak and a completion gets the streak bonus.
+// Returned ledger entries are the points the played game gets.
+func (h *Handler) applyStreak(ctx context.Context, played *PlayedGame, upd *PlayedGameUpdate) ([]LedgerEntry, error) {
+	status := *upd.Status
+	if status != PlayedGameStatusDropped && status != PlayedGameStatusCompleted {
+		return nil, nil
+	}
+
+	streak, err := h.streak(ctx, played.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	next, entries := streak.Finish(played, upd, h.opts.Streak)
+	next.UpdatedAt = time.Now()
+	if err := h.streakRepository.Save(ctx, &next); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// streak returns the stored streak of the player, the first time it is replayed from history
+func (h *Handler) streak(ctx context.Context, playerID string) (*Streak, error) {
+	streak, err := h.streakRepository.FindOne(ctx, playerID)
+	if !errors.Is(err, ErrStreakNotFound) {
+		return streak, err
+	}
+
+	played, err := h.playedGameRepository.FindAll(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("find played games: %w", err)
+	}
+	replayed := StreakFromHistory(playerID, played, h.opts.Streak)
+	return &replayed, nil
+}
+
+// replayStreak stores the streak replayed from history, imported games may be older
+// than the stored streak
+func (h *Handler) replayStreak(ctx context.Context, playerID string) error {
+	played, err := h.playedGameRepository.FindAll(ctx, playerID)
+	if err != nil {
+		return fmt.Errorf("find played games: %w", err)
+	}
+	streak := StreakFromHistory(playerID, played, h.opts.Streak)
+	streak.UpdatedAt = time.Now()
+	return h.streakRepository.Save(ctx, &streak)
+}
+
+// appendLedger writes entries which change points, nothing is written for no entries
+func (h *Handler) appendLedger(ctx context.Context, entries []LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return h.ledgerRepository.Insert(ctx, entries)
+}
+
+// publishPlayedGame publishes events with the played game as it is stored in the transaction of ctx
+func (h *Handler) publishPlayedGame(ctx context.Context, playerID string, id int, types ...event.Type) error {
+	if len(types) == 0 {
+		return nil
+	}
+
+	played, err := h.playedGameRepository.FindOne(ctx, playerID, id)
+	if err != nil {
+		return fmt.Errorf("find for event: %w", err)
+	}
+	for _, t := range types {
+		if err := h.publisher.Publish(ctx, event.NewForPlayer(t, playerID, played)); err != nil {
+			return fmt.Errorf("publish %v: %w", t, err)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) containsNonterminatedPlayed(ctx context.Context, playerID string) error {
+	played, err := h.playedGameRepository.FindNonterminated(ctx, playerID)
+	if errors.Is(err, ErrPlayedGameNotFound) {
+		return nil
+	}
+	if err != nil {
+		return huma.Error400BadRequest("find played games", err)
 	}
-	return true
+	return huma.Error409Conflict(
+		fmt.Sprintf("player has game in nonterminated status: %v", played.ID),
+	)
 }
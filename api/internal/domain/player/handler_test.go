@@ -1,13 +1,18 @@
 package player
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/backlog"
 	"github.com/lardira/playtrack/internal/domain/game"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
 	"github.com/lardira/playtrack/internal/pkg/testutil"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,8 +25,9 @@ func TestGetAll(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playerRepository.
 		On("FindAll", t.Context()).
@@ -39,8 +45,9 @@ func TestGetOne(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playerRepository.
 		On("FindOne", t.Context(), mock.AnythingOfType("string")).
@@ -66,8 +73,9 @@ func TestUpdate(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playerRepository.
 		On("Update", ctx, mock.AnythingOfType("*player.PlayerUpdate")).
@@ -97,8 +105,9 @@ func TestUpdate_OtherPlayer(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playerRepository.AssertNotCalled(t, "Update")
 
@@ -121,8 +130,9 @@ func TestGetAllPlayedGames(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindAll", t.Context(), playerID).
@@ -146,8 +156,9 @@ func TestGetOnePlayedGame(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindOne", t.Context(), game.PlayerID, game.ID).
@@ -179,14 +190,15 @@ func TestCreatePlayedGame(t *testing.T) {
 	player := validPlayer()
 	played := validPlayedGame()
 	played.PlayerID = player.ID
-	playedGame := []PlayedGame{played}
 	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
 
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	backlogRepository := NewMockBacklogRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, backlogRepository, NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	gameRepository.
 		On("FindOne", ctx, game.ID).
@@ -194,9 +206,9 @@ func TestCreatePlayedGame(t *testing.T) {
 		Return(&game, nil)
 
 	playedGameRepository.
-		On("FindAll", ctx, player.ID).
+		On("FindNonterminated", ctx, player.ID).
 		Once().
-		Return(playedGame, nil)
+		Return(nil, ErrPlayedGameNotFound)
 
 	playedGameRepository.
 		On("Insert", ctx, mock.MatchedBy(func(p *PlayedGame) bool {
@@ -214,6 +226,30 @@ func TestCreatePlayedGame(t *testing.T) {
 		Once().
 		Return(played.ID, nil)
 
+	backlogRepository.
+		On("DeleteByGame", ctx, player.ID, game.ID).
+		Once().
+		Return(nil)
+
+	playedGameRepository.
+		On("FindOne", ctx, player.ID, played.ID).
+		Once().
+		Return(&played, nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.PlayedGameAdded && e.Data == &played
+		})).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.LeaderboardChanged && e.PlayerID == player.ID
+		})).
+		Once().
+		Return(nil)
+
 	req := RequestCreatePlayedGame{}
 	req.PlayerID = player.ID
 	req.Body.GameID = game.ID
@@ -224,6 +260,84 @@ func TestCreatePlayedGame(t *testing.T) {
 	assert.Equal(t, played.ID, resp.Body.ID)
 }
 
+func TestCreatePlayedGame_FromBacklog(t *testing.T) {
+	game := game.Game{ID: 7, Points: 2, HoursToBeat: 3}
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	backlogRepository := NewMockBacklogRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), gameRepository, playedGameRepository, backlogRepository, NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
+
+	backlogRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return([]backlog.Item{{ID: 1, GameID: game.ID}, {ID: 2, GameID: 9, Position: 1}}, nil)
+
+	gameRepository.
+		On("FindOne", ctx, game.ID).
+		Once().
+		Return(&game, nil)
+
+	playedGameRepository.
+		On("FindNonterminated", ctx, player.ID).
+		Once().
+		Return(nil, ErrPlayedGameNotFound)
+
+	playedGameRepository.
+		On("Insert", ctx, mock.MatchedBy(func(p *PlayedGame) bool { return p.GameID == game.ID })).
+		Once().
+		Return(3, nil)
+
+	backlogRepository.
+		On("DeleteByGame", ctx, player.ID, game.ID).
+		Once().
+		Return(nil)
+
+	playedGameRepository.
+		On("FindOne", ctx, player.ID, 3).
+		Once().
+		Return(&PlayedGame{ID: 3}, nil)
+
+	publisher.
+		On("Publish", ctx, mock.Anything).
+		Twice().
+		Return(nil)
+
+	req := RequestCreatePlayedGame{PlayerID: player.ID}
+	req.Body.FromBacklog = true
+
+	resp, err := handler.CreatePlayedGame(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resp.Body.ID)
+}
+
+func TestCreatePlayedGame_EmptyBacklog(t *testing.T) {
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	backlogRepository := NewMockBacklogRepository(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, backlogRepository, NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), Options{})
+
+	backlogRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return([]backlog.Item{}, nil)
+
+	playedGameRepository.AssertNotCalled(t, "Insert")
+
+	req := RequestCreatePlayedGame{PlayerID: player.ID}
+	req.Body.FromBacklog = true
+
+	_, err := handler.CreatePlayedGame(ctx, &req)
+	assert.Error(t, err)
+}
+
 func TestCreatePlayed_OtherPlayer(t *testing.T) {
 	player := validPlayer()
 	otherPlayer := validPlayer()
@@ -233,8 +347,9 @@ func TestCreatePlayed_OtherPlayer(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	gameRepository.AssertNotCalled(t, "FindOne")
 	playedGameRepository.AssertNotCalled(t, "FindAll")
@@ -258,8 +373,9 @@ func TestUpdatePlayedGame(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindOne", ctx, player.ID, played.ID).
@@ -293,8 +409,9 @@ func TestUpdatePlayedGame_OtherPlayer_AsAdmin(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindOne", ctx, player.ID, played.ID).
@@ -326,8 +443,9 @@ func TestUpdatePlayedGame_OtherPlayer(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.AssertNotCalled(t, "FindOne")
 	playedGameRepository.AssertNotCalled(t, "Update")
@@ -342,6 +460,139 @@ func TestUpdatePlayedGame_OtherPlayer(t *testing.T) {
 	assert.Equal(t, nil, resp)
 }
 
+func TestUpdatePlayedGame_PointsRejected(t *testing.T) {
+	tcases := []struct {
+		name    string
+		isAdmin bool
+		status  int
+	}{
+		{"player", false, 403},
+		{"admin", true, 400},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			player := validPlayer()
+			played := validPlayedGame()
+			ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID, IsAdmin: tc.isAdmin})
+
+			playedGameRepository := NewMockPlayedGameRepository(t)
+			handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), Options{})
+
+			playedGameRepository.AssertNotCalled(t, "Update")
+
+			points := 1000
+			req := RequestUpdatePlayedGame{PlayerID: player.ID, GameID: played.ID}
+			req.Body.Points = &points
+			req.Body.Rating = played.Rating
+
+			resp, err := handler.UpdatePlayedGame(ctx, &req)
+			assert.Equal(t, nil, resp)
+
+			var model *huma.ErrorModel
+			assert.True(t, errors.As(err, &model))
+			assert.Equal(t, tc.status, model.Status)
+		})
+	}
+}
+
+func TestAdjustPoints(t *testing.T) {
+	admin := validPlayer()
+	played := validPlayedGame()
+	played.Points = 3
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: admin.ID, IsAdmin: true})
+
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	adjustmentRepository := NewMockAdjustmentRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), adjustmentRepository, ledgerRepository, passTx(t), publisher, Options{})
+
+	playedGameRepository.
+		On("FindOne", ctx, played.PlayerID, played.ID).
+		Twice().
+		Return(&played, nil)
+
+	playedGameRepository.
+		On("Update", ctx, mock.MatchedBy(func(p *PlayedGameUpdate) bool {
+			return p.ID == played.ID && p.Points != nil && *p.Points == 5 && p.Status == nil
+		})).
+		Once().
+		Return(played.ID, nil)
+
+	adjustmentRepository.
+		On("Insert", ctx, mock.MatchedBy(func(a *Adjustment) bool {
+			return a.PlayedGameID == played.ID &&
+				a.PlayerID == played.PlayerID &&
+				a.AdjustedBy != nil && *a.AdjustedBy == admin.ID &&
+				a.PointsBefore == 3 && a.Points == 5 &&
+				a.Reason == "hours to beat were wrong"
+		})).
+		Once().
+		Return(7, nil)
+
+	ledgerRepository.
+		On("Insert", ctx, []LedgerEntry{
+			{PlayerID: played.PlayerID, PlayedGameID: played.ID, Delta: 2, Reason: LedgerReasonAdjustment},
+		}).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool { return e.Type == event.LeaderboardChanged })).
+		Once().
+		Return(nil)
+
+	req := RequestAdjustPoints{PlayerID: played.PlayerID, GameID: played.ID}
+	req.Body.Points = 5
+	req.Body.Reason = " hours to beat were wrong "
+
+	resp, err := handler.AdjustPoints(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, resp.Body.ID)
+}
+
+func TestAdjustPoints_Rejected(t *testing.T) {
+	tcases := []struct {
+		name    string
+		isAdmin bool
+		reason  string
+		status  int
+	}{
+		{"not admin", false, "hours to beat were wrong", 403},
+		{"no reason", true, "   ", 400},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			played := validPlayedGame()
+			ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: played.PlayerID, IsAdmin: tc.isAdmin})
+
+			playedGameRepository := NewMockPlayedGameRepository(t)
+			adjustmentRepository := NewMockAdjustmentRepository(t)
+			handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), adjustmentRepository, NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), Options{})
+
+			playedGameRepository.
+				On("FindOne", ctx, played.PlayerID, played.ID).
+				Maybe().
+				Return(&played, nil)
+			playedGameRepository.AssertNotCalled(t, "Update")
+			adjustmentRepository.AssertNotCalled(t, "Insert")
+
+			req := RequestAdjustPoints{PlayerID: played.PlayerID, GameID: played.ID}
+			req.Body.Points = 1000
+			req.Body.Reason = tc.reason
+
+			_, err := handler.AdjustPoints(ctx, &req)
+
+			var model *huma.ErrorModel
+			assert.True(t, errors.As(err, &model))
+			assert.Equal(t, tc.status, model.Status)
+		})
+	}
+}
+
 func TestUpdatePlayedGame_ConsecutiveDrop(t *testing.T) {
 	played := []PlayedGame{
 		validPlayedGame(),
@@ -361,18 +612,28 @@ func TestUpdatePlayedGame_ConsecutiveDrop(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	streakRepository := NewMockStreakRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), streakRepository, NewMockAdjustmentRepository(t), ledgerRepository, passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindOne", ctx, player.ID, played[1].ID).
-		Once().
+		Twice().
 		Return(&played[1], nil)
 
-	playedGameRepository.
-		On("FindLastNotReroll", ctx, player.ID).
+	streakRepository.
+		On("FindOne", ctx, player.ID).
 		Once().
-		Return(&played[0], nil)
+		Return(&Streak{PlayerID: player.ID, Drops: 1, Penalty: 1}, nil)
+
+	streakRepository.
+		On("Save", ctx, mock.MatchedBy(func(s *Streak) bool {
+			return s.Drops == 2 && s.Penalty == 2 && s.Completions == 0
+		})).
+		Once().
+		Return(nil)
 
 	playedGameRepository.
 		On("Update", ctx, mock.MatchedBy(func(p *PlayedGameUpdate) bool {
@@ -387,6 +648,28 @@ func TestUpdatePlayedGame_ConsecutiveDrop(t *testing.T) {
 		Once().
 		Return(played[1].ID, nil)
 
+	ledgerRepository.
+		On("Insert", ctx, []LedgerEntry{
+			{PlayerID: player.ID, PlayedGameID: played[1].ID, Delta: -1, Reason: LedgerReasonDropPenalty},
+			{PlayerID: player.ID, PlayedGameID: played[1].ID, Delta: -1, Reason: LedgerReasonDropStacking},
+		}).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.PlayedGameDropped
+		})).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.LeaderboardChanged && e.PlayerID == player.ID
+		})).
+		Once().
+		Return(nil)
+
 	newStatus := PlayedGameStatusDropped
 	req := RequestUpdatePlayedGame{}
 	req.PlayerID = player.ID
@@ -400,6 +683,79 @@ func TestUpdatePlayedGame_ConsecutiveDrop(t *testing.T) {
 	assert.Equal(t, played[1].ID, resp.Body.ID)
 }
 
+func TestUpdatePlayedGame_CompletionStreak(t *testing.T) {
+	player := validPlayer()
+	played := validPlayedGame()
+	played.PlayerID = player.ID
+	played.Status = PlayedGameStatusInProgress
+	played.Points = 3
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	streakRepository := NewMockStreakRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), streakRepository, NewMockAdjustmentRepository(t), ledgerRepository, passTx(t), publisher, Options{})
+
+	playedGameRepository.
+		On("FindOne", ctx, player.ID, played.ID).
+		Twice().
+		Return(&played, nil)
+
+	// no stored streak yet, two completions after a drop are replayed from history
+	streakRepository.
+		On("FindOne", ctx, player.ID).
+		Once().
+		Return(nil, ErrStreakNotFound)
+
+	history := []PlayedGame{validPlayedGame(), validPlayedGame(), validPlayedGame(), played}
+	for i, status := range []PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusCompleted, PlayedGameStatusCompleted} {
+		completedAt := time.Now().AddDate(0, 0, i-10)
+		history[i].ID = i + 100
+		history[i].Status = status
+		history[i].CompletedAt = &completedAt
+	}
+	playedGameRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return(history, nil)
+
+	streakRepository.
+		On("Save", ctx, mock.MatchedBy(func(s *Streak) bool {
+			return s.PlayerID == player.ID && s.Completions == 3 && s.BestCompletions == 3 && s.Penalty == 0
+		})).
+		Once().
+		Return(nil)
+
+	playedGameRepository.
+		On("Update", ctx, mock.MatchedBy(func(p *PlayedGameUpdate) bool {
+			return p.Points != nil && *p.Points == played.Points+DefaultStreakRules.BonusPoints
+		})).
+		Once().
+		Return(played.ID, nil)
+
+	ledgerRepository.
+		On("Insert", ctx, []LedgerEntry{
+			{PlayerID: player.ID, PlayedGameID: played.ID, Delta: played.Points, Reason: LedgerReasonCompletion},
+			{PlayerID: player.ID, PlayedGameID: played.ID, Delta: DefaultStreakRules.BonusPoints, Reason: LedgerReasonStreakBonus},
+		}).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.Anything).
+		Twice().
+		Return(nil)
+
+	newStatus := PlayedGameStatusCompleted
+	req := RequestUpdatePlayedGame{PlayerID: player.ID, GameID: played.ID}
+	req.Body.Status = &newStatus
+
+	_, err := handler.UpdatePlayedGame(ctx, &req)
+	assert.NoError(t, err)
+}
+
 func TestUpdatePlayedGame_Reroll(t *testing.T) {
 	played := []PlayedGame{
 		validPlayedGame(),
@@ -419,12 +775,13 @@ func TestUpdatePlayedGame_Reroll(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	playedGameRepository.
 		On("FindOne", ctx, player.ID, played[1].ID).
-		Once().
+		Twice().
 		Return(&played[1], nil)
 
 	playedGameRepository.
@@ -440,6 +797,20 @@ func TestUpdatePlayedGame_Reroll(t *testing.T) {
 		Once().
 		Return(played[1].ID, nil)
 
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.PlayedGameRerolled
+		})).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.LeaderboardChanged && e.PlayerID == player.ID
+		})).
+		Once().
+		Return(nil)
+
 	newStatus := PlayedGameStatusRerolled
 	req := RequestUpdatePlayedGame{}
 	req.PlayerID = player.ID
@@ -454,25 +825,20 @@ func TestUpdatePlayedGame_Reroll(t *testing.T) {
 }
 
 func TestContainsNonterminatedPlayed(t *testing.T) {
+
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
-
-	played := []PlayedGame{
-		validPlayedGame(),
-		validPlayedGame(),
-	}
+	publisher := NewMockEventPublisher(t)
 
 	player := validPlayer()
-	played[0].PlayerID = player.ID
-	played[1].PlayerID = player.ID
 
 	playedGameRepository.
-		On("FindAll", t.Context(), player.ID).
+		On("FindNonterminated", t.Context(), player.ID).
 		Once().
-		Return(played, nil)
+		Return(nil, ErrPlayedGameNotFound)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	err := handler.containsNonterminatedPlayed(t.Context(), player.ID)
 	assert.NoError(t, err)
@@ -483,24 +849,404 @@ func TestContainsNonterminatedPlayed_Nonterminated(t *testing.T) {
 	playerRepository := NewMockPlayerRepository(t)
 	gameRepository := NewMockGameRepository(t)
 	playedGameRepository := NewMockPlayedGameRepository(t)
-
-	played := []PlayedGame{
-		validPlayedGame(),
-		validPlayedGame(),
-	}
+	publisher := NewMockEventPublisher(t)
 
 	player := validPlayer()
-	played[0].PlayerID = player.ID
-	played[1].PlayerID = player.ID
-	played[1].Status = PlayedGameStatusAdded
+	played := validPlayedGame()
+	played.PlayerID = player.ID
+	played.Status = PlayedGameStatusAdded
 
 	playedGameRepository.
-		On("FindAll", t.Context(), player.ID).
+		On("FindNonterminated", t.Context(), player.ID).
 		Once().
-		Return(played, nil)
+		Return(&played, nil)
 
-	handler := NewHandler(playerRepository, gameRepository, playedGameRepository)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
 
 	err := handler.containsNonterminatedPlayed(t.Context(), player.ID)
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, 409, statusErr.GetStatus())
+}
+
+// TestCreatePlayedGame_Conflict covers a game added by another request after the check
+func TestCreatePlayedGame_Conflict(t *testing.T) {
+	g := game.Game{ID: 4, Points: 2, HoursToBeat: 3, Title: "Celeste"}
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
+
+	gameRepository.
+		On("FindOne", ctx, g.ID).
+		Once().
+		Return(&g, nil)
+
+	playedGameRepository.
+		On("FindNonterminated", ctx, player.ID).
+		Once().
+		Return(nil, ErrPlayedGameNotFound)
+
+	playedGameRepository.
+		On("Insert", ctx, mock.Anything).
+		Once().
+		Return(0, ErrNonterminatedExists)
+
+	publisher.AssertNotCalled(t, "Publish")
+
+	req := RequestCreatePlayedGame{PlayerID: player.ID}
+	req.Body.GameID = g.ID
+
+	_, err := handler.CreatePlayedGame(ctx, &req)
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, 409, statusErr.GetStatus())
+}
+
+// TestUpdatePlayedGame_StatusChanged covers a status changed by another request after the check
+func TestUpdatePlayedGame_StatusChanged(t *testing.T) {
+	player := validPlayer()
+	played := validPlayedGame()
+	played.PlayerID = player.ID
+	played.Status = PlayedGameStatusInProgress
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
+
+	playedGameRepository.
+		On("FindOne", ctx, player.ID, played.ID).
+		Once().
+		Return(&played, nil)
+
+	playedGameRepository.
+		On("Update", ctx, mock.MatchedBy(func(p *PlayedGameUpdate) bool {
+			return p.FromStatus != nil && *p.FromStatus == PlayedGameStatusInProgress
+		})).
+		Once().
+		Return(0, ErrPlayedGameStatusChanged)
+
+	publisher.AssertNotCalled(t, "Publish")
+
+	newStatus := PlayedGameStatusRerolled
+	req := RequestUpdatePlayedGame{PlayerID: player.ID, GameID: played.ID}
+	req.Body.Status = &newStatus
+
+	_, err := handler.UpdatePlayedGame(ctx, &req)
+	var statusErr huma.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, 409, statusErr.GetStatus())
+}
+
+func TestUpdatePlayedGame_PublishFails(t *testing.T) {
+	player := validPlayer()
+	played := validPlayedGame()
+	played.PlayerID = player.ID
+	played.Status = PlayedGameStatusInProgress
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playerRepository := NewMockPlayerRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	streakRepository := NewMockStreakRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), streakRepository, NewMockAdjustmentRepository(t), ledgerRepository, passTx(t), publisher, Options{})
+
+	streakRepository.
+		On("FindOne", ctx, player.ID).
+		Once().
+		Return(&Streak{PlayerID: player.ID}, nil)
+	streakRepository.
+		On("Save", ctx, mock.Anything).
+		Once().
+		Return(nil)
+	ledgerRepository.
+		On("Insert", ctx, mock.Anything).
+		Maybe().
+		Return(nil)
+
+	playedGameRepository.
+		On("FindOne", ctx, player.ID, played.ID).
+		Twice().
+		Return(&played, nil)
+
+	playedGameRepository.
+		On("Update", ctx, mock.AnythingOfType("*player.PlayedGameUpdate")).
+		Once().
+		Return(played.ID, nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool {
+			return e.Type == event.PlayedGameCompleted
+		})).
+		Once().
+		Return(errors.New("outbox is not available"))
+
+	newStatus := PlayedGameStatusCompleted
+	req := RequestUpdatePlayedGame{}
+	req.PlayerID = player.ID
+	req.GameID = played.ID
+	req.Body.Status = &newStatus
+
+	resp, err := handler.UpdatePlayedGame(ctx, &req)
 	assert.Error(t, err)
+	assert.Equal(t, nil, resp)
+}
+
+func TestExportPlayedGames(t *testing.T) {
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playerRepository := NewMockPlayerRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
+
+	started := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	playedGameRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return([]PlayedGame{{GameID: 3, Points: 2, Status: PlayedGameStatusCompleted, StartedAt: started}}, nil)
+
+	gameRepository.
+		On("FindAll", ctx).
+		Once().
+		Return([]game.Game{{ID: 3, Title: "Celeste", HoursToBeat: 8}}, nil)
+
+	resp, err := handler.ExportPlayedGames(ctx, &RequestExportPlayedGames{PlayerID: player.ID, Format: HistoryFormatCSV})
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.ContentType)
+	assert.Equal(t,
+		"title,status,points,rating,comment,started_at,completed_at,play_time,hours_to_beat,url\n"+
+			"Celeste,completed,2,,,2025-03-01T10:00:00Z,,,8,\n",
+		string(resp.Body),
+	)
+}
+
+func TestExportPlayedGames_OtherPlayer(t *testing.T) {
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	handler := NewHandler(NewMockPlayerRepository(t), NewMockGameRepository(t), playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), NewMockEventPublisher(t), Options{})
+
+	playedGameRepository.AssertNotCalled(t, "FindAll")
+
+	_, err := handler.ExportPlayedGames(ctx, &RequestExportPlayedGames{PlayerID: uuid.NewString(), Format: HistoryFormatJSON})
+	assert.Error(t, err)
+}
+
+func TestImportPlayedGames(t *testing.T) {
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID, IsAdmin: true})
+
+	playerRepository := NewMockPlayerRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	streakRepository := NewMockStreakRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), streakRepository, NewMockAdjustmentRepository(t), ledgerRepository, passTx(t), publisher, Options{})
+
+	data := "title,status,started_at,hours_to_beat\n" +
+		"Celeste,completed,2024-01-02,\n" +
+		"New Game,dropped,2024-02-02,10\n" +
+		"Celeste,rerolled,2024-03-02,\n"
+
+	celeste := game.Game{ID: 1, Title: "Celeste", Points: 3}
+	newGame := game.Game{ID: 2, Title: "New Game", Points: 3}
+
+	gameRepository.
+		On("FindByTitle", ctx, "Celeste").
+		Once().
+		Return(&celeste, nil)
+
+	gameRepository.
+		On("FindByTitle", ctx, "New Game").
+		Once().
+		Return(nil, game.ErrGameNotFound)
+
+	gameRepository.
+		On("Insert", ctx, mock.MatchedBy(func(g *game.Game) bool {
+			return g.Title == "New Game" && g.HoursToBeat == 10 && g.Points == 3
+		})).
+		Once().
+		Return(newGame.ID, nil)
+
+	gameRepository.
+		On("FindOne", ctx, newGame.ID).
+		Once().
+		Return(&newGame, nil)
+
+	var imported []PlayedGame
+	playedGameRepository.
+		On("Import", ctx, mock.MatchedBy(func(pg *PlayedGame) bool {
+			imported = append(imported, *pg)
+			return pg.PlayerID == player.ID
+		})).
+		Times(3).
+		Return(1, nil)
+
+	// the rerolled game has no points
+	var entries []LedgerEntry
+	ledgerRepository.
+		On("Insert", ctx, mock.MatchedBy(func(e []LedgerEntry) bool {
+			entries = append(entries, e...)
+			return true
+		})).
+		Twice().
+		Return(nil)
+
+	playedGameRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return(func(context.Context, string) ([]PlayedGame, error) { return imported, nil })
+
+	streakRepository.
+		On("Save", ctx, mock.MatchedBy(func(s *Streak) bool {
+			return s.PlayerID == player.ID && s.Completions == 0 && s.Drops == 1 && s.Penalty == 1
+		})).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool { return e.Type == event.GameCreated })).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.MatchedBy(func(e event.Event) bool { return e.Type == event.LeaderboardChanged })).
+		Once().
+		Return(nil)
+
+	resp, err := handler.ImportPlayedGames(ctx, &RequestImportPlayedGames{
+		PlayerID: player.ID,
+		Format:   HistoryFormatCSV,
+		RawBody:  []byte(data),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resp.Body.Imported)
+	assert.Equal(t, []string{"New Game"}, resp.Body.CreatedGames)
+	assert.Equal(t, []int{3, -1, 0}, []int{imported[0].Points, imported[1].Points, imported[2].Points})
+	assert.Equal(t, []int{1, 2, 1}, []int{imported[0].GameID, imported[1].GameID, imported[2].GameID})
+	assert.Equal(t, []int{3, -1}, []int{entries[0].Delta, entries[1].Delta})
+}
+
+// TestImportPlayedGames_NoPoints covers players crediting themselves with imported games
+func TestImportPlayedGames_NoPoints(t *testing.T) {
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	streakRepository := NewMockStreakRepository(t)
+	ledgerRepository := NewMockLedgerRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(NewMockPlayerRepository(t), gameRepository, playedGameRepository, NewMockBacklogRepository(t), streakRepository, NewMockAdjustmentRepository(t), ledgerRepository, passTx(t), publisher, Options{})
+
+	data := `[{"title": "Celeste", "status": "completed", "points": 1000, "started_at": "2024-01-02T00:00:00Z"}]`
+
+	gameRepository.
+		On("FindByTitle", ctx, "Celeste").
+		Once().
+		Return(&game.Game{ID: 1, Title: "Celeste", Points: 3}, nil)
+
+	playedGameRepository.
+		On("Import", ctx, mock.MatchedBy(func(pg *PlayedGame) bool { return pg.Points == 0 })).
+		Once().
+		Return(9, nil)
+
+	ledgerRepository.AssertNotCalled(t, "Insert")
+
+	playedGameRepository.
+		On("FindAll", ctx, player.ID).
+		Once().
+		Return([]PlayedGame{}, nil)
+
+	streakRepository.
+		On("Save", ctx, mock.Anything).
+		Once().
+		Return(nil)
+
+	publisher.
+		On("Publish", ctx, mock.Anything).
+		Once().
+		Return(nil)
+
+	_, err := handler.ImportPlayedGames(ctx, &RequestImportPlayedGames{
+		PlayerID: player.ID,
+		Format:   HistoryFormatJSON,
+		RawBody:  []byte(data),
+	})
+	assert.NoError(t, err)
+}
+
+func TestImportPlayedGames_InvalidRows(t *testing.T) {
+	player := validPlayer()
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: player.ID})
+
+	playerRepository := NewMockPlayerRepository(t)
+	gameRepository := NewMockGameRepository(t)
+	playedGameRepository := NewMockPlayedGameRepository(t)
+	publisher := NewMockEventPublisher(t)
+
+	handler := NewHandler(playerRepository, gameRepository, playedGameRepository, NewMockBacklogRepository(t), NewMockStreakRepository(t), NewMockAdjustmentRepository(t), NewMockLedgerRepository(t), passTx(t), publisher, Options{})
+
+	data := `[
+		{"title": "Celeste", "status": "completed", "started_at": "2024-01-02T00:00:00Z", "rating": 500},
+		{"title": "Unknown", "status": "dropped", "started_at": "2024-01-02T00:00:00Z", "hours_to_beat": 3},
+		{"title": "Celeste", "status": "in_progress", "started_at": "2024-01-02T00:00:00Z"}
+	]`
+
+	gameRepository.
+		On("FindByTitle", ctx, "Celeste").
+		Once().
+		Return(&game.Game{ID: 1, Title: "Celeste"}, nil)
+
+	gameRepository.
+		On("FindByTitle", ctx, "Unknown").
+		Once().
+		Return(nil, game.ErrGameNotFound)
+
+	gameRepository.AssertNotCalled(t, "Insert")
+	playedGameRepository.AssertNotCalled(t, "Import")
+	publisher.AssertNotCalled(t, "Publish")
+
+	_, err := handler.ImportPlayedGames(ctx, &RequestImportPlayedGames{
+		PlayerID: player.ID,
+		Format:   HistoryFormatJSON,
+		RawBody:  []byte(data),
+	})
+
+	var model *huma.ErrorModel
+	assert.True(t, errors.As(err, &model))
+	assert.Equal(t, 422, model.Status)
+	assert.Equal(t, 3, len(model.Errors))
+	assert.Equal(t, "body[1]", model.Errors[0].Location)
+	assert.Equal(t, ErrGameRating.Error(), model.Errors[0].Message)
+	assert.Equal(t, "body[2]", model.Errors[1].Location)
+	assert.Equal(t, "body[3]", model.Errors[2].Location)
+}
+
+// passTx returns a transactor which runs the function in the given context
+func passTx(t *testing.T) *MockTransactor {
+	tx := NewMockTransactor(t)
+	tx.
+		On("WithTx", mock.Anything, mock.Anything).
+		Maybe().
+		Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tx
 }
@@ -0,0 +1,313 @@
+package player
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+const (
+	HistoryFormatCSV  = "csv"
+	HistoryFormatJSON = "json"
+
+	// MaxHistoryRows limits the size of a single import
+	MaxHistoryRows = 1000
+
+	historyDateLayout = "2006-01-02"
+)
+
+var (
+	ErrHistoryFormat     = errors.New("unknown history format")
+	ErrHistoryTooLarge   = fmt.Errorf("history must not have more than %d rows", MaxHistoryRows)
+	ErrHistoryNoTitle    = errors.New("title is required")
+	ErrHistoryNoStarted  = errors.New("started_at is required")
+	ErrHistoryNotFinal   = errors.New("only completed, dropped or rerolled games can be imported")
+	ErrHistoryMissingCol = errors.New("csv header must contain title, status and started_at")
+)
+
+// historyColumns is the csv header of exported history, imported files may order columns freely
+var historyColumns = []string{
+	"title",
+	"status",
+	"points",
+	"rating",
+	"comment",
+	"started_at",
+	"completed_at",
+	"play_time",
+	"hours_to_beat",
+	"url",
+}
+
+// HistoryRow is a played game in a portable form, the game is referenced by title.
+// HoursToBeat and URL describe the game so it can be created on import.
+type HistoryRow struct {
+	Title       string                `json:"title"`
+	Status      PlayedGameStatus      `json:"status"`
+	Points      *int                  `json:"points,omitempty"`
+	Rating      *int                  `json:"rating,omitempty"`
+	Comment     *string               `json:"comment,omitempty"`
+	StartedAt   time.Time             `json:"started_at"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+	PlayTime    *types.DurationString `json:"play_time,omitempty"`
+	HoursToBeat *int                  `json:"hours_to_beat,omitempty"`
+	URL         *string               `json:"url,omitempty"`
+}
+
+// HistoryRowError is a problem of a single imported row, rows are numbered from 1
+type HistoryRowError struct {
+	Row int
+	Err error
+}
+
+func (e *HistoryRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *HistoryRowError) Unwrap() error {
+	return e.Err
+}
+
+func NewHistoryRow(pg *PlayedGame, g *game.Game) HistoryRow {
+	points := pg.Points
+	hours := g.HoursToBeat
+	return HistoryRow{
+		Title:       g.Title,
+		Status:      pg.Status,
+		Points:      &points,
+		Rating:      pg.Rating,
+		Comment:     pg.Comment,
+		StartedAt:   pg.StartedAt,
+		CompletedAt: pg.CompletedAt,
+		PlayTime:    pg.PlayTime,
+		HoursToBeat: &hours,
+		URL:         g.URL,
+	}
+}
+
+// Valid checks the row itself, the game is resolved by the caller
+func (r *HistoryRow) Valid() error {
+	if strings.TrimSpace(r.Title) == "" {
+		return ErrHistoryNoTitle
+	}
+	if r.StartedAt.IsZero() {
+		return ErrHistoryNoStarted
+	}
+	pg := PlayedGame{Status: r.Status}
+	if !pg.StatusTerminated() {
+		return ErrHistoryNotFinal
+	}
+	return nil
+}
+
+// PlayedGame builds the played game of the row, points default to the ones
+// the status would give when they are not set
+func (r *HistoryRow) PlayedGame(playerID string, g *game.Game) PlayedGame {
+	pg := PlayedGame{
+		PlayerID:    playerID,
+		GameID:      g.ID,
+		Rating:      r.Rating,
+		Comment:     r.Comment,
+		Status:      r.Status,
+		StartedAt:   r.StartedAt.UTC(),
+		CompletedAt: r.CompletedAt,
+		PlayTime:    r.PlayTime,
+	}
+	if pg.CompletedAt != nil {
+		completed := pg.CompletedAt.UTC()
+		pg.CompletedAt = &completed
+	}
+
+	switch {
+	case r.Points != nil:
+		pg.Points = *r.Points
+	case r.Status == PlayedGameStatusCompleted:
+		pg.Points = g.Points
+	case r.Status == PlayedGameStatusDropped:
+		pg.Points = -1
+	}
+	return pg
+}
+
+func EncodeHistory(w io.Writer, format string, rows []HistoryRow) error {
+	switch format {
+	case HistoryFormatJSON:
+		return json.NewEncoder(w).Encode(rows)
+	case HistoryFormatCSV:
+		return encodeHistoryCSV(w, rows)
+	}
+	return ErrHistoryFormat
+}
+
+// DecodeHistory parses rows of an import, an error of a whole file is returned as error
+// and errors of separate rows are returned as HistoryRowError list
+func DecodeHistory(data []byte, format string) ([]HistoryRow, []*HistoryRowError, error) {
+	switch format {
+	case HistoryFormatJSON:
+		var rows []HistoryRow
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, nil, err
+		}
+		if len(rows) > MaxHistoryRows {
+			return nil, nil, ErrHistoryTooLarge
+		}
+		return rows, nil, nil
+	case HistoryFormatCSV:
+		return decodeHistoryCSV(data)
+	}
+	return nil, nil, ErrHistoryFormat
+}
+
+func encodeHistoryCSV(w io.Writer, rows []HistoryRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(historyColumns); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		record := []string{
+			r.Title,
+			string(r.Status),
+			formatOptional(r.Points, strconv.Itoa),
+			formatOptional(r.Rating, strconv.Itoa),
+			formatOptional(r.Comment, func(s string) string { return s }),
+			r.StartedAt.UTC().Format(time.RFC3339),
+			formatOptional(r.CompletedAt, func(t time.Time) string { return t.UTC().Format(time.RFC3339) }),
+			formatOptional(r.PlayTime, types.DurationString.String),
+			formatOptional(r.HoursToBeat, strconv.Itoa),
+			formatOptional(r.URL, func(s string) string { return s }),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func decodeHistoryCSV(data []byte) ([]HistoryRow, []*HistoryRowError, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "status", "started_at"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, ErrHistoryMissingCol
+		}
+	}
+
+	var rows []HistoryRow
+	var rowErrs []*HistoryRowError
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("csv: %w", err)
+		}
+		if n > MaxHistoryRows {
+			return nil, nil, ErrHistoryTooLarge
+		}
+
+		row, err := historyRowFromRecord(cols, record)
+		if err != nil {
+			rowErrs = append(rowErrs, &HistoryRowError{Row: n, Err: err})
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrs, nil
+}
+
+func historyRowFromRecord(cols map[string]int, record []string) (HistoryRow, error) {
+	get := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var row HistoryRow
+	var err error
+
+	row.Title = get("title")
+	row.Status = PlayedGameStatus(strings.ToLower(get("status")))
+	if row.Points, err = parseOptional(get("points"), strconv.Atoi); err != nil {
+		return row, fmt.Errorf("points: %w", err)
+	}
+	if row.Rating, err = parseOptional(get("rating"), strconv.Atoi); err != nil {
+		return row, fmt.Errorf("rating: %w", err)
+	}
+	if comment := get("comment"); comment != "" {
+		row.Comment = &comment
+	}
+	if started := get("started_at"); started != "" {
+		if row.StartedAt, err = parseHistoryTime(started); err != nil {
+			return row, fmt.Errorf("started_at: %w", err)
+		}
+	}
+	if row.CompletedAt, err = parseOptional(get("completed_at"), parseHistoryTime); err != nil {
+		return row, fmt.Errorf("completed_at: %w", err)
+	}
+	playTime, err := parseOptional(get("play_time"), time.ParseDuration)
+	if err != nil {
+		return row, fmt.Errorf("play_time: %w", err)
+	}
+	if playTime != nil {
+		ds := types.NewDurationString(*playTime)
+		row.PlayTime = &ds
+	}
+	if row.HoursToBeat, err = parseOptional(get("hours_to_beat"), strconv.Atoi); err != nil {
+		return row, fmt.Errorf("hours_to_beat: %w", err)
+	}
+	if url := get("url"); url != "" {
+		row.URL = &url
+	}
+	return row, nil
+}
+
+// parseHistoryTime accepts RFC3339 timestamps and plain dates which spreadsheets export
+func parseHistoryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(historyDateLayout, s)
+}
+
+func parseOptional[T any](s string, parse func(string) (T, error)) (*T, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func formatOptional[T any](v *T, format func(T) string) string {
+	if v == nil {
+		return ""
+	}
+	return format(*v)
+}
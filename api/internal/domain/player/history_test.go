@@ -0,0 +1,128 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	started := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(48 * time.Hour)
+	rating := 90
+	comment := "great, \"really\""
+	playTime := types.NewDurationString(20 * time.Hour)
+	url := "https://example.com/game"
+
+	played := PlayedGame{
+		GameID:      3,
+		Points:      4,
+		Rating:      &rating,
+		Comment:     &comment,
+		Status:      PlayedGameStatusCompleted,
+		StartedAt:   started,
+		CompletedAt: &completed,
+		PlayTime:    &playTime,
+	}
+	g := game.Game{ID: 3, Title: "Hollow Knight", HoursToBeat: 27, Points: 7, URL: &url}
+	rows := []HistoryRow{NewHistoryRow(&played, &g)}
+
+	for _, format := range []string{HistoryFormatCSV, HistoryFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, EncodeHistory(&buf, format, rows))
+
+			decoded, rowErrs, err := DecodeHistory(buf.Bytes(), format)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, len(rowErrs))
+			assert.Equal(t, rows, decoded)
+		})
+	}
+}
+
+func TestDecodeHistoryCSV(t *testing.T) {
+	data := []byte("Status,Title,Started_At,Rating\n" +
+		"completed,Celeste,2024-01-02,80\n" +
+		"dropped,Dark Souls,yesterday,\n" +
+		"rerolled,Hades,2024-02-01T10:00:00Z,good\n")
+
+	rows, rowErrs, err := DecodeHistory(data, HistoryFormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, "Celeste", rows[0].Title)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), rows[0].StartedAt)
+	assert.Equal(t, 80, *rows[0].Rating)
+
+	assert.Equal(t, 2, len(rowErrs))
+	assert.Equal(t, 2, rowErrs[0].Row)
+	assert.Equal(t, 3, rowErrs[1].Row)
+}
+
+func TestDecodeHistoryCSV_MissingColumn(t *testing.T) {
+	_, _, err := DecodeHistory([]byte("title,status\nCeleste,completed\n"), HistoryFormatCSV)
+	assert.IsError(t, err, ErrHistoryMissingCol)
+}
+
+func TestHistoryRowValid(t *testing.T) {
+	tests := []struct {
+		name string
+		row  HistoryRow
+		err  error
+	}{
+		{
+			name: "valid",
+			row:  HistoryRow{Title: "Celeste", Status: PlayedGameStatusDropped, StartedAt: time.Now()},
+		},
+		{
+			name: "no title",
+			row:  HistoryRow{Title: " ", Status: PlayedGameStatusDropped, StartedAt: time.Now()},
+			err:  ErrHistoryNoTitle,
+		},
+		{
+			name: "no started",
+			row:  HistoryRow{Title: "Celeste", Status: PlayedGameStatusDropped},
+			err:  ErrHistoryNoStarted,
+		},
+		{
+			name: "in progress",
+			row:  HistoryRow{Title: "Celeste", Status: PlayedGameStatusInProgress, StartedAt: time.Now()},
+			err:  ErrHistoryNotFinal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.row.Valid()
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsError(t, err, tt.err)
+		})
+	}
+}
+
+func TestHistoryRowPlayedGame_DefaultPoints(t *testing.T) {
+	g := game.Game{ID: 2, Points: 5}
+	explicit := 9
+
+	tests := []struct {
+		status PlayedGameStatus
+		points *int
+		want   int
+	}{
+		{status: PlayedGameStatusCompleted, want: 5},
+		{status: PlayedGameStatusDropped, want: -1},
+		{status: PlayedGameStatusRerolled, want: 0},
+		{status: PlayedGameStatusCompleted, points: &explicit, want: 9},
+	}
+	for _, tt := range tests {
+		row := HistoryRow{Status: tt.status, Points: tt.points, StartedAt: time.Now()}
+		pg := row.PlayedGame("player", &g)
+		assert.Equal(t, tt.want, pg.Points)
+		assert.Equal(t, 2, pg.GameID)
+	}
+}
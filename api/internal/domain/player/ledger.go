@@ -0,0 +1,98 @@
+package player
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+type LedgerReason string
+
+const (
+	LedgerReasonCompletion     LedgerReason = "completion"
+	LedgerReasonStreakBonus    LedgerReason = "streak_bonus"
+	LedgerReasonDropPenalty    LedgerReason = "drop_penalty"
+	LedgerReasonDropStacking   LedgerReason = "drop_stacking"
+	LedgerReasonAdjustment     LedgerReason = "adjustment"
+	LedgerReasonChallengeBonus LedgerReason = "challenge_bonus"
+	LedgerReasonImport         LedgerReason = "import"
+	// LedgerReasonHistory entries are derived from played games, by the backfill
+	// of the ledger and by corrections of a rebuild
+	LedgerReasonHistory LedgerReason = "history"
+)
+
+// LedgerEntry is a change of points of a player, entries are only appended.
+// A played game counts in totals once it is finished so entries of a played game
+// sum up to its points when it is finished and to zero before.
+type LedgerEntry struct {
+	ID           int          `json:"id"`
+	PlayerID     string       `json:"player_id"`
+	PlayedGameID int          `json:"played_game_id"`
+	Delta        int          `json:"delta"`
+	Reason       LedgerReason `json:"reason"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// LedgerMismatch is a played game whose entries do not sum up to its points
+type LedgerMismatch struct {
+	PlayedGameID int
+	Expected     int
+	Actual       int
+}
+
+func newLedgerEntry(played *PlayedGame, delta int, reason LedgerReason) LedgerEntry {
+	return LedgerEntry{
+		PlayerID:     played.PlayerID,
+		PlayedGameID: played.ID,
+		Delta:        delta,
+		Reason:       reason,
+	}
+}
+
+// ledgerEntries drops entries which do not change points
+func ledgerEntries(entries ...LedgerEntry) []LedgerEntry {
+	return slices.DeleteFunc(entries, func(e LedgerEntry) bool {
+		return e.Delta == 0
+	})
+}
+
+// LedgerCorrections returns entries which bring sums of mismatching played games
+// to their expected points, existing entries are kept for the audit trail
+func LedgerCorrections(playerID string, mismatches []LedgerMismatch) []LedgerEntry {
+	out := make([]LedgerEntry, 0, len(mismatches))
+	for _, m := range mismatches {
+		played := PlayedGame{ID: m.PlayedGameID, PlayerID: playerID}
+		out = append(out, newLedgerEntry(&played, m.Expected-m.Actual, LedgerReasonHistory))
+	}
+	return ledgerEntries(out...)
+}
+
+// VerifyLedger compares entries with points of played games, entries of
+// unknown played games are reported with zero expected points
+func VerifyLedger(played []PlayedGame, entries []LedgerEntry) []LedgerMismatch {
+	actual := make(map[int]int, len(played))
+	for _, e := range entries {
+		actual[e.PlayedGameID] += e.Delta
+	}
+
+	out := make([]LedgerMismatch, 0)
+	for _, pg := range played {
+		expected := 0
+		if pg.StatusTerminated() {
+			expected = pg.Points
+		}
+		if actual[pg.ID] != expected {
+			out = append(out, LedgerMismatch{PlayedGameID: pg.ID, Expected: expected, Actual: actual[pg.ID]})
+		}
+		delete(actual, pg.ID)
+	}
+	for id, sum := range actual {
+		if sum != 0 {
+			out = append(out, LedgerMismatch{PlayedGameID: id, Actual: sum})
+		}
+	}
+	slices.SortFunc(out, func(a, b LedgerMismatch) int {
+		return cmp.Compare(a.PlayedGameID, b.PlayedGameID)
+	})
+	return out
+}
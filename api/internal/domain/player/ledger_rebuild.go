@@ -0,0 +1,87 @@
+package player
+
+import (
+	"context"
+	"fmt"
+)
+
+type LedgerRebuildRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error)
+	Insert(ctx context.Context, entries []LedgerEntry) error
+}
+
+// LedgerCheck is the result of checking the ledger of a player against played games
+type LedgerCheck struct {
+	PlayerID    string           `json:"player_id"`
+	Username    string           `json:"username"`
+	PlayedGames int              `json:"played_games"`
+	Mismatches  []LedgerMismatch `json:"mismatches"`
+	Rebuilt     bool             `json:"rebuilt"`
+}
+
+// LedgerRebuilder corrects the ledger of players whose entries do not sum up
+// to points of their played games
+type LedgerRebuilder struct {
+	playedGameRepository PlayedGameRepository
+	ledgerRepository     LedgerRebuildRepository
+	tx                   Transactor
+}
+
+func NewLedgerRebuilder(
+	playedGameRepository PlayedGameRepository,
+	ledgerRepository LedgerRebuildRepository,
+	tx Transactor,
+) *LedgerRebuilder {
+	return &LedgerRebuilder{
+		playedGameRepository: playedGameRepository,
+		ledgerRepository:     ledgerRepository,
+		tx:                   tx,
+	}
+}
+
+// Rebuild checks the ledger of the player and appends correcting entries for
+// played games which do not match, with verify it only checks
+func (r *LedgerRebuilder) Rebuild(ctx context.Context, p *Player, verify bool) (*LedgerCheck, error) {
+	check := LedgerCheck{PlayerID: p.ID, Username: p.Username}
+
+	err := r.tx.WithTx(ctx, func(ctx context.Context) error {
+		played, mismatches, err := r.mismatches(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		check.PlayedGames = len(played)
+		check.Mismatches = mismatches
+		if len(mismatches) == 0 || verify {
+			return nil
+		}
+
+		if err := r.ledgerRepository.Insert(ctx, LedgerCorrections(p.ID, mismatches)); err != nil {
+			return fmt.Errorf("ledger insert: %w", err)
+		}
+		if _, mismatches, err = r.mismatches(ctx, p.ID); err != nil {
+			return err
+		}
+		if len(mismatches) > 0 {
+			return fmt.Errorf("%d played games do not match after rebuild", len(mismatches))
+		}
+		check.Rebuilt = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// mismatches returns played games of the player and those whose entries do not sum up to their points
+func (r *LedgerRebuilder) mismatches(ctx context.Context, playerID string) ([]PlayedGame, []LedgerMismatch, error) {
+	played, err := r.playedGameRepository.FindAll(ctx, playerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("played games find: %w", err)
+	}
+	entries, err := r.ledgerRepository.FindAll(ctx, playerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ledger find: %w", err)
+	}
+	return played, VerifyLedger(played, entries), nil
+}
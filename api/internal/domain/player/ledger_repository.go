@@ -0,0 +1,112 @@
+package player
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	TablePointsLedger = "points_ledger"
+
+	ledgerColumns string = "id, player_id, played_game_id, delta, reason, created_at"
+)
+
+type PGLedgerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGLedgerRepository(pool *pgxpool.Pool) *PGLedgerRepository {
+	return &PGLedgerRepository{
+		pool: pool,
+	}
+}
+
+// FindAll returns entries of the player in order they were written
+func (r *PGLedgerRepository) FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error) {
+	query, args, err := sq.Select(ledgerColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePointsLedger).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LedgerEntry, 0)
+	for rows.Next() {
+		var e LedgerEntry
+		if err := rows.Scan(&e.ID, &e.PlayerID, &e.PlayedGameID, &e.Delta, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *PGLedgerRepository) Insert(ctx context.Context, entries []LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	insBuild := sq.Insert(TablePointsLedger).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "played_game_id", "delta", "reason")
+	for _, e := range entries {
+		insBuild = insBuild.Values(e.PlayerID, e.PlayedGameID, e.Delta, e.Reason)
+	}
+
+	query, args, err := insBuild.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// Leaderboard returns totals of all players from the ledger, the best first
+func (r *PGLedgerRepository) Leaderboard(ctx context.Context) ([]LeaderboardPlayer, error) {
+	query, args, err := sq.Select(
+		"p.id",
+		"p.username",
+		"COALESCE(l.total, 0) AS total",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'completed')",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'dropped')",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'rerolled')",
+	).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePlayer+" p").
+		LeftJoin("(SELECT player_id, SUM(delta) AS total FROM "+TablePointsLedger+" GROUP BY player_id) l ON l.player_id = p.id").
+		LeftJoin(TablePlayedGame+" pg ON pg.player_id = p.id").
+		GroupBy("p.id", "p.username", "l.total").
+		OrderBy("total DESC", "lower(p.username) COLLATE \"C\"", "p.id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LeaderboardPlayer, 0)
+	for rows.Next() {
+		var p LeaderboardPlayer
+		if err := rows.Scan(&p.PlayerID, &p.Username, &p.Total, &p.Completed, &p.Dropped, &p.Rerolled); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
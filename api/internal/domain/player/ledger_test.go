@@ -0,0 +1,96 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedgerCorrections(t *testing.T) {
+	played := []PlayedGame{
+		{ID: 1, PlayerID: "p", Points: 3, Status: PlayedGameStatusCompleted},
+		{ID: 2, PlayerID: "p", Points: -2, Status: PlayedGameStatusDropped},
+		{ID: 3, PlayerID: "p", Points: 0, Status: PlayedGameStatusRerolled},
+		{ID: 4, PlayerID: "p", Points: 5, Status: PlayedGameStatusInProgress},
+	}
+	entries := []LedgerEntry{
+		{PlayerID: "p", PlayedGameID: 1, Delta: 3, Reason: LedgerReasonCompletion},
+		{PlayerID: "p", PlayedGameID: 2, Delta: -1, Reason: LedgerReasonDropPenalty},
+		{PlayerID: "p", PlayedGameID: 4, Delta: 5, Reason: LedgerReasonCompletion},
+	}
+
+	corrections := LedgerCorrections("p", VerifyLedger(played, entries))
+	assert.Equal(t, []LedgerEntry{
+		{PlayerID: "p", PlayedGameID: 2, Delta: -1, Reason: LedgerReasonHistory},
+		{PlayerID: "p", PlayedGameID: 4, Delta: -5, Reason: LedgerReasonHistory},
+	}, corrections)
+	assert.Equal(t, []LedgerMismatch{}, VerifyLedger(played, append(entries, corrections...)))
+}
+
+func TestVerifyLedger(t *testing.T) {
+	played := []PlayedGame{
+		{ID: 1, Points: 4, Status: PlayedGameStatusCompleted},
+		{ID: 2, Points: -3, Status: PlayedGameStatusDropped},
+		{ID: 3, Points: 5, Status: PlayedGameStatusInProgress},
+	}
+	entries := []LedgerEntry{
+		{PlayedGameID: 1, Delta: 3, Reason: LedgerReasonCompletion},
+		{PlayedGameID: 1, Delta: 1, Reason: LedgerReasonStreakBonus},
+		{PlayedGameID: 2, Delta: -1, Reason: LedgerReasonDropPenalty},
+		{PlayedGameID: 3, Delta: 5, Reason: LedgerReasonCompletion},
+		{PlayedGameID: 9, Delta: 2, Reason: LedgerReasonAdjustment},
+	}
+
+	assert.Equal(t, []LedgerMismatch{
+		{PlayedGameID: 2, Expected: -3, Actual: -1},
+		{PlayedGameID: 3, Expected: 0, Actual: 5},
+		{PlayedGameID: 9, Expected: 0, Actual: 2},
+	}, VerifyLedger(played, entries))
+}
+
+func TestLedgerRebuilder(t *testing.T) {
+	playedRepo := NewMockPlayedGameRepository(t)
+	ledgerRepo := NewMockLedgerRebuildRepository(t)
+	rebuilder := NewLedgerRebuilder(playedRepo, ledgerRepo, passTx(t))
+
+	p := Player{ID: "p", Username: "test"}
+	played := []PlayedGame{
+		{ID: 1, PlayerID: "p", Points: 3, Status: PlayedGameStatusCompleted},
+		{ID: 2, PlayerID: "p", Points: -1, Status: PlayedGameStatusDropped},
+	}
+	entries := []LedgerEntry{{PlayerID: "p", PlayedGameID: 1, Delta: 3, Reason: LedgerReasonCompletion}}
+	correction := LedgerEntry{PlayerID: "p", PlayedGameID: 2, Delta: -1, Reason: LedgerReasonHistory}
+
+	playedRepo.On("FindAll", mock.Anything, "p").Twice().Return(played, nil)
+	ledgerRepo.On("FindAll", mock.Anything, "p").Once().Return(entries, nil)
+	// existing entries are kept, only the difference is appended
+	ledgerRepo.On("Insert", mock.Anything, []LedgerEntry{correction}).Once().Return(nil)
+	ledgerRepo.On("FindAll", mock.Anything, "p").Once().Return(append(entries, correction), nil)
+
+	check, err := rebuilder.Rebuild(t.Context(), &p, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &LedgerCheck{
+		PlayerID:    "p",
+		Username:    "test",
+		PlayedGames: 2,
+		Mismatches:  []LedgerMismatch{{PlayedGameID: 2, Expected: -1}},
+		Rebuilt:     true,
+	}, check)
+}
+
+func TestLedgerRebuilder_Verify(t *testing.T) {
+	playedRepo := NewMockPlayedGameRepository(t)
+	ledgerRepo := NewMockLedgerRebuildRepository(t)
+	rebuilder := NewLedgerRebuilder(playedRepo, ledgerRepo, passTx(t))
+
+	played := []PlayedGame{{ID: 1, PlayerID: "p", Points: 3, Status: PlayedGameStatusCompleted}}
+	playedRepo.On("FindAll", mock.Anything, "p").Once().Return(played, nil)
+	ledgerRepo.On("FindAll", mock.Anything, "p").Once().Return([]LedgerEntry{}, nil)
+	ledgerRepo.AssertNotCalled(t, "Insert")
+
+	check, err := rebuilder.Rebuild(t.Context(), &Player{ID: "p"}, true)
+	assert.NoError(t, err)
+	assert.False(t, check.Rebuilt)
+	assert.Equal(t, []LedgerMismatch{{PlayedGameID: 1, Expected: 3}}, check.Mismatches)
+}
@@ -7,7 +7,9 @@ package player
 import (
 	"context"
 
+	"github.com/lardira/playtrack/internal/domain/backlog"
 	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/pkg/event"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -395,12 +397,12 @@ func (_c *MockPlayedGameRepository_FindAll_Call) RunAndReturn(run func(ctx conte
 	return _c
 }
 
-// FindLastNotReroll provides a mock function for the type MockPlayedGameRepository
-func (_mock *MockPlayedGameRepository) FindLastNotReroll(ctx context.Context, playerID string) (*PlayedGame, error) {
+// FindNonterminated provides a mock function for the type MockPlayedGameRepository
+func (_mock *MockPlayedGameRepository) FindNonterminated(ctx context.Context, playerID string) (*PlayedGame, error) {
 	ret := _mock.Called(ctx, playerID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for FindLastNotReroll")
+		panic("no return value specified for FindNonterminated")
 	}
 
 	var r0 *PlayedGame
@@ -423,19 +425,19 @@ func (_mock *MockPlayedGameRepository) FindLastNotReroll(ctx context.Context, pl
 	return r0, r1
 }
 
-// MockPlayedGameRepository_FindLastNotReroll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindLastNotReroll'
-type MockPlayedGameRepository_FindLastNotReroll_Call struct {
+// MockPlayedGameRepository_FindNonterminated_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindNonterminated'
+type MockPlayedGameRepository_FindNonterminated_Call struct {
 	*mock.Call
 }
 
-// FindLastNotReroll is a helper method to define mock.On call
+// FindNonterminated is a helper method to define mock.On call
 //   - ctx context.Context
 //   - playerID string
-func (_e *MockPlayedGameRepository_Expecter) FindLastNotReroll(ctx interface{}, playerID interface{}) *MockPlayedGameRepository_FindLastNotReroll_Call {
-	return &MockPlayedGameRepository_FindLastNotReroll_Call{Call: _e.mock.On("FindLastNotReroll", ctx, playerID)}
+func (_e *MockPlayedGameRepository_Expecter) FindNonterminated(ctx interface{}, playerID interface{}) *MockPlayedGameRepository_FindNonterminated_Call {
+	return &MockPlayedGameRepository_FindNonterminated_Call{Call: _e.mock.On("FindNonterminated", ctx, playerID)}
 }
 
-func (_c *MockPlayedGameRepository_FindLastNotReroll_Call) Run(run func(ctx context.Context, playerID string)) *MockPlayedGameRepository_FindLastNotReroll_Call {
+func (_c *MockPlayedGameRepository_FindNonterminated_Call) Run(run func(ctx context.Context, playerID string)) *MockPlayedGameRepository_FindNonterminated_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -453,12 +455,12 @@ func (_c *MockPlayedGameRepository_FindLastNotReroll_Call) Run(run func(ctx cont
 	return _c
 }
 
-func (_c *MockPlayedGameRepository_FindLastNotReroll_Call) Return(playedGame *PlayedGame, err error) *MockPlayedGameRepository_FindLastNotReroll_Call {
+func (_c *MockPlayedGameRepository_FindNonterminated_Call) Return(playedGame *PlayedGame, err error) *MockPlayedGameRepository_FindNonterminated_Call {
 	_c.Call.Return(playedGame, err)
 	return _c
 }
 
-func (_c *MockPlayedGameRepository_FindLastNotReroll_Call) RunAndReturn(run func(ctx context.Context, playerID string) (*PlayedGame, error)) *MockPlayedGameRepository_FindLastNotReroll_Call {
+func (_c *MockPlayedGameRepository_FindNonterminated_Call) RunAndReturn(run func(ctx context.Context, playerID string) (*PlayedGame, error)) *MockPlayedGameRepository_FindNonterminated_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -537,6 +539,72 @@ func (_c *MockPlayedGameRepository_FindOne_Call) RunAndReturn(run func(ctx conte
 	return _c
 }
 
+// Import provides a mock function for the type MockPlayedGameRepository
+func (_mock *MockPlayedGameRepository) Import(ctx context.Context, game *PlayedGame) (int, error) {
+	ret := _mock.Called(ctx, game)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *PlayedGame) (int, error)); ok {
+		return returnFunc(ctx, game)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *PlayedGame) int); ok {
+		r0 = returnFunc(ctx, game)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *PlayedGame) error); ok {
+		r1 = returnFunc(ctx, game)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPlayedGameRepository_Import_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Import'
+type MockPlayedGameRepository_Import_Call struct {
+	*mock.Call
+}
+
+// Import is a helper method to define mock.On call
+//   - ctx context.Context
+//   - game *PlayedGame
+func (_e *MockPlayedGameRepository_Expecter) Import(ctx interface{}, game interface{}) *MockPlayedGameRepository_Import_Call {
+	return &MockPlayedGameRepository_Import_Call{Call: _e.mock.On("Import", ctx, game)}
+}
+
+func (_c *MockPlayedGameRepository_Import_Call) Run(run func(ctx context.Context, game *PlayedGame)) *MockPlayedGameRepository_Import_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *PlayedGame
+		if args[1] != nil {
+			arg1 = args[1].(*PlayedGame)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPlayedGameRepository_Import_Call) Return(n int, err error) *MockPlayedGameRepository_Import_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockPlayedGameRepository_Import_Call) RunAndReturn(run func(ctx context.Context, game *PlayedGame) (int, error)) *MockPlayedGameRepository_Import_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Insert provides a mock function for the type MockPlayedGameRepository
 func (_mock *MockPlayedGameRepository) Insert(ctx context.Context, player *PlayedGame) (int, error) {
 	ret := _mock.Called(ctx, player)
@@ -696,6 +764,136 @@ func (_m *MockGameRepository) EXPECT() *MockGameRepository_Expecter {
 	return &MockGameRepository_Expecter{mock: &_m.Mock}
 }
 
+// FindAll provides a mock function for the type MockGameRepository
+func (_mock *MockGameRepository) FindAll(ctx context.Context) ([]game.Game, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []game.Game
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]game.Game, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []game.Game); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]game.Game)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGameRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type MockGameRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockGameRepository_Expecter) FindAll(ctx interface{}) *MockGameRepository_FindAll_Call {
+	return &MockGameRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx)}
+}
+
+func (_c *MockGameRepository_FindAll_Call) Run(run func(ctx context.Context)) *MockGameRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockGameRepository_FindAll_Call) Return(games []game.Game, err error) *MockGameRepository_FindAll_Call {
+	_c.Call.Return(games, err)
+	return _c
+}
+
+func (_c *MockGameRepository_FindAll_Call) RunAndReturn(run func(ctx context.Context) ([]game.Game, error)) *MockGameRepository_FindAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByTitle provides a mock function for the type MockGameRepository
+func (_mock *MockGameRepository) FindByTitle(ctx context.Context, title string) (*game.Game, error) {
+	ret := _mock.Called(ctx, title)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByTitle")
+	}
+
+	var r0 *game.Game
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*game.Game, error)); ok {
+		return returnFunc(ctx, title)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *game.Game); ok {
+		r0 = returnFunc(ctx, title)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*game.Game)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, title)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGameRepository_FindByTitle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByTitle'
+type MockGameRepository_FindByTitle_Call struct {
+	*mock.Call
+}
+
+// FindByTitle is a helper method to define mock.On call
+//   - ctx context.Context
+//   - title string
+func (_e *MockGameRepository_Expecter) FindByTitle(ctx interface{}, title interface{}) *MockGameRepository_FindByTitle_Call {
+	return &MockGameRepository_FindByTitle_Call{Call: _e.mock.On("FindByTitle", ctx, title)}
+}
+
+func (_c *MockGameRepository_FindByTitle_Call) Run(run func(ctx context.Context, title string)) *MockGameRepository_FindByTitle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockGameRepository_FindByTitle_Call) Return(game1 *game.Game, err error) *MockGameRepository_FindByTitle_Call {
+	_c.Call.Return(game1, err)
+	return _c
+}
+
+func (_c *MockGameRepository_FindByTitle_Call) RunAndReturn(run func(ctx context.Context, title string) (*game.Game, error)) *MockGameRepository_FindByTitle_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindOne provides a mock function for the type MockGameRepository
 func (_mock *MockGameRepository) FindOne(ctx context.Context, id int) (*game.Game, error) {
 	ret := _mock.Called(ctx, id)
@@ -763,3 +961,1074 @@ func (_c *MockGameRepository_FindOne_Call) RunAndReturn(run func(ctx context.Con
 	_c.Call.Return(run)
 	return _c
 }
+
+// Insert provides a mock function for the type MockGameRepository
+func (_mock *MockGameRepository) Insert(ctx context.Context, game1 *game.Game) (int, error) {
+	ret := _mock.Called(ctx, game1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *game.Game) (int, error)); ok {
+		return returnFunc(ctx, game1)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *game.Game) int); ok {
+		r0 = returnFunc(ctx, game1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *game.Game) error); ok {
+		r1 = returnFunc(ctx, game1)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGameRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockGameRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - game1 *game.Game
+func (_e *MockGameRepository_Expecter) Insert(ctx interface{}, game1 interface{}) *MockGameRepository_Insert_Call {
+	return &MockGameRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, game1)}
+}
+
+func (_c *MockGameRepository_Insert_Call) Run(run func(ctx context.Context, game1 *game.Game)) *MockGameRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *game.Game
+		if args[1] != nil {
+			arg1 = args[1].(*game.Game)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockGameRepository_Insert_Call) Return(n int, err error) *MockGameRepository_Insert_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockGameRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, game1 *game.Game) (int, error)) *MockGameRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStreakRepository creates a new instance of MockStreakRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStreakRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStreakRepository {
+	mock := &MockStreakRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockStreakRepository is an autogenerated mock type for the StreakRepository type
+type MockStreakRepository struct {
+	mock.Mock
+}
+
+type MockStreakRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStreakRepository) EXPECT() *MockStreakRepository_Expecter {
+	return &MockStreakRepository_Expecter{mock: &_m.Mock}
+}
+
+// FindOne provides a mock function for the type MockStreakRepository
+func (_mock *MockStreakRepository) FindOne(ctx context.Context, playerID string) (*Streak, error) {
+	ret := _mock.Called(ctx, playerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOne")
+	}
+
+	var r0 *Streak
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*Streak, error)); ok {
+		return returnFunc(ctx, playerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *Streak); ok {
+		r0 = returnFunc(ctx, playerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Streak)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, playerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStreakRepository_FindOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindOne'
+type MockStreakRepository_FindOne_Call struct {
+	*mock.Call
+}
+
+// FindOne is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+func (_e *MockStreakRepository_Expecter) FindOne(ctx interface{}, playerID interface{}) *MockStreakRepository_FindOne_Call {
+	return &MockStreakRepository_FindOne_Call{Call: _e.mock.On("FindOne", ctx, playerID)}
+}
+
+func (_c *MockStreakRepository_FindOne_Call) Run(run func(ctx context.Context, playerID string)) *MockStreakRepository_FindOne_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStreakRepository_FindOne_Call) Return(streak *Streak, err error) *MockStreakRepository_FindOne_Call {
+	_c.Call.Return(streak, err)
+	return _c
+}
+
+func (_c *MockStreakRepository_FindOne_Call) RunAndReturn(run func(ctx context.Context, playerID string) (*Streak, error)) *MockStreakRepository_FindOne_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function for the type MockStreakRepository
+func (_mock *MockStreakRepository) Save(ctx context.Context, s *Streak) error {
+	ret := _mock.Called(ctx, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Streak) error); ok {
+		r0 = returnFunc(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockStreakRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockStreakRepository_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - s *Streak
+func (_e *MockStreakRepository_Expecter) Save(ctx interface{}, s interface{}) *MockStreakRepository_Save_Call {
+	return &MockStreakRepository_Save_Call{Call: _e.mock.On("Save", ctx, s)}
+}
+
+func (_c *MockStreakRepository_Save_Call) Run(run func(ctx context.Context, s *Streak)) *MockStreakRepository_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Streak
+		if args[1] != nil {
+			arg1 = args[1].(*Streak)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStreakRepository_Save_Call) Return(err error) *MockStreakRepository_Save_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockStreakRepository_Save_Call) RunAndReturn(run func(ctx context.Context, s *Streak) error) *MockStreakRepository_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAdjustmentRepository creates a new instance of MockAdjustmentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAdjustmentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAdjustmentRepository {
+	mock := &MockAdjustmentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAdjustmentRepository is an autogenerated mock type for the AdjustmentRepository type
+type MockAdjustmentRepository struct {
+	mock.Mock
+}
+
+type MockAdjustmentRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAdjustmentRepository) EXPECT() *MockAdjustmentRepository_Expecter {
+	return &MockAdjustmentRepository_Expecter{mock: &_m.Mock}
+}
+
+// FindAll provides a mock function for the type MockAdjustmentRepository
+func (_mock *MockAdjustmentRepository) FindAll(ctx context.Context, playedGameID int) ([]Adjustment, error) {
+	ret := _mock.Called(ctx, playedGameID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []Adjustment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]Adjustment, error)); ok {
+		return returnFunc(ctx, playedGameID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []Adjustment); ok {
+		r0 = returnFunc(ctx, playedGameID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Adjustment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, playedGameID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdjustmentRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type MockAdjustmentRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playedGameID int
+func (_e *MockAdjustmentRepository_Expecter) FindAll(ctx interface{}, playedGameID interface{}) *MockAdjustmentRepository_FindAll_Call {
+	return &MockAdjustmentRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx, playedGameID)}
+}
+
+func (_c *MockAdjustmentRepository_FindAll_Call) Run(run func(ctx context.Context, playedGameID int)) *MockAdjustmentRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_FindAll_Call) Return(adjustments []Adjustment, err error) *MockAdjustmentRepository_FindAll_Call {
+	_c.Call.Return(adjustments, err)
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_FindAll_Call) RunAndReturn(run func(ctx context.Context, playedGameID int) ([]Adjustment, error)) *MockAdjustmentRepository_FindAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockAdjustmentRepository
+func (_mock *MockAdjustmentRepository) Insert(ctx context.Context, a *Adjustment) (int, error) {
+	ret := _mock.Called(ctx, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Adjustment) (int, error)); ok {
+		return returnFunc(ctx, a)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Adjustment) int); ok {
+		r0 = returnFunc(ctx, a)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Adjustment) error); ok {
+		r1 = returnFunc(ctx, a)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdjustmentRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockAdjustmentRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - a *Adjustment
+func (_e *MockAdjustmentRepository_Expecter) Insert(ctx interface{}, a interface{}) *MockAdjustmentRepository_Insert_Call {
+	return &MockAdjustmentRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, a)}
+}
+
+func (_c *MockAdjustmentRepository_Insert_Call) Run(run func(ctx context.Context, a *Adjustment)) *MockAdjustmentRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Adjustment
+		if args[1] != nil {
+			arg1 = args[1].(*Adjustment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_Insert_Call) Return(n int, err error) *MockAdjustmentRepository_Insert_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, a *Adjustment) (int, error)) *MockAdjustmentRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLedgerRepository creates a new instance of MockLedgerRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLedgerRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLedgerRepository {
+	mock := &MockLedgerRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockLedgerRepository is an autogenerated mock type for the LedgerRepository type
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+type MockLedgerRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLedgerRepository) EXPECT() *MockLedgerRepository_Expecter {
+	return &MockLedgerRepository_Expecter{mock: &_m.Mock}
+}
+
+// FindAll provides a mock function for the type MockLedgerRepository
+func (_mock *MockLedgerRepository) FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error) {
+	ret := _mock.Called(ctx, playerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []LedgerEntry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]LedgerEntry, error)); ok {
+		return returnFunc(ctx, playerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []LedgerEntry); ok {
+		r0 = returnFunc(ctx, playerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]LedgerEntry)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, playerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLedgerRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type MockLedgerRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+func (_e *MockLedgerRepository_Expecter) FindAll(ctx interface{}, playerID interface{}) *MockLedgerRepository_FindAll_Call {
+	return &MockLedgerRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx, playerID)}
+}
+
+func (_c *MockLedgerRepository_FindAll_Call) Run(run func(ctx context.Context, playerID string)) *MockLedgerRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLedgerRepository_FindAll_Call) Return(ledgerEntrys []LedgerEntry, err error) *MockLedgerRepository_FindAll_Call {
+	_c.Call.Return(ledgerEntrys, err)
+	return _c
+}
+
+func (_c *MockLedgerRepository_FindAll_Call) RunAndReturn(run func(ctx context.Context, playerID string) ([]LedgerEntry, error)) *MockLedgerRepository_FindAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockLedgerRepository
+func (_mock *MockLedgerRepository) Insert(ctx context.Context, entries []LedgerEntry) error {
+	ret := _mock.Called(ctx, entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []LedgerEntry) error); ok {
+		r0 = returnFunc(ctx, entries)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLedgerRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockLedgerRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entries []LedgerEntry
+func (_e *MockLedgerRepository_Expecter) Insert(ctx interface{}, entries interface{}) *MockLedgerRepository_Insert_Call {
+	return &MockLedgerRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, entries)}
+}
+
+func (_c *MockLedgerRepository_Insert_Call) Run(run func(ctx context.Context, entries []LedgerEntry)) *MockLedgerRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []LedgerEntry
+		if args[1] != nil {
+			arg1 = args[1].([]LedgerEntry)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLedgerRepository_Insert_Call) Return(err error) *MockLedgerRepository_Insert_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLedgerRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, entries []LedgerEntry) error) *MockLedgerRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Leaderboard provides a mock function for the type MockLedgerRepository
+func (_mock *MockLedgerRepository) Leaderboard(ctx context.Context) ([]LeaderboardPlayer, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Leaderboard")
+	}
+
+	var r0 []LeaderboardPlayer
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]LeaderboardPlayer, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []LeaderboardPlayer); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]LeaderboardPlayer)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLedgerRepository_Leaderboard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Leaderboard'
+type MockLedgerRepository_Leaderboard_Call struct {
+	*mock.Call
+}
+
+// Leaderboard is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockLedgerRepository_Expecter) Leaderboard(ctx interface{}) *MockLedgerRepository_Leaderboard_Call {
+	return &MockLedgerRepository_Leaderboard_Call{Call: _e.mock.On("Leaderboard", ctx)}
+}
+
+func (_c *MockLedgerRepository_Leaderboard_Call) Run(run func(ctx context.Context)) *MockLedgerRepository_Leaderboard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLedgerRepository_Leaderboard_Call) Return(leaderboardPlayers []LeaderboardPlayer, err error) *MockLedgerRepository_Leaderboard_Call {
+	_c.Call.Return(leaderboardPlayers, err)
+	return _c
+}
+
+func (_c *MockLedgerRepository_Leaderboard_Call) RunAndReturn(run func(ctx context.Context) ([]LeaderboardPlayer, error)) *MockLedgerRepository_Leaderboard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLedgerRebuildRepository creates a new instance of MockLedgerRebuildRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLedgerRebuildRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLedgerRebuildRepository {
+	mock := &MockLedgerRebuildRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockLedgerRebuildRepository is an autogenerated mock type for the LedgerRebuildRepository type
+type MockLedgerRebuildRepository struct {
+	mock.Mock
+}
+
+type MockLedgerRebuildRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLedgerRebuildRepository) EXPECT() *MockLedgerRebuildRepository_Expecter {
+	return &MockLedgerRebuildRepository_Expecter{mock: &_m.Mock}
+}
+
+// FindAll provides a mock function for the type MockLedgerRebuildRepository
+func (_mock *MockLedgerRebuildRepository) FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error) {
+	ret := _mock.Called(ctx, playerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []LedgerEntry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]LedgerEntry, error)); ok {
+		return returnFunc(ctx, playerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []LedgerEntry); ok {
+		r0 = returnFunc(ctx, playerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]LedgerEntry)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, playerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLedgerRebuildRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type MockLedgerRebuildRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+func (_e *MockLedgerRebuildRepository_Expecter) FindAll(ctx interface{}, playerID interface{}) *MockLedgerRebuildRepository_FindAll_Call {
+	return &MockLedgerRebuildRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx, playerID)}
+}
+
+func (_c *MockLedgerRebuildRepository_FindAll_Call) Run(run func(ctx context.Context, playerID string)) *MockLedgerRebuildRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLedgerRebuildRepository_FindAll_Call) Return(ledgerEntrys []LedgerEntry, err error) *MockLedgerRebuildRepository_FindAll_Call {
+	_c.Call.Return(ledgerEntrys, err)
+	return _c
+}
+
+func (_c *MockLedgerRebuildRepository_FindAll_Call) RunAndReturn(run func(ctx context.Context, playerID string) ([]LedgerEntry, error)) *MockLedgerRebuildRepository_FindAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Insert provides a mock function for the type MockLedgerRebuildRepository
+func (_mock *MockLedgerRebuildRepository) Insert(ctx context.Context, entries []LedgerEntry) error {
+	ret := _mock.Called(ctx, entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []LedgerEntry) error); ok {
+		r0 = returnFunc(ctx, entries)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLedgerRebuildRepository_Insert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Insert'
+type MockLedgerRebuildRepository_Insert_Call struct {
+	*mock.Call
+}
+
+// Insert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entries []LedgerEntry
+func (_e *MockLedgerRebuildRepository_Expecter) Insert(ctx interface{}, entries interface{}) *MockLedgerRebuildRepository_Insert_Call {
+	return &MockLedgerRebuildRepository_Insert_Call{Call: _e.mock.On("Insert", ctx, entries)}
+}
+
+func (_c *MockLedgerRebuildRepository_Insert_Call) Run(run func(ctx context.Context, entries []LedgerEntry)) *MockLedgerRebuildRepository_Insert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []LedgerEntry
+		if args[1] != nil {
+			arg1 = args[1].([]LedgerEntry)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLedgerRebuildRepository_Insert_Call) Return(err error) *MockLedgerRebuildRepository_Insert_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLedgerRebuildRepository_Insert_Call) RunAndReturn(run func(ctx context.Context, entries []LedgerEntry) error) *MockLedgerRebuildRepository_Insert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockBacklogRepository creates a new instance of MockBacklogRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBacklogRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBacklogRepository {
+	mock := &MockBacklogRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBacklogRepository is an autogenerated mock type for the BacklogRepository type
+type MockBacklogRepository struct {
+	mock.Mock
+}
+
+type MockBacklogRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBacklogRepository) EXPECT() *MockBacklogRepository_Expecter {
+	return &MockBacklogRepository_Expecter{mock: &_m.Mock}
+}
+
+// DeleteByGame provides a mock function for the type MockBacklogRepository
+func (_mock *MockBacklogRepository) DeleteByGame(ctx context.Context, playerID string, gameID int) error {
+	ret := _mock.Called(ctx, playerID, gameID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByGame")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = returnFunc(ctx, playerID, gameID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockBacklogRepository_DeleteByGame_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByGame'
+type MockBacklogRepository_DeleteByGame_Call struct {
+	*mock.Call
+}
+
+// DeleteByGame is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+//   - gameID int
+func (_e *MockBacklogRepository_Expecter) DeleteByGame(ctx interface{}, playerID interface{}, gameID interface{}) *MockBacklogRepository_DeleteByGame_Call {
+	return &MockBacklogRepository_DeleteByGame_Call{Call: _e.mock.On("DeleteByGame", ctx, playerID, gameID)}
+}
+
+func (_c *MockBacklogRepository_DeleteByGame_Call) Run(run func(ctx context.Context, playerID string, gameID int)) *MockBacklogRepository_DeleteByGame_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBacklogRepository_DeleteByGame_Call) Return(err error) *MockBacklogRepository_DeleteByGame_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockBacklogRepository_DeleteByGame_Call) RunAndReturn(run func(ctx context.Context, playerID string, gameID int) error) *MockBacklogRepository_DeleteByGame_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAll provides a mock function for the type MockBacklogRepository
+func (_mock *MockBacklogRepository) FindAll(ctx context.Context, playerID string) ([]backlog.Item, error) {
+	ret := _mock.Called(ctx, playerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []backlog.Item
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]backlog.Item, error)); ok {
+		return returnFunc(ctx, playerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []backlog.Item); ok {
+		r0 = returnFunc(ctx, playerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]backlog.Item)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, playerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBacklogRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type MockBacklogRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - playerID string
+func (_e *MockBacklogRepository_Expecter) FindAll(ctx interface{}, playerID interface{}) *MockBacklogRepository_FindAll_Call {
+	return &MockBacklogRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx, playerID)}
+}
+
+func (_c *MockBacklogRepository_FindAll_Call) Run(run func(ctx context.Context, playerID string)) *MockBacklogRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBacklogRepository_FindAll_Call) Return(items []backlog.Item, err error) *MockBacklogRepository_FindAll_Call {
+	_c.Call.Return(items, err)
+	return _c
+}
+
+func (_c *MockBacklogRepository_FindAll_Call) RunAndReturn(run func(ctx context.Context, playerID string) ([]backlog.Item, error)) *MockBacklogRepository_FindAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTransactor creates a new instance of MockTransactor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransactor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransactor {
+	mock := &MockTransactor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTransactor is an autogenerated mock type for the Transactor type
+type MockTransactor struct {
+	mock.Mock
+}
+
+type MockTransactor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransactor) EXPECT() *MockTransactor_Expecter {
+	return &MockTransactor_Expecter{mock: &_m.Mock}
+}
+
+// WithTx provides a mock function for the type MockTransactor
+func (_mock *MockTransactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	ret := _mock.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithTx")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, func(ctx context.Context) error) error); ok {
+		r0 = returnFunc(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransactor_WithTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithTx'
+type MockTransactor_WithTx_Call struct {
+	*mock.Call
+}
+
+// WithTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(ctx context.Context) error
+func (_e *MockTransactor_Expecter) WithTx(ctx interface{}, fn interface{}) *MockTransactor_WithTx_Call {
+	return &MockTransactor_WithTx_Call{Call: _e.mock.On("WithTx", ctx, fn)}
+}
+
+func (_c *MockTransactor_WithTx_Call) Run(run func(ctx context.Context, fn func(ctx context.Context) error)) *MockTransactor_WithTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 func(ctx context.Context) error
+		if args[1] != nil {
+			arg1 = args[1].(func(ctx context.Context) error)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransactor_WithTx_Call) Return(err error) *MockTransactor_WithTx_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTransactor_WithTx_Call) RunAndReturn(run func(ctx context.Context, fn func(ctx context.Context) error) error) *MockTransactor_WithTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockEventPublisher creates a new instance of MockEventPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockEventPublisher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockEventPublisher {
+	mock := &MockEventPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockEventPublisher is an autogenerated mock type for the EventPublisher type
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+type MockEventPublisher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockEventPublisher) EXPECT() *MockEventPublisher_Expecter {
+	return &MockEventPublisher_Expecter{mock: &_m.Mock}
+}
+
+// Publish provides a mock function for the type MockEventPublisher
+func (_mock *MockEventPublisher) Publish(ctx context.Context, e event.Event) error {
+	ret := _mock.Called(ctx, e)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, event.Event) error); ok {
+		r0 = returnFunc(ctx, e)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEventPublisher_Publish_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Publish'
+type MockEventPublisher_Publish_Call struct {
+	*mock.Call
+}
+
+// Publish is a helper method to define mock.On call
+//   - ctx context.Context
+//   - e event.Event
+func (_e *MockEventPublisher_Expecter) Publish(ctx interface{}, e interface{}) *MockEventPublisher_Publish_Call {
+	return &MockEventPublisher_Publish_Call{Call: _e.mock.On("Publish", ctx, e)}
+}
+
+func (_c *MockEventPublisher_Publish_Call) Run(run func(ctx context.Context, e event.Event)) *MockEventPublisher_Publish_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 event.Event
+		if args[1] != nil {
+			arg1 = args[1].(event.Event)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEventPublisher_Publish_Call) Return(err error) *MockEventPublisher_Publish_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEventPublisher_Publish_Call) RunAndReturn(run func(ctx context.Context, e event.Event) error) *MockEventPublisher_Publish_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -7,17 +7,27 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
 	"github.com/lardira/playtrack/internal/pkg/types"
 )
 
 const (
 	TablePlayer     = "player"
 	TablePlayedGame = "played_game"
+
+	nonterminatedUniqueIndex = "played_game_player_nonterminated_key"
 )
 
 var (
 	ErrPlayedGameNotFound = errors.New("played game is not found")
+	// ErrNonterminatedExists is returned when a player would have two played games
+	// that are not finished
+	ErrNonterminatedExists = errors.New("player has a played game in nonterminated status")
+	// ErrPlayedGameStatusChanged is returned when the status of a played game was changed
+	// after it was read
+	ErrPlayedGameStatusChanged = errors.New("played game status was changed")
 )
 
 type PGPlayedRepository struct {
@@ -43,7 +53,7 @@ func (r *PGPlayedRepository) FindAll(ctx context.Context, playerID string) ([]Pl
 	if err != nil {
 		return nil, err
 	}
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,43 +69,77 @@ func (r *PGPlayedRepository) FindAll(ctx context.Context, playerID string) ([]Pl
 	return out, nil
 }
 
-func (r *PGPlayedRepository) FindOne(ctx context.Context, playerID string, id int) (*PlayedGame, error) {
+// FindStuck returns played games of all players in progress since before startedBefore, the oldest first
+func (r *PGPlayedRepository) FindStuck(ctx context.Context, startedBefore time.Time) ([]PlayedGame, error) {
+	out := make([]PlayedGame, 0)
+
 	sqlBuild := sq.Select(playedGameColumns).
 		PlaceholderFormat(sq.Dollar).
 		From(TablePlayedGame).
-		Where(sq.Eq{"player_id": playerID, "id": id})
+		Where(sq.Eq{"status": PlayedGameStatusInProgress}).
+		Where(sq.Lt{"started_at": startedBefore.UTC()}).
+		OrderBy("started_at", "id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := playedGameFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+// FindNonterminated returns the played game of the player that is not finished,
+// ErrPlayedGameNotFound if there is none
+func (r *PGPlayedRepository) FindNonterminated(ctx context.Context, playerID string) (*PlayedGame, error) {
+	sqlBuild := sq.Select(playedGameColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePlayedGame).
+		Where(sq.Eq{"player_id": playerID, "status": nonterminatedStatus}).
+		Limit(1)
 
 	query, args, err := sqlBuild.ToSql()
 	if err != nil {
 		return nil, err
 	}
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	p, err := playedGameFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlayedGameNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
-func (r *PGPlayedRepository) FindLastNotReroll(ctx context.Context, playerID string) (*PlayedGame, error) {
+func (r *PGPlayedRepository) FindOne(ctx context.Context, playerID string, id int) (*PlayedGame, error) {
 	sqlBuild := sq.Select(playedGameColumns).
 		PlaceholderFormat(sq.Dollar).
 		From(TablePlayedGame).
-		Where(sq.Eq{"player_id": playerID}, sq.NotEq{"status": PlayedGameStatusRerolled}).
-		OrderBy("started_at::date DESC", "completed_at::date DESC", "id DESC").
-		Limit(1).
-		Offset(1)
+		Where(sq.Eq{"player_id": playerID, "id": id})
 
 	query, args, err := sqlBuild.ToSql()
 	if err != nil {
 		return nil, err
 	}
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	p, err := playedGameFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlayedGameNotFound
+	}
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrPlayedGameNotFound
-		}
 		return nil, err
 	}
 	return p, nil
@@ -114,11 +158,48 @@ func (r *PGPlayedRepository) Insert(ctx context.Context, game *PlayedGame) (int,
 	if err != nil {
 		return id, err
 	}
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 
 	if err := row.Scan(&id); err != nil {
+		return id, nonterminatedOr(err)
+	}
+	return id, nil
+}
+
+// Import inserts a played game from history with all its fields as they are
+func (r *PGPlayedRepository) Import(ctx context.Context, game *PlayedGame) (int, error) {
+	var id int
+
+	var playTime *time.Duration
+	if game.PlayTime != nil {
+		playTime = &game.PlayTime.Duration
+	}
+
+	sqlBuild := sq.Insert(TablePlayedGame).
+		PlaceholderFormat(sq.Dollar).
+		Columns("player_id", "game_id", "status", "points", "comment", "rating", "started_at", "completed_at", "play_time").
+		Values(
+			game.PlayerID,
+			game.GameID,
+			game.Status,
+			game.Points,
+			game.Comment,
+			game.Rating,
+			game.StartedAt,
+			game.CompletedAt,
+			playTime,
+		).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
 		return id, err
 	}
+
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, nonterminatedOr(err)
+	}
 	return id, nil
 }
 
@@ -146,19 +227,40 @@ func (r *PGPlayedRepository) Update(ctx context.Context, game *PlayedGameUpdate)
 		updBuild = updBuild.Set("play_time", game.PlayTime.Duration)
 	}
 
-	query, args, err := updBuild.Where(sq.Eq{"id": game.ID}).Suffix("RETURNING id").ToSql()
+	updBuild = updBuild.Where(sq.Eq{"id": game.ID})
+	if game.FromStatus != nil {
+		updBuild = updBuild.Where(sq.Eq{"status": *game.FromStatus})
+	}
+
+	query, args, err := updBuild.Suffix("RETURNING id").ToSql()
 	if err != nil {
 		return id, err
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
 	err = row.Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) && game.FromStatus != nil {
+		return id, ErrPlayedGameStatusChanged
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return id, ErrPlayedGameNotFound
+	}
 	if err != nil {
-		return id, err
+		return id, nonterminatedOr(err)
 	}
 	return id, nil
 }
 
+// nonterminatedOr returns ErrNonterminatedExists for violations of the index of
+// played games that are not finished
+func nonterminatedOr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == nonterminatedUniqueIndex {
+		return ErrNonterminatedExists
+	}
+	return err
+}
+
 func playedGameFromRow(row pgx.Row) (*PlayedGame, error) {
 	var p PlayedGame
 	var ptime *time.Duration
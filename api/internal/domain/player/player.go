@@ -1,16 +1,23 @@
 package player
 
 import (
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/lardira/playtrack/internal/pkg/event"
 	"github.com/lardira/playtrack/internal/pkg/types"
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
 	MinPasswordLength = 8
 	MinUsernameLength = 4
+	MaxUsernameLength = 32
 )
 
 const (
@@ -30,6 +37,12 @@ const (
 
 var (
 	ErrUsernameMinLen         = fmt.Errorf("username must not be less than %d symbols", MinUsernameLength)
+	ErrUsernameMaxLen         = fmt.Errorf("username must not be more than %d symbols", MaxUsernameLength)
+	ErrUsernameSymbols        = errors.New("username must not contain line breaks, tabs, invisible symbols or @")
+	ErrUsernameReserved       = errors.New("username is reserved")
+	ErrUsernameTaken          = errors.New("username is taken")
+	ErrEmailTaken             = errors.New("email is taken")
+	ErrPlayerNotFound         = errors.New("player is not found")
 	ErrPasswordMinLen         = fmt.Errorf("password must not be less than %d symbols", MinPasswordLength)
 	ErrCompletedBeforeStarted = fmt.Errorf("completed time is before started")
 	ErrGameRating             = fmt.Errorf("rating must be in range [%v; %v]", minRating, maxRating)
@@ -41,6 +54,12 @@ var (
 		PlayedGameStatusDropped,
 		PlayedGameStatusRerolled,
 	}
+	// nonterminatedStatus are statuses of the only played game a player has unfinished,
+	// the database keeps them in a unique index
+	nonterminatedStatus = []PlayedGameStatus{
+		PlayedGameStatusAdded,
+		PlayedGameStatusInProgress,
+	}
 
 	validPlayedGameStatuses = map[PlayedGameStatus][]PlayedGameStatus{
 		PlayedGameStatusAdded:      {PlayedGameStatusInProgress, PlayedGameStatusCompleted, PlayedGameStatusDropped, PlayedGameStatusRerolled},
@@ -49,48 +68,200 @@ var (
 		PlayedGameStatusRerolled:   {},
 		PlayedGameStatusCompleted:  {},
 	}
+
+	// reservedUsernames could be mistaken for the staff or the app, compared in lower case
+	reservedUsernames = []string{
+		"admin",
+		"administrator",
+		"anonymous",
+		"deleted",
+		"moderator",
+		"null",
+		"official",
+		"playtrack",
+		"root",
+		"staff",
+		"support",
+		"system",
+		"undefined",
+	}
+
+	statusEvents = map[PlayedGameStatus]event.Type{
+		PlayedGameStatusInProgress: event.PlayedGameStarted,
+		PlayedGameStatusCompleted:  event.PlayedGameCompleted,
+		PlayedGameStatusDropped:    event.PlayedGameDropped,
+		PlayedGameStatusRerolled:   event.PlayedGameRerolled,
+	}
 )
 
 type Player struct {
-	ID          string    `json:"id" format:"uuid"`
-	Username    string    `json:"username"`
-	Password    string    `json:"-"`
-	IsAdmin     bool      `json:"is_admin"`
-	Img         *string   `json:"img" required:"false"`
-	Email       *string   `json:"email" format:"email" required:"false"`
-	Description *string   `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            string    `json:"id" format:"uuid"`
+	Username      string    `json:"username"`
+	Password      string    `json:"-"`
+	IsAdmin       bool      `json:"is_admin"`
+	Img           *string   `json:"img" required:"false"`
+	Email         *string   `json:"email" format:"email" required:"false"`
+	EmailVerified bool      `json:"email_verified"`
+	Description   *string   `json:"description"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	FailedLoginAttempts int       `json:"-"`
+	LockedUntil         time.Time `json:"-"`
+	// MustChangePassword is returned on login and shown only to admins
+	MustChangePassword bool `json:"-"`
+	// TokensValidAfter invalidates tokens issued before it (password change, logout)
+	TokensValidAfter time.Time `json:"-"`
+	// Suspension disables the account, it is shown only to admins
+	Suspension *Suspension `json:"-"`
 }
 
 func (p *Player) Valid() error {
-	if len(p.Username) < MinUsernameLength {
-		return ErrUsernameMinLen
+	if err := validUsername(p.Username); err != nil {
+		return err
 	}
-	if len(p.Password) < MinPasswordLength {
+	if utf8.RuneCountInString(p.Password) < MinPasswordLength {
 		return ErrPasswordMinLen
 	}
 	return nil
 }
 
+// Normalize brings the username and the email to the form they are stored and compared in
+func (p *Player) Normalize() {
+	p.Username = NormalizeUsername(p.Username)
+	if p.Email != nil {
+		email := NormalizeEmail(*p.Email)
+		p.Email = &email
+	}
+}
+
+// Locked reports whether the player is temporarily locked after failed logins
+func (p *Player) Locked(now time.Time) bool {
+	return p.LockedUntil.After(now)
+}
+
+// TokenRevoked reports whether a token issued at issuedAt was invalidated.
+// Tokens have seconds precision so the same second is still valid.
+func (p *Player) TokenRevoked(issuedAt time.Time) bool {
+	return issuedAt.Before(p.TokensValidAfter.Truncate(time.Second))
+}
+
+// Suspended reports whether the account is disabled by a suspension active at now
+func (p *Player) Suspended(now time.Time) bool {
+	return p.Suspension != nil && p.Suspension.Active(now)
+}
+
+type SuspensionKind string
+
+const (
+	SuspensionDeactivated SuspensionKind = "deactivated"
+	SuspensionBanned      SuspensionKind = "banned"
+)
+
+// Suspension disables an account until it is lifted or Until passes
+type Suspension struct {
+	Kind   SuspensionKind `json:"kind"`
+	Reason string         `json:"reason"`
+	At     time.Time      `json:"at"`
+	// Until is nil for suspensions lifted only by an admin
+	Until *time.Time `json:"until"`
+}
+
+func (s *Suspension) Active(now time.Time) bool {
+	return s.Until == nil || now.Before(*s.Until)
+}
+
 type PlayerUpdate struct {
-	ID          string
-	Username    *string
-	Img         *string
-	Email       *string
-	Password    *string
-	Description *string
+	ID            string
+	Username      *string
+	Img           *string
+	Email         *string
+	EmailVerified *bool
+	Password      *string
+	Description   *string
+
+	MustChangePassword *bool
+	TokensValidAfter   *time.Time
+	IsAdmin            *bool
 }
 
 func (p *PlayerUpdate) Valid() error {
-	if p.Username != nil && len(*p.Username) < MinUsernameLength {
-		return ErrUsernameMinLen
+	if p.Username != nil {
+		if err := validUsername(*p.Username); err != nil {
+			return err
+		}
 	}
-	if p.Password != nil && len(*p.Password) < MinPasswordLength {
+	if p.Password != nil && utf8.RuneCountInString(*p.Password) < MinPasswordLength {
 		return ErrPasswordMinLen
 	}
 	return nil
 }
 
+func (p *PlayerUpdate) Normalize() {
+	if p.Username != nil {
+		username := NormalizeUsername(*p.Username)
+		p.Username = &username
+	}
+	if p.Email != nil {
+		email := NormalizeEmail(*p.Email)
+		p.Email = &email
+	}
+}
+
+// NormalizeUsername composes the username so the same looking names are equal,
+// usernames are unique regardless of the case
+func NormalizeUsername(username string) string {
+	return norm.NFC.String(strings.TrimSpace(username))
+}
+
+// NormalizeEmail trims the email, emails are unique regardless of the case
+func NormalizeEmail(email string) string {
+	return strings.TrimSpace(email)
+}
+
+// UsernameReserved reports whether the username could be mistaken for the staff or the app
+func UsernameReserved(username string) bool {
+	return slices.Contains(reservedUsernames, strings.ToLower(username))
+}
+
+// validUsername checks the length in symbols, not bytes, and the symbols of a normalized username
+func validUsername(username string) error {
+	n := utf8.RuneCountInString(username)
+	if n < MinUsernameLength {
+		return ErrUsernameMinLen
+	}
+	if n > MaxUsernameLength {
+		return ErrUsernameMaxLen
+	}
+	for _, r := range username {
+		// @ keeps usernames apart from emails, which are also used to log in
+		if r == '@' || (r != ' ' && unicode.IsSpace(r)) || unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return ErrUsernameSymbols
+		}
+	}
+	if UsernameReserved(username) {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
+const (
+	RoleFilterAdmin  = "admin"
+	RoleFilterPlayer = "player"
+
+	StateFilterActive      = "active"
+	StateFilterDeactivated = string(SuspensionDeactivated)
+	StateFilterBanned      = string(SuspensionBanned)
+)
+
+// PlayerFilter selects players, empty fields match all players
+type PlayerFilter struct {
+	// Username is a part of the username, case insensitive
+	Username string
+	Role     string
+	// State is active or the kind of an active suspension
+	State string
+}
+
 type PlayedGame struct {
 	ID          int                   `json:"id"`
 	PlayerID    string                `json:"player_id"`
@@ -134,6 +305,9 @@ type PlayedGameUpdate struct {
 	Status      *PlayedGameStatus
 	CompletedAt *time.Time
 	PlayTime    *types.DurationString
+	// FromStatus is the status the game must still have, the update fails with
+	// ErrPlayedGameStatusChanged if it was changed since it was read
+	FromStatus *PlayedGameStatus
 }
 
 func (p *PlayedGameUpdate) Valid() error {
@@ -145,8 +319,10 @@ func (p *PlayedGameUpdate) Valid() error {
 
 type LeaderboardPlayer struct {
 	PlayerID  string `json:"player_id"`
+	Username  string `json:"username"`
 	Completed int    `json:"completed"`
-	Total     int    `json:"total"`
-	Dropped   int    `json:"dropped"`
-	Rerolled  int    `json:"rerolled"`
+	// Total is the sum of points in the ledger
+	Total    int `json:"total"`
+	Dropped  int `json:"dropped"`
+	Rerolled int `json:"rerolled"`
 }
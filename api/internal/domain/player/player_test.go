@@ -40,6 +40,51 @@ func TestPlayerValid(t *testing.T) {
 			},
 			ErrUsernameMinLen,
 		},
+		{
+			"username counted in symbols",
+			func() Player {
+				p := validPlayer()
+				p.Username = strings.Repeat("ё", MaxUsernameLength)
+				return p
+			},
+			nil,
+		},
+		{
+			"username more than max",
+			func() Player {
+				p := validPlayer()
+				p.Username = strings.Repeat("a", MaxUsernameLength+1)
+				return p
+			},
+			ErrUsernameMaxLen,
+		},
+		{
+			"username with @",
+			func() Player {
+				p := validPlayer()
+				p.Username = "alex@home"
+				return p
+			},
+			ErrUsernameSymbols,
+		},
+		{
+			"username with zero width space",
+			func() Player {
+				p := validPlayer()
+				p.Username = "al\u200bex"
+				return p
+			},
+			ErrUsernameSymbols,
+		},
+		{
+			"username reserved",
+			func() Player {
+				p := validPlayer()
+				p.Username = "Admin"
+				return p
+			},
+			ErrUsernameReserved,
+		},
 	}
 
 	for _, tt := range tcases {
@@ -52,6 +97,35 @@ func TestPlayerValid(t *testing.T) {
 	}
 }
 
+func TestPlayerNormalize(t *testing.T) {
+	email := " Alex@Example.com "
+	p := Player{Username: " Zoe\u0308 ", Email: &email}
+	p.Normalize()
+
+	assert.Equal(t, "Zoë", p.Username)
+	assert.Equal(t, "Alex@Example.com", *p.Email)
+}
+
+func TestPlayerLocked(t *testing.T) {
+	now := time.Now()
+	p := validPlayer()
+	assert.False(t, p.Locked(now))
+
+	p.LockedUntil = now.Add(time.Minute)
+	assert.True(t, p.Locked(now))
+	assert.False(t, p.Locked(p.LockedUntil.Add(time.Second)))
+}
+
+func TestPlayerTokenRevoked(t *testing.T) {
+	now := time.Now()
+	p := validPlayer()
+	assert.False(t, p.TokenRevoked(now))
+
+	p.TokensValidAfter = now
+	assert.True(t, p.TokenRevoked(now.Add(-time.Minute)))
+	assert.False(t, p.TokenRevoked(now.Add(time.Second)))
+}
+
 func TestPlayerUpdateValid(t *testing.T) {
 	tcases := []struct {
 		name   string
@@ -2,16 +2,33 @@ package player
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	pgUniqueViolation = "23505"
+
+	usernameUniqueIndex = "player_username_lower_key"
+	emailUniqueIndex    = "player_email_lower_key"
+
+	// usernameOrder sorts by code points regardless of the case, so every storage
+	// orders players the same way whatever the collation of the database is
+	usernameOrder = `lower(username) COLLATE "C"`
+)
+
 var (
 	playerColumns string = `id, username, img, email, password,
-	created_at, is_admin, description`
+	created_at, is_admin, description, failed_login_attempts, locked_until,
+	email_verified, must_change_password, tokens_valid_after,
+	suspension_kind, suspension_reason, suspended_at, suspended_until`
 
 	playedGameColumns string = `id, player_id, game_id, points, comment, 
 	rating, status, started_at, completed_at, play_time`
@@ -32,7 +49,61 @@ func (r *PGRepository) FindAll(ctx context.Context) ([]Player, error) {
 
 	sqlBuild := sq.Select(playerColumns).
 		PlaceholderFormat(sq.Dollar).
-		From(TablePlayer)
+		From(TablePlayer).
+		OrderBy(usernameOrder, "id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := playerFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+// FindByFilter returns players matching all set fields of the filter ordered by username
+func (r *PGRepository) FindByFilter(ctx context.Context, f PlayerFilter) ([]Player, error) {
+	out := make([]Player, 0)
+
+	sqlBuild := sq.Select(playerColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePlayer).
+		OrderBy(usernameOrder, "id")
+
+	if f.Username != "" {
+		sqlBuild = sqlBuild.Where(sq.ILike{"username": "%" + escapeLike(f.Username) + "%"})
+	}
+	switch f.Role {
+	case RoleFilterAdmin:
+		sqlBuild = sqlBuild.Where(sq.Eq{"is_admin": true})
+	case RoleFilterPlayer:
+		sqlBuild = sqlBuild.Where(sq.Eq{"is_admin": false})
+	}
+
+	switch f.State {
+	case StateFilterActive:
+		sqlBuild = sqlBuild.Where(sq.Or{
+			sq.Eq{"suspended_at": nil},
+			sq.Expr("suspended_until <= NOW()"),
+		})
+	case StateFilterDeactivated, StateFilterBanned:
+		sqlBuild = sqlBuild.
+			Where(sq.NotEq{"suspended_at": nil}).
+			Where(sq.Or{sq.Eq{"suspended_until": nil}, sq.Expr("suspended_until > NOW()")}).
+			Where(sq.Eq{"suspension_kind": f.State})
+	}
 
 	query, args, err := sqlBuild.ToSql()
 	if err != nil {
@@ -68,6 +139,9 @@ func (r *PGRepository) FindOne(ctx context.Context, id string) (*Player, error)
 
 	row := r.pool.QueryRow(ctx, query, args...)
 	p, err := playerFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlayerNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +152,7 @@ func (r *PGRepository) FindOneByUsername(ctx context.Context, username string) (
 	sqlBuild := sq.Select(playerColumns).
 		PlaceholderFormat(sq.Dollar).
 		From(TablePlayer).
-		Where(sq.Eq{"username": username})
+		Where(sq.Expr("lower(username) = lower(?)", NormalizeUsername(username)))
 
 	query, args, err := sqlBuild.ToSql()
 	if err != nil {
@@ -87,6 +161,31 @@ func (r *PGRepository) FindOneByUsername(ctx context.Context, username string) (
 
 	row := r.pool.QueryRow(ctx, query, args...)
 	p, err := playerFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *PGRepository) FindOneByEmail(ctx context.Context, email string) (*Player, error) {
+	sqlBuild := sq.Select(playerColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePlayer).
+		Where(sq.Expr("lower(email) = lower(?)", NormalizeEmail(email)))
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	p, err := playerFromRow(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlayerNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -98,13 +197,15 @@ func (r *PGRepository) Insert(ctx context.Context, player *Player) (string, erro
 
 	sqlBuild := sq.Insert(TablePlayer).
 		PlaceholderFormat(sq.Dollar).
-		Columns("id", "username", "img", "email", "password").
+		Columns("id", "username", "img", "email", "password", "email_verified", "is_admin").
 		Values(
 			uuid.NewString(),
 			player.Username,
 			player.Img,
 			player.Email,
 			player.Password,
+			player.EmailVerified,
+			player.IsAdmin,
 		).
 		Suffix("RETURNING id")
 
@@ -115,7 +216,7 @@ func (r *PGRepository) Insert(ctx context.Context, player *Player) (string, erro
 
 	row := r.pool.QueryRow(ctx, query, args...)
 	if err := row.Scan(&id); err != nil {
-		return id, err
+		return id, takenOr(err)
 	}
 	return id, nil
 }
@@ -126,6 +227,13 @@ func (r *PGRepository) Update(ctx context.Context, player *PlayerUpdate) (string
 
 	if player.Email != nil {
 		updBuild = updBuild.Set("email", *player.Email)
+		// a new email must be verified again
+		if player.EmailVerified == nil {
+			updBuild = updBuild.Set("email_verified", false)
+		}
+	}
+	if player.EmailVerified != nil {
+		updBuild = updBuild.Set("email_verified", *player.EmailVerified)
 	}
 	if player.Img != nil {
 		updBuild = updBuild.Set("img", *player.Img)
@@ -139,6 +247,15 @@ func (r *PGRepository) Update(ctx context.Context, player *PlayerUpdate) (string
 	if player.Description != nil {
 		updBuild = updBuild.Set("description", *player.Description)
 	}
+	if player.MustChangePassword != nil {
+		updBuild = updBuild.Set("must_change_password", *player.MustChangePassword)
+	}
+	if player.TokensValidAfter != nil {
+		updBuild = updBuild.Set("tokens_valid_after", *player.TokensValidAfter)
+	}
+	if player.IsAdmin != nil {
+		updBuild = updBuild.Set("is_admin", *player.IsAdmin)
+	}
 
 	query, args, err := updBuild.Where(sq.Eq{"id": player.ID}).Suffix("RETURNING id").ToSql()
 	if err != nil {
@@ -147,14 +264,116 @@ func (r *PGRepository) Update(ctx context.Context, player *PlayerUpdate) (string
 
 	row := r.pool.QueryRow(ctx, query, args...)
 	err = row.Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return id, ErrPlayerNotFound
+	}
 	if err != nil {
-		return id, err
+		return id, takenOr(err)
 	}
 	return id, nil
 }
 
+// Suspend sets the suspension of the player, nil lifts it
+func (r *PGRepository) Suspend(ctx context.Context, id string, s *Suspension) error {
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"id": id})
+
+	if s == nil {
+		updBuild = updBuild.
+			Set("suspension_kind", nil).
+			Set("suspension_reason", nil).
+			Set("suspended_at", nil).
+			Set("suspended_until", nil)
+	} else {
+		var until *time.Time
+		if s.Until != nil {
+			u := s.Until.UTC()
+			until = &u
+		}
+		updBuild = updBuild.
+			Set("suspension_kind", s.Kind).
+			Set("suspension_reason", s.Reason).
+			Set("suspended_at", s.At.UTC()).
+			Set("suspended_until", until)
+	}
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// RegisterFailedLogin increments failed login attempts of the player.
+// When maxAttempts is reached the player is locked until now + lockout and attempts are reset.
+func (r *PGRepository) RegisterFailedLogin(
+	ctx context.Context,
+	id string,
+	maxAttempts int,
+	lockout time.Duration,
+) (*time.Time, error) {
+	var lockedUntil *time.Time
+	lockUntil := time.Now().UTC().Add(lockout)
+
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Dollar).
+		Set("failed_login_attempts", sq.Expr(
+			"CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END",
+			maxAttempts,
+		)).
+		Set("locked_until", sq.Expr(
+			"CASE WHEN failed_login_attempts + 1 >= ? THEN ?::timestamp ELSE locked_until END",
+			maxAttempts, lockUntil,
+		)).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING locked_until")
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.pool.QueryRow(ctx, query, args...)
+	if err := row.Scan(&lockedUntil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+	return lockedUntil, nil
+}
+
+func (r *PGRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Dollar).
+		Set("failed_login_attempts", 0).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, query, args...)
+	return err
+}
+
 func playerFromRow(row pgx.Row) (*Player, error) {
 	var p Player
+	var lockedUntil, tokensValidAfter *time.Time
+	var suspensionKind *SuspensionKind
+	var suspensionReason *string
+	var suspendedAt, suspendedUntil *time.Time
 	err := row.Scan(
 		&p.ID,
 		&p.Username,
@@ -164,9 +383,54 @@ func playerFromRow(row pgx.Row) (*Player, error) {
 		&p.CreatedAt,
 		&p.IsAdmin,
 		&p.Description,
+		&p.FailedLoginAttempts,
+		&lockedUntil,
+		&p.EmailVerified,
+		&p.MustChangePassword,
+		&tokensValidAfter,
+		&suspensionKind,
+		&suspensionReason,
+		&suspendedAt,
+		&suspendedUntil,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if lockedUntil != nil {
+		p.LockedUntil = *lockedUntil
+	}
+	if tokensValidAfter != nil {
+		p.TokensValidAfter = *tokensValidAfter
+	}
+	if suspensionKind != nil && suspendedAt != nil {
+		p.Suspension = &Suspension{
+			Kind:  *suspensionKind,
+			At:    *suspendedAt,
+			Until: suspendedUntil,
+		}
+		if suspensionReason != nil {
+			p.Suspension.Reason = *suspensionReason
+		}
+	}
 	return &p, nil
 }
+
+// takenOr returns ErrUsernameTaken or ErrEmailTaken for violations of their unique indexes
+func takenOr(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case usernameUniqueIndex:
+		return ErrUsernameTaken
+	case emailUniqueIndex:
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// escapeLike escapes wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -19,7 +19,9 @@ type RequestUpdatePlayer struct {
 type RequestCreatePlayedGame struct {
 	PlayerID string `path:"id" format:"uuid"`
 	Body     struct {
-		GameID int `json:"game_id"`
+		GameID int `json:"game_id" required:"false"`
+		// FromBacklog takes the first game of the backlog when game_id is not set
+		FromBacklog bool `json:"from_backlog" required:"false"`
 	}
 }
 
@@ -27,6 +29,8 @@ type RequestUpdatePlayedGame struct {
 	PlayerID string `path:"id" format:"uuid"`
 	GameID   int    `path:"gameID"`
 	Body     struct {
+		// Points are derived by the server, requests with points are rejected,
+		// admins change points with an adjustment
 		Points      *int                  `json:"points" required:"false"`
 		Comment     *string               `json:"comment" required:"false"`
 		Rating      *int                  `json:"rating" required:"false"`
@@ -35,3 +39,48 @@ type RequestUpdatePlayedGame struct {
 		PlayTime    *types.DurationString `json:"play_time" required:"false"`
 	}
 }
+
+type RequestAdjustPoints struct {
+	PlayerID string `path:"id" format:"uuid"`
+	GameID   int    `path:"gameID"`
+	Body     struct {
+		Points int    `json:"points"`
+		Reason string `json:"reason" minLength:"1" maxLength:"256"`
+	}
+}
+
+type RequestAdjustments struct {
+	PlayerID string `path:"id" format:"uuid"`
+	GameID   int    `path:"gameID"`
+}
+
+type ResponseLedger struct {
+	Body struct {
+		Total int           `json:"total"`
+		Items []LedgerEntry `json:"items"`
+	}
+}
+
+type RequestExportPlayedGames struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Format   string `query:"format" enum:"csv,json" default:"json"`
+}
+
+type ResponseExportPlayedGames struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+type RequestImportPlayedGames struct {
+	PlayerID string `path:"id" format:"uuid"`
+	Format   string `query:"format" enum:"csv,json" default:"json"`
+	RawBody  []byte
+}
+
+type ResponseImportPlayedGames struct {
+	Body struct {
+		Imported     int      `json:"imported"`
+		CreatedGames []string `json:"created_games"`
+	}
+}
@@ -0,0 +1,77 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteAdjustmentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAdjustmentRepository(sqlDB *sql.DB) *SQLiteAdjustmentRepository {
+	return &SQLiteAdjustmentRepository{
+		db: sqlDB,
+	}
+}
+
+// FindAll returns adjustments of the played game, the latest first
+func (r *SQLiteAdjustmentRepository) FindAll(ctx context.Context, playedGameID int) ([]Adjustment, error) {
+	query, args, err := sq.Select(adjustmentColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePointsAdjustment).
+		Where(sq.Eq{"played_game_id": playedGameID}).
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Adjustment, 0)
+	for rows.Next() {
+		var a Adjustment
+		err := rows.Scan(
+			&a.ID,
+			&a.PlayedGameID,
+			&a.PlayerID,
+			&a.AdjustedBy,
+			&a.PointsBefore,
+			&a.Points,
+			&a.Reason,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteAdjustmentRepository) Insert(ctx context.Context, a *Adjustment) (int, error) {
+	var id int
+
+	query, args, err := sq.Insert(TablePointsAdjustment).
+		PlaceholderFormat(sq.Question).
+		Columns("played_game_id", "player_id", "adjusted_by", "points_before", "points", "reason").
+		Values(a.PlayedGameID, a.PlayerID, a.AdjustedBy, a.PointsBefore, a.Points, a.Reason).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	if err := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
@@ -0,0 +1,106 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLedgerRepository(sqlDB *sql.DB) *SQLiteLedgerRepository {
+	return &SQLiteLedgerRepository{
+		db: sqlDB,
+	}
+}
+
+// FindAll returns entries of the player in order they were written
+func (r *SQLiteLedgerRepository) FindAll(ctx context.Context, playerID string) ([]LedgerEntry, error) {
+	query, args, err := sq.Select(ledgerColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePointsLedger).
+		Where(sq.Eq{"player_id": playerID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LedgerEntry, 0)
+	for rows.Next() {
+		var e LedgerEntry
+		if err := rows.Scan(&e.ID, &e.PlayerID, &e.PlayedGameID, &e.Delta, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteLedgerRepository) Insert(ctx context.Context, entries []LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	insBuild := sq.Insert(TablePointsLedger).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "played_game_id", "delta", "reason")
+	for _, e := range entries {
+		insBuild = insBuild.Values(e.PlayerID, e.PlayedGameID, e.Delta, e.Reason)
+	}
+
+	query, args, err := insBuild.ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// Leaderboard returns totals of all players from the ledger, the best first
+func (r *SQLiteLedgerRepository) Leaderboard(ctx context.Context) ([]LeaderboardPlayer, error) {
+	query, args, err := sq.Select(
+		"p.id",
+		"p.username",
+		"COALESCE(l.total, 0) AS total",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'completed')",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'dropped')",
+		"COUNT(pg.id) FILTER (WHERE pg.status = 'rerolled')",
+	).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayer+" p").
+		LeftJoin("(SELECT player_id, SUM(delta) AS total FROM "+TablePointsLedger+" GROUP BY player_id) l ON l.player_id = p.id").
+		LeftJoin(TablePlayedGame+" pg ON pg.player_id = p.id").
+		GroupBy("p.id", "p.username", "l.total").
+		OrderBy("total DESC", "p."+sqliteUsernameOrder, "p.id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LeaderboardPlayer, 0)
+	for rows.Next() {
+		var p LeaderboardPlayer
+		if err := rows.Scan(&p.PlayerID, &p.Username, &p.Total, &p.Completed, &p.Dropped, &p.Rerolled); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,261 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+// SQLitePlayedRepository stores played games in sqlite, play_time is kept
+// in microseconds, the precision of a postgres interval
+type SQLitePlayedRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePlayedRepository(sqlDB *sql.DB) *SQLitePlayedRepository {
+	return &SQLitePlayedRepository{
+		db: sqlDB,
+	}
+}
+
+// FindAll returns played games of the player, not completed ones first and then by the
+// day of completion and id, the latest first
+func (r *SQLitePlayedRepository) FindAll(ctx context.Context, playerID string) ([]PlayedGame, error) {
+	sqlBuild := sq.Select(playedGameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayedGame).
+		Where(sq.Eq{"player_id": playerID}).
+		// postgres puts nulls first in descending order
+		OrderBy("date(completed_at) DESC NULLS FIRST", "id DESC")
+
+	return r.findAll(ctx, sqlBuild)
+}
+
+// FindStuck returns played games of all players in progress since before startedBefore, the oldest first
+func (r *SQLitePlayedRepository) FindStuck(ctx context.Context, startedBefore time.Time) ([]PlayedGame, error) {
+	sqlBuild := sq.Select(playedGameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayedGame).
+		Where(sq.Eq{"status": PlayedGameStatusInProgress}).
+		Where(sq.Lt{"started_at": db.SQLiteTime(startedBefore)}).
+		OrderBy("started_at", "id")
+
+	return r.findAll(ctx, sqlBuild)
+}
+
+// FindNonterminated returns the played game of the player that is not finished,
+// ErrPlayedGameNotFound if there is none
+func (r *SQLitePlayedRepository) FindNonterminated(ctx context.Context, playerID string) (*PlayedGame, error) {
+	sqlBuild := sq.Select(playedGameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayedGame).
+		Where(sq.Eq{"player_id": playerID, "status": nonterminatedStatus}).
+		Limit(1)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	p, err := sqlitePlayedGameFromRow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlayedGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *SQLitePlayedRepository) FindOne(ctx context.Context, playerID string, id int) (*PlayedGame, error) {
+	sqlBuild := sq.Select(playedGameColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayedGame).
+		Where(sq.Eq{"player_id": playerID, "id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	p, err := sqlitePlayedGameFromRow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlayedGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *SQLitePlayedRepository) Insert(ctx context.Context, game *PlayedGame) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TablePlayedGame).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "game_id", "status", "points", "started_at").
+		Values(game.PlayerID, game.GameID, PlayedGameStatusAdded, game.Points, db.SQLiteTime(time.Now())).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+
+	if err := row.Scan(&id); err != nil {
+		return id, sqliteNonterminatedOr(err)
+	}
+	return id, nil
+}
+
+// Import inserts a played game from history with all its fields as they are
+func (r *SQLitePlayedRepository) Import(ctx context.Context, game *PlayedGame) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TablePlayedGame).
+		PlaceholderFormat(sq.Question).
+		Columns("player_id", "game_id", "status", "points", "comment", "rating", "started_at", "completed_at", "play_time").
+		Values(
+			game.PlayerID,
+			game.GameID,
+			game.Status,
+			game.Points,
+			game.Comment,
+			game.Rating,
+			db.SQLiteTime(game.StartedAt),
+			db.SQLiteTimePtr(game.CompletedAt),
+			playTimeMicros(game.PlayTime),
+		).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, sqliteNonterminatedOr(err)
+	}
+	return id, nil
+}
+
+func (r *SQLitePlayedRepository) Update(ctx context.Context, game *PlayedGameUpdate) (int, error) {
+	var id int
+
+	updBuild := sq.Update(TablePlayedGame).PlaceholderFormat(sq.Question)
+
+	if game.Points != nil {
+		updBuild = updBuild.Set("points", *game.Points)
+	}
+	if game.Comment != nil {
+		updBuild = updBuild.Set("comment", *game.Comment)
+	}
+	if game.Rating != nil {
+		updBuild = updBuild.Set("rating", *game.Rating)
+	}
+	if game.Status != nil {
+		updBuild = updBuild.Set("status", *game.Status)
+	}
+	if game.CompletedAt != nil {
+		updBuild = updBuild.Set("completed_at", db.SQLiteTime(*game.CompletedAt))
+	}
+	if game.PlayTime != nil {
+		updBuild = updBuild.Set("play_time", playTimeMicros(game.PlayTime))
+	}
+
+	updBuild = updBuild.Where(sq.Eq{"id": game.ID})
+	if game.FromStatus != nil {
+		updBuild = updBuild.Where(sq.Eq{"status": *game.FromStatus})
+	}
+
+	query, args, err := updBuild.Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	err = row.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) && game.FromStatus != nil {
+		return id, ErrPlayedGameStatusChanged
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return id, ErrPlayedGameNotFound
+	}
+	if err != nil {
+		return id, sqliteNonterminatedOr(err)
+	}
+	return id, nil
+}
+
+func (r *SQLitePlayedRepository) findAll(ctx context.Context, sqlBuild sq.SelectBuilder) ([]PlayedGame, error) {
+	out := make([]PlayedGame, 0)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := sqlitePlayedGameFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+// sqliteNonterminatedOr returns ErrNonterminatedExists for violations of the index of
+// played games that are not finished
+func sqliteNonterminatedOr(err error) error {
+	if db.SQLiteUniqueViolation(err, TablePlayedGame, "player_id") {
+		return ErrNonterminatedExists
+	}
+	return err
+}
+
+func sqlitePlayedGameFromRow(row pgx.Row) (*PlayedGame, error) {
+	var p PlayedGame
+	var playTime *int64
+	err := row.Scan(
+		&p.ID,
+		&p.PlayerID,
+		&p.GameID,
+		&p.Points,
+		&p.Comment,
+		&p.Rating,
+		&p.Status,
+		&p.StartedAt,
+		&p.CompletedAt,
+		&playTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if playTime != nil {
+		ds := types.NewDurationString(time.Duration(*playTime) * time.Microsecond)
+		p.PlayTime = &ds
+	}
+	return &p, nil
+}
+
+func playTimeMicros(d *types.DurationString) *int64 {
+	if d == nil {
+		return nil
+	}
+	us := d.Microseconds()
+	return &us
+}
@@ -0,0 +1,326 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	// sqliteUsernameOrder matches usernameOrder, keys are lowercased usernames
+	// and sqlite compares texts by bytes
+	sqliteUsernameOrder = "username_key"
+)
+
+// SQLiteRepository stores players in sqlite. Lowercased username_key and email_key
+// columns keep them unique regardless of the case as lower() of sqlite only knows ascii.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context) ([]Player, error) {
+	return r.FindByFilter(ctx, PlayerFilter{})
+}
+
+// FindByFilter returns players matching all set fields of the filter ordered by username
+func (r *SQLiteRepository) FindByFilter(ctx context.Context, f PlayerFilter) ([]Player, error) {
+	out := make([]Player, 0)
+	now := db.SQLiteTime(time.Now())
+
+	sqlBuild := sq.Select(playerColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayer).
+		OrderBy(sqliteUsernameOrder, "id")
+
+	if f.Username != "" {
+		pattern := "%" + escapeLike(strings.ToLower(f.Username)) + "%"
+		sqlBuild = sqlBuild.Where(sq.Expr(`username_key LIKE ? ESCAPE '\'`, pattern))
+	}
+	switch f.Role {
+	case RoleFilterAdmin:
+		sqlBuild = sqlBuild.Where(sq.Eq{"is_admin": true})
+	case RoleFilterPlayer:
+		sqlBuild = sqlBuild.Where(sq.Eq{"is_admin": false})
+	}
+
+	switch f.State {
+	case StateFilterActive:
+		sqlBuild = sqlBuild.Where(sq.Or{
+			sq.Eq{"suspended_at": nil},
+			sq.LtOrEq{"suspended_until": now},
+		})
+	case StateFilterDeactivated, StateFilterBanned:
+		sqlBuild = sqlBuild.
+			Where(sq.NotEq{"suspended_at": nil}).
+			Where(sq.Or{sq.Eq{"suspended_until": nil}, sq.Gt{"suspended_until": now}}).
+			Where(sq.Eq{"suspension_kind": f.State})
+	}
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := playerFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) FindOne(ctx context.Context, id string) (*Player, error) {
+	return r.findOne(ctx, sq.Eq{"id": id})
+}
+
+func (r *SQLiteRepository) FindOneByUsername(ctx context.Context, username string) (*Player, error) {
+	return r.findOne(ctx, sq.Eq{"username_key": strings.ToLower(NormalizeUsername(username))})
+}
+
+func (r *SQLiteRepository) FindOneByEmail(ctx context.Context, email string) (*Player, error) {
+	return r.findOne(ctx, sq.Eq{"email_key": strings.ToLower(NormalizeEmail(email))})
+}
+
+func (r *SQLiteRepository) Insert(ctx context.Context, player *Player) (string, error) {
+	var id string
+
+	sqlBuild := sq.Insert(TablePlayer).
+		PlaceholderFormat(sq.Question).
+		Columns(
+			"id", "username", "username_key", "img", "email", "email_key",
+			"password", "email_verified", "is_admin", "created_at",
+		).
+		Values(
+			uuid.NewString(),
+			player.Username,
+			strings.ToLower(player.Username),
+			player.Img,
+			player.Email,
+			emailKey(player.Email),
+			player.Password,
+			player.EmailVerified,
+			player.IsAdmin,
+			db.SQLiteTime(time.Now()),
+		).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, sqliteTakenOr(err)
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, player *PlayerUpdate) (string, error) {
+	var id string
+	updBuild := sq.Update(TablePlayer).PlaceholderFormat(sq.Question)
+
+	if player.Email != nil {
+		updBuild = updBuild.
+			Set("email", *player.Email).
+			Set("email_key", emailKey(player.Email))
+		// a new email must be verified again
+		if player.EmailVerified == nil {
+			updBuild = updBuild.Set("email_verified", false)
+		}
+	}
+	if player.EmailVerified != nil {
+		updBuild = updBuild.Set("email_verified", *player.EmailVerified)
+	}
+	if player.Img != nil {
+		updBuild = updBuild.Set("img", *player.Img)
+	}
+	if player.Username != nil {
+		updBuild = updBuild.
+			Set("username", *player.Username).
+			Set("username_key", strings.ToLower(*player.Username))
+	}
+	if player.Password != nil {
+		updBuild = updBuild.Set("password", *player.Password)
+	}
+	if player.Description != nil {
+		updBuild = updBuild.Set("description", *player.Description)
+	}
+	if player.MustChangePassword != nil {
+		updBuild = updBuild.Set("must_change_password", *player.MustChangePassword)
+	}
+	if player.TokensValidAfter != nil {
+		updBuild = updBuild.Set("tokens_valid_after", db.SQLiteTime(*player.TokensValidAfter))
+	}
+	if player.IsAdmin != nil {
+		updBuild = updBuild.Set("is_admin", *player.IsAdmin)
+	}
+
+	query, args, err := updBuild.Where(sq.Eq{"id": player.ID}).Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	err = row.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return id, ErrPlayerNotFound
+	}
+	if err != nil {
+		return id, sqliteTakenOr(err)
+	}
+	return id, nil
+}
+
+// Suspend sets the suspension of the player, nil lifts it
+func (r *SQLiteRepository) Suspend(ctx context.Context, id string, s *Suspension) error {
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"id": id})
+
+	if s == nil {
+		updBuild = updBuild.
+			Set("suspension_kind", nil).
+			Set("suspension_reason", nil).
+			Set("suspended_at", nil).
+			Set("suspended_until", nil)
+	} else {
+		updBuild = updBuild.
+			Set("suspension_kind", s.Kind).
+			Set("suspension_reason", s.Reason).
+			Set("suspended_at", db.SQLiteTime(s.At)).
+			Set("suspended_until", db.SQLiteTimePtr(s.Until))
+	}
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// RegisterFailedLogin increments failed login attempts of the player.
+// When maxAttempts is reached the player is locked until now + lockout and attempts are reset.
+func (r *SQLiteRepository) RegisterFailedLogin(
+	ctx context.Context,
+	id string,
+	maxAttempts int,
+	lockout time.Duration,
+) (*time.Time, error) {
+	lockUntil := db.SQLiteTime(time.Now().Add(lockout))
+
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Question).
+		Set("failed_login_attempts", sq.Expr(
+			"CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END",
+			maxAttempts,
+		)).
+		Set("locked_until", sq.Expr(
+			"CASE WHEN failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END",
+			maxAttempts, lockUntil,
+		)).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING locked_until")
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var lockedUntil *time.Time
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+	return lockedUntil, nil
+}
+
+func (r *SQLiteRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	updBuild := sq.Update(TablePlayer).
+		PlaceholderFormat(sq.Question).
+		Set("failed_login_attempts", 0).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := updBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *SQLiteRepository) findOne(ctx context.Context, where sq.Eq) (*Player, error) {
+	sqlBuild := sq.Select(playerColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayer).
+		Where(where)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	p, err := playerFromRow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func emailKey(email *string) *string {
+	if email == nil {
+		return nil
+	}
+	key := strings.ToLower(*email)
+	return &key
+}
+
+// sqliteTakenOr returns ErrUsernameTaken or ErrEmailTaken for violations of their unique keys
+func sqliteTakenOr(err error) error {
+	switch {
+	case db.SQLiteUniqueViolation(err, TablePlayer, "username_key"):
+		return ErrUsernameTaken
+	case db.SQLiteUniqueViolation(err, TablePlayer, "email_key"):
+		return ErrEmailTaken
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+type SQLiteStreakRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteStreakRepository(sqlDB *sql.DB) *SQLiteStreakRepository {
+	return &SQLiteStreakRepository{
+		db: sqlDB,
+	}
+}
+
+func (r *SQLiteStreakRepository) FindOne(ctx context.Context, playerID string) (*Streak, error) {
+	query, args, err := sq.Select(streakColumns).
+		PlaceholderFormat(sq.Question).
+		From(TablePlayerStreak).
+		Where(sq.Eq{"player_id": playerID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var s Streak
+	err = db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&s.PlayerID,
+		&s.Completions,
+		&s.Drops,
+		&s.Penalty,
+		&s.BestCompletions,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrStreakNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save inserts or replaces the streak of the player
+func (r *SQLiteStreakRepository) Save(ctx context.Context, s *Streak) error {
+	query, args, err := sq.Insert(TablePlayerStreak).
+		PlaceholderFormat(sq.Question).
+		Columns(streakColumns).
+		Values(s.PlayerID, s.Completions, s.Drops, s.Penalty, s.BestCompletions, db.SQLiteTime(s.UpdatedAt)).
+		Suffix(`ON CONFLICT (player_id) DO UPDATE SET
+			completions = excluded.completions,
+			drops = excluded.drops,
+			penalty = excluded.penalty,
+			best_completions = excluded.best_completions,
+			updated_at = excluded.updated_at`).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
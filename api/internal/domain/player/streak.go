@@ -0,0 +1,130 @@
+package player
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrStreakNotFound = errors.New("streak is not found")
+)
+
+// DefaultStreakRules gives a bonus point for the third completion in a row and every
+// next one, each completion takes one level off the drop penalty
+var DefaultStreakRules = StreakRules{
+	BonusAfter:  3,
+	BonusPoints: 1,
+	DecayAfter:  1,
+}
+
+// StreakRules configure points of streaks
+type StreakRules struct {
+	// BonusAfter is the completion streak from which completions get BonusPoints, 0 disables bonuses
+	BonusAfter  int
+	BonusPoints int
+	// DecayAfter completions in a row lower the drop penalty by one level,
+	// 0 resets the penalty on any completion
+	DecayAfter int
+}
+
+// Streak is the state of results of a player in order of finishing games, rerolls do not change it
+type Streak struct {
+	PlayerID string `json:"player_id"`
+	// Completions is the number of completions in a row
+	Completions int `json:"completions"`
+	// Drops is the number of drops in a row
+	Drops int `json:"drops"`
+	// Penalty is the level of the drop penalty, the next drop costs Penalty+1 points
+	Penalty         int       `json:"penalty"`
+	BestCompletions int       `json:"best_completions"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Next returns the streak after a game finished with status and points the result gives:
+// negative points of a dropped game or the bonus of a completed one
+func (s Streak) Next(status PlayedGameStatus, rules StreakRules) (Streak, int) {
+	switch status {
+	case PlayedGameStatusDropped:
+		points := -(s.Penalty + 1)
+		s.Penalty++
+		s.Drops++
+		s.Completions = 0
+		return s, points
+
+	case PlayedGameStatusCompleted:
+		s.Completions++
+		s.Drops = 0
+		s.BestCompletions = max(s.BestCompletions, s.Completions)
+
+		switch {
+		case rules.DecayAfter == 0:
+			s.Penalty = 0
+		case s.Completions%rules.DecayAfter == 0:
+			s.Penalty = max(0, s.Penalty-1)
+		}
+
+		bonus := 0
+		if rules.BonusAfter > 0 && s.Completions >= rules.BonusAfter {
+			bonus = rules.BonusPoints
+		}
+		return s, bonus
+	}
+	return s, 0
+}
+
+// Finish returns the streak after the played game finished with the status of upd,
+// sets points the played game ends with to upd and returns ledger entries of them.
+// Statuses other than dropped and completed leave the streak and upd as they are.
+func (s Streak) Finish(played *PlayedGame, upd *PlayedGameUpdate, rules StreakRules) (Streak, []LedgerEntry) {
+	if upd.Status == nil {
+		return s, nil
+	}
+	next, points := s.Next(*upd.Status, rules)
+
+	switch *upd.Status {
+	case PlayedGameStatusDropped:
+		upd.Points = &points
+		return next, ledgerEntries(
+			newLedgerEntry(played, -1, LedgerReasonDropPenalty),
+			newLedgerEntry(played, points+1, LedgerReasonDropStacking),
+		)
+	case PlayedGameStatusCompleted:
+		base := played.Points
+		if upd.Points != nil {
+			base = *upd.Points
+		}
+		total := base + points
+		upd.Points = &total
+		return next, ledgerEntries(
+			newLedgerEntry(played, base, LedgerReasonCompletion),
+			newLedgerEntry(played, points, LedgerReasonStreakBonus),
+		)
+	}
+	return s, nil
+}
+
+// StreakFromHistory replays finished played games of a player in order of finishing
+func StreakFromHistory(playerID string, played []PlayedGame, rules StreakRules) Streak {
+	finished := slices.DeleteFunc(slices.Clone(played), func(pg PlayedGame) bool {
+		return !pg.StatusTerminated()
+	})
+	slices.SortFunc(finished, func(a, b PlayedGame) int {
+		return cmp.Or(a.finishedAt().Compare(b.finishedAt()), cmp.Compare(a.ID, b.ID))
+	})
+
+	s := Streak{PlayerID: playerID}
+	for _, pg := range finished {
+		s, _ = s.Next(pg.Status, rules)
+	}
+	return s
+}
+
+// finishedAt orders played games completed without completion time by their start
+func (pg *PlayedGame) finishedAt() time.Time {
+	if pg.CompletedAt == nil {
+		return pg.StartedAt
+	}
+	return *pg.CompletedAt
+}
@@ -0,0 +1,76 @@
+package player
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	TablePlayerStreak = "player_streak"
+
+	streakColumns string = "player_id, completions, drops, penalty, best_completions, updated_at"
+)
+
+type PGStreakRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGStreakRepository(pool *pgxpool.Pool) *PGStreakRepository {
+	return &PGStreakRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGStreakRepository) FindOne(ctx context.Context, playerID string) (*Streak, error) {
+	query, args, err := sq.Select(streakColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TablePlayerStreak).
+		Where(sq.Eq{"player_id": playerID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var s Streak
+	err = db.Conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&s.PlayerID,
+		&s.Completions,
+		&s.Drops,
+		&s.Penalty,
+		&s.BestCompletions,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStreakNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save inserts or replaces the streak of the player
+func (r *PGStreakRepository) Save(ctx context.Context, s *Streak) error {
+	query, args, err := sq.Insert(TablePlayerStreak).
+		PlaceholderFormat(sq.Dollar).
+		Columns(streakColumns).
+		Values(s.PlayerID, s.Completions, s.Drops, s.Penalty, s.BestCompletions, s.UpdatedAt.UTC()).
+		Suffix(`ON CONFLICT (player_id) DO UPDATE SET
+			completions = EXCLUDED.completions,
+			drops = EXCLUDED.drops,
+			penalty = EXCLUDED.penalty,
+			best_completions = EXCLUDED.best_completions,
+			updated_at = EXCLUDED.updated_at`).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
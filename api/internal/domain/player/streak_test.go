@@ -0,0 +1,145 @@
+package player
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestStreakNext(t *testing.T) {
+	tcases := []struct {
+		name     string
+		statuses []PlayedGameStatus
+		rules    StreakRules
+		points   []int
+		expected Streak
+	}{
+		{
+			"drops escalate",
+			[]PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusDropped, PlayedGameStatusDropped},
+			DefaultStreakRules,
+			[]int{-1, -2, -3},
+			Streak{Drops: 3, Penalty: 3},
+		},
+		{
+			"completions decay the penalty",
+			[]PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusDropped, PlayedGameStatusCompleted, PlayedGameStatusDropped},
+			DefaultStreakRules,
+			[]int{-1, -2, 0, -2},
+			Streak{Drops: 1, Penalty: 2, BestCompletions: 1},
+		},
+		{
+			"rerolls are ignored",
+			[]PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusRerolled, PlayedGameStatusDropped},
+			DefaultStreakRules,
+			[]int{-1, 0, -2},
+			Streak{Drops: 2, Penalty: 2},
+		},
+		{
+			"bonus after three completions",
+			[]PlayedGameStatus{PlayedGameStatusCompleted, PlayedGameStatusCompleted, PlayedGameStatusCompleted, PlayedGameStatusCompleted},
+			DefaultStreakRules,
+			[]int{0, 0, 1, 1},
+			Streak{Completions: 4, BestCompletions: 4},
+		},
+		{
+			"reset on completion",
+			[]PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusDropped, PlayedGameStatusCompleted, PlayedGameStatusDropped},
+			StreakRules{},
+			[]int{-1, -2, 0, -1},
+			Streak{Drops: 1, Penalty: 1, BestCompletions: 1},
+		},
+		{
+			"slow decay",
+			[]PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusDropped, PlayedGameStatusCompleted, PlayedGameStatusCompleted, PlayedGameStatusDropped},
+			StreakRules{DecayAfter: 2},
+			[]int{-1, -2, 0, 0, -2},
+			Streak{Drops: 1, Penalty: 2, BestCompletions: 2},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Streak{}
+			points := make([]int, 0, len(tc.statuses))
+			for _, status := range tc.statuses {
+				var p int
+				s, p = s.Next(status, tc.rules)
+				points = append(points, p)
+			}
+			assert.Equal(t, tc.points, points)
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestStreakNext_Properties(t *testing.T) {
+	statuses := []PlayedGameStatus{
+		PlayedGameStatusCompleted,
+		PlayedGameStatusDropped,
+		PlayedGameStatusRerolled,
+		PlayedGameStatusInProgress,
+	}
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	for range 200 {
+		rules := StreakRules{
+			BonusAfter:  rnd.IntN(5),
+			BonusPoints: rnd.IntN(3),
+			DecayAfter:  rnd.IntN(4),
+		}
+
+		s := Streak{}
+		drops := 0
+		for range 50 {
+			status := statuses[rnd.IntN(len(statuses))]
+			next, points := s.Next(status, rules)
+
+			switch status {
+			case PlayedGameStatusDropped:
+				drops++
+				assert.True(t, points < 0, "drop gives negative points")
+				assert.True(t, -points <= drops, "drop penalty is bounded by drops")
+				assert.Equal(t, s.Penalty+1, next.Penalty)
+			case PlayedGameStatusCompleted:
+				assert.True(t, points == 0 || points == rules.BonusPoints, "bonus is either none or full")
+				assert.True(t, next.Penalty <= s.Penalty, "completion does not raise the penalty")
+				assert.Equal(t, 0, next.Drops)
+			default:
+				assert.Equal(t, s, next)
+				assert.Equal(t, 0, points)
+			}
+			assert.True(t, next.BestCompletions >= next.Completions)
+			s = next
+		}
+	}
+}
+
+func TestStreakFromHistory(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	finished := func(id int, day int, status PlayedGameStatus) PlayedGame {
+		completedAt := start.AddDate(0, 0, day)
+		return PlayedGame{ID: id, Status: status, CompletedAt: &completedAt}
+	}
+
+	played := []PlayedGame{
+		finished(4, 4, PlayedGameStatusCompleted),
+		finished(1, 1, PlayedGameStatusDropped),
+		{ID: 5, Status: PlayedGameStatusInProgress, StartedAt: start},
+		finished(3, 3, PlayedGameStatusRerolled),
+		finished(2, 2, PlayedGameStatusDropped),
+		// completed without completion time is ordered by start
+		{ID: 6, Status: PlayedGameStatusCompleted, StartedAt: start.AddDate(0, 0, 5)},
+	}
+
+	expected := Streak{PlayerID: "p"}
+	for _, status := range []PlayedGameStatus{PlayedGameStatusDropped, PlayedGameStatusDropped, PlayedGameStatusCompleted, PlayedGameStatusCompleted} {
+		expected, _ = expected.Next(status, DefaultStreakRules)
+	}
+
+	actual := StreakFromHistory("p", played, DefaultStreakRules)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, Streak{PlayerID: "p", Completions: 2, BestCompletions: 2}, actual)
+}
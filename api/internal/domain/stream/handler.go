@@ -0,0 +1,161 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/middleware"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+const (
+	keepAliveInterval = 25 * time.Second
+	// retryMillis tells EventSource how long to wait before reconnecting
+	retryMillis = 3000
+)
+
+type Hub interface {
+	Subscribe(lastEventID string) (*event.Subscription, []event.Event)
+	Unsubscribe(sub *event.Subscription)
+}
+
+type Handler struct {
+	hub Hub
+}
+
+func NewHandler(hub Hub) *Handler {
+	return &Handler{
+		hub: hub,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "events-stream",
+		Method:      http.MethodGet,
+		Path:        "/events",
+		Summary:     "stream events",
+		Description: "stream played game, leaderboard and game events as server-sent events",
+		Tags:        []string{"events"},
+		Metadata:    map[string]any{middleware.MetadataAllowQueryToken: true},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "event stream, each event data is an event json",
+				Content: map[string]*huma.MediaType{
+					"text/event-stream": {Schema: &huma.Schema{Type: huma.TypeString}},
+				},
+			},
+		},
+	}, h.Stream)
+}
+
+func (h *Handler) Stream(ctx context.Context, i *RequestEvents) (*huma.StreamResponse, error) {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("player id is invalid")
+	}
+
+	filter := Filter{PlayerID: i.PlayerID, Types: i.Types}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			hctx.SetHeader("Content-Type", "text/event-stream")
+			hctx.SetHeader("Cache-Control", "no-cache")
+			// disables response buffering of nginx
+			hctx.SetHeader("X-Accel-Buffering", "no")
+
+			w := hctx.BodyWriter()
+			flush := flusher(w)
+
+			sub, replay := h.hub.Subscribe(i.LastEventID)
+			defer h.hub.Unsubscribe(sub)
+			log.Printf("player %v subscribed to events", ctxPlayer.ID)
+
+			fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+			for _, e := range replay {
+				if filter.Match(e) {
+					if err := WriteEvent(w, e); err != nil {
+						return
+					}
+				}
+			}
+			flush()
+
+			keepAlive := time.NewTicker(keepAliveInterval)
+			defer keepAlive.Stop()
+
+			for {
+				select {
+				case e, ok := <-sub.C:
+					if !ok {
+						// the subscriber fell behind, the client resumes from the last event id
+						return
+					}
+					if !filter.Match(e) {
+						continue
+					}
+					if err := WriteEvent(w, e); err != nil {
+						return
+					}
+					flush()
+
+				case <-keepAlive.C:
+					if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+						return
+					}
+					flush()
+
+				case <-hctx.Context().Done():
+					return
+				}
+			}
+		},
+	}, nil
+}
+
+// Filter selects events of a subscriber, empty fields match everything
+type Filter struct {
+	PlayerID string
+	Types    []event.Type
+}
+
+func (f *Filter) Match(e event.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.PlayerID != "" && e.PlayerID != "" && e.PlayerID != f.PlayerID {
+		return false
+	}
+	return true
+}
+
+// WriteEvent writes the event in the server-sent events format
+func WriteEvent(w io.Writer, e event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func flusher(w io.Writer) func() {
+	for {
+		if f, ok := w.(http.Flusher); ok {
+			return f.Flush
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return func() {}
+		}
+		w = u.Unwrap()
+	}
+}
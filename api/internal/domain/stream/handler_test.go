@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+// stream runs the stream response until ctx is done and returns the written body
+func stream(t *testing.T, ctx context.Context, resp *huma.StreamResponse) string {
+	r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/events", nil)
+	w := httptest.NewRecorder()
+	resp.Body(humatest.NewContext(&huma.Operation{}, r, w))
+	return w.Body.String()
+}
+
+func TestStream_Replay(t *testing.T) {
+	hub := event.NewHub(0)
+	handler := NewHandler(hub)
+
+	playerID := uuid.NewString()
+	events := []event.Event{
+		event.NewForPlayer(event.PlayedGameAdded, playerID, nil),
+		event.NewForPlayer(event.PlayedGameStarted, uuid.NewString(), nil),
+		event.NewForPlayer(event.PlayedGameCompleted, playerID, nil),
+		event.New(event.GameCreated, nil),
+	}
+	for _, e := range events {
+		hub.Broadcast(e)
+	}
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: playerID})
+	resp, err := handler.Stream(ctx, &RequestEvents{PlayerID: playerID, LastEventID: events[0].ID})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	body := stream(t, ctx, resp)
+
+	assert.NotContains(t, body, events[0].ID)
+	assert.NotContains(t, body, events[1].ID)
+	assert.Contains(t, body, "id: "+events[2].ID+"\nevent: played_game.completed\n")
+	assert.Contains(t, body, "id: "+events[3].ID+"\nevent: game.created\n")
+}
+
+// subscribedHub signals subscriptions so events are broadcast to a running stream
+type subscribedHub struct {
+	*event.Hub
+	subscribed chan struct{}
+}
+
+func (h subscribedHub) Subscribe(lastEventID string) (*event.Subscription, []event.Event) {
+	defer close(h.subscribed)
+	return h.Hub.Subscribe(lastEventID)
+}
+
+func TestStream_Live(t *testing.T) {
+	hub := subscribedHub{Hub: event.NewHub(0), subscribed: make(chan struct{})}
+	handler := NewHandler(hub)
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+	resp, err := handler.Stream(ctx, &RequestEvents{Types: []event.Type{event.GameCreated}})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan string)
+	go func() {
+		done <- stream(t, ctx, resp)
+	}()
+
+	<-hub.subscribed
+	skipped := event.New(event.PlayedGameAdded, nil)
+	e := event.New(event.GameCreated, nil)
+	hub.Broadcast(skipped)
+	hub.Broadcast(e)
+
+	body := <-done
+	assert.Contains(t, body, "id: "+e.ID)
+	assert.NotContains(t, body, skipped.ID)
+}
+
+func TestStream_Unauthorized(t *testing.T) {
+	handler := NewHandler(event.NewHub(0))
+
+	_, err := handler.Stream(t.Context(), &RequestEvents{})
+	assert.Error(t, err)
+}
+
+func TestFilterMatch(t *testing.T) {
+	playerID := uuid.NewString()
+	filter := Filter{PlayerID: playerID, Types: []event.Type{event.PlayedGameCompleted, event.GameCreated}}
+
+	assert.True(t, filter.Match(event.NewForPlayer(event.PlayedGameCompleted, playerID, nil)))
+	assert.True(t, filter.Match(event.New(event.GameCreated, nil)))
+	assert.False(t, filter.Match(event.NewForPlayer(event.PlayedGameCompleted, uuid.NewString(), nil)))
+	assert.False(t, filter.Match(event.NewForPlayer(event.PlayedGameDropped, playerID, nil)))
+
+	empty := Filter{}
+	assert.True(t, empty.Match(event.NewForPlayer(event.PlayedGameDropped, uuid.NewString(), nil)))
+}
+
+func TestWriteEvent(t *testing.T) {
+	e := event.New(event.GameCreated, map[string]int{"id": 1})
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteEvent(&buf, e))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "id: "+e.ID, lines[0])
+	assert.Equal(t, "event: game.created", lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], `data: {"id":"`+e.ID))
+	assert.True(t, strings.HasSuffix(buf.String(), "\n\n"))
+}
@@ -0,0 +1,11 @@
+package stream
+
+import "github.com/lardira/playtrack/internal/pkg/event"
+
+type RequestEvents struct {
+	PlayerID string       `query:"player_id" format:"uuid" required:"false" doc:"only events of the player and events not about a player"`
+	Types    []event.Type `query:"types" required:"false" doc:"only events of the types"`
+	// LastEventID is sent by EventSource on reconnect
+	LastEventID string `header:"Last-Event-ID" required:"false"`
+	AccessToken string `query:"access_token" required:"false" doc:"token for clients which cannot set the Authorization header"`
+}
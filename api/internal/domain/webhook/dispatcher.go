@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBatchSize    = 20
+	defaultBackoffBase  = 30 * time.Second
+	defaultBackoffMax   = 6 * time.Hour
+
+	maxErrorLength = 512
+)
+
+type DeliveryRepository interface {
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+	SaveAttempt(ctx context.Context, delivery *Delivery) error
+}
+
+type DispatcherOptions struct {
+	PollInterval time.Duration
+	// Timeout of a single request to a webhook
+	Timeout     time.Duration
+	MaxAttempts int
+	BatchSize   int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	HTTPClient  *http.Client
+}
+
+// Dispatcher sends queued deliveries and retries failed ones with an exponential backoff
+type Dispatcher struct {
+	deliveryRepository DeliveryRepository
+	opts               DispatcherOptions
+}
+
+func NewDispatcher(deliveryRepository DeliveryRepository, opts DispatcherOptions) *Dispatcher {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.BackoffBase == 0 {
+		opts.BackoffBase = defaultBackoffBase
+	}
+	if opts.BackoffMax == 0 {
+		opts.BackoffMax = defaultBackoffMax
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: opts.Timeout}
+	}
+	return &Dispatcher{
+		deliveryRepository: deliveryRepository,
+		opts:               opts,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.Tick(d.opts.PollInterval)
+
+	for {
+		select {
+		case <-ticker:
+			if _, err := d.DispatchDue(ctx); err != nil {
+				log.Printf("webhook dispatch: %v", err)
+			}
+
+		case <-ctx.Done():
+			log.Printf("webhook dispatcher stopped")
+			return
+		}
+	}
+}
+
+// DispatchDue sends deliveries which are due and returns the number of sent ones
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	// the lease outlives sending the whole batch so a delivery is not claimed again
+	// by another replica while this one still holds it
+	lease := time.Duration(d.opts.BatchSize+1) * d.opts.Timeout
+	deliveries, err := d.deliveryRepository.ClaimDue(ctx, now, lease, d.opts.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim: %w", err)
+	}
+
+	deadline := now.Add(lease - d.opts.Timeout)
+	for i := range deliveries {
+		// the rest is left to be claimed again once the lease expires, a request
+		// started now could outlive it
+		if time.Now().After(deadline) {
+			log.Printf("webhook lease ends, %d deliveries left for the next claim", len(deliveries)-i)
+			return i, nil
+		}
+
+		delivery := &deliveries[i]
+		d.deliver(ctx, delivery)
+
+		if err := d.deliveryRepository.SaveAttempt(ctx, delivery); err != nil {
+			log.Printf("webhook delivery %v save attempt: %v", delivery.ID, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver sends the delivery and records the result in it
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) {
+	now := time.Now()
+	delivery.Attempts++
+
+	statusCode, err := d.send(ctx, delivery, now)
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	if err == nil {
+		delivery.Status = DeliveryStatusSucceeded
+		delivery.DeliveredAt = now
+		delivery.LastError = nil
+		log.Printf("webhook delivery %v sent to webhook %v", delivery.ID, delivery.WebhookID)
+		return
+	}
+
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+	delivery.LastError = &msg
+
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		delivery.Status = DeliveryStatusFailed
+		log.Printf("webhook delivery %v failed after %d attempts: %v", delivery.ID, delivery.Attempts, err)
+		return
+	}
+
+	delivery.Status = DeliveryStatusPending
+	delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts, d.opts.BackoffBase, d.opts.BackoffMax))
+	log.Printf("webhook delivery %v attempt %d: %v", delivery.ID, delivery.Attempts, err)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "playtrack-webhook")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorLength))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
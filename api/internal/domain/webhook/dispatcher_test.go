@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a webhook receiver which answers with status
+func newReceiver(t *testing.T, status int) (*httptest.Server, chan received) {
+	ch := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		ch <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func testDelivery(url string, attempts int) Delivery {
+	return Delivery{
+		ID:        1,
+		WebhookID: 2,
+		EventID:   uuid.NewString(),
+		EventType: event.PlayedGameCompleted,
+		Payload:   []byte(`{"type":"played_game.completed"}`),
+		Status:    DeliveryStatusPending,
+		Attempts:  attempts,
+		URL:       url,
+		Secret:    "secret",
+	}
+}
+
+func TestDispatchDue(t *testing.T) {
+	srv, ch := newReceiver(t, http.StatusNoContent)
+	delivery := testDelivery(srv.URL, 0)
+
+	deliveryRepository := NewMockDeliveryRepository(t)
+	dispatcher := NewDispatcher(deliveryRepository, DispatcherOptions{})
+
+	deliveryRepository.
+		On("ClaimDue", t.Context(), mock.Anything, mock.Anything, defaultBatchSize).
+		Once().
+		Return([]Delivery{delivery}, nil)
+
+	deliveryRepository.
+		On("SaveAttempt", t.Context(), mock.MatchedBy(func(d *Delivery) bool {
+			return d.Status == DeliveryStatusSucceeded && d.Attempts == 1 &&
+				!d.DeliveredAt.IsZero() && *d.LastStatusCode == http.StatusNoContent
+		})).
+		Once().
+		Return(nil)
+
+	n, err := dispatcher.DispatchDue(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	got := <-ch
+	assert.Equal(t, string(delivery.Payload), string(got.body))
+	assert.Equal(t, string(delivery.EventType), got.header.Get(HeaderEvent))
+	assert.Equal(t, delivery.EventID, got.header.Get(HeaderDelivery))
+
+	timestamp, err := strconv.ParseInt(got.header.Get(HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, Verify(delivery.Secret, timestamp, got.body, got.header.Get(HeaderSignature)))
+}
+
+func TestDispatchDue_Retry(t *testing.T) {
+	srv, ch := newReceiver(t, http.StatusInternalServerError)
+	delivery := testDelivery(srv.URL, 1)
+
+	deliveryRepository := NewMockDeliveryRepository(t)
+	dispatcher := NewDispatcher(deliveryRepository, DispatcherOptions{
+		BackoffBase: time.Minute,
+		BackoffMax:  time.Hour,
+	})
+
+	deliveryRepository.
+		On("ClaimDue", t.Context(), mock.Anything, mock.Anything, defaultBatchSize).
+		Once().
+		Return([]Delivery{delivery}, nil)
+
+	before := time.Now()
+	deliveryRepository.
+		On("SaveAttempt", t.Context(), mock.MatchedBy(func(d *Delivery) bool {
+			// the second attempt doubles the base delay
+			return d.Status == DeliveryStatusPending && d.Attempts == 2 &&
+				!d.NextAttemptAt.Before(before.Add(2*time.Minute)) &&
+				*d.LastStatusCode == http.StatusInternalServerError &&
+				d.LastError != nil && d.DeliveredAt.IsZero()
+		})).
+		Once().
+		Return(nil)
+
+	_, err := dispatcher.DispatchDue(t.Context())
+	assert.NoError(t, err)
+	<-ch
+}
+
+func TestDispatchDue_MaxAttempts(t *testing.T) {
+	srv, ch := newReceiver(t, http.StatusBadGateway)
+	delivery := testDelivery(srv.URL, 2)
+
+	deliveryRepository := NewMockDeliveryRepository(t)
+	dispatcher := NewDispatcher(deliveryRepository, DispatcherOptions{MaxAttempts: 3})
+
+	deliveryRepository.
+		On("ClaimDue", t.Context(), mock.Anything, mock.Anything, defaultBatchSize).
+		Once().
+		Return([]Delivery{delivery}, nil)
+
+	deliveryRepository.
+		On("SaveAttempt", t.Context(), mock.MatchedBy(func(d *Delivery) bool {
+			return d.Status == DeliveryStatusFailed && d.Attempts == 3
+		})).
+		Once().
+		Return(nil)
+
+	_, err := dispatcher.DispatchDue(t.Context())
+	assert.NoError(t, err)
+	<-ch
+}
+
+func TestDispatchDue_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	delivery := testDelivery(srv.URL, 0)
+
+	deliveryRepository := NewMockDeliveryRepository(t)
+	dispatcher := NewDispatcher(deliveryRepository, DispatcherOptions{})
+
+	deliveryRepository.
+		On("ClaimDue", t.Context(), mock.Anything, mock.Anything, defaultBatchSize).
+		Once().
+		Return([]Delivery{delivery}, nil)
+
+	deliveryRepository.
+		On("SaveAttempt", t.Context(), mock.MatchedBy(func(d *Delivery) bool {
+			return d.Status == DeliveryStatusPending && d.Attempts == 1 &&
+				d.LastStatusCode == nil && d.LastError != nil
+		})).
+		Once().
+		Return(nil)
+
+	_, err := dispatcher.DispatchDue(t.Context())
+	assert.NoError(t, err)
+}
+
+func TestDispatchDue_TimeoutsWithinLease(t *testing.T) {
+	// the receiver hangs until the request times out
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	const batchSize = 3
+	timeout := 50 * time.Millisecond
+
+	deliveryRepository := NewMockDeliveryRepository(t)
+	dispatcher := NewDispatcher(deliveryRepository, DispatcherOptions{Timeout: timeout, BatchSize: batchSize})
+
+	deliveries := make([]Delivery, batchSize)
+	for i := range deliveries {
+		deliveries[i] = testDelivery(srv.URL, 0)
+		deliveries[i].ID = int64(i + 1)
+	}
+
+	var claimedAt time.Time
+	var lease time.Duration
+	deliveryRepository.
+		On("ClaimDue", t.Context(), mock.Anything, mock.Anything, batchSize).
+		Once().
+		Run(func(args mock.Arguments) {
+			claimedAt = args.Get(1).(time.Time)
+			lease = args.Get(2).(time.Duration)
+		}).
+		Return(deliveries, nil)
+
+	var savedAt []time.Time
+	deliveryRepository.
+		On("SaveAttempt", t.Context(), mock.MatchedBy(func(d *Delivery) bool {
+			return d.Status == DeliveryStatusPending && d.LastError != nil
+		})).
+		Times(batchSize).
+		Run(func(mock.Arguments) { savedAt = append(savedAt, time.Now()) }).
+		Return(nil)
+
+	n, err := dispatcher.DispatchDue(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, batchSize, n)
+	assert.Equal(t, batchSize, len(savedAt))
+	for _, at := range savedAt {
+		assert.True(t, at.Before(claimedAt.Add(lease)))
+	}
+}
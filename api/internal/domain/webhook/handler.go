@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/domain"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+)
+
+type WebhookRepository interface {
+	FindAll(ctx context.Context) ([]Webhook, error)
+	FindOne(ctx context.Context, id int) (*Webhook, error)
+	Insert(ctx context.Context, webhook *Webhook) (int, error)
+	Update(ctx context.Context, webhook *WebhookUpdate) (int, error)
+	Delete(ctx context.Context, id int) error
+	FindDeliveries(ctx context.Context, webhookID int, limit int) ([]Delivery, error)
+}
+
+type Handler struct {
+	webhookRepository WebhookRepository
+}
+
+func NewHandler(webhookRepository WebhookRepository) *Handler {
+	return &Handler{
+		webhookRepository: webhookRepository,
+	}
+}
+
+func (h *Handler) Register(api huma.API) {
+	grp := huma.NewGroup(api, "/webhooks")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"webhooks"}
+	})
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-get-all",
+		Method:      http.MethodGet,
+		Path:        "/",
+		Summary:     "get all webhooks",
+		Description: "get all webhooks, admin only",
+	}, h.GetAll)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-get-one",
+		Method:      http.MethodGet,
+		Path:        "/{id}",
+		Summary:     "get webhook",
+		Description: "get one webhook, admin only",
+	}, h.GetOne)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-create-one",
+		Method:      http.MethodPost,
+		Path:        "/",
+		Summary:     "create webhook",
+		Description: "register a webhook, the signing secret is returned only once",
+	}, h.Create)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-update-one",
+		Method:      http.MethodPatch,
+		Path:        "/{id}",
+		Summary:     "update webhook",
+		Description: "update a webhook, admin only",
+	}, h.Update)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-delete-one",
+		Method:      http.MethodDelete,
+		Path:        "/{id}",
+		Summary:     "delete webhook",
+		Description: "delete a webhook with its deliveries, admin only",
+	}, h.Delete)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "webhooks-get-deliveries",
+		Method:      http.MethodGet,
+		Path:        "/{id}/deliveries",
+		Summary:     "get webhook deliveries",
+		Description: "get the delivery log of a webhook, newest first",
+	}, h.GetDeliveries)
+}
+
+func (h *Handler) GetAll(ctx context.Context, i *struct{}) (*domain.ResponseItems[Webhook], error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	webhooks, err := h.webhookRepository.FindAll(ctx)
+	if err != nil {
+		log.Printf("webhook find all: %v", err)
+		return nil, huma.Error500InternalServerError("find all", err)
+	}
+
+	resp := domain.ResponseItems[Webhook]{}
+	resp.Body.Items = webhooks
+	return &resp, nil
+}
+
+func (h *Handler) GetOne(ctx context.Context, i *RequestWebhook) (*domain.ResponseItem[Webhook], error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	webhook, err := h.webhookRepository.FindOne(ctx, i.ID)
+	if err != nil {
+		log.Printf("webhook find one %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "find")
+	}
+
+	resp := domain.ResponseItem[Webhook]{}
+	resp.Body.Item = webhook
+	return &resp, nil
+}
+
+func (h *Handler) Create(ctx context.Context, i *RequestCreateWebhook) (*ResponseCreatedWebhook, error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		log.Printf("webhook secret: %v", err)
+		return nil, huma.Error500InternalServerError("could not create webhook")
+	}
+
+	nWebhook := Webhook{
+		URL:         i.Body.URL,
+		Secret:      secret,
+		Events:      i.Body.Events,
+		Description: i.Body.Description,
+		Active:      true,
+	}
+	if err := nWebhook.Valid(); err != nil {
+		log.Printf("webhook valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	id, err := h.webhookRepository.Insert(ctx, &nWebhook)
+	if err != nil {
+		log.Printf("webhook insert: %v", err)
+		return nil, huma.Error500InternalServerError("create", err)
+	}
+	nWebhook.ID = id
+
+	log.Printf("webhook %v created", id)
+	resp := ResponseCreatedWebhook{}
+	resp.Body.Item = &nWebhook
+	resp.Body.Secret = secret
+	return &resp, nil
+}
+
+func (h *Handler) Update(ctx context.Context, i *RequestUpdateWebhook) (*domain.ResponseID[int], error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	nWebhook := WebhookUpdate{
+		ID:          i.ID,
+		URL:         i.Body.URL,
+		Events:      i.Body.Events,
+		Description: i.Body.Description,
+		Active:      i.Body.Active,
+	}
+	if err := nWebhook.Valid(); err != nil {
+		log.Printf("webhook update valid: %v", err)
+		return nil, huma.Error400BadRequest("entity is not valid", err)
+	}
+
+	id, err := h.webhookRepository.Update(ctx, &nWebhook)
+	if err != nil {
+		log.Printf("webhook update %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "update")
+	}
+
+	log.Printf("webhook %v updated", id)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = id
+	return &resp, nil
+}
+
+func (h *Handler) Delete(ctx context.Context, i *RequestWebhook) (*domain.ResponseID[int], error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if err := h.webhookRepository.Delete(ctx, i.ID); err != nil {
+		log.Printf("webhook delete %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "delete")
+	}
+
+	log.Printf("webhook %v deleted", i.ID)
+	resp := domain.ResponseID[int]{}
+	resp.Body.ID = i.ID
+	return &resp, nil
+}
+
+func (h *Handler) GetDeliveries(
+	ctx context.Context,
+	i *RequestWebhookDeliveries,
+) (*domain.ResponseItems[Delivery], error) {
+	if !checkAdmin(ctx) {
+		return nil, huma.Error403Forbidden("player cannot access this entity")
+	}
+
+	if _, err := h.webhookRepository.FindOne(ctx, i.ID); err != nil {
+		log.Printf("webhook find one %v: %v", i.ID, err)
+		return nil, notFoundOr500(err, "find")
+	}
+
+	deliveries, err := h.webhookRepository.FindDeliveries(ctx, i.ID, i.Limit)
+	if err != nil {
+		log.Printf("webhook %v find deliveries: %v", i.ID, err)
+		return nil, huma.Error500InternalServerError("find deliveries", err)
+	}
+
+	resp := domain.ResponseItems[Delivery]{}
+	resp.Body.Items = deliveries
+	return &resp, nil
+}
+
+func notFoundOr500(err error, msg string) error {
+	if errors.Is(err, ErrWebhookNotFound) {
+		return huma.Error404NotFound("webhook not found")
+	}
+	return huma.Error500InternalServerError(msg, err)
+}
+
+func checkAdmin(ctx context.Context) bool {
+	ctxPlayer, ok := ctxutil.GetPlayer(ctx)
+	return ok && ctxPlayer.IsAdmin
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate(t *testing.T) {
+	webhookRepository := NewMockWebhookRepository(t)
+	handler := NewHandler(webhookRepository)
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	req := RequestCreateWebhook{}
+	req.Body.URL = "https://discord.example/hook"
+	req.Body.Events = []event.Type{event.PlayedGameCompleted, event.PlayedGameDropped}
+
+	var inserted *Webhook
+	webhookRepository.
+		On("Insert", ctx, mock.MatchedBy(func(w *Webhook) bool {
+			inserted = w
+			return w.URL == req.Body.URL && w.Active && w.Secret != ""
+		})).
+		Once().
+		Return(4, nil)
+
+	resp, err := handler.Create(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Body.Item.ID)
+	assert.Equal(t, inserted.Secret, resp.Body.Secret)
+}
+
+func TestCreate_NotAdmin(t *testing.T) {
+	webhookRepository := NewMockWebhookRepository(t)
+	handler := NewHandler(webhookRepository)
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString()})
+
+	webhookRepository.AssertNotCalled(t, "Insert")
+
+	req := RequestCreateWebhook{}
+	req.Body.URL = "https://discord.example/hook"
+	req.Body.Events = []event.Type{event.GameCreated}
+
+	_, err := handler.Create(ctx, &req)
+	assert.Error(t, err)
+}
+
+func TestGetDeliveries(t *testing.T) {
+	webhookRepository := NewMockWebhookRepository(t)
+	handler := NewHandler(webhookRepository)
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+	deliveries := []Delivery{{ID: 2, WebhookID: 1, Status: DeliveryStatusFailed}}
+
+	webhookRepository.
+		On("FindOne", ctx, 1).
+		Once().
+		Return(&Webhook{ID: 1}, nil)
+
+	webhookRepository.
+		On("FindDeliveries", ctx, 1, 50).
+		Once().
+		Return(deliveries, nil)
+
+	resp, err := handler.GetDeliveries(ctx, &RequestWebhookDeliveries{ID: 1, Limit: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, deliveries, resp.Body.Items)
+}
+
+func TestGetDeliveries_NotFound(t *testing.T) {
+	webhookRepository := NewMockWebhookRepository(t)
+	handler := NewHandler(webhookRepository)
+
+	ctx := ctxutil.SetPlayer(t.Context(), ctxutil.CtxPlayer{ID: uuid.NewString(), IsAdmin: true})
+
+	webhookRepository.
+		On("FindOne", ctx, 1).
+		Once().
+		Return(nil, ErrWebhookNotFound)
+
+	webhookRepository.AssertNotCalled(t, "FindDeliveries")
+
+	_, err := handler.GetDeliveries(ctx, &RequestWebhookDeliveries{ID: 1, Limit: 50})
+	assert.Error(t, err)
+}
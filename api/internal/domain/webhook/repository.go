@@ -0,0 +1,350 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+const (
+	TableWebhook         = "webhook"
+	TableWebhookDelivery = "webhook_delivery"
+)
+
+const (
+	webhookColumns  string = "id, url, secret, events, description, active, created_at"
+	deliveryColumns string = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, delivered_at, created_at`
+)
+
+// claimDueQuery postpones due deliveries by a lease so concurrent workers
+// do not send them twice, a delivery is retried when the worker dies before saving the attempt
+const claimDueQuery = `WITH due AS (
+	SELECT id FROM webhook_delivery
+	WHERE status = $1 AND next_attempt_at <= $2
+	ORDER BY id
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_delivery d SET next_attempt_at = $4
+FROM due, webhook w
+WHERE d.id = due.id AND w.id = d.webhook_id
+RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at,
+	w.url, w.secret`
+
+type PGRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGRepository(pool *pgxpool.Pool) *PGRepository {
+	return &PGRepository{
+		pool: pool,
+	}
+}
+
+func (r *PGRepository) FindAll(ctx context.Context) ([]Webhook, error) {
+	out := make([]Webhook, 0)
+
+	sqlBuild := sq.Select(webhookColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableWebhook).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := webhookFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *w)
+	}
+	return out, nil
+}
+
+func (r *PGRepository) FindOne(ctx context.Context, id int) (*Webhook, error) {
+	sqlBuild := sq.Select(webhookColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableWebhook).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := webhookFromRow(db.Conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *PGRepository) Insert(ctx context.Context, webhook *Webhook) (int, error) {
+	var id int
+
+	sqlBuild := sq.Insert(TableWebhook).
+		PlaceholderFormat(sq.Dollar).
+		Columns("url", "secret", "events", "description", "active").
+		Values(webhook.URL, webhook.Secret, eventStrings(webhook.Events), webhook.Description, webhook.Active).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *PGRepository) Update(ctx context.Context, webhook *WebhookUpdate) (int, error) {
+	var id int
+
+	updBuild := sq.Update(TableWebhook).PlaceholderFormat(sq.Dollar)
+
+	if webhook.URL != nil {
+		updBuild = updBuild.Set("url", *webhook.URL)
+	}
+	if webhook.Events != nil {
+		updBuild = updBuild.Set("events", eventStrings(webhook.Events))
+	}
+	if webhook.Description != nil {
+		updBuild = updBuild.Set("description", *webhook.Description)
+	}
+	if webhook.Active != nil {
+		updBuild = updBuild.Set("active", *webhook.Active)
+	}
+
+	query, args, err := updBuild.Where(sq.Eq{"id": webhook.ID}).Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.Conn(ctx, r.pool).QueryRow(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return id, ErrWebhookNotFound
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *PGRepository) Delete(ctx context.Context, id int) error {
+	sqlBuild := sq.Delete(TableWebhook).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// FindDeliveries returns the last deliveries of the webhook, newest first
+func (r *PGRepository) FindDeliveries(ctx context.Context, webhookID int, limit int) ([]Delivery, error) {
+	out := make([]Delivery, 0)
+
+	sqlBuild := sq.Select(deliveryColumns).
+		PlaceholderFormat(sq.Dollar).
+		From(TableWebhookDelivery).
+		Where(sq.Eq{"webhook_id": webhookID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := deliveryFromRow(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, nil
+}
+
+// Publish queues the event for every active webhook subscribed to it. It joins the transaction
+// of ctx so the event is sent only if the change is committed.
+func (r *PGRepository) Publish(ctx context.Context, e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	sqlBuild := sq.Insert(TableWebhookDelivery).
+		PlaceholderFormat(sq.Dollar).
+		Columns("webhook_id", "event_id", "event_type", "payload", "next_attempt_at").
+		Select(
+			sq.Select("id").
+				Column("?::uuid", e.ID).
+				Column("?::text", string(e.Type)).
+				Column("?::jsonb", payload).
+				Column("?::timestamp", e.OccurredAt.UTC()).
+				From(TableWebhook).
+				Where("active AND ?::text = ANY(events)", string(e.Type)),
+		)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// ClaimDue returns pending deliveries due at now and postpones them by lease
+func (r *PGRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	out := make([]Delivery, 0)
+
+	rows, err := db.Conn(ctx, r.pool).Query(
+		ctx,
+		claimDueQuery,
+		DeliveryStatusPending,
+		now.UTC(),
+		limit,
+		now.Add(lease).UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := deliveryFromRow(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
+
+// SaveAttempt stores the result of a delivery attempt
+func (r *PGRepository) SaveAttempt(ctx context.Context, delivery *Delivery) error {
+	var deliveredAt *time.Time
+	if !delivery.DeliveredAt.IsZero() {
+		d := delivery.DeliveredAt.UTC()
+		deliveredAt = &d
+	}
+
+	sqlBuild := sq.Update(TableWebhookDelivery).
+		PlaceholderFormat(sq.Dollar).
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("next_attempt_at", delivery.NextAttemptAt.UTC()).
+		Set("last_status_code", delivery.LastStatusCode).
+		Set("last_error", delivery.LastError).
+		Set("delivered_at", deliveredAt).
+		Where(sq.Eq{"id": delivery.ID})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+func webhookFromRow(row pgx.Row) (*Webhook, error) {
+	var w Webhook
+	var events []string
+	err := row.Scan(
+		&w.ID,
+		&w.URL,
+		&w.Secret,
+		&events,
+		&w.Description,
+		&w.Active,
+		&w.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		w.Events = append(w.Events, event.Type(e))
+	}
+	return &w, nil
+}
+
+// deliveryFromRow scans a delivery, withWebhook scans url and secret of the webhook as well
+func deliveryFromRow(row pgx.Row, withWebhook bool) (*Delivery, error) {
+	var d Delivery
+	var deliveredAt *time.Time
+	dest := []any{
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&deliveredAt,
+		&d.CreatedAt,
+	}
+	if withWebhook {
+		dest = append(dest, &d.URL, &d.Secret)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if deliveredAt != nil {
+		d.DeliveredAt = *deliveredAt
+	}
+	return &d, nil
+}
+
+func eventStrings(events []event.Type) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, string(e))
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import "github.com/lardira/playtrack/internal/pkg/event"
+
+type RequestWebhook struct {
+	ID int `path:"id"`
+}
+
+type RequestCreateWebhook struct {
+	Body struct {
+		URL         string       `json:"url" format:"uri" maxLength:"2048"`
+		Events      []event.Type `json:"events" minItems:"1" uniqueItems:"true" enum:"played_game.added,played_game.started,played_game.completed,played_game.dropped,played_game.rerolled,game.created,leaderboard.changed"`
+		Description *string      `json:"description" required:"false" maxLength:"256"`
+	}
+}
+
+type RequestUpdateWebhook struct {
+	ID   int `path:"id"`
+	Body struct {
+		URL         *string      `json:"url" required:"false" format:"uri" maxLength:"2048"`
+		Events      []event.Type `json:"events" required:"false" minItems:"1" uniqueItems:"true" enum:"played_game.added,played_game.started,played_game.completed,played_game.dropped,played_game.rerolled,game.created,leaderboard.changed"`
+		Description *string      `json:"description" required:"false" maxLength:"256"`
+		Active      *bool        `json:"active" required:"false"`
+	}
+}
+
+type RequestWebhookDeliveries struct {
+	ID    int `path:"id"`
+	Limit int `query:"limit" default:"50" minimum:"1" maximum:"500"`
+}
+
+type ResponseCreatedWebhook struct {
+	Body struct {
+		Item *Webhook `json:"item"`
+		// Secret signs payloads, it is shown only once
+		Secret string `json:"secret" readOnly:"true"`
+	}
+}
@@ -0,0 +1,359 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+// SQLiteRepository stores webhooks in sqlite, events are kept as a json array.
+// Writes to sqlite are serialized so deliveries are claimed without row locks.
+type SQLiteRepository struct {
+	db *sql.DB
+	tx *db.SQLiteTxManager
+}
+
+func NewSQLiteRepository(sqlDB *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{
+		db: sqlDB,
+		tx: db.NewSQLiteTxManager(sqlDB),
+	}
+}
+
+func (r *SQLiteRepository) FindAll(ctx context.Context) ([]Webhook, error) {
+	out := make([]Webhook, 0)
+
+	sqlBuild := sq.Select(webhookColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableWebhook).
+		OrderBy("id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := sqliteWebhookFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *w)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) FindOne(ctx context.Context, id int) (*Webhook, error) {
+	sqlBuild := sq.Select(webhookColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableWebhook).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := sqliteWebhookFromRow(db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *SQLiteRepository) Insert(ctx context.Context, webhook *Webhook) (int, error) {
+	var id int
+
+	events, err := db.SQLiteJSON(eventStrings(webhook.Events))
+	if err != nil {
+		return id, err
+	}
+
+	sqlBuild := sq.Insert(TableWebhook).
+		PlaceholderFormat(sq.Question).
+		Columns("url", "secret", "events", "description", "active").
+		Values(webhook.URL, webhook.Secret, events, webhook.Description, webhook.Active).
+		Suffix("RETURNING id")
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, webhook *WebhookUpdate) (int, error) {
+	var id int
+
+	updBuild := sq.Update(TableWebhook).PlaceholderFormat(sq.Question)
+
+	if webhook.URL != nil {
+		updBuild = updBuild.Set("url", *webhook.URL)
+	}
+	if webhook.Events != nil {
+		events, err := db.SQLiteJSON(eventStrings(webhook.Events))
+		if err != nil {
+			return id, err
+		}
+		updBuild = updBuild.Set("events", events)
+	}
+	if webhook.Description != nil {
+		updBuild = updBuild.Set("description", *webhook.Description)
+	}
+	if webhook.Active != nil {
+		updBuild = updBuild.Set("active", *webhook.Active)
+	}
+
+	query, args, err := updBuild.Where(sq.Eq{"id": webhook.ID}).Suffix("RETURNING id").ToSql()
+	if err != nil {
+		return id, err
+	}
+
+	row := db.SQLiteConn(ctx, r.db).QueryRowContext(ctx, query, args...)
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return id, ErrWebhookNotFound
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, id int) error {
+	sqlBuild := sq.Delete(TableWebhook).
+		PlaceholderFormat(sq.Question).
+		Where(sq.Eq{"id": id})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// FindDeliveries returns the last deliveries of the webhook, newest first
+func (r *SQLiteRepository) FindDeliveries(ctx context.Context, webhookID int, limit int) ([]Delivery, error) {
+	sqlBuild := sq.Select(deliveryColumns).
+		PlaceholderFormat(sq.Question).
+		From(TableWebhookDelivery).
+		Where(sq.Eq{"webhook_id": webhookID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+
+	return r.findDeliveries(ctx, sqlBuild, false)
+}
+
+// Publish queues the event for every active webhook subscribed to it. It joins the transaction
+// of ctx so the event is sent only if the change is committed.
+func (r *SQLiteRepository) Publish(ctx context.Context, e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	sqlBuild := sq.Insert(TableWebhookDelivery).
+		PlaceholderFormat(sq.Question).
+		Columns("webhook_id", "event_id", "event_type", "payload", "next_attempt_at").
+		Select(
+			sq.Select("id").
+				Column("?", e.ID).
+				Column("?", string(e.Type)).
+				Column("?", string(payload)).
+				Column("?", db.SQLiteTime(e.OccurredAt)).
+				From(TableWebhook).
+				Where("active AND EXISTS (SELECT 1 FROM json_each(events) WHERE value = ?)", string(e.Type)),
+		)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// ClaimDue returns pending deliveries due at now and postpones them by lease
+func (r *SQLiteRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	var out []Delivery
+	err := r.tx.WithTx(ctx, func(ctx context.Context) error {
+		sqlBuild := sq.Select(
+			"d.id", "d.webhook_id", "d.event_id", "d.event_type", "d.payload", "d.status", "d.attempts",
+			"d.next_attempt_at", "d.last_status_code", "d.last_error", "d.delivered_at", "d.created_at",
+			"w.url", "w.secret",
+		).
+			PlaceholderFormat(sq.Question).
+			From(TableWebhookDelivery + " d").
+			Join(TableWebhook + " w ON w.id = d.webhook_id").
+			Where(sq.Eq{"d.status": DeliveryStatusPending}).
+			Where(sq.LtOrEq{"d.next_attempt_at": db.SQLiteTime(now)}).
+			OrderBy("d.id").
+			Limit(uint64(limit))
+
+		var err error
+		out, err = r.findDeliveries(ctx, sqlBuild, true)
+		if err != nil || len(out) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(out))
+		for i := range out {
+			ids = append(ids, out[i].ID)
+		}
+		nextAttemptAt := now.Add(lease)
+
+		query, args, err := sq.Update(TableWebhookDelivery).
+			PlaceholderFormat(sq.Question).
+			Set("next_attempt_at", db.SQLiteTime(nextAttemptAt)).
+			Where(sq.Eq{"id": ids}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		for i := range out {
+			out[i].NextAttemptAt = nextAttemptAt.UTC().Truncate(time.Microsecond)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SaveAttempt stores the result of a delivery attempt
+func (r *SQLiteRepository) SaveAttempt(ctx context.Context, delivery *Delivery) error {
+	var deliveredAt *time.Time
+	if !delivery.DeliveredAt.IsZero() {
+		deliveredAt = &delivery.DeliveredAt
+	}
+
+	sqlBuild := sq.Update(TableWebhookDelivery).
+		PlaceholderFormat(sq.Question).
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("next_attempt_at", db.SQLiteTime(delivery.NextAttemptAt)).
+		Set("last_status_code", delivery.LastStatusCode).
+		Set("last_error", delivery.LastError).
+		Set("delivered_at", db.SQLiteTimePtr(deliveredAt)).
+		Where(sq.Eq{"id": delivery.ID})
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.SQLiteConn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *SQLiteRepository) findDeliveries(ctx context.Context, sqlBuild sq.SelectBuilder, withWebhook bool) ([]Delivery, error) {
+	out := make([]Delivery, 0)
+
+	query, args, err := sqlBuild.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SQLiteConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := sqliteDeliveryFromRow(rows, withWebhook)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
+
+func sqliteWebhookFromRow(row pgx.Row) (*Webhook, error) {
+	var w Webhook
+	var events []string
+	err := row.Scan(
+		&w.ID,
+		&w.URL,
+		&w.Secret,
+		db.SQLiteJSONScanner(&events),
+		&w.Description,
+		&w.Active,
+		&w.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		w.Events = append(w.Events, event.Type(e))
+	}
+	return &w, nil
+}
+
+// sqliteDeliveryFromRow scans a delivery like deliveryFromRow, the payload is a text
+func sqliteDeliveryFromRow(row pgx.Row, withWebhook bool) (*Delivery, error) {
+	var d Delivery
+	var payload string
+	var deliveredAt *time.Time
+	dest := []any{
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&deliveredAt,
+		&d.CreatedAt,
+	}
+	if withWebhook {
+		dest = append(dest, &d.URL, &d.Secret)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	d.Payload = json.RawMessage(payload)
+	if deliveredAt != nil {
+		d.DeliveredAt = *deliveredAt
+	}
+	return &d, nil
+}
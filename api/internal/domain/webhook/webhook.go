@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+const (
+	secretBytes = 32
+
+	// SignaturePrefix names the hash of the signature header value
+	SignaturePrefix = "sha256="
+)
+
+const (
+	HeaderEvent     = "X-Playtrack-Event"
+	HeaderDelivery  = "X-Playtrack-Delivery"
+	HeaderTimestamp = "X-Playtrack-Timestamp"
+	HeaderSignature = "X-Playtrack-Signature"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook is not found")
+	ErrInvalidURL      = errors.New("url must be an absolute http or https url")
+	ErrNoEvents        = errors.New("at least one event is required")
+	ErrInvalidEvent    = fmt.Errorf("event must be one of %v", event.Types)
+)
+
+// Webhook is an endpoint registered by an admin which receives events it is subscribed to
+type Webhook struct {
+	ID          int          `json:"id"`
+	URL         string       `json:"url"`
+	Secret      string       `json:"-"`
+	Events      []event.Type `json:"events"`
+	Description *string      `json:"description"`
+	Active      bool         `json:"active"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+func (w *Webhook) Valid() error {
+	if err := validURL(w.URL); err != nil {
+		return err
+	}
+	return validEvents(w.Events)
+}
+
+func (w *Webhook) Subscribed(t event.Type) bool {
+	return slices.Contains(w.Events, t)
+}
+
+type WebhookUpdate struct {
+	ID          int
+	URL         *string
+	Events      []event.Type
+	Description *string
+	Active      *bool
+}
+
+func (w *WebhookUpdate) Valid() error {
+	if w.URL != nil {
+		if err := validURL(*w.URL); err != nil {
+			return err
+		}
+	}
+	if w.Events != nil {
+		return validEvents(w.Events)
+	}
+	return nil
+}
+
+// Delivery is an event queued for a webhook, it is kept after sending as a delivery log
+type Delivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        string          `json:"event_id" format:"uuid"`
+	EventType      event.Type      `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    time.Time       `json:"delivered_at,omitzero"`
+	CreatedAt      time.Time       `json:"created_at"`
+
+	// URL and Secret of the webhook are loaded for sending
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// GenerateSecret returns a random secret for signing payloads
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature of the payload sent at timestamp. Receivers compute
+// hmac-sha256 of "<timestamp>.<body>" with the secret and compare it with the header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the payload
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Backoff returns the delay before the next attempt, it doubles from base up to max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
+
+func validURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+func validEvents(events []event.Type) error {
+	if len(events) == 0 {
+		return ErrNoEvents
+	}
+	for _, e := range events {
+		if !slices.Contains(event.Types, e) {
+			return ErrInvalidEvent
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+func TestWebhookValid(t *testing.T) {
+	tcases := []struct {
+		name    string
+		webhook Webhook
+		err     error
+	}{
+		{
+			"valid",
+			Webhook{URL: "https://discord.example/hook", Events: []event.Type{event.PlayedGameCompleted}},
+			nil,
+		},
+		{
+			"relative url",
+			Webhook{URL: "/hook", Events: []event.Type{event.PlayedGameCompleted}},
+			ErrInvalidURL,
+		},
+		{
+			"not http",
+			Webhook{URL: "ftp://example.com/hook", Events: []event.Type{event.PlayedGameCompleted}},
+			ErrInvalidURL,
+		},
+		{
+			"no events",
+			Webhook{URL: "https://example.com/hook"},
+			ErrNoEvents,
+		},
+		{
+			"unknown event",
+			Webhook{URL: "https://example.com/hook", Events: []event.Type{"player.deleted"}},
+			ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.IsError(t, tt.webhook.Valid(), tt.err)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"game.created"}`)
+	timestamp := time.Now().Unix()
+
+	signature := Sign("secret", timestamp, body)
+	assert.True(t, Verify("secret", timestamp, body, signature))
+	assert.False(t, Verify("other", timestamp, body, signature))
+	assert.False(t, Verify("secret", timestamp+1, body, signature))
+	assert.False(t, Verify("secret", timestamp, []byte(`{}`), signature))
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, time.Minute, Backoff(2, base, max))
+	assert.Equal(t, 4*time.Minute, Backoff(4, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(100, base, max))
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/lardira/playtrack/internal/domain/account"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/challenge"
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+type AccountRepository struct {
+	s *Store
+}
+
+func NewAccountRepository(store *Store) *AccountRepository {
+	return &AccountRepository{
+		s: store,
+	}
+}
+
+// Anonymize removes personal data of the player and their credentials, played
+// games stay without comments so standings of other players do not change
+func (r *AccountRepository) Anonymize(ctx context.Context, playerID string) error {
+	err := account.ErrPlayerNotFound
+	r.s.write(func(t *tables) {
+		i := t.playerIndex(playerID)
+		if i < 0 {
+			return
+		}
+		err = nil
+
+		p := t.players[i]
+		t.players[i] = player.Player{
+			ID:               p.ID,
+			Username:         account.AnonymousUsername(p.ID),
+			CreatedAt:        p.CreatedAt,
+			TokensValidAfter: now(),
+			Suspension:       p.Suspension,
+		}
+
+		remove(&t.authTokens, func(row authTokenRow) bool { return row.PlayerID == playerID })
+		remove(&t.oauthStates, func(row oauthStateRow) bool { return row.PlayerID == playerID })
+		remove(&t.apiTokens, func(token apitoken.Token) bool { return token.PlayerID == playerID })
+		remove(&t.identities, func(identity auth.Identity) bool { return identity.PlayerID == playerID })
+		remove(&t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID })
+		for j, pg := range t.played {
+			if pg.PlayerID == playerID {
+				t.played[j].Comment = nil
+			}
+		}
+	})
+	return err
+}
+
+// Erase deletes the player with all their data like foreign keys do in postgres
+func (r *AccountRepository) Erase(ctx context.Context, playerID string) error {
+	err := account.ErrPlayerNotFound
+	r.s.write(func(t *tables) {
+		if remove(&t.players, func(p player.Player) bool { return p.ID == playerID }) == 0 {
+			return
+		}
+		err = nil
+
+		played := make(map[int]bool)
+		for _, pg := range t.played {
+			if pg.PlayerID == playerID {
+				played[pg.ID] = true
+			}
+		}
+		remove(&t.played, func(pg player.PlayedGame) bool { return played[pg.ID] })
+		remove(&t.streaks, func(s player.Streak) bool { return s.PlayerID == playerID })
+		remove(&t.ledger, func(e player.LedgerEntry) bool {
+			return e.PlayerID == playerID || played[e.PlayedGameID]
+		})
+		remove(&t.adjustments, func(a player.Adjustment) bool {
+			return a.PlayerID == playerID || played[a.PlayedGameID]
+		})
+		for j, a := range t.adjustments {
+			if a.AdjustedBy != nil && *a.AdjustedBy == playerID {
+				t.adjustments[j].AdjustedBy = nil
+			}
+		}
+		remove(&t.authTokens, func(row authTokenRow) bool { return row.PlayerID == playerID })
+		remove(&t.oauthStates, func(row oauthStateRow) bool { return row.PlayerID == playerID })
+		remove(&t.apiTokens, func(token apitoken.Token) bool { return token.PlayerID == playerID })
+		remove(&t.identities, func(identity auth.Identity) bool { return identity.PlayerID == playerID })
+		remove(&t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID })
+		remove(&t.achievements, func(a achievementRow) bool { return a.PlayerID == playerID })
+
+		remove(&t.challenges, func(c challenge.Challenge) bool { return c.ChallengerID == playerID })
+		for j, c := range t.challenges {
+			if c.WinnerID != nil && *c.WinnerID == playerID {
+				t.challenges[j].WinnerID = nil
+			}
+			participants := make([]challenge.Participant, 0, len(c.Participants))
+			for _, p := range c.Participants {
+				if p.PlayerID == playerID {
+					continue
+				}
+				if p.PlayedGameID != nil && played[*p.PlayedGameID] {
+					p.PlayedGameID = nil
+				}
+				participants = append(participants, p)
+			}
+			t.challenges[j].Participants = participants
+		}
+	})
+	return err
+}
+
+// Export returns rows of the player in tables named and ordered as in the postgres export,
+// rows are the json representation of the api
+func (r *AccountRepository) Export(ctx context.Context, playerID string) ([]account.ExportTable, error) {
+	out := make([]account.ExportTable, 0)
+	var err error
+	add := func(name string, rows []any) {
+		if err != nil {
+			return
+		}
+		table := account.ExportTable{Name: name, Rows: make([]json.RawMessage, 0, len(rows))}
+		for _, row := range rows {
+			var data []byte
+			if data, err = json.Marshal(row); err != nil {
+				return
+			}
+			table.Rows = append(table.Rows, data)
+		}
+		out = append(out, table)
+	}
+
+	r.s.read(func(t *tables) {
+		add("player", rowsOf(filter(t.players, func(p player.Player) bool { return p.ID == playerID })))
+		add("played_game", rowsOf(filter(t.played, func(pg player.PlayedGame) bool { return pg.PlayerID == playerID })))
+		add("player_streak", rowsOf(filter(t.streaks, func(s player.Streak) bool { return s.PlayerID == playerID })))
+		add("points_ledger", rowsOf(filter(t.ledger, func(e player.LedgerEntry) bool { return e.PlayerID == playerID })))
+		add("points_adjustment", rowsOf(filter(t.adjustments, func(a player.Adjustment) bool { return a.PlayerID == playerID })))
+		add("backlog_item", rowsOf(filter(t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID })))
+
+		challenges := make([]any, 0)
+		participants := make([]any, 0)
+		for _, c := range t.challenges {
+			i := slices.IndexFunc(c.Participants, func(p challenge.Participant) bool { return p.PlayerID == playerID })
+			if i >= 0 {
+				participants = append(participants, map[string]any{
+					"challenge_id":   c.ID,
+					"player_id":      playerID,
+					"status":         c.Participants[i].Status,
+					"played_game_id": c.Participants[i].PlayedGameID,
+				})
+			}
+			if c.ChallengerID == playerID {
+				// participants are exported as their own table
+				c.Participants = nil
+				challenges = append(challenges, c)
+			}
+		}
+		add("challenge", challenges)
+		add("challenge_participant", participants)
+
+		achievements := make([]any, 0)
+		for _, a := range t.achievements {
+			if a.PlayerID == playerID {
+				achievements = append(achievements, map[string]any{
+					"id":          a.id,
+					"player_id":   a.PlayerID,
+					"code":        a.Code,
+					"unlocked_at": a.UnlockedAt,
+				})
+			}
+		}
+		add("achievement", achievements)
+		add("identity", rowsOf(filter(t.identities, func(identity auth.Identity) bool { return identity.PlayerID == playerID })))
+		add("api_token", rowsOf(filter(t.apiTokens, func(token apitoken.Token) bool { return token.PlayerID == playerID })))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func rowsOf[T any](rows []T) []any {
+	out := make([]any, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, row)
+	}
+	return out
+}
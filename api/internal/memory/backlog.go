@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/lardira/playtrack/internal/domain/backlog"
+)
+
+// BacklogRepository keeps positions of a player dense like the postgres one
+type BacklogRepository struct {
+	s *Store
+}
+
+func NewBacklogRepository(store *Store) *BacklogRepository {
+	return &BacklogRepository{
+		s: store,
+	}
+}
+
+func (r *BacklogRepository) FindAll(ctx context.Context, playerID string) ([]backlog.Item, error) {
+	var out []backlog.Item
+	r.s.read(func(t *tables) {
+		out = t.backlogOf(playerID)
+	})
+	return out, nil
+}
+
+func (r *BacklogRepository) FindOne(ctx context.Context, playerID string, id int) (*backlog.Item, error) {
+	var out *backlog.Item
+	r.s.read(func(t *tables) {
+		if i := t.backlogIndex(playerID, id); i >= 0 {
+			item := t.backlog[i]
+			out = &item
+		}
+	})
+	if out == nil {
+		return nil, backlog.ErrItemNotFound
+	}
+	return out, nil
+}
+
+// Insert appends the item to the end of the backlog
+func (r *BacklogRepository) Insert(ctx context.Context, item *backlog.Item) (int, error) {
+	var id int
+	var err error
+	r.s.write(func(t *tables) {
+		items := t.backlogOf(item.PlayerID)
+		if slices.ContainsFunc(items, func(other backlog.Item) bool { return other.GameID == item.GameID }) {
+			err = backlog.ErrItemExists
+			return
+		}
+		id = int(t.nextID("backlog_item"))
+		t.backlog = append(t.backlog, backlog.Item{
+			ID:        id,
+			PlayerID:  item.PlayerID,
+			GameID:    item.GameID,
+			Position:  len(items),
+			Note:      item.Note,
+			CreatedAt: now(),
+		})
+	})
+	return id, err
+}
+
+func (r *BacklogRepository) UpdateNote(ctx context.Context, playerID string, id int, note *string) error {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.backlogIndex(playerID, id)
+		if i < 0 {
+			err = backlog.ErrItemNotFound
+			return
+		}
+		t.backlog[i].Note = note
+	})
+	return err
+}
+
+// Move places the item at position shifting items in between, position is
+// clamped to the size of the backlog
+func (r *BacklogRepository) Move(ctx context.Context, playerID string, id int, position int) error {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.backlogIndex(playerID, id)
+		if i < 0 {
+			err = backlog.ErrItemNotFound
+			return
+		}
+		from := t.backlog[i].Position
+		position = max(0, min(position, len(t.backlogOf(playerID))-1))
+
+		for j, item := range t.backlog {
+			if item.PlayerID != playerID {
+				continue
+			}
+			switch {
+			case item.ID == id:
+				t.backlog[j].Position = position
+			case position < from && item.Position >= position && item.Position < from:
+				t.backlog[j].Position++
+			case position > from && item.Position > from && item.Position <= position:
+				t.backlog[j].Position--
+			}
+		}
+	})
+	return err
+}
+
+func (r *BacklogRepository) Delete(ctx context.Context, playerID string, id int) error {
+	return r.delete(playerID, func(item backlog.Item) bool { return item.ID == id })
+}
+
+// DeleteByGame removes the game from the backlog of the player if it is there
+func (r *BacklogRepository) DeleteByGame(ctx context.Context, playerID string, gameID int) error {
+	err := r.delete(playerID, func(item backlog.Item) bool { return item.GameID == gameID })
+	if errors.Is(err, backlog.ErrItemNotFound) {
+		return nil
+	}
+	return err
+}
+
+// delete removes an item and closes the gap in positions
+func (r *BacklogRepository) delete(playerID string, fn func(backlog.Item) bool) error {
+	var err error
+	r.s.write(func(t *tables) {
+		i := find(t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID && fn(item) })
+		if i < 0 {
+			err = backlog.ErrItemNotFound
+			return
+		}
+		position := t.backlog[i].Position
+		t.backlog = slices.Delete(t.backlog, i, i+1)
+		t.closeBacklogGap(playerID, position)
+	})
+	return err
+}
+
+// backlogOf returns items of the player ordered by position
+func (t *tables) backlogOf(playerID string) []backlog.Item {
+	out := filter(t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID })
+	slices.SortFunc(out, func(a, b backlog.Item) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+func (t *tables) backlogIndex(playerID string, id int) int {
+	return find(t.backlog, func(item backlog.Item) bool { return item.PlayerID == playerID && item.ID == id })
+}
+
+// closeBacklogGap shifts items of the player after the removed position
+func (t *tables) closeBacklogGap(playerID string, position int) {
+	for j, item := range t.backlog {
+		if item.PlayerID == playerID && item.Position > position {
+			t.backlog[j].Position--
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/challenge"
+)
+
+type ChallengeRepository struct {
+	s *Store
+}
+
+func NewChallengeRepository(store *Store) *ChallengeRepository {
+	return &ChallengeRepository{
+		s: store,
+	}
+}
+
+// FindAll returns challenges the player participates in, only open ones unless all is set
+func (r *ChallengeRepository) FindAll(ctx context.Context, playerID string, all bool) ([]challenge.Challenge, error) {
+	out := r.find(func(c challenge.Challenge) bool {
+		return (all || c.Open()) && slices.ContainsFunc(c.Participants, func(p challenge.Participant) bool {
+			return p.PlayerID == playerID
+		})
+	})
+	slices.SortFunc(out, func(a, b challenge.Challenge) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return out, nil
+}
+
+func (r *ChallengeRepository) FindOne(ctx context.Context, id int) (*challenge.Challenge, error) {
+	return r.findOne(func(c challenge.Challenge) bool { return c.ID == id })
+}
+
+// FindOpenByPlayedGame returns the open challenge the played game is linked to
+func (r *ChallengeRepository) FindOpenByPlayedGame(ctx context.Context, playedGameID int) (*challenge.Challenge, error) {
+	return r.findOne(func(c challenge.Challenge) bool {
+		return c.Open() && slices.ContainsFunc(c.Participants, func(p challenge.Participant) bool {
+			return p.PlayedGameID != nil && *p.PlayedGameID == playedGameID
+		})
+	})
+}
+
+// FindDue returns open challenges past their expiration which still have invited players
+func (r *ChallengeRepository) FindDue(ctx context.Context, now time.Time) ([]challenge.Challenge, error) {
+	return r.find(func(c challenge.Challenge) bool {
+		return c.Open() && !c.ExpiresAt.After(now) && slices.ContainsFunc(c.Participants, func(p challenge.Participant) bool {
+			return p.Status == challenge.ParticipantInvited
+		})
+	}), nil
+}
+
+// Insert inserts the challenge with its participants
+func (r *ChallengeRepository) Insert(ctx context.Context, c *challenge.Challenge) (int, error) {
+	stored := challenge.Challenge{
+		ChallengerID: c.ChallengerID,
+		GameID:       c.GameID,
+		Mode:         c.Mode,
+		Bonus:        c.Bonus,
+		Status:       c.Status,
+		ExpiresAt:    timestamp(c.ExpiresAt),
+		CreatedAt:    now(),
+		Participants: slices.Clone(c.Participants),
+	}
+	r.s.write(func(t *tables) {
+		stored.ID = int(t.nextID("challenge"))
+		t.challenges = append(t.challenges, stored)
+	})
+	return stored.ID, nil
+}
+
+func (r *ChallengeRepository) Update(ctx context.Context, u *challenge.ChallengeUpdate) (int, error) {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.challengeIndex(u.ID)
+		if i < 0 {
+			err = challenge.ErrChallengeNotFound
+			return
+		}
+		c := t.challenges[i]
+		if u.Status != nil {
+			c.Status = *u.Status
+		}
+		if u.WinnerID != nil {
+			c.WinnerID = u.WinnerID
+		}
+		if u.FinishedAt != nil {
+			c.FinishedAt = timestampPtr(u.FinishedAt)
+		}
+		t.challenges[i] = c
+	})
+	if err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (r *ChallengeRepository) UpdateParticipant(ctx context.Context, challengeID int, p *challenge.Participant) error {
+	err := challenge.ErrParticipantNotFound
+	r.s.write(func(t *tables) {
+		i := t.challengeIndex(challengeID)
+		if i < 0 {
+			return
+		}
+		t.updateParticipants(i, func(other challenge.Participant) (challenge.Participant, bool) {
+			if other.PlayerID != p.PlayerID {
+				return other, false
+			}
+			err = nil
+			other.Status = p.Status
+			other.PlayedGameID = p.PlayedGameID
+			return other, true
+		})
+	})
+	return err
+}
+
+// ExpireInvitations expires invitations of the challenge nobody answered
+func (r *ChallengeRepository) ExpireInvitations(ctx context.Context, challengeID int) error {
+	r.s.write(func(t *tables) {
+		i := t.challengeIndex(challengeID)
+		if i < 0 {
+			return
+		}
+		t.updateParticipants(i, func(p challenge.Participant) (challenge.Participant, bool) {
+			if p.Status != challenge.ParticipantInvited {
+				return p, false
+			}
+			p.Status = challenge.ParticipantExpired
+			return p, true
+		})
+	})
+	return nil
+}
+
+func (r *ChallengeRepository) findOne(fn func(challenge.Challenge) bool) (*challenge.Challenge, error) {
+	challenges := r.find(fn)
+	if len(challenges) == 0 {
+		return nil, challenge.ErrChallengeNotFound
+	}
+	return &challenges[0], nil
+}
+
+// find returns copies of challenges with their participants ordered by id
+func (r *ChallengeRepository) find(fn func(challenge.Challenge) bool) []challenge.Challenge {
+	var out []challenge.Challenge
+	r.s.read(func(t *tables) {
+		out = filter(t.challenges, fn)
+	})
+	for i := range out {
+		out[i].Participants = slices.Clone(out[i].Participants)
+	}
+	return out
+}
+
+func (t *tables) challengeIndex(id int) int {
+	return find(t.challenges, func(c challenge.Challenge) bool { return c.ID == id })
+}
+
+// updateParticipants replaces participants of the challenge at i changed by fn,
+// participants are copied as the old slice may be shared with a snapshot
+func (t *tables) updateParticipants(i int, fn func(challenge.Participant) (challenge.Participant, bool)) {
+	participants := slices.Clone(t.challenges[i].Participants)
+	for j, p := range participants {
+		if changed, ok := fn(p); ok {
+			participants[j] = changed
+		}
+	}
+	t.challenges[i].Participants = participants
+}
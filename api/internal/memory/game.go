@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lardira/playtrack/internal/domain/game"
+)
+
+type GameRepository struct {
+	s *Store
+}
+
+func NewGameRepository(store *Store) *GameRepository {
+	return &GameRepository{
+		s: store,
+	}
+}
+
+func (r *GameRepository) FindAll(ctx context.Context) ([]game.Game, error) {
+	var out []game.Game
+	r.s.read(func(t *tables) {
+		out = filter(t.games, func(game.Game) bool { return true })
+	})
+	return out, nil
+}
+
+func (r *GameRepository) FindOne(ctx context.Context, id int) (*game.Game, error) {
+	var out *game.Game
+	r.s.read(func(t *tables) {
+		if i := find(t.games, func(g game.Game) bool { return g.ID == id }); i >= 0 {
+			g := t.games[i]
+			out = &g
+		}
+	})
+	if out == nil {
+		return nil, game.ErrGameNotFound
+	}
+	return out, nil
+}
+
+func (r *GameRepository) FindByTitle(ctx context.Context, title string) (*game.Game, error) {
+	var out *game.Game
+	r.s.read(func(t *tables) {
+		if i := find(t.games, func(g game.Game) bool { return g.Title == title }); i >= 0 {
+			g := t.games[i]
+			out = &g
+		}
+	})
+	if out == nil {
+		return nil, game.ErrGameNotFound
+	}
+	return out, nil
+}
+
+func (r *GameRepository) Insert(ctx context.Context, g *game.Game) (int, error) {
+	var id int
+	var err error
+	r.s.write(func(t *tables) {
+		if find(t.games, func(other game.Game) bool { return other.Title == g.Title }) >= 0 {
+			err = game.ErrFoundByTitle
+			return
+		}
+		id = int(t.nextID("game"))
+		t.games = append(t.games, game.Game{
+			ID:          id,
+			Points:      g.Points,
+			HoursToBeat: g.HoursToBeat,
+			Title:       g.Title,
+			URL:         g.URL,
+			CreatedAt:   now(),
+		})
+	})
+	return id, err
+}
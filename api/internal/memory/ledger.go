@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+type StreakRepository struct {
+	s *Store
+}
+
+func NewStreakRepository(store *Store) *StreakRepository {
+	return &StreakRepository{
+		s: store,
+	}
+}
+
+func (r *StreakRepository) FindOne(ctx context.Context, playerID string) (*player.Streak, error) {
+	var out *player.Streak
+	r.s.read(func(t *tables) {
+		if i := find(t.streaks, func(s player.Streak) bool { return s.PlayerID == playerID }); i >= 0 {
+			s := t.streaks[i]
+			out = &s
+		}
+	})
+	if out == nil {
+		return nil, player.ErrStreakNotFound
+	}
+	return out, nil
+}
+
+// Save inserts or replaces the streak of the player
+func (r *StreakRepository) Save(ctx context.Context, s *player.Streak) error {
+	streak := *s
+	streak.UpdatedAt = timestamp(s.UpdatedAt)
+
+	r.s.write(func(t *tables) {
+		if i := find(t.streaks, func(other player.Streak) bool { return other.PlayerID == s.PlayerID }); i >= 0 {
+			t.streaks[i] = streak
+			return
+		}
+		t.streaks = append(t.streaks, streak)
+	})
+	return nil
+}
+
+type AdjustmentRepository struct {
+	s *Store
+}
+
+func NewAdjustmentRepository(store *Store) *AdjustmentRepository {
+	return &AdjustmentRepository{
+		s: store,
+	}
+}
+
+// FindAll returns adjustments of the played game, the latest first
+func (r *AdjustmentRepository) FindAll(ctx context.Context, playedGameID int) ([]player.Adjustment, error) {
+	var out []player.Adjustment
+	r.s.read(func(t *tables) {
+		out = filter(t.adjustments, func(a player.Adjustment) bool { return a.PlayedGameID == playedGameID })
+	})
+	slices.SortFunc(out, func(a, b player.Adjustment) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return out, nil
+}
+
+func (r *AdjustmentRepository) Insert(ctx context.Context, a *player.Adjustment) (int, error) {
+	adjustment := player.Adjustment{
+		PlayedGameID: a.PlayedGameID,
+		PlayerID:     a.PlayerID,
+		AdjustedBy:   a.AdjustedBy,
+		PointsBefore: a.PointsBefore,
+		Points:       a.Points,
+		Reason:       a.Reason,
+		CreatedAt:    now(),
+	}
+	r.s.write(func(t *tables) {
+		adjustment.ID = int(t.nextID("points_adjustment"))
+		t.adjustments = append(t.adjustments, adjustment)
+	})
+	return adjustment.ID, nil
+}
+
+type LedgerRepository struct {
+	s *Store
+}
+
+func NewLedgerRepository(store *Store) *LedgerRepository {
+	return &LedgerRepository{
+		s: store,
+	}
+}
+
+// FindAll returns entries of the player in order they were written
+func (r *LedgerRepository) FindAll(ctx context.Context, playerID string) ([]player.LedgerEntry, error) {
+	var out []player.LedgerEntry
+	r.s.read(func(t *tables) {
+		out = filter(t.ledger, func(e player.LedgerEntry) bool { return e.PlayerID == playerID })
+	})
+	return out, nil
+}
+
+func (r *LedgerRepository) Insert(ctx context.Context, entries []player.LedgerEntry) error {
+	r.s.write(func(t *tables) {
+		t.insertLedger(entries)
+	})
+	return nil
+}
+
+// Leaderboard returns totals of all players from the ledger, the best first
+func (r *LedgerRepository) Leaderboard(ctx context.Context) ([]player.LeaderboardPlayer, error) {
+	var out []player.LeaderboardPlayer
+	r.s.read(func(t *tables) {
+		out = make([]player.LeaderboardPlayer, 0, len(t.players))
+		index := make(map[string]int, len(t.players))
+		for _, p := range t.players {
+			index[p.ID] = len(out)
+			out = append(out, player.LeaderboardPlayer{PlayerID: p.ID, Username: p.Username})
+		}
+		for _, e := range t.ledger {
+			if i, ok := index[e.PlayerID]; ok {
+				out[i].Total += e.Delta
+			}
+		}
+		for _, pg := range t.played {
+			i, ok := index[pg.PlayerID]
+			if !ok {
+				continue
+			}
+			switch pg.Status {
+			case player.PlayedGameStatusCompleted:
+				out[i].Completed++
+			case player.PlayedGameStatusDropped:
+				out[i].Dropped++
+			case player.PlayedGameStatusRerolled:
+				out[i].Rerolled++
+			}
+		}
+	})
+	slices.SortFunc(out, func(a, b player.LeaderboardPlayer) int {
+		return cmp.Or(
+			cmp.Compare(b.Total, a.Total),
+			strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username)),
+			strings.Compare(a.PlayerID, b.PlayerID),
+		)
+	})
+	return out, nil
+}
+
+func (t *tables) insertLedger(entries []player.LedgerEntry) {
+	createdAt := now()
+	for _, e := range entries {
+		t.ledger = append(t.ledger, player.LedgerEntry{
+			ID:           int(t.nextID("points_ledger")),
+			PlayerID:     e.PlayerID,
+			PlayedGameID: e.PlayedGameID,
+			Delta:        e.Delta,
+			Reason:       e.Reason,
+			CreatedAt:    createdAt,
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+type PlayedRepository struct {
+	s *Store
+}
+
+func NewPlayedRepository(store *Store) *PlayedRepository {
+	return &PlayedRepository{
+		s: store,
+	}
+}
+
+// FindAll returns played games of the player, not completed ones first and then by the
+// day of completion and id, the latest first
+func (r *PlayedRepository) FindAll(ctx context.Context, playerID string) ([]player.PlayedGame, error) {
+	var out []player.PlayedGame
+	r.s.read(func(t *tables) {
+		out = filter(t.played, func(pg player.PlayedGame) bool { return pg.PlayerID == playerID })
+	})
+	slices.SortFunc(out, func(a, b player.PlayedGame) int {
+		return cmp.Or(
+			compareCompletedDay(b.CompletedAt, a.CompletedAt),
+			cmp.Compare(b.ID, a.ID),
+		)
+	})
+	return out, nil
+}
+
+// FindStuck returns played games of all players in progress since before startedBefore, the oldest first
+func (r *PlayedRepository) FindStuck(ctx context.Context, startedBefore time.Time) ([]player.PlayedGame, error) {
+	var out []player.PlayedGame
+	r.s.read(func(t *tables) {
+		out = filter(t.played, func(pg player.PlayedGame) bool {
+			return pg.Status == player.PlayedGameStatusInProgress && pg.StartedAt.Before(startedBefore)
+		})
+	})
+	slices.SortFunc(out, func(a, b player.PlayedGame) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return out, nil
+}
+
+// FindNonterminated returns the played game of the player that is not finished,
+// ErrPlayedGameNotFound if there is none
+func (r *PlayedRepository) FindNonterminated(ctx context.Context, playerID string) (*player.PlayedGame, error) {
+	var out *player.PlayedGame
+	r.s.read(func(t *tables) {
+		i := findNonterminated(t.played, playerID, 0)
+		if i >= 0 {
+			pg := t.played[i]
+			out = &pg
+		}
+	})
+	if out == nil {
+		return nil, player.ErrPlayedGameNotFound
+	}
+	return out, nil
+}
+
+func (r *PlayedRepository) FindOne(ctx context.Context, playerID string, id int) (*player.PlayedGame, error) {
+	var out *player.PlayedGame
+	r.s.read(func(t *tables) {
+		i := find(t.played, func(pg player.PlayedGame) bool { return pg.PlayerID == playerID && pg.ID == id })
+		if i >= 0 {
+			pg := t.played[i]
+			out = &pg
+		}
+	})
+	if out == nil {
+		return nil, player.ErrPlayedGameNotFound
+	}
+	return out, nil
+}
+
+func (r *PlayedRepository) Insert(ctx context.Context, game *player.PlayedGame) (int, error) {
+	return r.insert(player.PlayedGame{
+		PlayerID:  game.PlayerID,
+		GameID:    game.GameID,
+		Points:    game.Points,
+		Status:    player.PlayedGameStatusAdded,
+		StartedAt: now(),
+	})
+}
+
+// Import inserts a played game from history with all its fields as they are
+func (r *PlayedRepository) Import(ctx context.Context, game *player.PlayedGame) (int, error) {
+	if err := checkStatus(game.Status); err != nil {
+		return 0, err
+	}
+	return r.insert(player.PlayedGame{
+		PlayerID:    game.PlayerID,
+		GameID:      game.GameID,
+		Points:      game.Points,
+		Comment:     game.Comment,
+		Rating:      game.Rating,
+		Status:      game.Status,
+		StartedAt:   timestamp(game.StartedAt),
+		CompletedAt: timestampPtr(game.CompletedAt),
+		PlayTime:    playTime(game.PlayTime),
+	})
+}
+
+func (r *PlayedRepository) Update(ctx context.Context, game *player.PlayedGameUpdate) (int, error) {
+	if game.Status != nil {
+		if err := checkStatus(*game.Status); err != nil {
+			return 0, err
+		}
+	}
+
+	var err error
+	r.s.write(func(t *tables) {
+		i := find(t.played, func(pg player.PlayedGame) bool { return pg.ID == game.ID })
+		if i < 0 {
+			err = player.ErrPlayedGameNotFound
+			return
+		}
+
+		pg := t.played[i]
+		if game.FromStatus != nil && pg.Status != *game.FromStatus {
+			err = player.ErrPlayedGameStatusChanged
+			return
+		}
+		if game.Points != nil {
+			pg.Points = *game.Points
+		}
+		if game.Comment != nil {
+			pg.Comment = game.Comment
+		}
+		if game.Rating != nil {
+			pg.Rating = game.Rating
+		}
+		if game.Status != nil {
+			pg.Status = *game.Status
+		}
+		if game.CompletedAt != nil {
+			pg.CompletedAt = timestampPtr(game.CompletedAt)
+		}
+		if game.PlayTime != nil {
+			pg.PlayTime = playTime(game.PlayTime)
+		}
+		if !pg.StatusTerminated() && findNonterminated(t.played, pg.PlayerID, pg.ID) >= 0 {
+			err = player.ErrNonterminatedExists
+			return
+		}
+		t.played[i] = pg
+	})
+	if err != nil {
+		return 0, err
+	}
+	return game.ID, nil
+}
+
+// insert keeps a single played game of a player unfinished like the unique index does
+func (r *PlayedRepository) insert(game player.PlayedGame) (int, error) {
+	var err error
+	r.s.write(func(t *tables) {
+		if !game.StatusTerminated() && findNonterminated(t.played, game.PlayerID, 0) >= 0 {
+			err = player.ErrNonterminatedExists
+			return
+		}
+		game.ID = int(t.nextID("played_game"))
+		t.played = append(t.played, game)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return game.ID, nil
+}
+
+// findNonterminated returns the index of the unfinished played game of the player
+// other than the one with id exceptID
+func findNonterminated(played []player.PlayedGame, playerID string, exceptID int) int {
+	return find(played, func(pg player.PlayedGame) bool {
+		return pg.PlayerID == playerID && pg.ID != exceptID && !pg.StatusTerminated()
+	})
+}
+
+// checkStatus rejects values the played_game_status enum does not have
+func checkStatus(status player.PlayedGameStatus) error {
+	switch status {
+	case player.PlayedGameStatusAdded,
+		player.PlayedGameStatusInProgress,
+		player.PlayedGameStatusCompleted,
+		player.PlayedGameStatusDropped,
+		player.PlayedGameStatusRerolled:
+		return nil
+	}
+	return fmt.Errorf("invalid input value for enum played_game_status: %q", status)
+}
+
+// playTime copies the duration as an interval column stores it, in microseconds
+func playTime(d *types.DurationString) *types.DurationString {
+	if d == nil {
+		return nil
+	}
+	ds := types.NewDurationString(d.Duration.Truncate(time.Microsecond))
+	return &ds
+}
+
+// compareCompletedDay compares days of completion, not completed is the latest
+func compareCompletedDay(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.UTC().Truncate(24 * time.Hour).Compare(b.UTC().Truncate(24 * time.Hour))
+}
@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+type PlayerRepository struct {
+	s *Store
+}
+
+func NewPlayerRepository(store *Store) *PlayerRepository {
+	return &PlayerRepository{
+		s: store,
+	}
+}
+
+func (r *PlayerRepository) FindAll(ctx context.Context) ([]player.Player, error) {
+	return r.FindByFilter(ctx, player.PlayerFilter{})
+}
+
+// FindByFilter returns players matching all set fields of the filter ordered by username
+func (r *PlayerRepository) FindByFilter(ctx context.Context, f player.PlayerFilter) ([]player.Player, error) {
+	at := time.Now()
+	username := strings.ToLower(f.Username)
+
+	var out []player.Player
+	r.s.read(func(t *tables) {
+		out = filter(t.players, func(p player.Player) bool {
+			if !strings.Contains(strings.ToLower(p.Username), username) {
+				return false
+			}
+			switch f.Role {
+			case player.RoleFilterAdmin:
+				if !p.IsAdmin {
+					return false
+				}
+			case player.RoleFilterPlayer:
+				if p.IsAdmin {
+					return false
+				}
+			}
+			switch f.State {
+			case player.StateFilterActive:
+				return !p.Suspended(at)
+			case player.StateFilterDeactivated, player.StateFilterBanned:
+				return p.Suspended(at) && string(p.Suspension.Kind) == f.State
+			}
+			return true
+		})
+	})
+	sortPlayers(out)
+	return out, nil
+}
+
+func (r *PlayerRepository) FindOne(ctx context.Context, id string) (*player.Player, error) {
+	return r.findOne(func(p player.Player) bool { return p.ID == id })
+}
+
+func (r *PlayerRepository) FindOneByUsername(ctx context.Context, username string) (*player.Player, error) {
+	username = strings.ToLower(player.NormalizeUsername(username))
+	return r.findOne(func(p player.Player) bool { return strings.ToLower(p.Username) == username })
+}
+
+func (r *PlayerRepository) FindOneByEmail(ctx context.Context, email string) (*player.Player, error) {
+	email = strings.ToLower(player.NormalizeEmail(email))
+	return r.findOne(func(p player.Player) bool {
+		return p.Email != nil && strings.ToLower(*p.Email) == email
+	})
+}
+
+func (r *PlayerRepository) Insert(ctx context.Context, p *player.Player) (string, error) {
+	id := uuid.NewString()
+	var err error
+	r.s.write(func(t *tables) {
+		if err = t.playerTaken(id, &p.Username, p.Email); err != nil {
+			return
+		}
+		t.players = append(t.players, player.Player{
+			ID:            id,
+			Username:      p.Username,
+			Password:      p.Password,
+			IsAdmin:       p.IsAdmin,
+			Img:           p.Img,
+			Email:         p.Email,
+			EmailVerified: p.EmailVerified,
+			CreatedAt:     now(),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *PlayerRepository) Update(ctx context.Context, u *player.PlayerUpdate) (string, error) {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.playerIndex(u.ID)
+		if i < 0 {
+			err = player.ErrPlayerNotFound
+			return
+		}
+		if err = t.playerTaken(u.ID, u.Username, u.Email); err != nil {
+			return
+		}
+
+		p := t.players[i]
+		if u.Email != nil {
+			p.Email = u.Email
+			// a new email must be verified again
+			p.EmailVerified = false
+		}
+		if u.EmailVerified != nil {
+			p.EmailVerified = *u.EmailVerified
+		}
+		if u.Img != nil {
+			p.Img = u.Img
+		}
+		if u.Username != nil {
+			p.Username = *u.Username
+		}
+		if u.Password != nil {
+			p.Password = *u.Password
+		}
+		if u.Description != nil {
+			p.Description = u.Description
+		}
+		if u.MustChangePassword != nil {
+			p.MustChangePassword = *u.MustChangePassword
+		}
+		if u.TokensValidAfter != nil {
+			p.TokensValidAfter = timestamp(*u.TokensValidAfter)
+		}
+		if u.IsAdmin != nil {
+			p.IsAdmin = *u.IsAdmin
+		}
+		t.players[i] = p
+	})
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
+// Suspend sets the suspension of the player, nil lifts it
+func (r *PlayerRepository) Suspend(ctx context.Context, id string, s *player.Suspension) error {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.playerIndex(id)
+		if i < 0 {
+			err = player.ErrPlayerNotFound
+			return
+		}
+		var suspension *player.Suspension
+		if s != nil {
+			suspension = &player.Suspension{
+				Kind:   s.Kind,
+				Reason: s.Reason,
+				At:     timestamp(s.At),
+				Until:  timestampPtr(s.Until),
+			}
+		}
+		t.players[i].Suspension = suspension
+	})
+	return err
+}
+
+// RegisterFailedLogin increments failed login attempts of the player.
+// When maxAttempts is reached the player is locked until now + lockout and attempts are reset.
+func (r *PlayerRepository) RegisterFailedLogin(
+	ctx context.Context,
+	id string,
+	maxAttempts int,
+	lockout time.Duration,
+) (*time.Time, error) {
+	var lockedUntil *time.Time
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.playerIndex(id)
+		if i < 0 {
+			err = player.ErrPlayerNotFound
+			return
+		}
+		p := &t.players[i]
+		if p.FailedLoginAttempts+1 >= maxAttempts {
+			p.FailedLoginAttempts = 0
+			p.LockedUntil = now().Add(lockout)
+		} else {
+			p.FailedLoginAttempts++
+		}
+		if !p.LockedUntil.IsZero() {
+			until := p.LockedUntil
+			lockedUntil = &until
+		}
+	})
+	return lockedUntil, err
+}
+
+func (r *PlayerRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	r.s.write(func(t *tables) {
+		if i := t.playerIndex(id); i >= 0 {
+			t.players[i].FailedLoginAttempts = 0
+			t.players[i].LockedUntil = time.Time{}
+		}
+	})
+	return nil
+}
+
+func (r *PlayerRepository) findOne(fn func(player.Player) bool) (*player.Player, error) {
+	var out *player.Player
+	r.s.read(func(t *tables) {
+		if i := find(t.players, fn); i >= 0 {
+			p := t.players[i]
+			out = &p
+		}
+	})
+	if out == nil {
+		return nil, player.ErrPlayerNotFound
+	}
+	return out, nil
+}
+
+func (t *tables) playerIndex(id string) int {
+	return find(t.players, func(p player.Player) bool { return p.ID == id })
+}
+
+// playerTaken checks the username and the email are unique regardless of the case,
+// the player with id is skipped
+func (t *tables) playerTaken(id string, username, email *string) error {
+	for _, p := range t.players {
+		if p.ID == id {
+			continue
+		}
+		if username != nil && strings.ToLower(p.Username) == strings.ToLower(*username) {
+			return player.ErrUsernameTaken
+		}
+		if email != nil && p.Email != nil && strings.ToLower(*p.Email) == strings.ToLower(*email) {
+			return player.ErrEmailTaken
+		}
+	}
+	return nil
+}
+
+// sortPlayers orders players by username regardless of the case
+func sortPlayers(players []player.Player) {
+	slices.SortFunc(players, func(a, b player.Player) int {
+		return cmp.Or(
+			strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username)),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+}
@@ -0,0 +1,184 @@
+// Package memory keeps all data in the process, it backs the server in the
+// memory storage mode for development and nothing survives a restart.
+// Repositories have the same semantics as the postgres ones.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/challenge"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/domain/webhook"
+)
+
+// Store holds the tables shared by the repositories, a single lock keeps
+// queries touching several tables consistent
+type Store struct {
+	mu sync.RWMutex
+	t  tables
+
+	// txMu runs transactions one at a time
+	txMu sync.Mutex
+}
+
+func NewStore() *Store {
+	return &Store{
+		t: tables{ids: make(map[string]int64)},
+	}
+}
+
+// Ping lets the health checker watch the store as it watches the database
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Store) read(fn func(t *tables)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(&s.t)
+}
+
+func (s *Store) write(fn func(t *tables)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.t)
+}
+
+// tables are kept in order of ids. Slices inside rows are never changed in place,
+// updates replace them, so a copy of the tables is a snapshot.
+type tables struct {
+	ids map[string]int64
+
+	games        []game.Game
+	players      []player.Player
+	played       []player.PlayedGame
+	streaks      []player.Streak
+	adjustments  []player.Adjustment
+	ledger       []player.LedgerEntry
+	backlog      []backlog.Item
+	achievements []achievementRow
+	apiTokens    []apitoken.Token
+	authTokens   []authTokenRow
+	identities   []auth.Identity
+	oauthStates  []oauthStateRow
+	challenges   []challenge.Challenge
+	webhooks     []webhook.Webhook
+	deliveries   []webhook.Delivery
+	discordLinks []discordLinkRow
+}
+
+// nextID returns the next id of the table like a serial column does
+func (t *tables) nextID(table string) int64 {
+	t.ids[table]++
+	return t.ids[table]
+}
+
+func (t *tables) snapshot() tables {
+	return tables{
+		ids:          maps.Clone(t.ids),
+		games:        slices.Clone(t.games),
+		players:      slices.Clone(t.players),
+		played:       slices.Clone(t.played),
+		streaks:      slices.Clone(t.streaks),
+		adjustments:  slices.Clone(t.adjustments),
+		ledger:       slices.Clone(t.ledger),
+		backlog:      slices.Clone(t.backlog),
+		achievements: slices.Clone(t.achievements),
+		apiTokens:    slices.Clone(t.apiTokens),
+		authTokens:   slices.Clone(t.authTokens),
+		identities:   slices.Clone(t.identities),
+		oauthStates:  slices.Clone(t.oauthStates),
+		challenges:   slices.Clone(t.challenges),
+		webhooks:     slices.Clone(t.webhooks),
+		deliveries:   slices.Clone(t.deliveries),
+		discordLinks: slices.Clone(t.discordLinks),
+	}
+}
+
+type txKey struct{}
+
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{
+		store: store,
+	}
+}
+
+// WithTx runs fn in a transaction which is rolled back if fn fails, nested calls join
+// the outer transaction. Transactions run one at a time, the rollback restores all tables
+// so changes made outside of transactions while fn runs are rolled back too.
+func (m *TxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(bool); ok {
+		return fn(ctx)
+	}
+
+	m.store.txMu.Lock()
+	defer m.store.txMu.Unlock()
+
+	var snapshot tables
+	m.store.read(func(t *tables) { snapshot = t.snapshot() })
+
+	ctx, commit := db.WithCommitHooks(context.WithValue(ctx, txKey{}, true))
+	if err := fn(ctx); err != nil {
+		m.store.write(func(t *tables) {
+			// ids are not reused like values of rolled back sequences
+			snapshot.ids = t.ids
+			*t = snapshot
+		})
+		return err
+	}
+	commit()
+	return nil
+}
+
+// now returns the time as a timestamp column stores it
+func now() time.Time {
+	return timestamp(time.Now())
+}
+
+func timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+func timestampPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	ts := timestamp(*t)
+	return &ts
+}
+
+// find returns the index of the first row matching fn or -1
+func find[T any](rows []T, fn func(T) bool) int {
+	return slices.IndexFunc(rows, fn)
+}
+
+// filter returns copies of rows matching fn
+func filter[T any](rows []T, fn func(T) bool) []T {
+	out := make([]T, 0)
+	for _, row := range rows {
+		if fn(row) {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// remove deletes rows matching fn and returns the number of deleted rows
+func remove[T any](rows *[]T, fn func(T) bool) int {
+	n := len(*rows)
+	*rows = slices.DeleteFunc(*rows, fn)
+	return n - len(*rows)
+}
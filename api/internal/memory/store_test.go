@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := t.Context()
+	store := NewStore()
+	games := NewGameRepository(store)
+	txManager := NewTxManager(store)
+
+	committed := false
+	errRollback := errors.New("rollback")
+	err := txManager.WithTx(ctx, func(ctx context.Context) error {
+		db.AfterCommit(ctx, func() { committed = true })
+		return txManager.WithTx(ctx, func(ctx context.Context) error {
+			_, err := games.Insert(ctx, &game.Game{Title: "Celeste", Points: 3, HoursToBeat: 8})
+			assert.NoError(t, err)
+			return errRollback
+		})
+	})
+	assert.IsError(t, err, errRollback)
+	assert.False(t, committed)
+
+	all, err := games.FindAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(all))
+
+	err = txManager.WithTx(ctx, func(ctx context.Context) error {
+		db.AfterCommit(ctx, func() { committed = true })
+		_, err := games.Insert(ctx, &game.Game{Title: "Celeste", Points: 3, HoursToBeat: 8})
+		assert.False(t, committed)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.True(t, committed)
+
+	// the id of the rolled back game is not reused
+	got, err := games.FindByTitle(ctx, "Celeste")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.ID)
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	ctx := t.Context()
+	store := NewStore()
+	players := NewPlayerRepository(store)
+	played := NewPlayedRepository(store)
+	ledger := NewLedgerRepository(store)
+	txManager := NewTxManager(store)
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			err := txManager.WithTx(ctx, func(ctx context.Context) error {
+				id, err := players.Insert(ctx, &player.Player{Username: fmt.Sprintf("player%d", i), Password: "hash"})
+				if err != nil {
+					return err
+				}
+				playedID, err := played.Insert(ctx, &player.PlayedGame{PlayerID: id, GameID: 1, Points: 1})
+				if err != nil {
+					return err
+				}
+				return ledger.Insert(ctx, []player.LedgerEntry{
+					{PlayerID: id, PlayedGameID: playedID, Delta: 1, Reason: player.LedgerReasonCompletion},
+				})
+			})
+			assert.NoError(t, err)
+		})
+		wg.Go(func() {
+			_, err := ledger.Leaderboard(ctx)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	leaderboard, err := ledger.Leaderboard(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, n, len(leaderboard))
+	for _, p := range leaderboard {
+		assert.Equal(t, 1, p.Total)
+	}
+}
@@ -0,0 +1,365 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/discord"
+)
+
+type achievementRow struct {
+	id int
+	achievement.Unlock
+}
+
+type AchievementRepository struct {
+	s *Store
+}
+
+func NewAchievementRepository(store *Store) *AchievementRepository {
+	return &AchievementRepository{
+		s: store,
+	}
+}
+
+func (r *AchievementRepository) FindAll(ctx context.Context, playerID string) ([]achievement.Unlock, error) {
+	var rows []achievementRow
+	r.s.read(func(t *tables) {
+		rows = filter(t.achievements, func(a achievementRow) bool { return a.PlayerID == playerID })
+	})
+	slices.SortFunc(rows, func(a, b achievementRow) int {
+		return cmp.Or(a.UnlockedAt.Compare(b.UnlockedAt), cmp.Compare(a.id, b.id))
+	})
+
+	out := make([]achievement.Unlock, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, row.Unlock)
+	}
+	return out, nil
+}
+
+// Insert stores unlocks the player does not have yet and returns them
+func (r *AchievementRepository) Insert(ctx context.Context, unlocks []achievement.Unlock) ([]achievement.Unlock, error) {
+	out := make([]achievement.Unlock, 0)
+	r.s.write(func(t *tables) {
+		for _, u := range unlocks {
+			exists := slices.ContainsFunc(t.achievements, func(a achievementRow) bool {
+				return a.PlayerID == u.PlayerID && a.Code == u.Code
+			})
+			if exists {
+				continue
+			}
+			u.UnlockedAt = timestamp(u.UnlockedAt)
+			t.achievements = append(t.achievements, achievementRow{id: int(t.nextID("achievement")), Unlock: u})
+			out = append(out, u)
+		}
+	})
+	return out, nil
+}
+
+type APITokenRepository struct {
+	s *Store
+}
+
+func NewAPITokenRepository(store *Store) *APITokenRepository {
+	return &APITokenRepository{
+		s: store,
+	}
+}
+
+func (r *APITokenRepository) FindAll(ctx context.Context, playerID string) ([]apitoken.Token, error) {
+	var out []apitoken.Token
+	r.s.read(func(t *tables) {
+		out = filter(t.apiTokens, func(token apitoken.Token) bool { return token.PlayerID == playerID })
+	})
+	for i := range out {
+		out[i].Scopes = slices.Clone(out[i].Scopes)
+	}
+	return out, nil
+}
+
+func (r *APITokenRepository) FindOneByHash(ctx context.Context, hash string) (*apitoken.Token, error) {
+	var out *apitoken.Token
+	r.s.read(func(t *tables) {
+		if i := find(t.apiTokens, func(token apitoken.Token) bool { return token.Hash == hash }); i >= 0 {
+			token := t.apiTokens[i]
+			token.Scopes = slices.Clone(token.Scopes)
+			out = &token
+		}
+	})
+	if out == nil {
+		return nil, apitoken.ErrTokenNotFound
+	}
+	return out, nil
+}
+
+func (r *APITokenRepository) Insert(ctx context.Context, token *apitoken.Token) (int, error) {
+	stored := apitoken.Token{
+		PlayerID:  token.PlayerID,
+		Name:      token.Name,
+		Scopes:    slices.Clone(token.Scopes),
+		Prefix:    token.Prefix,
+		Hash:      token.Hash,
+		CreatedAt: now(),
+	}
+	if !token.ExpiresAt.IsZero() {
+		stored.ExpiresAt = timestamp(token.ExpiresAt)
+	}
+	r.s.write(func(t *tables) {
+		stored.ID = int(t.nextID("api_token"))
+		t.apiTokens = append(t.apiTokens, stored)
+	})
+	return stored.ID, nil
+}
+
+// Delete revokes the token of the player
+func (r *APITokenRepository) Delete(ctx context.Context, playerID string, id int) error {
+	var n int
+	r.s.write(func(t *tables) {
+		n = remove(&t.apiTokens, func(token apitoken.Token) bool {
+			return token.ID == id && token.PlayerID == playerID
+		})
+	})
+	if n == 0 {
+		return apitoken.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *APITokenRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	r.s.write(func(t *tables) {
+		if i := find(t.apiTokens, func(token apitoken.Token) bool { return token.ID == id }); i >= 0 {
+			t.apiTokens[i].LastUsedAt = timestamp(at)
+		}
+	})
+	return nil
+}
+
+type authTokenRow struct {
+	auth.Token
+	used bool
+}
+
+// TokenRepository stores single-use tokens sent by email
+type TokenRepository struct {
+	s *Store
+}
+
+func NewTokenRepository(store *Store) *TokenRepository {
+	return &TokenRepository{
+		s: store,
+	}
+}
+
+func (r *TokenRepository) Insert(ctx context.Context, token *auth.Token) (int, error) {
+	row := authTokenRow{Token: *token}
+	row.ExpiresAt = timestamp(token.ExpiresAt)
+	row.CreatedAt = now()
+	r.s.write(func(t *tables) {
+		row.ID = int(t.nextID("auth_token"))
+		t.authTokens = append(t.authTokens, row)
+	})
+	return row.ID, nil
+}
+
+// Consume marks the token as used and returns it. Other unused tokens of the player
+// with the same purpose are invalidated as well.
+func (r *TokenRepository) Consume(ctx context.Context, purpose auth.TokenPurpose, hash string) (*auth.Token, error) {
+	at := time.Now()
+	var out *auth.Token
+	r.s.write(func(t *tables) {
+		i := find(t.authTokens, func(row authTokenRow) bool {
+			return row.Purpose == purpose && row.Hash == hash && !row.used && row.ExpiresAt.After(at)
+		})
+		if i < 0 {
+			return
+		}
+		token := t.authTokens[i].Token
+		out = &token
+		for j, row := range t.authTokens {
+			if row.PlayerID == token.PlayerID && row.Purpose == purpose {
+				t.authTokens[j].used = true
+			}
+		}
+	})
+	if out == nil {
+		return nil, auth.ErrTokenNotFound
+	}
+	return out, nil
+}
+
+type IdentityRepository struct {
+	s *Store
+}
+
+func NewIdentityRepository(store *Store) *IdentityRepository {
+	return &IdentityRepository{
+		s: store,
+	}
+}
+
+func (r *IdentityRepository) FindOne(ctx context.Context, provider, subject string) (*auth.Identity, error) {
+	var out *auth.Identity
+	r.s.read(func(t *tables) {
+		i := find(t.identities, func(identity auth.Identity) bool {
+			return identity.Provider == provider && identity.Subject == subject
+		})
+		if i >= 0 {
+			identity := t.identities[i]
+			out = &identity
+		}
+	})
+	if out == nil {
+		return nil, auth.ErrIdentityNotFound
+	}
+	return out, nil
+}
+
+func (r *IdentityRepository) FindAllByPlayer(ctx context.Context, playerID string) ([]auth.Identity, error) {
+	var out []auth.Identity
+	r.s.read(func(t *tables) {
+		out = filter(t.identities, func(identity auth.Identity) bool { return identity.PlayerID == playerID })
+	})
+	return out, nil
+}
+
+// Insert links the identity to the player, ErrIdentityLinked is returned when
+// the identity or the provider is already linked
+func (r *IdentityRepository) Insert(ctx context.Context, identity *auth.Identity) (int, error) {
+	stored := auth.Identity{
+		PlayerID:  identity.PlayerID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: now(),
+	}
+	var err error
+	r.s.write(func(t *tables) {
+		linked := slices.ContainsFunc(t.identities, func(other auth.Identity) bool {
+			return other.Provider == stored.Provider &&
+				(other.Subject == stored.Subject || other.PlayerID == stored.PlayerID)
+		})
+		if linked {
+			err = auth.ErrIdentityLinked
+			return
+		}
+		stored.ID = int(t.nextID("identity"))
+		t.identities = append(t.identities, stored)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return stored.ID, nil
+}
+
+func (r *IdentityRepository) Delete(ctx context.Context, playerID, provider string) error {
+	var n int
+	r.s.write(func(t *tables) {
+		n = remove(&t.identities, func(identity auth.Identity) bool {
+			return identity.PlayerID == playerID && identity.Provider == provider
+		})
+	})
+	if n == 0 {
+		return auth.ErrIdentityNotFound
+	}
+	return nil
+}
+
+type oauthStateRow struct {
+	auth.OAuthState
+	used bool
+}
+
+type OAuthStateRepository struct {
+	s *Store
+}
+
+func NewOAuthStateRepository(store *Store) *OAuthStateRepository {
+	return &OAuthStateRepository{
+		s: store,
+	}
+}
+
+func (r *OAuthStateRepository) Insert(ctx context.Context, state *auth.OAuthState) (int, error) {
+	row := oauthStateRow{OAuthState: *state}
+	row.ExpiresAt = timestamp(state.ExpiresAt)
+	row.CreatedAt = now()
+	r.s.write(func(t *tables) {
+		row.ID = int(t.nextID("oauth_state"))
+		t.oauthStates = append(t.oauthStates, row)
+	})
+	return row.ID, nil
+}
+
+// Consume marks the state as used and returns it, the state is valid only once
+func (r *OAuthStateRepository) Consume(ctx context.Context, provider, hash string) (*auth.OAuthState, error) {
+	at := time.Now()
+	var out *auth.OAuthState
+	r.s.write(func(t *tables) {
+		i := find(t.oauthStates, func(row oauthStateRow) bool {
+			return row.Provider == provider && row.Hash == hash && !row.used && row.ExpiresAt.After(at)
+		})
+		if i < 0 {
+			return
+		}
+		t.oauthStates[i].used = true
+		state := t.oauthStates[i].OAuthState
+		out = &state
+	})
+	if out == nil {
+		return nil, auth.ErrOAuthStateNotFound
+	}
+	return out, nil
+}
+
+type discordLinkRow struct {
+	discord.Link
+	used bool
+}
+
+type DiscordLinkRepository struct {
+	s *Store
+}
+
+func NewDiscordLinkRepository(store *Store) *DiscordLinkRepository {
+	return &DiscordLinkRepository{
+		s: store,
+	}
+}
+
+func (r *DiscordLinkRepository) Insert(ctx context.Context, link *discord.Link) (int, error) {
+	row := discordLinkRow{Link: *link}
+	row.ExpiresAt = timestamp(link.ExpiresAt)
+	row.CreatedAt = now()
+	r.s.write(func(t *tables) {
+		row.ID = int(t.nextID("discord_link"))
+		t.discordLinks = append(t.discordLinks, row)
+	})
+	return row.ID, nil
+}
+
+// Consume marks the link as used and returns it, the link is valid only once
+func (r *DiscordLinkRepository) Consume(ctx context.Context, hash string) (*discord.Link, error) {
+	at := time.Now()
+	var out *discord.Link
+	r.s.write(func(t *tables) {
+		i := find(t.discordLinks, func(row discordLinkRow) bool {
+			return row.Hash == hash && !row.used && row.ExpiresAt.After(at)
+		})
+		if i < 0 {
+			return
+		}
+		t.discordLinks[i].used = true
+		link := t.discordLinks[i].Link
+		out = &link
+	})
+	if out == nil {
+		return nil, discord.ErrLinkNotFound
+	}
+	return out, nil
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/webhook"
+	"github.com/lardira/playtrack/internal/pkg/event"
+)
+
+type WebhookRepository struct {
+	s *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{
+		s: store,
+	}
+}
+
+func (r *WebhookRepository) FindAll(ctx context.Context) ([]webhook.Webhook, error) {
+	var out []webhook.Webhook
+	r.s.read(func(t *tables) {
+		out = filter(t.webhooks, func(webhook.Webhook) bool { return true })
+	})
+	for i := range out {
+		out[i].Events = slices.Clone(out[i].Events)
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) FindOne(ctx context.Context, id int) (*webhook.Webhook, error) {
+	var out *webhook.Webhook
+	r.s.read(func(t *tables) {
+		if i := t.webhookIndex(id); i >= 0 {
+			w := t.webhooks[i]
+			w.Events = slices.Clone(w.Events)
+			out = &w
+		}
+	})
+	if out == nil {
+		return nil, webhook.ErrWebhookNotFound
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) Insert(ctx context.Context, w *webhook.Webhook) (int, error) {
+	stored := webhook.Webhook{
+		URL:         w.URL,
+		Secret:      w.Secret,
+		Events:      slices.Clone(w.Events),
+		Description: w.Description,
+		Active:      w.Active,
+		CreatedAt:   now(),
+	}
+	r.s.write(func(t *tables) {
+		stored.ID = int(t.nextID("webhook"))
+		t.webhooks = append(t.webhooks, stored)
+	})
+	return stored.ID, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, u *webhook.WebhookUpdate) (int, error) {
+	var err error
+	r.s.write(func(t *tables) {
+		i := t.webhookIndex(u.ID)
+		if i < 0 {
+			err = webhook.ErrWebhookNotFound
+			return
+		}
+		w := t.webhooks[i]
+		if u.URL != nil {
+			w.URL = *u.URL
+		}
+		if u.Events != nil {
+			w.Events = slices.Clone(u.Events)
+		}
+		if u.Description != nil {
+			w.Description = u.Description
+		}
+		if u.Active != nil {
+			w.Active = *u.Active
+		}
+		t.webhooks[i] = w
+	})
+	if err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// Delete removes the webhook with its deliveries
+func (r *WebhookRepository) Delete(ctx context.Context, id int) error {
+	var n int
+	r.s.write(func(t *tables) {
+		n = remove(&t.webhooks, func(w webhook.Webhook) bool { return w.ID == id })
+		remove(&t.deliveries, func(d webhook.Delivery) bool { return d.WebhookID == id })
+	})
+	if n == 0 {
+		return webhook.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// FindDeliveries returns the last deliveries of the webhook, newest first
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, webhookID int, limit int) ([]webhook.Delivery, error) {
+	var out []webhook.Delivery
+	r.s.read(func(t *tables) {
+		out = filter(t.deliveries, func(d webhook.Delivery) bool { return d.WebhookID == webhookID })
+	})
+	slices.Reverse(out)
+	return out[:min(limit, len(out))], nil
+}
+
+// Publish queues the event for every active webhook subscribed to it, the event
+// is queued in the transaction of ctx like with postgres
+func (r *WebhookRepository) Publish(ctx context.Context, e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	createdAt := now()
+	r.s.write(func(t *tables) {
+		for _, w := range t.webhooks {
+			if !w.Active || !slices.Contains(w.Events, e.Type) {
+				continue
+			}
+			t.deliveries = append(t.deliveries, webhook.Delivery{
+				ID:            t.nextID("webhook_delivery"),
+				WebhookID:     w.ID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				Payload:       payload,
+				Status:        webhook.DeliveryStatusPending,
+				NextAttemptAt: timestamp(e.OccurredAt),
+				CreatedAt:     createdAt,
+			})
+		}
+	})
+	return nil
+}
+
+// ClaimDue returns pending deliveries due at now and postpones them by lease
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	out := make([]webhook.Delivery, 0)
+	r.s.write(func(t *tables) {
+		for i, d := range t.deliveries {
+			if len(out) == limit {
+				break
+			}
+			if d.Status != webhook.DeliveryStatusPending || d.NextAttemptAt.After(now) {
+				continue
+			}
+			w := t.webhookIndex(d.WebhookID)
+			if w < 0 {
+				continue
+			}
+			d.NextAttemptAt = timestamp(now.Add(lease))
+			t.deliveries[i] = d
+
+			d.URL = t.webhooks[w].URL
+			d.Secret = t.webhooks[w].Secret
+			out = append(out, d)
+		}
+	})
+	return out, nil
+}
+
+// SaveAttempt stores the result of a delivery attempt
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery *webhook.Delivery) error {
+	r.s.write(func(t *tables) {
+		i := find(t.deliveries, func(d webhook.Delivery) bool { return d.ID == delivery.ID })
+		if i < 0 {
+			return
+		}
+		d := t.deliveries[i]
+		d.Status = delivery.Status
+		d.Attempts = delivery.Attempts
+		d.NextAttemptAt = timestamp(delivery.NextAttemptAt)
+		d.LastStatusCode = delivery.LastStatusCode
+		d.LastError = delivery.LastError
+		d.DeliveredAt = time.Time{}
+		if !delivery.DeliveredAt.IsZero() {
+			d.DeliveredAt = timestamp(delivery.DeliveredAt)
+		}
+		t.deliveries[i] = d
+	})
+	return nil
+}
+
+func (t *tables) webhookIndex(id int) int {
+	return find(t.webhooks, func(w webhook.Webhook) bool { return w.ID == id })
+}
@@ -2,25 +2,52 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
-	"slices"
 	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/player"
 	"github.com/lardira/playtrack/internal/pkg/apiutil"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
 )
 
 const (
 	authHeader = "Authorization"
 
 	authPrefix = "Bearer "
+
+	queryToken = "access_token"
+
+	lastUsedInterval = time.Minute
 )
 
+const (
+	// MetadataAllowMustChangePassword marks operations available to players
+	// who must change their password before using the api
+	MetadataAllowMustChangePassword = "allowMustChangePassword"
+
+	// MetadataAllowQueryToken marks operations which accept the token in the access_token
+	// query parameter, browsers cannot set headers of an EventSource
+	MetadataAllowQueryToken = "allowQueryToken"
+)
+
+type PlayerRepository interface {
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+}
+
+type APITokenRepository interface {
+	FindOneByHash(ctx context.Context, hash string) (*apitoken.Token, error)
+	Touch(ctx context.Context, id int, at time.Time) error
+}
+
 type humaContext huma.Context
 
 type authContext struct {
@@ -36,15 +63,30 @@ func (c *authContext) Context() context.Context {
 	)
 }
 
-func Authorize(secret string) func(ctx huma.Context, next func(huma.Context)) {
+// Authorize authenticates a player by a jwt or by an api token. Api tokens are accepted
+// only when apiTokenRepository is set and must grant the scope of the operation.
+func Authorize(
+	keys *keyset.KeySet,
+	playerRepository PlayerRepository,
+	apiTokenRepository APITokenRepository,
+) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		auth := ctx.Header(authHeader)
 		tokenString, ok := strings.CutPrefix(auth, authPrefix)
+		if !ok && allowsQueryToken(ctx.Operation()) {
+			tokenString = ctx.Query(queryToken)
+			ok = tokenString != ""
+		}
 		if !ok {
 			ctx.SetStatus(http.StatusUnauthorized)
 			return
 		}
 
+		if strings.HasPrefix(tokenString, apitoken.Prefix) {
+			authorizeAPIToken(ctx, next, tokenString, playerRepository, apiTokenRepository)
+			return
+		}
+
 		parseToken := func(t *jwt.Token) (any, error) {
 			exp, err := t.Claims.GetExpirationTime()
 			if err != nil {
@@ -53,13 +95,13 @@ func Authorize(secret string) func(ctx huma.Context, next func(huma.Context)) {
 			if exp.Before(time.Now()) {
 				return nil, fmt.Errorf("token expired")
 			}
-			return []byte(secret), nil
+			return keys.Keyfunc(t)
 		}
 
 		token, err := jwt.Parse(
 			tokenString,
 			parseToken,
-			jwt.WithValidMethods([]string{apiutil.DefaultSigningMethod.Alg()}),
+			jwt.WithValidMethods(keys.Methods()),
 			jwt.WithAudience(apiutil.RoleAdmin, apiutil.RolePlayer),
 			jwt.WithIssuedAt(),
 			jwt.WithNotBeforeRequired(),
@@ -80,17 +122,138 @@ func Authorize(secret string) func(ctx huma.Context, next func(huma.Context)) {
 			return
 		}
 
-		aud, err := token.Claims.GetAudience()
+		issuedAt, err := token.Claims.GetIssuedAt()
+		if err != nil || issuedAt == nil {
+			ctx.SetStatus(http.StatusUnauthorized)
+			return
+		}
+
+		found, err := playerRepository.FindOne(ctx.Context(), playerID)
 		if err != nil {
 			ctx.SetStatus(http.StatusUnauthorized)
 			return
 		}
+		if found.TokenRevoked(issuedAt.Time) {
+			ctx.SetStatus(http.StatusUnauthorized)
+			return
+		}
+		if found.Suspended(time.Now()) {
+			writeError(ctx, http.StatusForbidden, "account is suspended")
+			return
+		}
+		if found.MustChangePassword && !allowsMustChangePassword(ctx.Operation()) {
+			writeError(ctx, http.StatusForbidden, "password must be changed")
+			return
+		}
 
+		// the role is read from the player, the audience of the token may be outdated
 		authCtx := authContext{
 			humaContext: ctx,
 			playerID:    playerID,
-			isAdmin:     slices.Contains(aud, apiutil.RoleAdmin),
+			isAdmin:     found.IsAdmin,
 		}
 		next(&authCtx)
 	}
 }
+
+func authorizeAPIToken(
+	ctx huma.Context,
+	next func(huma.Context),
+	tokenString string,
+	playerRepository PlayerRepository,
+	apiTokenRepository APITokenRepository,
+) {
+	if apiTokenRepository == nil {
+		ctx.SetStatus(http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	token, err := apiTokenRepository.FindOneByHash(ctx.Context(), apitoken.Hash(tokenString))
+	if err != nil || token.Expired(now) {
+		ctx.SetStatus(http.StatusUnauthorized)
+		return
+	}
+	if !token.Allows(requiredScope(ctx.Operation())) {
+		writeError(ctx, http.StatusForbidden, "api token scope does not allow this operation")
+		return
+	}
+
+	found, err := playerRepository.FindOne(ctx.Context(), token.PlayerID)
+	if err != nil {
+		ctx.SetStatus(http.StatusUnauthorized)
+		return
+	}
+	// api tokens are revoked with sessions, on password change and forced logout
+	if found.TokenRevoked(token.CreatedAt) {
+		ctx.SetStatus(http.StatusUnauthorized)
+		return
+	}
+	if found.Suspended(now) {
+		writeError(ctx, http.StatusForbidden, "account is suspended")
+		return
+	}
+	if found.MustChangePassword {
+		writeError(ctx, http.StatusForbidden, "password must be changed")
+		return
+	}
+
+	// last usage is approximate to not write on every request
+	if now.Sub(token.LastUsedAt) > lastUsedInterval {
+		if err := apiTokenRepository.Touch(ctx.Context(), token.ID, now); err != nil {
+			log.Printf("api token %v touch: %v", token.ID, err)
+		}
+	}
+
+	authCtx := authContext{
+		humaContext: ctx,
+		playerID:    found.ID,
+		isAdmin:     found.IsAdmin && token.HasScope(apiutil.ScopeAdmin),
+	}
+	next(&authCtx)
+}
+
+// requiredScope returns the api token scope an operation requires
+func requiredScope(op *huma.Operation) string {
+	if op == nil {
+		return apiutil.ScopeAdmin
+	}
+	if scope, ok := op.Metadata[apiutil.MetadataScope].(string); ok {
+		return scope
+	}
+	if op.Method == http.MethodGet || op.Method == http.MethodHead {
+		return apiutil.ScopeRead
+	}
+	return apiutil.ScopeAdmin
+}
+
+func allowsMustChangePassword(op *huma.Operation) bool {
+	if op == nil {
+		return false
+	}
+	allowed, _ := op.Metadata[MetadataAllowMustChangePassword].(bool)
+	return allowed
+}
+
+func allowsQueryToken(op *huma.Operation) bool {
+	if op == nil {
+		return false
+	}
+	allowed, _ := op.Metadata[MetadataAllowQueryToken].(bool)
+	return allowed
+}
+
+func writeError(ctx huma.Context, status int, msg string) {
+	ctx.SetHeader("Content-Type", "application/problem+json")
+	ctx.SetStatus(status)
+
+	body, err := json.Marshal(huma.ErrorModel{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: msg,
+	})
+	if err != nil {
+		return
+	}
+	ctx.BodyWriter().Write(body)
+}
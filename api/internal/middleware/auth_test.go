@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -10,15 +11,31 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/player"
 	"github.com/lardira/playtrack/internal/pkg/apiutil"
 	"github.com/lardira/playtrack/internal/pkg/ctxutil"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
 	"github.com/lardira/playtrack/internal/pkg/testutil"
+	"github.com/stretchr/testify/mock"
 )
 
-const (
-	testSecret = "test"
+var (
+	testKeys = newTestKeys()
 )
 
+func newTestKeys() *keyset.KeySet {
+	key, err := keyset.GenerateEd25519("test")
+	if err != nil {
+		panic(err)
+	}
+	keys, err := keyset.New(key)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
 type TestHumaContext interface {
 	huma.Context
 }
@@ -28,6 +45,8 @@ type testCtx struct {
 
 	onSetStatus func(int)
 	onHeader    func() string
+	op          *huma.Operation
+	query       map[string]string
 }
 
 func (t testCtx) Header(_ string) string {
@@ -42,6 +61,35 @@ func (t testCtx) Context() context.Context {
 	return context.Background()
 }
 
+func (t testCtx) Operation() *huma.Operation {
+	if t.op == nil {
+		return &huma.Operation{}
+	}
+	return t.op
+}
+
+func (t testCtx) Query(name string) string {
+	return t.query[name]
+}
+
+func (t testCtx) SetHeader(_, _ string) {}
+
+func (t testCtx) BodyWriter() io.Writer {
+	return io.Discard
+}
+
+func signTestToken(playerID string, issuedAt time.Time, aud ...string) string {
+	claims := jwt.RegisteredClaims{
+		Subject:   playerID,
+		ExpiresAt: jwt.NewNumericDate(issuedAt.Add(1 * time.Minute)),
+		NotBefore: jwt.NewNumericDate(issuedAt),
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		Audience:  aud,
+	}
+	signedToken, _ := testKeys.Sign(claims)
+	return signedToken
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	playerID := uuid.NewString()
 	now := time.Now()
@@ -52,10 +100,15 @@ func TestAuthMiddleware(t *testing.T) {
 		IssuedAt:  jwt.NewNumericDate(now),
 		Audience:  []string{apiutil.RolePlayer},
 	}
-	token := jwt.NewWithClaims(apiutil.DefaultSigningMethod, claims)
-	signedToken, _ := token.SignedString([]byte(testSecret))
+	signedToken, _ := testKeys.Sign(claims)
+
+	playerRepository := NewMockPlayerRepository(t)
+	playerRepository.
+		On("FindOne", context.Background(), playerID).
+		Once().
+		Return(&player.Player{ID: playerID}, nil)
 
-	authFunc := Authorize(testSecret)
+	authFunc := Authorize(testKeys, playerRepository, nil)
 
 	ctx := testCtx{
 		onHeader: func() string {
@@ -87,10 +140,15 @@ func TestAuthMiddleware_Admin(t *testing.T) {
 		IssuedAt:  jwt.NewNumericDate(now),
 		Audience:  []string{apiutil.RolePlayer, apiutil.RoleAdmin},
 	}
-	token := jwt.NewWithClaims(apiutil.DefaultSigningMethod, claims)
-	signedToken, _ := token.SignedString([]byte(testSecret))
+	signedToken, _ := testKeys.Sign(claims)
+
+	playerRepository := NewMockPlayerRepository(t)
+	playerRepository.
+		On("FindOne", context.Background(), playerID).
+		Once().
+		Return(&player.Player{ID: playerID, IsAdmin: true}, nil)
 
-	authFunc := Authorize(testSecret)
+	authFunc := Authorize(testKeys, playerRepository, nil)
 
 	ctx := testCtx{
 		onHeader: func() string {
@@ -112,6 +170,123 @@ func TestAuthMiddleware_Admin(t *testing.T) {
 	})
 }
 
+func TestAuthMiddleware_RoleFromPlayer(t *testing.T) {
+	tcases := []struct {
+		name    string
+		aud     []string
+		isAdmin bool
+	}{
+		{"demoted admin token", []string{apiutil.RolePlayer, apiutil.RoleAdmin}, false},
+		{"promoted player token", []string{apiutil.RolePlayer}, true},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID := uuid.NewString()
+			signedToken := signTestToken(playerID, time.Now(), tt.aud...)
+
+			playerRepository := NewMockPlayerRepository(t)
+			playerRepository.
+				On("FindOne", context.Background(), playerID).
+				Once().
+				Return(&player.Player{ID: playerID, IsAdmin: tt.isAdmin}, nil)
+
+			authFunc := Authorize(testKeys, playerRepository, nil)
+
+			ctx := testCtx{
+				onHeader:    func() string { return authPrefix + signedToken },
+				onSetStatus: func(code int) { t.Fatalf("unexpected status %v", code) },
+			}
+
+			called := false
+			authFunc(ctx, func(ctx huma.Context) {
+				called = true
+				ctxP, ok := ctxutil.GetPlayer(ctx.Context())
+				assert.True(t, ok)
+				assert.Equal(t, tt.isAdmin, ctxP.IsAdmin)
+			})
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestAuthMiddleware_Suspended(t *testing.T) {
+	tcases := []struct {
+		name   string
+		until  *time.Time
+		called bool
+		status int
+	}{
+		{"without expiry", nil, false, http.StatusForbidden},
+		{"not expired", ptr(time.Now().Add(time.Hour)), false, http.StatusForbidden},
+		{"expired", ptr(time.Now().Add(-time.Hour)), true, 0},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID := uuid.NewString()
+			signedToken := signTestToken(playerID, time.Now(), apiutil.RolePlayer)
+
+			playerRepository := NewMockPlayerRepository(t)
+			playerRepository.
+				On("FindOne", context.Background(), playerID).
+				Once().
+				Return(&player.Player{ID: playerID, Suspension: &player.Suspension{
+					Kind:  player.SuspensionBanned,
+					At:    time.Now().Add(-2 * time.Hour),
+					Until: tt.until,
+				}}, nil)
+
+			authFunc := Authorize(testKeys, playerRepository, nil)
+
+			status := 0
+			ctx := testCtx{
+				onHeader:    func() string { return authPrefix + signedToken },
+				onSetStatus: func(code int) { status = code },
+			}
+
+			called := false
+			authFunc(ctx, func(huma.Context) { called = true })
+
+			assert.Equal(t, tt.called, called)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestPlayerCache(t *testing.T) {
+	playerID := uuid.NewString()
+	now := time.Now()
+
+	playerRepository := NewMockPlayerRepository(t)
+	playerRepository.
+		On("FindOne", context.Background(), playerID).
+		Times(3).
+		Return(&player.Player{ID: playerID}, nil)
+
+	cache := NewPlayerCache(playerRepository, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := cache.FindOne(context.Background(), playerID)
+		assert.NoError(t, err)
+	}
+
+	// invalidated players are read again
+	cache.Invalidate(playerID)
+	_, err := cache.FindOne(context.Background(), playerID)
+	assert.NoError(t, err)
+
+	// expired players are read again
+	now = now.Add(time.Minute)
+	_, err = cache.FindOne(context.Background(), playerID)
+	assert.NoError(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestAuthMiddleware_InvalidHeader(t *testing.T) {
 	tcases := []struct {
 		name     string
@@ -135,7 +310,7 @@ func TestAuthMiddleware_InvalidHeader(t *testing.T) {
 		},
 	}
 
-	authFunc := Authorize(testSecret)
+	authFunc := Authorize(testKeys, NewMockPlayerRepository(t), nil)
 
 	for _, tt := range tcases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -157,3 +332,334 @@ func TestAuthMiddleware_InvalidHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	playerID := uuid.NewString()
+	issuedAt := time.Now().Add(-10 * time.Second)
+	signedToken := signTestToken(playerID, issuedAt, apiutil.RolePlayer)
+
+	playerRepository := NewMockPlayerRepository(t)
+	playerRepository.
+		On("FindOne", context.Background(), playerID).
+		Once().
+		Return(&player.Player{ID: playerID, TokensValidAfter: time.Now()}, nil)
+
+	authFunc := Authorize(testKeys, playerRepository, nil)
+
+	status := 0
+	ctx := testCtx{
+		onHeader:    func() string { return authPrefix + signedToken },
+		onSetStatus: func(code int) { status = code },
+	}
+
+	called := false
+	authFunc(ctx, func(huma.Context) { called = true })
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuthMiddleware_MustChangePassword(t *testing.T) {
+	tcases := []struct {
+		name   string
+		op     *huma.Operation
+		called bool
+		status int
+	}{
+		{
+			"operation not allowed",
+			&huma.Operation{},
+			false,
+			http.StatusForbidden,
+		},
+		{
+			"operation allowed",
+			&huma.Operation{Metadata: map[string]any{MetadataAllowMustChangePassword: true}},
+			true,
+			0,
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID := uuid.NewString()
+			signedToken := signTestToken(playerID, time.Now(), apiutil.RolePlayer)
+
+			playerRepository := NewMockPlayerRepository(t)
+			playerRepository.
+				On("FindOne", context.Background(), playerID).
+				Once().
+				Return(&player.Player{ID: playerID, MustChangePassword: true}, nil)
+
+			authFunc := Authorize(testKeys, playerRepository, nil)
+
+			status := 0
+			ctx := testCtx{
+				onHeader:    func() string { return authPrefix + signedToken },
+				onSetStatus: func(code int) { status = code },
+				op:          tt.op,
+			}
+
+			called := false
+			authFunc(ctx, func(huma.Context) { called = true })
+
+			assert.Equal(t, tt.called, called)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestAuthMiddleware_UnknownKey(t *testing.T) {
+	playerID := uuid.NewString()
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   playerID,
+		ExpiresAt: jwt.NewNumericDate(now.Add(1 * time.Minute)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Audience:  []string{apiutil.RolePlayer},
+	}
+	signedToken, _ := newTestKeys().Sign(claims)
+
+	authFunc := Authorize(testKeys, NewMockPlayerRepository(t), nil)
+
+	status := 0
+	ctx := testCtx{
+		onHeader:    func() string { return authPrefix + signedToken },
+		onSetStatus: func(code int) { status = code },
+	}
+
+	called := false
+	authFunc(ctx, func(huma.Context) { called = true })
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuthMiddleware_APIToken(t *testing.T) {
+	playerID := uuid.NewString()
+
+	tcases := []struct {
+		name    string
+		scopes  []string
+		op      *huma.Operation
+		isAdmin bool
+		called  bool
+		status  int
+	}{
+		{
+			"read",
+			[]string{apiutil.ScopeRead},
+			&huma.Operation{Method: http.MethodGet},
+			false,
+			true,
+			0,
+		},
+		{
+			"read cannot write",
+			[]string{apiutil.ScopeRead},
+			&huma.Operation{
+				Method:   http.MethodPost,
+				Metadata: map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite},
+			},
+			false,
+			false,
+			http.StatusForbidden,
+		},
+		{
+			"played games write",
+			[]string{apiutil.ScopePlayedGamesWrite},
+			&huma.Operation{
+				Method:   http.MethodPost,
+				Metadata: map[string]any{apiutil.MetadataScope: apiutil.ScopePlayedGamesWrite},
+			},
+			false,
+			true,
+			0,
+		},
+		{
+			"admin",
+			[]string{apiutil.ScopeAdmin},
+			&huma.Operation{Method: http.MethodPost},
+			true,
+			true,
+			0,
+		},
+		{
+			"admin cannot use session",
+			[]string{apiutil.ScopeAdmin},
+			&huma.Operation{
+				Method:   http.MethodGet,
+				Metadata: map[string]any{apiutil.MetadataScope: apiutil.ScopeSession},
+			},
+			false,
+			false,
+			http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			token, plain, err := apitoken.New(playerID, "bot", tt.scopes, time.Time{})
+			assert.NoError(t, err)
+			token.ID = 1
+
+			playerRepository := NewMockPlayerRepository(t)
+			apiTokenRepository := NewMockAPITokenRepository(t)
+
+			apiTokenRepository.
+				On("FindOneByHash", context.Background(), apitoken.Hash(plain)).
+				Once().
+				Return(token, nil)
+
+			if tt.called {
+				playerRepository.
+					On("FindOne", context.Background(), playerID).
+					Once().
+					Return(&player.Player{ID: playerID, IsAdmin: true}, nil)
+
+				apiTokenRepository.
+					On("Touch", context.Background(), token.ID, mock.Anything).
+					Once().
+					Return(nil)
+			}
+
+			authFunc := Authorize(testKeys, playerRepository, apiTokenRepository)
+
+			status := 0
+			ctx := testCtx{
+				onHeader:    func() string { return authPrefix + plain },
+				onSetStatus: func(code int) { status = code },
+				op:          tt.op,
+			}
+
+			called := false
+			authFunc(ctx, func(ctx huma.Context) {
+				called = true
+
+				ctxP, ok := ctxutil.GetPlayer(ctx.Context())
+				assert.True(t, ok)
+				assert.Equal(t, playerID, ctxP.ID)
+				assert.Equal(t, tt.isAdmin, ctxP.IsAdmin)
+			})
+
+			assert.Equal(t, tt.called, called)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestAuthMiddleware_APITokenExpired(t *testing.T) {
+	token, plain, err := apitoken.New(uuid.NewString(), "bot", []string{apiutil.ScopeRead}, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+
+	apiTokenRepository := NewMockAPITokenRepository(t)
+	apiTokenRepository.
+		On("FindOneByHash", context.Background(), apitoken.Hash(plain)).
+		Once().
+		Return(token, nil)
+
+	authFunc := Authorize(testKeys, NewMockPlayerRepository(t), apiTokenRepository)
+
+	status := 0
+	ctx := testCtx{
+		onHeader:    func() string { return authPrefix + plain },
+		onSetStatus: func(code int) { status = code },
+		op:          &huma.Operation{Method: http.MethodGet},
+	}
+
+	called := false
+	authFunc(ctx, func(huma.Context) { called = true })
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuthMiddleware_APITokenRevoked(t *testing.T) {
+	playerID := uuid.NewString()
+	token, plain, err := apitoken.New(playerID, "bot", []string{apiutil.ScopeRead}, time.Time{})
+	assert.NoError(t, err)
+	token.CreatedAt = time.Now().Add(-time.Hour)
+
+	playerRepository := NewMockPlayerRepository(t)
+	apiTokenRepository := NewMockAPITokenRepository(t)
+
+	apiTokenRepository.
+		On("FindOneByHash", context.Background(), apitoken.Hash(plain)).
+		Once().
+		Return(token, nil)
+
+	playerRepository.
+		On("FindOne", context.Background(), playerID).
+		Once().
+		Return(&player.Player{ID: playerID, TokensValidAfter: time.Now().Add(-time.Minute)}, nil)
+
+	authFunc := Authorize(testKeys, playerRepository, apiTokenRepository)
+
+	status := 0
+	ctx := testCtx{
+		onHeader:    func() string { return authPrefix + plain },
+		onSetStatus: func(code int) { status = code },
+		op:          &huma.Operation{Method: http.MethodGet},
+	}
+
+	called := false
+	authFunc(ctx, func(huma.Context) { called = true })
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuthMiddleware_QueryToken(t *testing.T) {
+	tcases := []struct {
+		name   string
+		op     *huma.Operation
+		called bool
+		status int
+	}{
+		{
+			"operation not allowed",
+			&huma.Operation{},
+			false,
+			http.StatusUnauthorized,
+		},
+		{
+			"operation allowed",
+			&huma.Operation{Metadata: map[string]any{MetadataAllowQueryToken: true}},
+			true,
+			0,
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID := uuid.NewString()
+			signedToken := signTestToken(playerID, time.Now(), apiutil.RolePlayer)
+
+			playerRepository := NewMockPlayerRepository(t)
+			if tt.called {
+				playerRepository.
+					On("FindOne", context.Background(), playerID).
+					Once().
+					Return(&player.Player{ID: playerID}, nil)
+			}
+
+			authFunc := Authorize(testKeys, playerRepository, nil)
+
+			status := 0
+			ctx := testCtx{
+				onHeader:    func() string { return "" },
+				onSetStatus: func(code int) { status = code },
+				op:          tt.op,
+				query:       map[string]string{queryToken: signedToken},
+			}
+
+			called := false
+			authFunc(ctx, func(huma.Context) { called = true })
+
+			assert.Equal(t, tt.called, called)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lardira/playtrack/internal/domain/player"
+)
+
+// DefaultPlayerCacheTTL is short so role and suspension changes of other
+// replicas apply to requests soon
+const DefaultPlayerCacheTTL = 5 * time.Second
+
+type cachedPlayer struct {
+	player    *player.Player
+	expiresAt time.Time
+}
+
+// PlayerCache keeps players found by Authorize for a short time so every request
+// does not read the player, changes made by this replica invalidate it at once
+type PlayerCache struct {
+	repository PlayerRepository
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	players map[string]cachedPlayer
+}
+
+func NewPlayerCache(repository PlayerRepository, ttl time.Duration) *PlayerCache {
+	if ttl == 0 {
+		ttl = DefaultPlayerCacheTTL
+	}
+	return &PlayerCache{
+		repository: repository,
+		ttl:        ttl,
+		now:        time.Now,
+		players:    make(map[string]cachedPlayer),
+	}
+}
+
+func (c *PlayerCache) FindOne(ctx context.Context, id string) (*player.Player, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	cached, ok := c.players[id]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.player, nil
+	}
+
+	found, err := c.repository.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// expired players are dropped on writes so the map does not grow with old players
+	for key, p := range c.players {
+		if !now.Before(p.expiresAt) {
+			delete(c.players, key)
+		}
+	}
+	c.players[id] = cachedPlayer{player: found, expiresAt: now.Add(c.ttl)}
+	return found, nil
+}
+
+// Invalidate drops the cached player, the next request reads it again
+func (c *PlayerCache) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.players, id)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/pkg/ratelimit"
+)
+
+const (
+	realIPHeader       = "X-Real-IP"
+	forwardedForHeader = "X-Forwarded-For"
+	retryAfterHeader   = "Retry-After"
+)
+
+// RateLimit rejects requests with 429 when the bucket of the request key is empty
+func RateLimit(
+	limiter *ratelimit.Limiter,
+	keyFunc func(huma.Context) string,
+) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		ok, wait := limiter.Reserve(keyFunc(ctx))
+		if !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			ctx.SetHeader(retryAfterHeader, strconv.Itoa(retryAfter))
+			ctx.SetStatus(http.StatusTooManyRequests)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// ParseTrustedProxies parses addresses and cidrs of reverse proxies in front of the api
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+			}
+			out = append(out, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+		}
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
+
+// ClientIP returns a key func with the ip of the client. X-Real-IP and X-Forwarded-For
+// are honoured only when the request comes from one of trustedProxies, anyone else
+// could set them to get a new rate limit bucket for every request.
+func ClientIP(trustedProxies []netip.Prefix) func(huma.Context) string {
+	return func(ctx huma.Context) string {
+		remote := ctx.RemoteAddr()
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+		if !trusted(trustedProxies, remote) {
+			return remote
+		}
+
+		if ip := strings.TrimSpace(ctx.Header(realIPHeader)); ip != "" {
+			return ip
+		}
+		// every proxy appends the address it got the request from, the client is
+		// the last address which is not a trusted proxy
+		if fwd := ctx.Header(forwardedForHeader); fwd != "" {
+			hops := strings.Split(fwd, ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop := strings.TrimSpace(hops[i])
+				if i == 0 || !trusted(trustedProxies, hop) {
+					return hop
+				}
+			}
+		}
+		return remote
+	}
+}
+
+func trusted(proxies []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range proxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
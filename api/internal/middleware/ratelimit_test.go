@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/lardira/playtrack/internal/pkg/ratelimit"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+)
+
+type rateLimitCtx struct {
+	testCtx
+
+	headers    map[string]string
+	remoteAddr string
+	status     int
+}
+
+func (c *rateLimitCtx) Header(name string) string {
+	return c.headers[name]
+}
+
+func (c *rateLimitCtx) SetHeader(name, value string) {
+	c.headers[name] = value
+}
+
+func (c *rateLimitCtx) SetStatus(code int) {
+	c.status = code
+}
+
+func (c *rateLimitCtx) RemoteAddr() string {
+	return c.remoteAddr
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := 2
+	limitFunc := RateLimit(ratelimit.New(limit, time.Minute), ClientIP(nil))
+
+	ctx := &rateLimitCtx{
+		headers:    map[string]string{},
+		remoteAddr: testutil.Faker().IPv4Address() + ":1234",
+	}
+
+	calls := 0
+	for range limit + 1 {
+		limitFunc(ctx, func(huma.Context) { calls++ })
+	}
+
+	assert.Equal(t, limit, calls)
+	assert.Equal(t, http.StatusTooManyRequests, ctx.status)
+	assert.NotZero(t, ctx.headers[retryAfterHeader])
+}
+
+func TestClientIP(t *testing.T) {
+	ip := testutil.Faker().IPv4Address()
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/24", "10.1.0.7"})
+	assert.NoError(t, err)
+
+	tcases := []struct {
+		name       string
+		headers    map[string]string
+		remoteAddr string
+	}{
+		{
+			"real ip",
+			map[string]string{realIPHeader: ip},
+			"10.0.0.1:5000",
+		},
+		{
+			"forwarded for",
+			map[string]string{forwardedForHeader: "203.0.113.9, " + ip + ", 10.1.0.7"},
+			"10.0.0.1:5000",
+		},
+		{
+			"forwarded for by proxies only",
+			map[string]string{forwardedForHeader: ip + ", 10.0.0.2"},
+			"10.0.0.1:5000",
+		},
+		{
+			"remote addr",
+			map[string]string{},
+			ip + ":5000",
+		},
+		{
+			"untrusted real ip",
+			map[string]string{realIPHeader: "203.0.113.9"},
+			ip + ":5000",
+		},
+		{
+			"untrusted forwarded for",
+			map[string]string{forwardedForHeader: "203.0.113.9"},
+			ip + ":5000",
+		},
+	}
+
+	for _, tt := range tcases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &rateLimitCtx{headers: tt.headers, remoteAddr: tt.remoteAddr}
+			assert.Equal(t, ip, ClientIP(proxies)(ctx))
+		})
+	}
+}
+
+func TestClientIP_NoTrustedProxies(t *testing.T) {
+	ctx := &rateLimitCtx{
+		headers:    map[string]string{realIPHeader: "203.0.113.9", forwardedForHeader: "203.0.113.9"},
+		remoteAddr: "10.0.0.1:5000",
+	}
+	assert.Equal(t, "10.0.0.1", ClientIP(nil)(ctx))
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/8", "::1", "192.168.1.1"})
+	assert.NoError(t, err)
+
+	_, err = ParseTrustedProxies([]string{"proxy"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
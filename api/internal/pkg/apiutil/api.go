@@ -1,15 +1,23 @@
 package apiutil
 
-import "github.com/golang-jwt/jwt/v5"
-
 const (
 	RoleAdmin  = "admin"
 	RolePlayer = "player"
 )
 
-var (
-	DefaultSigningMethod = jwt.SigningMethodHS256
+// scopes of api tokens, an operation sets the required one with MetadataScope.
+// Without it GET requires ScopeRead and other methods ScopeAdmin.
+const (
+	ScopeRead             = "read"
+	ScopePlayedGamesWrite = "played_games:write"
+	ScopeAdmin            = "admin"
+	// ScopeSession is never granted to api tokens, such operations require a jwt
+	ScopeSession = "session"
 
+	MetadataScope = "scope"
+)
+
+var (
 	OperationSecurity = []map[string][]string{
 		{"bearer": {"JWT"}},
 	}
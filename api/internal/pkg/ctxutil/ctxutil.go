@@ -24,3 +24,9 @@ func GetPlayer(ctx context.Context) (CtxPlayer, bool) {
 func SetPlayer(ctx context.Context, p CtxPlayer) context.Context {
 	return context.WithValue(ctx, keyPlayer, p)
 }
+
+// AuthorizedFor reports whether the context player is an admin or the player itself
+func AuthorizedFor(ctx context.Context, playerID string) bool {
+	p, ok := GetPlayer(ctx)
+	return ok && (p.IsAdmin || p.ID == playerID)
+}
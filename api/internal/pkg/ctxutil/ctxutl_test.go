@@ -43,3 +43,12 @@ func TestGetSetPlayer_IDNotSet(t *testing.T) {
 	assert.False(t, ok)
 	assert.Zero(t, parsed)
 }
+
+func TestAuthorizedFor(t *testing.T) {
+	playerID := uuid.NewString()
+
+	assert.False(t, AuthorizedFor(context.Background(), playerID))
+	assert.True(t, AuthorizedFor(SetPlayer(context.Background(), CtxPlayer{ID: playerID}), playerID))
+	assert.False(t, AuthorizedFor(SetPlayer(context.Background(), CtxPlayer{ID: uuid.NewString()}), playerID))
+	assert.True(t, AuthorizedFor(SetPlayer(context.Background(), CtxPlayer{ID: uuid.NewString(), IsAdmin: true}), playerID))
+}
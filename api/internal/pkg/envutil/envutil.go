@@ -3,6 +3,9 @@ package envutil
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -25,6 +28,48 @@ func GetOrDefault(key, def string) string {
 	return s
 }
 
+func GetIntOrDefault(key string, def int) int {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		panic(fmt.Sprintf("env '%s' must be an integer: %v", key, err))
+	}
+	return v
+}
+
+func GetDurationOrDefault(key string, def time.Duration) time.Duration {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return def
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		panic(fmt.Sprintf("env '%s' must be a duration: %v", key, err))
+	}
+	return v
+}
+
+// GetListOrDefault returns comma separated values, empty values are skipped
+func GetListOrDefault(key string, def []string) []string {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return def
+	}
+
+	out := make([]string, 0)
+	for v := range strings.SplitSeq(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func LoadEnvs() error {
 	envPath := GetOrDefault("ENV_PATH", "./.env")
 
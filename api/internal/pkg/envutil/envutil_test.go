@@ -3,6 +3,7 @@ package envutil
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 )
@@ -48,6 +49,40 @@ func TestGetOrDefault_Default(t *testing.T) {
 	assert.Equal(t, "default", got)
 }
 
+func TestGetIntOrDefault(t *testing.T) {
+	os.Setenv(testOSEnvKey, "42")
+	assert.Equal(t, 42, GetIntOrDefault(testOSEnvKey, 1))
+
+	os.Setenv(testOSEnvKey, "")
+	assert.Equal(t, 1, GetIntOrDefault(testOSEnvKey, 1))
+
+	os.Setenv(testOSEnvKey, testOSEnvValue)
+	assert.Panics(t, func() {
+		GetIntOrDefault(testOSEnvKey, 1)
+	})
+}
+
+func TestGetDurationOrDefault(t *testing.T) {
+	os.Setenv(testOSEnvKey, "15m")
+	assert.Equal(t, 15*time.Minute, GetDurationOrDefault(testOSEnvKey, time.Second))
+
+	os.Setenv(testOSEnvKey, "")
+	assert.Equal(t, time.Second, GetDurationOrDefault(testOSEnvKey, time.Second))
+
+	os.Setenv(testOSEnvKey, testOSEnvValue)
+	assert.Panics(t, func() {
+		GetDurationOrDefault(testOSEnvKey, time.Second)
+	})
+}
+
+func TestGetListOrDefault(t *testing.T) {
+	os.Setenv(testOSEnvKey, "discord, google,,steam ")
+	assert.Equal(t, []string{"discord", "google", "steam"}, GetListOrDefault(testOSEnvKey, nil))
+
+	os.Setenv(testOSEnvKey, "")
+	assert.Equal(t, []string{"default"}, GetListOrDefault(testOSEnvKey, []string{"default"}))
+}
+
 func TestLoadEnvs_InvalidPath(t *testing.T) {
 	os.Setenv("ENV_PATH", ".invalid")
 
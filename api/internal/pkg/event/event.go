@@ -0,0 +1,61 @@
+package event
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	PlayedGameAdded     Type = "played_game.added"
+	PlayedGameStarted   Type = "played_game.started"
+	PlayedGameCompleted Type = "played_game.completed"
+	PlayedGameDropped   Type = "played_game.dropped"
+	PlayedGameRerolled  Type = "played_game.rerolled"
+	GameCreated         Type = "game.created"
+	LeaderboardChanged  Type = "leaderboard.changed"
+	ChallengeFinished   Type = "challenge.finished"
+	AchievementUnlocked Type = "achievement.unlocked"
+)
+
+var (
+	Types = []Type{
+		PlayedGameAdded,
+		PlayedGameStarted,
+		PlayedGameCompleted,
+		PlayedGameDropped,
+		PlayedGameRerolled,
+		GameCreated,
+		LeaderboardChanged,
+		ChallengeFinished,
+		AchievementUnlocked,
+	}
+)
+
+// Event is a change in the domain that is interesting outside of the request, data is
+// serialized as json.
+type Event struct {
+	ID   string `json:"id"`
+	Type Type   `json:"type"`
+	// PlayerID is set for events about a player
+	PlayerID   string    `json:"player_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+func New(t Type, data any) Event {
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       t,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+func NewForPlayer(t Type, playerID string, data any) Event {
+	e := New(t, data)
+	e.PlayerID = playerID
+	return e
+}
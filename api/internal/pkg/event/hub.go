@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	defaultHistorySize = 256
+	subscriberBuffer   = 64
+)
+
+// Subscription receives events broadcast by the hub. C is closed when the subscriber
+// is too slow to keep up, it should reconnect with the last received event id.
+type Subscription struct {
+	C  <-chan Event
+	ch chan Event
+}
+
+// Hub is an in-process pub/sub of events, it keeps recent events so subscribers
+// can resume after reconnecting
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	history     []Event
+	historySize int
+}
+
+func NewHub(historySize int) *Hub {
+	if historySize == 0 {
+		historySize = defaultHistorySize
+	}
+	return &Hub{
+		subscribers: make(map[*Subscription]struct{}),
+		historySize: historySize,
+	}
+}
+
+// Publish broadcasts the event once the transaction of ctx is committed
+func (h *Hub) Publish(ctx context.Context, e Event) error {
+	db.AfterCommit(ctx, func() { h.Broadcast(e) })
+	return nil
+}
+
+func (h *Hub) Broadcast(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subscribers {
+		select {
+		case sub.ch <- e:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the events published after lastEventID.
+// Nothing is replayed when lastEventID is empty or too old to be kept.
+func (h *Hub) Subscribe(lastEventID string) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []Event
+	if lastEventID != "" {
+		for i := len(h.history) - 1; i >= 0; i-- {
+			if h.history[i].ID == lastEventID {
+				replay = append(replay, h.history[i+1:]...)
+				break
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	sub := Subscription{C: ch, ch: ch}
+	h.subscribers[&sub] = struct{}{}
+	return &sub, replay
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.ch)
+	}
+}
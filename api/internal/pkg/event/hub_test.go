@@ -0,0 +1,75 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub(0)
+	sub, replay := hub.Subscribe("")
+	assert.Equal(t, 0, len(replay))
+
+	e := New(GameCreated, nil)
+	hub.Broadcast(e)
+	assert.Equal(t, e, <-sub.C)
+
+	hub.Unsubscribe(sub)
+	_, ok := <-sub.C
+	assert.False(t, ok)
+
+	// unsubscribing twice does nothing
+	hub.Unsubscribe(sub)
+}
+
+func TestHubSubscribe_Replay(t *testing.T) {
+	hub := NewHub(2)
+	events := []Event{New(GameCreated, nil), New(PlayedGameAdded, nil), New(PlayedGameStarted, nil)}
+	for _, e := range events {
+		hub.Broadcast(e)
+	}
+
+	_, replay := hub.Subscribe(events[1].ID)
+	assert.Equal(t, events[2:], replay)
+
+	_, replay = hub.Subscribe(events[2].ID)
+	assert.Equal(t, 0, len(replay))
+
+	// the first event is out of the history
+	_, replay = hub.Subscribe(events[0].ID)
+	assert.Equal(t, 0, len(replay))
+}
+
+func TestHubBroadcast_SlowSubscriber(t *testing.T) {
+	hub := NewHub(0)
+	sub, _ := hub.Subscribe("")
+
+	for range subscriberBuffer + 1 {
+		hub.Broadcast(New(GameCreated, nil))
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, Event) error {
+	return errors.New("failed")
+}
+
+func TestPublishers(t *testing.T) {
+	hub := NewHub(0)
+	sub, _ := hub.Subscribe("")
+
+	e := New(GameCreated, nil)
+	err := Publishers{failingPublisher{}, hub}.Publish(t.Context(), e)
+	assert.Error(t, err)
+	assert.Equal(t, e, <-sub.C)
+}
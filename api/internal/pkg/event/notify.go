@@ -0,0 +1,87 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+)
+
+const (
+	NotifyChannel = "playtrack_events"
+
+	listenRetryDelay = 5 * time.Second
+)
+
+// PGNotifier shares events between api replicas with postgres LISTEN/NOTIFY.
+// Notifications are sent on commit so listeners never see rolled back changes.
+type PGNotifier struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGNotifier(pool *pgxpool.Pool) *PGNotifier {
+	return &PGNotifier{
+		pool: pool,
+	}
+}
+
+func (n *PGNotifier) Publish(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Conn(ctx, n.pool).Exec(ctx, "SELECT pg_notify($1, $2)", NotifyChannel, string(payload))
+	return err
+}
+
+// Listen broadcasts notified events to the hub until ctx is done, the connection
+// is reestablished on errors
+func (n *PGNotifier) Listen(ctx context.Context, hub *Hub) {
+	for {
+		if err := n.listen(ctx, hub); err != nil && ctx.Err() == nil {
+			log.Printf("event listen: %v", err)
+		}
+
+		select {
+		case <-time.After(listenRetryDelay):
+		case <-ctx.Done():
+			log.Printf("event listener stopped")
+			return
+		}
+	}
+}
+
+func (n *PGNotifier) listen(ctx context.Context, hub *Hub) error {
+	conn, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait: %w", err)
+		}
+
+		var e struct {
+			Event
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(notification.Payload), &e); err != nil {
+			log.Printf("event notification decode: %v", err)
+			continue
+		}
+		e.Event.Data = e.Data
+		hub.Broadcast(e.Event)
+	}
+}
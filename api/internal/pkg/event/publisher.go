@@ -0,0 +1,23 @@
+package event
+
+import (
+	"context"
+	"errors"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Publishers publishes an event to each of the publishers
+type Publishers []Publisher
+
+func (p Publishers) Publish(ctx context.Context, e Event) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
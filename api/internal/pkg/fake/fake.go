@@ -0,0 +1,11 @@
+package fake
+
+import (
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/brianvoe/gofakeit/v7/source"
+)
+
+// New returns a faker which generates the same values for the same seed
+func New(seed uint64) *gofakeit.Faker {
+	return gofakeit.NewFaker(source.NewJSF(seed), true)
+}
@@ -0,0 +1,13 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(42), New(42)
+	assert.Equal(t, a.Username(), b.Username())
+	assert.Equal(t, a.Uint64(), b.Uint64())
+}
@@ -0,0 +1,204 @@
+package keyset
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyFileExt = ".pem"
+)
+
+var (
+	ErrNoSigningKey   = errors.New("signing key is not found")
+	ErrKeyNotFound    = errors.New("key is not found")
+	ErrMethodMismatch = errors.New("token method does not match the key")
+	ErrUnsupportedKey = errors.New("key type is not supported")
+)
+
+// Key is a jwt key identified by kid. Keys without a private part only verify tokens.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// GenerateEd25519 creates a new EdDSA key
+func GenerateEd25519(id string) (*Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodEdDSA,
+		signKey:   private,
+		verifyKey: public,
+	}, nil
+}
+
+// NewHMAC creates a verification only key from a shared secret. It is used for tokens
+// issued before asymmetric keys and is never published.
+func NewHMAC(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		verifyKey: secret,
+	}
+}
+
+// ParsePEM parses a PKCS8 private key or a PKIX public key, Ed25519 and RSA are supported
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no pem block", id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q: %w: pem type %q", id, ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	key := Key{ID: id}
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.signKey = k
+		key.verifyKey = k.Public()
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.verifyKey = k
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+		key.signKey = k
+		key.verifyKey = &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+		key.verifyKey = k
+	default:
+		return nil, fmt.Errorf("key %q: %w: %T", id, ErrUnsupportedKey, parsed)
+	}
+	return &key, nil
+}
+
+// KeySet signs tokens with a single key and verifies them with any key of the set,
+// so old keys stay valid during rotation
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func New(signing *Key, verify ...*Key) (*KeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, ErrNoSigningKey
+	}
+
+	s := KeySet{
+		signing: signing,
+		keys:    map[string]*Key{signing.ID: signing},
+	}
+	for _, k := range verify {
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		s.keys[k.ID] = k
+	}
+	return &s, nil
+}
+
+// LoadDir loads <kid>.pem keys from dir. The key with signingID signs tokens,
+// if it is empty the last private key by name is used.
+func LoadDir(dir, signingID string, extra ...*Key) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+
+	var signing *Key
+	verify := slices.Clone(extra)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePEM(strings.TrimSuffix(filepath.Base(path), keyFileExt), data)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.CanSign() && (signingID == "" || key.ID == signingID) {
+			if signing != nil {
+				verify = append(verify, signing)
+			}
+			signing = key
+			continue
+		}
+		verify = append(verify, key)
+	}
+
+	if signing == nil {
+		return nil, fmt.Errorf("%w in %q", ErrNoSigningKey, dir)
+	}
+	return New(signing, verify...)
+}
+
+// Sign signs the claims with the signing key and sets its kid header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.signKey)
+}
+
+// Keyfunc selects the verification key by kid, tokens without kid use the key with empty id
+func (s *KeySet) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+	}
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: kid %q", ErrMethodMismatch, kid)
+	}
+	return key.verifyKey, nil
+}
+
+// Methods returns algorithms of the keys
+func (s *KeySet) Methods() []string {
+	out := make([]string, 0, len(s.keys))
+	for _, k := range s.keys {
+		if !slices.Contains(out, k.Method.Alg()) {
+			out = append(out, k.Method.Alg())
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,151 @@
+package keyset
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+)
+
+func writeKey(t *testing.T, dir, id string, key any) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, id+keyFileExt), data, 0o600))
+}
+
+func testClaims() jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Subject:   testutil.Faker().UUID(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+func parse(s *KeySet, token string) (*jwt.Token, error) {
+	return jwt.Parse(token, s.Keyfunc, jwt.WithValidMethods(s.Methods()))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	_, old, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2026-01", old)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	writeKey(t, dir, "2026-02", rsaKey)
+
+	s, err := LoadDir(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02", s.signing.ID)
+	assert.Equal(t, []string{"EdDSA", "RS256"}, s.Methods())
+
+	s, err = LoadDir(dir, "2026-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-01", s.signing.ID)
+
+	_, err = LoadDir(t.TempDir(), "")
+	assert.IsError(t, err, ErrNoSigningKey)
+}
+
+func TestSign_Rotation(t *testing.T) {
+	oldKey, err := GenerateEd25519("old")
+	assert.NoError(t, err)
+	newKey, err := GenerateEd25519("new")
+	assert.NoError(t, err)
+
+	before, err := New(oldKey)
+	assert.NoError(t, err)
+	oldToken, err := before.Sign(testClaims())
+	assert.NoError(t, err)
+
+	after, err := New(newKey, oldKey)
+	assert.NoError(t, err)
+	newToken, err := after.Sign(testClaims())
+	assert.NoError(t, err)
+
+	parsed, err := parse(after, newToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	// tokens of the previous key are valid during rotation
+	_, err = parse(after, oldToken)
+	assert.NoError(t, err)
+
+	// and invalid once the key is removed
+	removed, err := New(newKey)
+	assert.NoError(t, err)
+	_, err = parse(removed, oldToken)
+	assert.IsError(t, err, ErrKeyNotFound)
+}
+
+func TestKeyfunc_LegacyHMAC(t *testing.T) {
+	secret := []byte(testutil.Faker().Password(true, true, true, false, false, 32))
+	signing, err := GenerateEd25519("current")
+	assert.NoError(t, err)
+
+	s, err := New(signing, NewHMAC("", secret))
+	assert.NoError(t, err)
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString(secret)
+	assert.NoError(t, err)
+
+	_, err = parse(s, legacy)
+	assert.NoError(t, err)
+
+	// the public key must not be accepted as a hmac secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = "current"
+	forgedToken, err := forged.SignedString([]byte(signing.verifyKey.(ed25519.PublicKey)))
+	assert.NoError(t, err)
+
+	_, err = parse(s, forgedToken)
+	assert.IsError(t, err, ErrMethodMismatch)
+}
+
+func TestNew_NoSigningKey(t *testing.T) {
+	_, err := New(NewHMAC("", []byte("secret")))
+	assert.IsError(t, err, ErrNoSigningKey)
+
+	_, err = New(nil)
+	assert.IsError(t, err, ErrNoSigningKey)
+}
+
+func TestJWKS(t *testing.T) {
+	signing, err := GenerateEd25519("b")
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+	public, err := ParsePEM("a", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.False(t, public.CanSign())
+
+	s, err := New(signing, public, NewHMAC("", []byte("secret")))
+	assert.NoError(t, err)
+
+	jwks := s.JWKS()
+	assert.Equal(t, 2, len(jwks.Keys))
+
+	assert.Equal(t, "a", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+
+	assert.Equal(t, "b", jwks.Keys[1].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.Equal(t, encode(signing.verifyKey.(ed25519.PublicKey)), jwks.Keys[1].X)
+}
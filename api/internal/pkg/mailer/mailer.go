@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoRecipient = errors.New("message has no recipient")
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+func (m *Message) Valid() error {
+	if strings.TrimSpace(m.To) == "" {
+		return ErrNoRecipient
+	}
+	return nil
+}
+
+// Mailer sends messages to players (password reset, email verification etc.)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// build returns message in RFC 5322 format
+func build(from string, msg Message, now time.Time) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
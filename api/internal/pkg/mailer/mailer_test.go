@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+)
+
+func TestBuild(t *testing.T) {
+	msg := Message{
+		To:      testutil.Faker().Email(),
+		Subject: testutil.Faker().Sentence(3),
+		Body:    "line 1\nline 2",
+	}
+
+	got := string(build(defaultFrom, msg, time.Now()))
+
+	assert.True(t, strings.HasPrefix(got, "From: "+defaultFrom+"\r\n"))
+	assert.Contains(t, got, "To: "+msg.To+"\r\n")
+	assert.Contains(t, got, "Subject: "+msg.Subject+"\r\n")
+	assert.True(t, strings.HasSuffix(got, "\r\n\r\nline 1\r\nline 2"))
+}
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemory()
+
+	msg := Message{To: testutil.Faker().Email(), Subject: "subject", Body: "body"}
+	err := m.Send(t.Context(), msg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Message{msg}, m.Messages())
+}
+
+func TestMemoryMailer_NoRecipient(t *testing.T) {
+	m := NewMemory()
+
+	err := m.Send(t.Context(), Message{Subject: "subject"})
+	assert.IsError(t, ErrNoRecipient, err)
+	assert.Zero(t, m.Messages())
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	m, err := NewFile(dir)
+	assert.NoError(t, err)
+
+	msg := Message{To: testutil.Faker().Email(), Subject: "subject", Body: "body"}
+	err = m.Send(t.Context(), msg)
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: "+msg.To)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+type SMTPOptions struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type SMTPMailer struct {
+	opts SMTPOptions
+	auth smtp.Auth
+}
+
+func NewSMTP(opts SMTPOptions) *SMTPMailer {
+	var auth smtp.Auth
+	if opts.Username != "" {
+		auth = smtp.PlainAuth("", opts.Username, opts.Password, opts.Host)
+	}
+
+	return &SMTPMailer{
+		opts: opts,
+		auth: auth,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.opts.Host, m.opts.Port)
+	data := build(m.opts.From, msg, time.Now())
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- smtp.SendMail(addr, m.auth, m.opts.From, []string{msg.To}, data)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
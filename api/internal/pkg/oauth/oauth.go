@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	KindOIDC    = "oidc"
+	KindGoogle  = "google"
+	KindDiscord = "discord"
+	KindSteam   = "steam"
+
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	ErrUnknownKind     = errors.New("unknown provider kind")
+	ErrNoCode          = errors.New("authorization code is missing")
+	ErrNoSubject       = errors.New("provider returned no subject")
+	ErrInvalidResponse = errors.New("provider response is invalid")
+)
+
+// User is an identity returned by a provider
+type User struct {
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	Img           string
+}
+
+// Provider performs a browser redirect based sign in with an identity provider
+type Provider interface {
+	// AuthCodeURL returns url of the provider the player is redirected to
+	AuthCodeURL(state, verifier, redirectURL string) string
+	// Identify validates the callback params and returns the signed in user
+	Identify(ctx context.Context, params url.Values, verifier, redirectURL string) (*User, error)
+}
+
+// Config of a provider. Endpoints default to the public ones of the provider kind,
+// they can be overridden to use a self-hosted or a fake provider.
+type Config struct {
+	Kind         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+func New(cfg Config) (Provider, error) {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	switch cfg.Kind {
+	case KindGoogle:
+		setDefaults(&cfg, googleEndpoints)
+		return newOAuth2(cfg, parseOIDCUser), nil
+	case KindDiscord:
+		setDefaults(&cfg, discordEndpoints)
+		return newOAuth2(cfg, parseDiscordUser), nil
+	case KindOIDC:
+		if cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "" {
+			return nil, fmt.Errorf("oidc provider endpoints are required")
+		}
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = googleEndpoints.Scopes
+		}
+		return newOAuth2(cfg, parseOIDCUser), nil
+	case KindSteam:
+		setDefaults(&cfg, steamEndpoints)
+		return newSteam(cfg), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKind, cfg.Kind)
+}
+
+// GenerateVerifier returns a random PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+func setDefaults(cfg *Config, defaults Config) {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = defaults.AuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaults.TokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = defaults.UserInfoURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaults.Scopes
+	}
+}
@@ -0,0 +1,169 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	maxUserInfoSize = 1 << 20
+)
+
+var (
+	googleEndpoints = Config{
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:      []string{"openid", "profile", "email"},
+	}
+	discordEndpoints = Config{
+		AuthURL:     "https://discord.com/oauth2/authorize",
+		TokenURL:    "https://discord.com/api/oauth2/token",
+		UserInfoURL: "https://discord.com/api/users/@me",
+		Scopes:      []string{"identify", "email"},
+	}
+)
+
+// oauth2Provider uses the authorization code flow with PKCE
+// and reads the user from the userinfo endpoint
+type oauth2Provider struct {
+	config      oauth2.Config
+	userInfoURL string
+	client      *http.Client
+	parseUser   func([]byte) (*User, error)
+}
+
+func newOAuth2(cfg Config, parseUser func([]byte) (*User, error)) *oauth2Provider {
+	return &oauth2Provider{
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+			Scopes: cfg.Scopes,
+		},
+		userInfoURL: cfg.UserInfoURL,
+		client:      cfg.HTTPClient,
+		parseUser:   parseUser,
+	}
+}
+
+func (p *oauth2Provider) AuthCodeURL(state, verifier, redirectURL string) string {
+	config := p.config
+	config.RedirectURL = redirectURL
+	return config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *oauth2Provider) Identify(
+	ctx context.Context,
+	params url.Values,
+	verifier string,
+	redirectURL string,
+) (*User, error) {
+	if e := params.Get("error"); e != "" {
+		return nil, fmt.Errorf("provider error: %s %s", e, params.Get("error_description"))
+	}
+	code := params.Get("code")
+	if code == "" {
+		return nil, ErrNoCode
+	}
+
+	config := p.config
+	config.RedirectURL = redirectURL
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := config.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo: %w: status %d", ErrInvalidResponse, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxUserInfoSize))
+	if err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+
+	user, err := p.parseUser(body)
+	if err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	if user.Subject == "" {
+		return nil, ErrNoSubject
+	}
+	return user, nil
+}
+
+func parseOIDCUser(body []byte) (*User, error) {
+	var info struct {
+		Sub               string `json:"sub"`
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+		Name              string `json:"name"`
+		Picture           string `json:"picture"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	username := info.PreferredUsername
+	if username == "" {
+		username = info.Name
+	}
+	if username == "" {
+		username, _, _ = strings.Cut(info.Email, "@")
+	}
+
+	return &User{
+		Subject:       info.Sub,
+		Username:      username,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Img:           info.Picture,
+	}, nil
+}
+
+func parseDiscordUser(body []byte) (*User, error) {
+	var info struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Verified bool   `json:"verified"`
+		Avatar   string `json:"avatar"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	user := User{
+		Subject:       info.ID,
+		Username:      info.Username,
+		Email:         info.Email,
+		EmailVerified: info.Verified,
+	}
+	if info.Avatar != "" {
+		user.Img = fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", info.ID, info.Avatar)
+	}
+	return &user, nil
+}
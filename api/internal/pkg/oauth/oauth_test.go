@@ -0,0 +1,215 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+)
+
+const (
+	testRedirectURL = "http://localhost:8080/pub/auth/oauth/test/callback"
+)
+
+// fakeIdP is a minimal authorization server which checks the PKCE challenge
+type fakeIdP struct {
+	*httptest.Server
+
+	code      string
+	challenge string
+	userInfo  map[string]any
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	idp := &fakeIdP{code: testutil.Faker().UUID()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != idp.code ||
+			r.PostForm.Get("redirect_uri") != testRedirectURL ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"access","token_type":"Bearer","expires_in":3600}`)
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(idp.userInfo)
+	})
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) config(kind string) Config {
+	return Config{
+		Kind:        kind,
+		ClientID:    "client",
+		AuthURL:     idp.URL + "/authorize",
+		TokenURL:    idp.URL + "/token",
+		UserInfoURL: idp.URL + "/userinfo",
+	}
+}
+
+// authorize simulates the player consent, it remembers the challenge from the auth url
+func (idp *fakeIdP) authorize(t *testing.T, authURL string) {
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	idp.challenge = u.Query().Get("code_challenge")
+}
+
+func TestOIDCIdentify(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.userInfo = map[string]any{
+		"sub":                testutil.Faker().UUID(),
+		"email":              testutil.Faker().Email(),
+		"email_verified":     true,
+		"preferred_username": testutil.Faker().Username(),
+	}
+
+	provider, err := New(idp.config(KindOIDC))
+	assert.NoError(t, err)
+
+	verifier := GenerateVerifier()
+	state := testutil.Faker().UUID()
+	authURL := provider.AuthCodeURL(state, verifier, testRedirectURL)
+	idp.authorize(t, authURL)
+
+	u, _ := url.Parse(authURL)
+	assert.Equal(t, state, u.Query().Get("state"))
+	assert.Equal(t, testRedirectURL, u.Query().Get("redirect_uri"))
+
+	user, err := provider.Identify(t.Context(), url.Values{"code": {idp.code}}, verifier, testRedirectURL)
+	assert.NoError(t, err)
+	assert.Equal(t, User{
+		Subject:       idp.userInfo["sub"].(string),
+		Username:      idp.userInfo["preferred_username"].(string),
+		Email:         idp.userInfo["email"].(string),
+		EmailVerified: true,
+	}, *user)
+}
+
+func TestOIDCIdentify_WrongVerifier(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.userInfo = map[string]any{"sub": testutil.Faker().UUID()}
+
+	provider, err := New(idp.config(KindOIDC))
+	assert.NoError(t, err)
+
+	idp.authorize(t, provider.AuthCodeURL(testutil.Faker().UUID(), GenerateVerifier(), testRedirectURL))
+
+	_, err = provider.Identify(t.Context(), url.Values{"code": {idp.code}}, GenerateVerifier(), testRedirectURL)
+	assert.Error(t, err)
+}
+
+func TestDiscordIdentify(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.userInfo = map[string]any{
+		"id":       "80351110224678912",
+		"username": testutil.Faker().Username(),
+		"email":    testutil.Faker().Email(),
+		"verified": true,
+		"avatar":   "8342729096ea3675442027381ff50dfe",
+	}
+
+	provider, err := New(idp.config(KindDiscord))
+	assert.NoError(t, err)
+
+	verifier := GenerateVerifier()
+	idp.authorize(t, provider.AuthCodeURL(testutil.Faker().UUID(), verifier, testRedirectURL))
+
+	user, err := provider.Identify(t.Context(), url.Values{"code": {idp.code}}, verifier, testRedirectURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "80351110224678912", user.Subject)
+	assert.Equal(t, idp.userInfo["username"].(string), user.Username)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "https://cdn.discordapp.com/avatars/80351110224678912/8342729096ea3675442027381ff50dfe.png", user.Img)
+}
+
+func TestIdentify_ProviderError(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider, err := New(idp.config(KindGoogle))
+	assert.NoError(t, err)
+
+	_, err = provider.Identify(t.Context(), url.Values{"error": {"access_denied"}}, GenerateVerifier(), testRedirectURL)
+	assert.Error(t, err)
+
+	_, err = provider.Identify(t.Context(), url.Values{}, GenerateVerifier(), testRedirectURL)
+	assert.IsError(t, err, ErrNoCode)
+}
+
+func TestNew_UnknownKind(t *testing.T) {
+	_, err := New(Config{Kind: "unknown"})
+	assert.IsError(t, err, ErrUnknownKind)
+
+	_, err = New(Config{Kind: KindOIDC})
+	assert.Error(t, err)
+}
+
+func TestSteamIdentify(t *testing.T) {
+	steamID := "76561197960287930"
+	valid := true
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /openid/login", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("openid.mode") != "check_authentication" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "ns:%s\nis_valid:%v\n", openIDNamespace, valid)
+	})
+	idp := httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	provider, err := New(Config{Kind: KindSteam, AuthURL: idp.URL + "/openid/login"})
+	assert.NoError(t, err)
+
+	state := testutil.Faker().UUID()
+	authURL, _ := url.Parse(provider.AuthCodeURL(state, "", testRedirectURL))
+	returnTo := authURL.Query().Get("openid.return_to")
+	assert.Equal(t, "http://localhost:8080/", authURL.Query().Get("openid.realm"))
+
+	params := url.Values{
+		"state":             {state},
+		"openid.ns":         {openIDNamespace},
+		"openid.mode":       {"id_res"},
+		"openid.return_to":  {returnTo},
+		"openid.claimed_id": {idp.URL + "/openid/id/" + steamID},
+		"openid.identity":   {idp.URL + "/openid/id/" + steamID},
+		"openid.sig":        {"signature"},
+	}
+
+	user, err := provider.Identify(t.Context(), params, "", testRedirectURL)
+	assert.NoError(t, err)
+	assert.Equal(t, steamID, user.Subject)
+
+	valid = false
+	_, err = provider.Identify(t.Context(), params, "", testRedirectURL)
+	assert.IsError(t, err, ErrInvalidResponse)
+
+	valid = true
+	params.Set("openid.claimed_id", "https://evil.example.com/openid/id/"+steamID)
+	_, err = provider.Identify(t.Context(), params, "", testRedirectURL)
+	assert.IsError(t, err, ErrInvalidResponse)
+}
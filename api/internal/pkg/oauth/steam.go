@@ -0,0 +1,143 @@
+package oauth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	openIDNamespace        = "http://specs.openid.net/auth/2.0"
+	openIDIdentifierSelect = "http://specs.openid.net/auth/2.0/identifier_select"
+)
+
+var (
+	steamEndpoints = Config{
+		AuthURL: "https://steamcommunity.com/openid/login",
+	}
+)
+
+// steamProvider uses OpenID 2.0 which is the only sign in method supported by Steam.
+// It has no PKCE so the verifier is not used, the state is passed in return_to.
+type steamProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newSteam(cfg Config) *steamProvider {
+	return &steamProvider{
+		endpoint: cfg.AuthURL,
+		client:   cfg.HTTPClient,
+	}
+}
+
+func (p *steamProvider) AuthCodeURL(state, _, redirectURL string) string {
+	returnTo := redirectURL + "?" + url.Values{"state": {state}}.Encode()
+
+	params := url.Values{
+		"openid.ns":         {openIDNamespace},
+		"openid.mode":       {"checkid_setup"},
+		"openid.return_to":  {returnTo},
+		"openid.realm":      {realm(redirectURL)},
+		"openid.identity":   {openIDIdentifierSelect},
+		"openid.claimed_id": {openIDIdentifierSelect},
+	}
+	return p.endpoint + "?" + params.Encode()
+}
+
+func (p *steamProvider) Identify(
+	ctx context.Context,
+	params url.Values,
+	_ string,
+	redirectURL string,
+) (*User, error) {
+	if mode := params.Get("openid.mode"); mode != "id_res" {
+		return nil, fmt.Errorf("%w: openid mode %q", ErrInvalidResponse, mode)
+	}
+	if !strings.HasPrefix(params.Get("openid.return_to"), redirectURL+"?") {
+		return nil, fmt.Errorf("%w: return_to mismatch", ErrInvalidResponse)
+	}
+
+	subject, err := p.steamID(params.Get("openid.claimed_id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkAuthentication(ctx, params); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		Subject:  subject,
+		Username: "steam_" + subject,
+	}, nil
+}
+
+// steamID extracts id from the claimed id which is <endpoint host>/openid/id/<steam id>
+func (p *steamProvider) steamID(claimedID string) (string, error) {
+	claimed, err := url.Parse(claimedID)
+	if err != nil {
+		return "", fmt.Errorf("%w: claimed id: %w", ErrInvalidResponse, err)
+	}
+	endpoint, err := url.Parse(p.endpoint)
+	if err != nil {
+		return "", err
+	}
+	if claimed.Host != endpoint.Host || path.Dir(claimed.Path) != "/openid/id" {
+		return "", fmt.Errorf("%w: claimed id %q", ErrInvalidResponse, claimedID)
+	}
+
+	id := path.Base(claimed.Path)
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("%w: claimed id %q", ErrInvalidResponse, claimedID)
+		}
+	}
+	return id, nil
+}
+
+// checkAuthentication asks the provider to verify the signature of the response
+func (p *steamProvider) checkAuthentication(ctx context.Context, params url.Values) error {
+	form := url.Values{}
+	for k, v := range params {
+		if strings.HasPrefix(k, "openid.") {
+			form[k] = v
+		}
+	}
+	form.Set("openid.mode", "check_authentication")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("check authentication: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("check authentication: %w: status %d", ErrInvalidResponse, resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "is_valid:true" {
+			return nil
+		}
+	}
+	return fmt.Errorf("check authentication: %w: response is not valid", ErrInvalidResponse)
+}
+
+func realm(redirectURL string) string {
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return redirectURL
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
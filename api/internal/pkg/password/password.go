@@ -2,20 +2,48 @@ package password
 
 import (
 	"errors"
+	"fmt"
+	"sync/atomic"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	DefaultCost = bcrypt.DefaultCost
+)
+
+var (
+	ErrEmptyPass   = errors.New("password is empty")
+	ErrInvalidCost = fmt.Errorf("cost must be in range [%d; %d]", bcrypt.MinCost, bcrypt.MaxCost)
+)
+
 var (
-	ErrEmptyPass = errors.New("password is empty")
+	cost atomic.Int32
 )
 
+func init() {
+	cost.Store(int32(DefaultCost))
+}
+
+// SetCost sets bcrypt cost used for new hashes
+func SetCost(c int) error {
+	if c < bcrypt.MinCost || c > bcrypt.MaxCost {
+		return ErrInvalidCost
+	}
+	cost.Store(int32(c))
+	return nil
+}
+
+func Cost() int {
+	return int(cost.Load())
+}
+
 func Hash(pass string) (string, error) {
 	if pass == "" {
 		return "", ErrEmptyPass
 	}
 
-	bhash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	bhash, err := bcrypt.GenerateFromPassword([]byte(pass), Cost())
 	return string(bhash), err
 }
 
@@ -27,3 +55,12 @@ func CompareHash(pass string, passHash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(passHash), []byte(pass))
 	return err == nil
 }
+
+// NeedsRehash reports whether the hash was created with a cost lower than the current one
+func NeedsRehash(passHash string) bool {
+	c, err := bcrypt.Cost([]byte(passHash))
+	if err != nil {
+		return false
+	}
+	return c < Cost()
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/lardira/playtrack/internal/pkg/testutil"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -32,3 +33,32 @@ func TestHash_EmptyPassword(t *testing.T) {
 	ok := CompareHash(password, hash)
 	assert.False(t, ok)
 }
+
+func TestSetCost(t *testing.T) {
+	defer SetCost(DefaultCost)
+
+	err := SetCost(bcrypt.MinCost - 1)
+	assert.IsError(t, ErrInvalidCost, err)
+
+	err = SetCost(bcrypt.MaxCost + 1)
+	assert.IsError(t, ErrInvalidCost, err)
+
+	err = SetCost(bcrypt.MinCost)
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, Cost())
+}
+
+func TestNeedsRehash(t *testing.T) {
+	defer SetCost(DefaultCost)
+	password := testutil.Faker().Password(true, true, true, true, false, minPossiblePassLen)
+
+	assert.NoError(t, SetCost(bcrypt.MinCost))
+	hash, err := Hash(password)
+	assert.NoError(t, err)
+	assert.False(t, NeedsRehash(hash))
+
+	assert.NoError(t, SetCost(bcrypt.MinCost+1))
+	assert.True(t, NeedsRehash(hash))
+
+	assert.False(t, NeedsRehash("not a hash"))
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultSweepInterval = time.Minute
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter is an in-process token bucket rate limiter keyed by an arbitrary string
+// (ip address, username etc.). Each key gets its own bucket of burst tokens
+// refilled at limit/per tokens per second.
+type Limiter struct {
+	mu sync.Mutex
+
+	burst     float64
+	perSecond float64
+
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New(limit int, per time.Duration) *Limiter {
+	if limit < 1 {
+		limit = 1
+	}
+	if per <= 0 {
+		per = time.Second
+	}
+
+	return &Limiter{
+		burst:     float64(limit),
+		perSecond: float64(limit) / per.Seconds(),
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the key and reports whether it was available
+func (l *Limiter) Allow(key string) bool {
+	ok, _ := l.Reserve(key)
+	return ok
+}
+
+// Reserve takes a token from the bucket of the key. If there are no tokens left
+// it returns false and the time until the next token is available.
+func (l *Limiter) Reserve(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.perSecond)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep removes buckets which are full again, they are equal to new ones
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < defaultSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.perSecond >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
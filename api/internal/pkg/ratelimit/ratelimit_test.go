@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/pkg/testutil"
+)
+
+func TestAllow(t *testing.T) {
+	limit := 3
+	now := time.Now()
+
+	l := New(limit, time.Minute)
+	l.now = func() time.Time { return now }
+
+	key := testutil.Faker().IPv4Address()
+	for range limit {
+		assert.True(t, l.Allow(key))
+	}
+	assert.False(t, l.Allow(key))
+
+	// other keys have their own bucket
+	assert.True(t, l.Allow(testutil.Faker().IPv6Address()))
+}
+
+func TestAllow_Refill(t *testing.T) {
+	now := time.Now()
+
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	key := testutil.Faker().Username()
+	assert.True(t, l.Allow(key))
+	assert.True(t, l.Allow(key))
+
+	ok, wait := l.Reserve(key)
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, l.Allow(key))
+	assert.False(t, l.Allow(key))
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+
+	l := New(1, time.Second)
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.Allow(testutil.Faker().Username()))
+	assert.Equal(t, 1, len(l.buckets))
+
+	now = now.Add(2 * defaultSweepInterval)
+	assert.True(t, l.Allow(testutil.Faker().Username()))
+	assert.Equal(t, 1, len(l.buckets))
+}
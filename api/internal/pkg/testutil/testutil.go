@@ -5,7 +5,7 @@ import (
 	"sync"
 
 	"github.com/brianvoe/gofakeit/v7"
-	"github.com/brianvoe/gofakeit/v7/source"
+	"github.com/lardira/playtrack/internal/pkg/fake"
 )
 
 var (
@@ -22,7 +22,7 @@ func init() {
 func initFaker() {
 	fakerOnce.Do(func() {
 		seed = rand.Uint64()
-		faker = gofakeit.NewFaker(source.NewJSF(seed), true)
+		faker = fake.New(seed)
 	})
 }
 
@@ -0,0 +1,628 @@
+// Package repotest is a conformance suite for repositories, every storage
+// must pass it to behave the same behind the handlers
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+type GameRepository interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
+	FindOne(ctx context.Context, id int) (*game.Game, error)
+	FindByTitle(ctx context.Context, title string) (*game.Game, error)
+	Insert(ctx context.Context, g *game.Game) (int, error)
+}
+
+type PlayerRepository interface {
+	FindAll(ctx context.Context) ([]player.Player, error)
+	FindByFilter(ctx context.Context, f player.PlayerFilter) ([]player.Player, error)
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+	FindOneByUsername(ctx context.Context, username string) (*player.Player, error)
+	FindOneByEmail(ctx context.Context, email string) (*player.Player, error)
+	Insert(ctx context.Context, p *player.Player) (string, error)
+	Update(ctx context.Context, p *player.PlayerUpdate) (string, error)
+	Suspend(ctx context.Context, id string, s *player.Suspension) error
+	RegisterFailedLogin(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, id string) error
+}
+
+type PlayedRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]player.PlayedGame, error)
+	FindOne(ctx context.Context, playerID string, id int) (*player.PlayedGame, error)
+	FindNonterminated(ctx context.Context, playerID string) (*player.PlayedGame, error)
+	Insert(ctx context.Context, g *player.PlayedGame) (int, error)
+	Import(ctx context.Context, g *player.PlayedGame) (int, error)
+	Update(ctx context.Context, g *player.PlayedGameUpdate) (int, error)
+}
+
+type StreakRepository interface {
+	FindOne(ctx context.Context, playerID string) (*player.Streak, error)
+	Save(ctx context.Context, s *player.Streak) error
+}
+
+type LedgerRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]player.LedgerEntry, error)
+	Insert(ctx context.Context, entries []player.LedgerEntry) error
+	Leaderboard(ctx context.Context) ([]player.LeaderboardPlayer, error)
+}
+
+type BacklogRepository interface {
+	FindAll(ctx context.Context, playerID string) ([]backlog.Item, error)
+	FindOne(ctx context.Context, playerID string, id int) (*backlog.Item, error)
+	Insert(ctx context.Context, item *backlog.Item) (int, error)
+	Move(ctx context.Context, playerID string, id int, position int) error
+	Delete(ctx context.Context, playerID string, id int) error
+	DeleteByGame(ctx context.Context, playerID string, gameID int) error
+}
+
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Repositories are repositories of a single empty storage
+type Repositories struct {
+	Games   GameRepository
+	Players PlayerRepository
+	Played  PlayedRepository
+	Streaks StreakRepository
+	Ledger  LedgerRepository
+	Backlog BacklogRepository
+	Tx      TxManager
+}
+
+// Run runs the suite, newRepositories is called for every test and must
+// return repositories of an empty storage
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := map[string]func(t *testing.T, r Repositories){
+		"games":         testGames,
+		"players":       testPlayers,
+		"suspend":       testSuspend,
+		"played":        testPlayed,
+		"nonterminated": testNonterminated,
+		"transition":    testTransition,
+		"streaks":       testStreaks,
+		"ledger":        testLedger,
+		"backlog":       testBacklog,
+		"tx":            testTx,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepositories(t))
+		})
+	}
+}
+
+func testGames(t *testing.T, r Repositories) {
+	ctx := t.Context()
+
+	hadesID := insertGame(t, r, "Hades")
+	celesteID := insertGame(t, r, "Celeste")
+
+	got, err := r.Games.FindOne(ctx, celesteID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Celeste", got.Title)
+	assert.Equal(t, 3, got.Points)
+	assert.False(t, got.CreatedAt.IsZero())
+
+	_, err = r.Games.FindOne(ctx, celesteID+hadesID)
+	assert.IsError(t, err, game.ErrGameNotFound)
+
+	got, err = r.Games.FindByTitle(ctx, "Hades")
+	assert.NoError(t, err)
+	assert.Equal(t, hadesID, got.ID)
+
+	_, err = r.Games.FindByTitle(ctx, "Hollow Knight")
+	assert.IsError(t, err, game.ErrGameNotFound)
+
+	_, err = r.Games.Insert(ctx, &game.Game{Title: "Hades", Points: 1, HoursToBeat: 1})
+	assert.IsError(t, err, game.ErrFoundByTitle)
+
+	all, err := r.Games.FindAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{hadesID, celesteID}, gameIDs(all))
+}
+
+func testPlayers(t *testing.T, r Repositories) {
+	ctx := t.Context()
+
+	email := "Lardira@Example.com"
+	lardiraID, err := r.Players.Insert(ctx, &player.Player{Username: "lardira", Password: "hash", Email: &email})
+	assert.NoError(t, err)
+	adaID := insertPlayer(t, r, "Ada")
+	zedID := insertPlayer(t, r, "zed")
+
+	got, err := r.Players.FindOneByUsername(ctx, "LARDIRA")
+	assert.NoError(t, err)
+	assert.Equal(t, lardiraID, got.ID)
+	assert.Equal(t, "lardira", got.Username)
+
+	got, err = r.Players.FindOneByEmail(ctx, "lardira@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, lardiraID, got.ID)
+
+	_, err = r.Players.FindOne(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.IsError(t, err, player.ErrPlayerNotFound)
+	_, err = r.Players.FindOneByUsername(ctx, "nobody")
+	assert.IsError(t, err, player.ErrPlayerNotFound)
+	_, err = r.Players.FindOneByEmail(ctx, "nobody@example.com")
+	assert.IsError(t, err, player.ErrPlayerNotFound)
+
+	_, err = r.Players.Insert(ctx, &player.Player{Username: "ADA", Password: "hash"})
+	assert.IsError(t, err, player.ErrUsernameTaken)
+	other := "lardira@EXAMPLE.com"
+	_, err = r.Players.Insert(ctx, &player.Player{Username: "other", Password: "hash", Email: &other})
+	assert.IsError(t, err, player.ErrEmailTaken)
+
+	username := "Zed"
+	_, err = r.Players.Update(ctx, &player.PlayerUpdate{ID: adaID, Username: &username})
+	assert.IsError(t, err, player.ErrUsernameTaken)
+	// a player may change the case of their own username
+	_, err = r.Players.Update(ctx, &player.PlayerUpdate{ID: zedID, Username: &username})
+	assert.NoError(t, err)
+	_, err = r.Players.Update(ctx, &player.PlayerUpdate{ID: "00000000-0000-0000-0000-000000000000", Username: &username})
+	assert.IsError(t, err, player.ErrPlayerNotFound)
+
+	all, err := r.Players.FindAll(ctx)
+	assert.NoError(t, err)
+	ids := make([]string, 0, len(all))
+	for _, p := range all {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []string{adaID, lardiraID, zedID}, ids)
+}
+
+func testSuspend(t *testing.T, r Repositories) {
+	ctx := t.Context()
+
+	adaID := insertPlayer(t, r, "ada")
+	bannedID := insertPlayer(t, r, "banned")
+	expiredID := insertPlayer(t, r, "expired")
+
+	at := time.Now().Add(-time.Hour)
+	past := at.Add(time.Minute)
+	assert.NoError(t, r.Players.Suspend(ctx, bannedID, &player.Suspension{
+		Kind: player.SuspensionBanned, Reason: "cheating", At: at,
+	}))
+	assert.NoError(t, r.Players.Suspend(ctx, expiredID, &player.Suspension{
+		Kind: player.SuspensionBanned, Reason: "spam", At: at, Until: &past,
+	}))
+	assert.IsError(t, r.Players.Suspend(ctx, "00000000-0000-0000-0000-000000000000", nil), player.ErrPlayerNotFound)
+
+	got, err := r.Players.FindOne(ctx, bannedID)
+	assert.NoError(t, err)
+	assert.Equal(t, player.SuspensionBanned, got.Suspension.Kind)
+	assert.Equal(t, "cheating", got.Suspension.Reason)
+	assert.Equal(t, at.UTC().Truncate(time.Microsecond), got.Suspension.At)
+	assert.Zero(t, got.Suspension.Until)
+
+	filtered := func(f player.PlayerFilter) []string {
+		t.Helper()
+		players, err := r.Players.FindByFilter(ctx, f)
+		assert.NoError(t, err)
+		ids := make([]string, 0, len(players))
+		for _, p := range players {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{adaID, expiredID}, filtered(player.PlayerFilter{State: player.StateFilterActive}))
+	assert.Equal(t, []string{bannedID}, filtered(player.PlayerFilter{State: player.StateFilterBanned}))
+	assert.Equal(t, []string{bannedID}, filtered(player.PlayerFilter{Username: "AN"}))
+	assert.Equal(t, []string{}, filtered(player.PlayerFilter{Username: "%"}))
+
+	assert.NoError(t, r.Players.Suspend(ctx, bannedID, nil))
+	got, err = r.Players.FindOne(ctx, bannedID)
+	assert.NoError(t, err)
+	assert.Zero(t, got.Suspension)
+
+	// the player is locked on the last attempt and attempts start over
+	lockedUntil, err := r.Players.RegisterFailedLogin(ctx, adaID, 2, time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, lockedUntil)
+	lockedUntil, err = r.Players.RegisterFailedLogin(ctx, adaID, 2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.After(time.Now().Add(59*time.Minute)))
+
+	got, err = r.Players.FindOne(ctx, adaID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.FailedLoginAttempts)
+	assert.Equal(t, *lockedUntil, got.LockedUntil)
+
+	assert.NoError(t, r.Players.ResetFailedLogins(ctx, adaID))
+	got, err = r.Players.FindOne(ctx, adaID)
+	assert.NoError(t, err)
+	assert.True(t, got.LockedUntil.IsZero())
+}
+
+func testPlayed(t *testing.T, r Repositories) {
+	ctx := t.Context()
+
+	playerID := insertPlayer(t, r, "lardira")
+	otherID := insertPlayer(t, r, "ada")
+	gameID := insertGame(t, r, "Celeste")
+
+	day := func(d int) *time.Time {
+		at := time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+	played := func(completedAt *time.Time, status player.PlayedGameStatus) int {
+		id, err := r.Played.Import(ctx, &player.PlayedGame{
+			PlayerID:    playerID,
+			GameID:      gameID,
+			Points:      3,
+			Status:      status,
+			StartedAt:   *day(1),
+			CompletedAt: completedAt,
+			PlayTime:    &types.DurationString{Duration: 90 * time.Minute},
+		})
+		assert.NoError(t, err)
+		return id
+	}
+	first := played(day(3), player.PlayedGameStatusCompleted)
+	second := played(day(2), player.PlayedGameStatusDropped)
+	sameDay := played(day(3), player.PlayedGameStatusCompleted)
+
+	current, err := r.Played.Insert(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Points: 3})
+	assert.NoError(t, err)
+
+	got, err := r.Played.FindOne(ctx, playerID, current)
+	assert.NoError(t, err)
+	assert.Equal(t, player.PlayedGameStatusAdded, got.Status)
+	assert.False(t, got.StartedAt.IsZero())
+	assert.Zero(t, got.CompletedAt)
+
+	got, err = r.Played.FindOne(ctx, playerID, first)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, got.PlayTime.Duration)
+	assert.True(t, day(3).Equal(*got.CompletedAt))
+
+	_, err = r.Played.FindOne(ctx, otherID, first)
+	assert.IsError(t, err, player.ErrPlayedGameNotFound)
+
+	all, err := r.Played.FindAll(ctx, playerID)
+	assert.NoError(t, err)
+	ids := make([]int, 0, len(all))
+	for _, pg := range all {
+		ids = append(ids, pg.ID)
+	}
+	assert.Equal(t, []int{current, sameDay, first, second}, ids)
+
+	status := player.PlayedGameStatusInProgress
+	playTime := types.DurationString{Duration: 2 * time.Hour}
+	id, err := r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &status, PlayTime: &playTime})
+	assert.NoError(t, err)
+	assert.Equal(t, current, id)
+
+	got, err = r.Played.FindOne(ctx, playerID, current)
+	assert.NoError(t, err)
+	assert.Equal(t, status, got.Status)
+	assert.Equal(t, 2*time.Hour, got.PlayTime.Duration)
+
+	others, err := r.Played.FindAll(ctx, otherID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(others))
+
+	// statuses are an enum
+	invalid := player.PlayedGameStatus("paused")
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &invalid})
+	assert.Error(t, err)
+	_, err = r.Played.Import(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Status: invalid, StartedAt: *day(1)})
+	assert.Error(t, err)
+
+	// play time is kept in whole microseconds like an interval
+	playTime = types.DurationString{Duration: 26*time.Hour + 3*time.Second + 1500*time.Nanosecond}
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, PlayTime: &playTime})
+	assert.NoError(t, err)
+	got, err = r.Played.FindOne(ctx, playerID, current)
+	assert.NoError(t, err)
+	assert.Equal(t, 26*time.Hour+3*time.Second+time.Microsecond, got.PlayTime.Duration)
+	assert.Equal(t, status, got.Status)
+
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current + sameDay + first + second, Status: &status})
+	assert.IsError(t, err, player.ErrPlayedGameNotFound)
+}
+
+func testNonterminated(t *testing.T, r Repositories) {
+	ctx := t.Context()
+	playerID := insertPlayer(t, r, "lardira")
+	otherID := insertPlayer(t, r, "ada")
+	gameID := insertGame(t, r, "Celeste")
+
+	_, err := r.Played.FindNonterminated(ctx, playerID)
+	assert.IsError(t, err, player.ErrPlayedGameNotFound)
+
+	completedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	finished, err := r.Played.Import(ctx, &player.PlayedGame{
+		PlayerID:    playerID,
+		GameID:      gameID,
+		Status:      player.PlayedGameStatusCompleted,
+		StartedAt:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		CompletedAt: &completedAt,
+	})
+	assert.NoError(t, err)
+	_, err = r.Played.FindNonterminated(ctx, playerID)
+	assert.IsError(t, err, player.ErrPlayedGameNotFound)
+
+	current, err := r.Played.Insert(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Points: 3})
+	assert.NoError(t, err)
+	got, err := r.Played.FindNonterminated(ctx, playerID)
+	assert.NoError(t, err)
+	assert.Equal(t, current, got.ID)
+	assert.Equal(t, player.PlayedGameStatusAdded, got.Status)
+
+	_, err = r.Played.Insert(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Points: 3})
+	assert.IsError(t, err, player.ErrNonterminatedExists)
+	_, err = r.Played.Import(ctx, &player.PlayedGame{
+		PlayerID:  playerID,
+		GameID:    gameID,
+		Status:    player.PlayedGameStatusInProgress,
+		StartedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+	})
+	assert.IsError(t, err, player.ErrNonterminatedExists)
+	reopened := player.PlayedGameStatusInProgress
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: finished, Status: &reopened})
+	assert.IsError(t, err, player.ErrNonterminatedExists)
+
+	_, err = r.Played.Insert(ctx, &player.PlayedGame{PlayerID: otherID, GameID: gameID, Points: 3})
+	assert.NoError(t, err)
+
+	// the game in progress stays the unfinished one
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &reopened})
+	assert.NoError(t, err)
+	got, err = r.Played.FindNonterminated(ctx, playerID)
+	assert.NoError(t, err)
+	assert.Equal(t, player.PlayedGameStatusInProgress, got.Status)
+
+	dropped := player.PlayedGameStatusDropped
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &dropped})
+	assert.NoError(t, err)
+	_, err = r.Played.FindNonterminated(ctx, playerID)
+	assert.IsError(t, err, player.ErrPlayedGameNotFound)
+	next, err := r.Played.Insert(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Points: 3})
+	assert.NoError(t, err)
+	got, err = r.Played.FindNonterminated(ctx, playerID)
+	assert.NoError(t, err)
+	assert.Equal(t, next, got.ID)
+}
+
+func testTransition(t *testing.T, r Repositories) {
+	ctx := t.Context()
+	playerID := insertPlayer(t, r, "lardira")
+	gameID := insertGame(t, r, "Celeste")
+
+	current, err := r.Played.Insert(ctx, &player.PlayedGame{PlayerID: playerID, GameID: gameID, Points: 3})
+	assert.NoError(t, err)
+
+	// concurrent requests read the same status, only the first update applies
+	added := player.PlayedGameStatusAdded
+	const requests = 8
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := range requests {
+		status := player.PlayedGameStatusCompleted
+		if i%2 == 0 {
+			status = player.PlayedGameStatusDropped
+		}
+		wg.Go(func() {
+			errs <- r.Tx.WithTx(ctx, func(ctx context.Context) error {
+				_, err := r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &status, FromStatus: &added})
+				return err
+			})
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	applied := 0
+	for err := range errs {
+		if err == nil {
+			applied++
+			continue
+		}
+		assert.IsError(t, err, player.ErrPlayedGameStatusChanged)
+	}
+	assert.Equal(t, 1, applied)
+
+	got, err := r.Played.FindOne(ctx, playerID, current)
+	assert.NoError(t, err)
+	assert.True(t, got.StatusTerminated())
+
+	// the status is not checked without FromStatus
+	rerolled := player.PlayedGameStatusRerolled
+	_, err = r.Played.Update(ctx, &player.PlayedGameUpdate{ID: current, Status: &rerolled})
+	assert.NoError(t, err)
+}
+
+func testStreaks(t *testing.T, r Repositories) {
+	ctx := t.Context()
+	playerID := insertPlayer(t, r, "lardira")
+
+	_, err := r.Streaks.FindOne(ctx, playerID)
+	assert.IsError(t, err, player.ErrStreakNotFound)
+
+	assert.NoError(t, r.Streaks.Save(ctx, &player.Streak{PlayerID: playerID, Completions: 1, BestCompletions: 1}))
+	assert.NoError(t, r.Streaks.Save(ctx, &player.Streak{PlayerID: playerID, Drops: 1, Penalty: 1, BestCompletions: 1}))
+
+	got, err := r.Streaks.FindOne(ctx, playerID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.Completions)
+	assert.Equal(t, 1, got.Drops)
+	assert.Equal(t, 1, got.Penalty)
+	assert.Equal(t, 1, got.BestCompletions)
+}
+
+func testLedger(t *testing.T, r Repositories) {
+	ctx := t.Context()
+	lardiraID := insertPlayer(t, r, "lardira")
+	adaID := insertPlayer(t, r, "Ada")
+	zedID := insertPlayer(t, r, "zed")
+	gameID := insertGame(t, r, "Celeste")
+
+	completedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	completed := func(playerID string) int {
+		id, err := r.Played.Import(ctx, &player.PlayedGame{
+			PlayerID:    playerID,
+			GameID:      gameID,
+			Points:      3,
+			Status:      player.PlayedGameStatusCompleted,
+			StartedAt:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			CompletedAt: &completedAt,
+		})
+		assert.NoError(t, err)
+		return id
+	}
+	lardiraGame := completed(lardiraID)
+	adaGame := completed(adaID)
+
+	assert.NoError(t, r.Ledger.Insert(ctx, []player.LedgerEntry{
+		{PlayerID: lardiraID, PlayedGameID: lardiraGame, Delta: 3, Reason: player.LedgerReasonCompletion},
+		{PlayerID: lardiraID, PlayedGameID: lardiraGame, Delta: -1, Reason: player.LedgerReasonAdjustment},
+		{PlayerID: adaID, PlayedGameID: adaGame, Delta: 2, Reason: player.LedgerReasonCompletion},
+	}))
+
+	entries, err := r.Ledger.FindAll(ctx, lardiraID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, player.LedgerReasonCompletion, entries[0].Reason)
+	assert.Equal(t, player.LedgerReasonAdjustment, entries[1].Reason)
+
+	// ties are ordered by username regardless of case
+	leaderboard, err := r.Ledger.Leaderboard(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{adaID, lardiraID, zedID}, leaderboardIDs(leaderboard))
+	assert.Equal(t, 2, leaderboard[0].Total)
+	assert.Equal(t, 1, leaderboard[0].Completed)
+	assert.Equal(t, 0, leaderboard[2].Total)
+
+	assert.NoError(t, r.Ledger.Insert(ctx, []player.LedgerEntry{
+		{PlayerID: lardiraID, PlayedGameID: lardiraGame, Delta: 1, Reason: player.LedgerReasonHistory},
+	}))
+	leaderboard, err = r.Ledger.Leaderboard(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{lardiraID, adaID, zedID}, leaderboardIDs(leaderboard))
+}
+
+func testBacklog(t *testing.T, r Repositories) {
+	ctx := t.Context()
+	playerID := insertPlayer(t, r, "lardira")
+	otherID := insertPlayer(t, r, "ada")
+	items := make([]int, 0, 4)
+	games := make([]int, 0, 4)
+	for _, title := range []string{"Celeste", "Hades", "Inside", "Limbo"} {
+		gameID := insertGame(t, r, title)
+		id, err := r.Backlog.Insert(ctx, &backlog.Item{PlayerID: playerID, GameID: gameID})
+		assert.NoError(t, err)
+		items = append(items, id)
+		games = append(games, gameID)
+	}
+
+	_, err := r.Backlog.Insert(ctx, &backlog.Item{PlayerID: playerID, GameID: games[0]})
+	assert.IsError(t, err, backlog.ErrItemExists)
+	// other players have their own backlog
+	otherItem, err := r.Backlog.Insert(ctx, &backlog.Item{PlayerID: otherID, GameID: games[0]})
+	assert.NoError(t, err)
+
+	got, err := r.Backlog.FindOne(ctx, otherID, otherItem)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.Position)
+	_, err = r.Backlog.FindOne(ctx, otherID, items[0])
+	assert.IsError(t, err, backlog.ErrItemNotFound)
+
+	assertBacklog := func(want ...int) {
+		t.Helper()
+		all, err := r.Backlog.FindAll(ctx, playerID)
+		assert.NoError(t, err)
+		ids := make([]int, 0, len(all))
+		for i, item := range all {
+			assert.Equal(t, i, item.Position)
+			ids = append(ids, item.ID)
+		}
+		assert.Equal(t, want, ids)
+	}
+	assertBacklog(items...)
+
+	assert.NoError(t, r.Backlog.Move(ctx, playerID, items[3], 1))
+	assertBacklog(items[0], items[3], items[1], items[2])
+
+	assert.NoError(t, r.Backlog.Move(ctx, playerID, items[0], 10))
+	assertBacklog(items[3], items[1], items[2], items[0])
+
+	assert.IsError(t, r.Backlog.Move(ctx, otherID, items[0], 0), backlog.ErrItemNotFound)
+
+	assert.NoError(t, r.Backlog.Delete(ctx, playerID, items[1]))
+	assertBacklog(items[3], items[2], items[0])
+	assert.IsError(t, r.Backlog.Delete(ctx, playerID, items[1]), backlog.ErrItemNotFound)
+
+	assert.NoError(t, r.Backlog.DeleteByGame(ctx, playerID, games[3]))
+	assertBacklog(items[2], items[0])
+	assert.NoError(t, r.Backlog.DeleteByGame(ctx, playerID, games[3]))
+}
+
+func testTx(t *testing.T, r Repositories) {
+	ctx := t.Context()
+
+	errRollback := errors.New("rollback")
+	err := r.Tx.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.Games.Insert(ctx, &game.Game{Title: "Celeste", Points: 3, HoursToBeat: 8}); err != nil {
+			return err
+		}
+		// changes are visible inside the transaction
+		if _, err := r.Games.FindByTitle(ctx, "Celeste"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	assert.IsError(t, err, errRollback)
+
+	_, err = r.Games.FindByTitle(ctx, "Celeste")
+	assert.IsError(t, err, game.ErrGameNotFound)
+
+	err = r.Tx.WithTx(ctx, func(ctx context.Context) error {
+		_, err := r.Games.Insert(ctx, &game.Game{Title: "Hades", Points: 6, HoursToBeat: 20})
+		return err
+	})
+	assert.NoError(t, err)
+
+	_, err = r.Games.FindByTitle(ctx, "Hades")
+	assert.NoError(t, err)
+}
+
+func insertGame(t testing.TB, r Repositories, title string) int {
+	t.Helper()
+	id, err := r.Games.Insert(t.Context(), &game.Game{Title: title, Points: 3, HoursToBeat: 8})
+	assert.NoError(t, err)
+	return id
+}
+
+func insertPlayer(t testing.TB, r Repositories, username string) string {
+	t.Helper()
+	id, err := r.Players.Insert(t.Context(), &player.Player{Username: username, Password: "hash"})
+	assert.NoError(t, err)
+	return id
+}
+
+func gameIDs(games []game.Game) []int {
+	ids := make([]int, 0, len(games))
+	for _, g := range games {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+func leaderboardIDs(players []player.LeaderboardPlayer) []string {
+	ids := make([]string, 0, len(players))
+	for _, p := range players {
+		ids = append(ids, p.PlayerID)
+	}
+	return ids
+}
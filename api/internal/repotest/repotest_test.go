@@ -0,0 +1,178 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/memory"
+)
+
+func TestMemory(t *testing.T) {
+	Run(t, func(t *testing.T) Repositories {
+		store := memory.NewStore()
+		return Repositories{
+			Games:   memory.NewGameRepository(store),
+			Players: memory.NewPlayerRepository(store),
+			Played:  memory.NewPlayedRepository(store),
+			Streaks: memory.NewStreakRepository(store),
+			Ledger:  memory.NewLedgerRepository(store),
+			Backlog: memory.NewBacklogRepository(store),
+			Tx:      memory.NewTxManager(store),
+		}
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	Run(t, func(t *testing.T) Repositories { return newSQLite(t) })
+}
+
+func TestPostgres(t *testing.T) {
+	if os.Getenv("TEST_DB_URL") == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+	Run(t, func(t *testing.T) Repositories { return newPostgres(t) })
+}
+
+// BenchmarkNonterminated compares checks for an unfinished played game before one is
+// created, the scan of the whole history of the player and the query of the unfinished one
+func BenchmarkNonterminated(b *testing.B) {
+	storages := map[string]func(tb testing.TB) Repositories{"sqlite": newSQLite}
+	if os.Getenv("TEST_DB_URL") != "" {
+		storages["postgres"] = newPostgres
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(storages)) {
+		b.Run(name, func(b *testing.B) {
+			r := storages[name](b)
+			playerID := insertHistories(b, r, 20, 500)
+
+			b.Run("scan", func(b *testing.B) {
+				for b.Loop() {
+					played, err := r.Played.FindAll(b.Context(), playerID)
+					assert.NoError(b, err)
+					for _, pg := range played {
+						if !pg.StatusTerminated() {
+							b.Fatal("history has an unfinished game")
+						}
+					}
+				}
+			})
+			b.Run("exists", func(b *testing.B) {
+				for b.Loop() {
+					_, err := r.Played.FindNonterminated(b.Context(), playerID)
+					assert.IsError(b, err, player.ErrPlayedGameNotFound)
+				}
+			})
+		})
+	}
+}
+
+func newSQLite(tb testing.TB) Repositories {
+	sqlDB, err := db.NewSQLite(tb.Context(), filepath.Join(tb.TempDir(), "playtrack.db"))
+	assert.NoError(tb, err)
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := db.NewSQLiteMigrator(sqlDB, "")
+	assert.NoError(tb, err)
+	defer migrator.Close()
+	assert.NoError(tb, migrator.Up(tb.Context()))
+
+	return Repositories{
+		Games:   game.NewSQLiteRepository(sqlDB),
+		Players: player.NewSQLiteRepository(sqlDB),
+		Played:  player.NewSQLitePlayedRepository(sqlDB),
+		Streaks: player.NewSQLiteStreakRepository(sqlDB),
+		Ledger:  player.NewSQLiteLedgerRepository(sqlDB),
+		Backlog: backlog.NewSQLiteRepository(sqlDB),
+		Tx:      db.NewSQLiteTxManager(sqlDB),
+	}
+}
+
+func newPostgres(tb testing.TB) Repositories {
+	pool := testSchema(tb)
+	migrator, err := db.NewMigrator(pool, "")
+	assert.NoError(tb, err)
+	defer migrator.Close()
+	assert.NoError(tb, migrator.Up(tb.Context()))
+
+	return Repositories{
+		Games:   game.NewPGRepository(pool),
+		Players: player.NewPGRepository(pool),
+		Played:  player.NewPGPlayedRepository(pool),
+		Streaks: player.NewPGStreakRepository(pool),
+		Ledger:  player.NewPGLedgerRepository(pool),
+		Backlog: backlog.NewPGRepository(pool),
+		Tx:      db.NewTxManager(pool),
+	}
+}
+
+// insertHistories imports finished played games for players and returns the id of the last one
+func insertHistories(tb testing.TB, r Repositories, players, games int) string {
+	tb.Helper()
+	gameID := insertGame(tb, r, "Celeste")
+	startedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := startedAt.Add(24 * time.Hour)
+
+	var playerID string
+	for i := range players {
+		playerID = insertPlayer(tb, r, fmt.Sprintf("player%d", i))
+		err := r.Tx.WithTx(tb.Context(), func(ctx context.Context) error {
+			for range games {
+				_, err := r.Played.Import(ctx, &player.PlayedGame{
+					PlayerID:    playerID,
+					GameID:      gameID,
+					Points:      3,
+					Status:      player.PlayedGameStatusCompleted,
+					StartedAt:   startedAt,
+					CompletedAt: &completedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.NoError(tb, err)
+	}
+	return playerID
+}
+
+// testSchema connects to TEST_DB_URL with a fresh schema which is dropped after the test
+func testSchema(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DB_URL")
+	schema := "repotest_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	admin, err := pgx.Connect(t.Context(), dbURL)
+	assert.NoError(t, err)
+	_, err = admin.Exec(t.Context(), "CREATE SCHEMA "+schema)
+	assert.NoError(t, err)
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	assert.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(t.Context(), config)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close(context.Background())
+	})
+	return pool
+}
@@ -0,0 +1,444 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/pkg/fake"
+	"github.com/lardira/playtrack/internal/pkg/password"
+	"github.com/lardira/playtrack/internal/pkg/types"
+)
+
+const (
+	// AdminUsername is the seeded admin, other players get generated usernames
+	AdminUsername = "devadmin"
+
+	DefaultGames    = 60
+	DefaultPlayers  = 25
+	DefaultPassword = "playtrack-dev"
+
+	maxHistory = 30
+	day        = 24 * time.Hour
+)
+
+var (
+	ErrNotEmpty = errors.New("database already has players or games")
+)
+
+type GameRepository interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
+	Insert(ctx context.Context, g *game.Game) (int, error)
+}
+
+type PlayerRepository interface {
+	FindAll(ctx context.Context) ([]player.Player, error)
+	Insert(ctx context.Context, p *player.Player) (string, error)
+}
+
+type PlayedGameRepository interface {
+	Import(ctx context.Context, g *player.PlayedGame) (int, error)
+	Update(ctx context.Context, g *player.PlayedGameUpdate) (int, error)
+}
+
+type StreakRepository interface {
+	Save(ctx context.Context, s *player.Streak) error
+}
+
+type LedgerRepository interface {
+	Insert(ctx context.Context, entries []player.LedgerEntry) error
+}
+
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type AchievementBackfiller interface {
+	Backfill(ctx context.Context, playerIDs []string) (int, error)
+}
+
+type Options struct {
+	// Seed selects the data, the same seed and Now give the same catalog, players and histories
+	Seed    uint64
+	Games   int
+	Players int
+	// Password is the password of every seeded player
+	Password string
+	Streak   player.StreakRules
+	// Now is the end of histories, they go back up to a year and a half before it
+	Now time.Time
+}
+
+// Result counts the seeded data
+type Result struct {
+	Games        int
+	Players      int
+	PlayedGames  int
+	Achievements int
+}
+
+// Seeder fills an empty database with demo data. Played games are written through
+// the status state machine and scored by the streak rules as the api does.
+type Seeder struct {
+	gameRepository       GameRepository
+	playerRepository     PlayerRepository
+	playedGameRepository PlayedGameRepository
+	streakRepository     StreakRepository
+	ledgerRepository     LedgerRepository
+	tx                   TxManager
+	achievements         AchievementBackfiller
+}
+
+func NewSeeder(
+	gameRepository GameRepository,
+	playerRepository PlayerRepository,
+	playedGameRepository PlayedGameRepository,
+	streakRepository StreakRepository,
+	ledgerRepository LedgerRepository,
+	tx TxManager,
+	achievements AchievementBackfiller,
+) *Seeder {
+	return &Seeder{
+		gameRepository:       gameRepository,
+		playerRepository:     playerRepository,
+		playedGameRepository: playedGameRepository,
+		streakRepository:     streakRepository,
+		ledgerRepository:     ledgerRepository,
+		tx:                   tx,
+		achievements:         achievements,
+	}
+}
+
+// Seed writes the catalog, players and their histories, it returns ErrNotEmpty
+// unless the database has no players and games
+func (s *Seeder) Seed(ctx context.Context, opts Options) (*Result, error) {
+	if err := s.checkEmpty(ctx); err != nil {
+		return nil, err
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	faker := fake.New(opts.Seed)
+
+	games, err := s.seedGames(ctx, faker, opts.Games)
+	if err != nil {
+		return nil, fmt.Errorf("games: %w", err)
+	}
+	players, err := s.seedPlayers(ctx, faker, opts.Players, opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("players: %w", err)
+	}
+
+	res := Result{Games: len(games), Players: len(players)}
+	ids := make([]string, 0, len(players))
+	for _, p := range players {
+		h := history{
+			faker:  faker,
+			games:  games,
+			rules:  opts.Streak,
+			now:    opts.Now,
+			streak: player.Streak{PlayerID: p.ID},
+		}
+		err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+			return s.seedHistory(ctx, &h, p.ID)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("history of %s: %w", p.Username, err)
+		}
+		res.PlayedGames += h.played
+		ids = append(ids, p.ID)
+	}
+
+	res.Achievements, err = s.achievements.Backfill(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("achievements: %w", err)
+	}
+	return &res, nil
+}
+
+func (s *Seeder) checkEmpty(ctx context.Context) error {
+	games, err := s.gameRepository.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("games find: %w", err)
+	}
+	players, err := s.playerRepository.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("players find: %w", err)
+	}
+	if len(games) > 0 || len(players) > 0 {
+		return ErrNotEmpty
+	}
+	return nil
+}
+
+// seedGames inserts games with titles of movies and books, most of them are short
+func (s *Seeder) seedGames(ctx context.Context, faker *gofakeit.Faker, n int) ([]game.Game, error) {
+	out := make([]game.Game, 0, n)
+	titles := make(map[string]bool, n)
+	for len(out) < n {
+		title := faker.MovieName()
+		if faker.Bool() {
+			title = faker.BookTitle()
+		}
+		if len([]rune(title)) < 2 || titles[strings.ToLower(title)] {
+			continue
+		}
+		titles[strings.ToLower(title)] = true
+
+		g := game.Game{
+			Title:       title,
+			HoursToBeat: hoursToBeat(faker),
+		}
+		if faker.Float64() < 0.7 {
+			url := "https://example.com/games/" + slug(title)
+			g.URL = &url
+		}
+		g.CalculatePoints()
+		if err := g.Valid(); err != nil {
+			return nil, fmt.Errorf("game %q: %w", title, err)
+		}
+
+		id, err := s.gameRepository.Insert(ctx, &g)
+		if err != nil {
+			return nil, fmt.Errorf("insert %q: %w", title, err)
+		}
+		g.ID = id
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+// seedPlayers inserts the admin and n-1 players, all of them share the password
+func (s *Seeder) seedPlayers(ctx context.Context, faker *gofakeit.Faker, n int, pass string) ([]player.Player, error) {
+	hash, err := password.Hash(pass)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]player.Player, 0, n)
+	usernames := map[string]bool{AdminUsername: true}
+	for len(out) < n {
+		p := player.Player{
+			Username:      AdminUsername,
+			Password:      pass,
+			IsAdmin:       len(out) == 0,
+			EmailVerified: true,
+		}
+		if !p.IsAdmin {
+			p.Username = faker.Gamertag()
+		}
+		p.Normalize()
+		if err := p.Valid(); err != nil {
+			if p.IsAdmin {
+				return nil, err
+			}
+			continue
+		}
+		key := strings.ToLower(p.Username)
+		if !p.IsAdmin && usernames[key] {
+			continue
+		}
+		usernames[key] = true
+
+		email := key + "@example.com"
+		p.Email = &email
+		if faker.Float64() < 0.4 {
+			description := faker.Sentence(10)
+			p.Description = &description
+		}
+		p.Password = hash
+
+		id, err := s.playerRepository.Insert(ctx, &p)
+		if err != nil {
+			return nil, fmt.Errorf("insert %q: %w", p.Username, err)
+		}
+		p.ID = id
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// history generates played games of a player one after another, each game starts
+// after the previous one is finished
+type history struct {
+	faker  *gofakeit.Faker
+	games  []game.Game
+	rules  player.StreakRules
+	now    time.Time
+	streak player.Streak
+	played int
+}
+
+func (s *Seeder) seedHistory(ctx context.Context, h *history, playerID string) error {
+	n := h.faker.IntRange(0, min(maxHistory, len(h.games)))
+	order := make([]int, len(h.games))
+	for i := range order {
+		order[i] = i
+	}
+	h.faker.ShuffleInts(order)
+	order = order[:n]
+	at := h.now.Add(-time.Duration(h.faker.IntRange(30, 540)) * day)
+
+	for i, gi := range order {
+		g := h.games[gi]
+		startedAt := at.Add(time.Duration(h.faker.IntRange(1, 7*24)) * time.Hour)
+		status, finishedAt := h.result(&g, startedAt)
+
+		// the last game and games which would finish in the future stay unfinished
+		if i == len(order)-1 && h.faker.Bool() || !finishedAt.Before(h.now) {
+			if !startedAt.Before(h.now) {
+				break
+			}
+			status = player.PlayedGameStatusAdded
+			if h.faker.Bool() {
+				status = player.PlayedGameStatusInProgress
+			}
+		}
+
+		if err := s.play(ctx, h, playerID, &g, startedAt, status, finishedAt); err != nil {
+			return fmt.Errorf("game %d: %w", g.ID, err)
+		}
+		h.played++
+		if status == player.PlayedGameStatusAdded || status == player.PlayedGameStatusInProgress {
+			break
+		}
+		at = finishedAt
+	}
+
+	if h.streak.UpdatedAt.IsZero() {
+		return nil
+	}
+	return s.streakRepository.Save(ctx, &h.streak)
+}
+
+// play adds the game and moves it through statuses to status
+func (s *Seeder) play(
+	ctx context.Context,
+	h *history,
+	playerID string,
+	g *game.Game,
+	startedAt time.Time,
+	status player.PlayedGameStatus,
+	finishedAt time.Time,
+) error {
+	pg := player.PlayedGame{
+		PlayerID:  playerID,
+		GameID:    g.ID,
+		Points:    g.Points,
+		Status:    player.PlayedGameStatusAdded,
+		StartedAt: startedAt,
+	}
+	id, err := s.playedGameRepository.Import(ctx, &pg)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	pg.ID = id
+
+	for _, next := range statusPath(status) {
+		if err := pg.StatusNextValid(next); err != nil {
+			return fmt.Errorf("%s to %s: %w", pg.Status, next, err)
+		}
+
+		upd := player.PlayedGameUpdate{ID: pg.ID, Status: &next}
+		var entries []player.LedgerEntry
+		if next != player.PlayedGameStatusInProgress {
+			h.finish(&pg, &upd, finishedAt)
+			h.streak, entries = h.streak.Finish(&pg, &upd, h.rules)
+			if next != player.PlayedGameStatusRerolled {
+				h.streak.UpdatedAt = finishedAt
+			}
+		}
+		if err := upd.Valid(); err != nil {
+			return err
+		}
+
+		if _, err := s.playedGameRepository.Update(ctx, &upd); err != nil {
+			return fmt.Errorf("update to %s: %w", next, err)
+		}
+		if err := s.ledgerRepository.Insert(ctx, entries); err != nil {
+			return fmt.Errorf("ledger insert: %w", err)
+		}
+		pg.Status = next
+	}
+	return nil
+}
+
+// result picks how the game ends and when, completions take about the hours to beat
+// played a few hours a day
+func (h *history) result(g *game.Game, startedAt time.Time) (player.PlayedGameStatus, time.Time) {
+	switch r := h.faker.Float64(); {
+	case r < 0.6:
+		hours := float64(g.HoursToBeat) * h.faker.Float64Range(0.6, 1.6)
+		days := hours / h.faker.Float64Range(1, 4)
+		return player.PlayedGameStatusCompleted, startedAt.Add(time.Duration(max(days, 0.1) * float64(day)))
+	case r < 0.85:
+		return player.PlayedGameStatusDropped, startedAt.Add(time.Duration(h.faker.IntRange(1, 21)) * day)
+	default:
+		return player.PlayedGameStatusRerolled, startedAt.Add(time.Duration(h.faker.IntRange(1, 48)) * time.Hour)
+	}
+}
+
+// finish fills the update of a finished game the way players do, a reroll gets
+// no points as the api gives it
+func (h *history) finish(pg *player.PlayedGame, upd *player.PlayedGameUpdate, finishedAt time.Time) {
+	upd.CompletedAt = &finishedAt
+
+	switch *upd.Status {
+	case player.PlayedGameStatusCompleted:
+		hours := finishedAt.Sub(pg.StartedAt).Hours() / 24 * h.faker.Float64Range(1, 4)
+		playTime := types.NewDurationString(time.Duration(hours * float64(time.Hour)).Truncate(time.Minute))
+		upd.PlayTime = &playTime
+		if h.faker.Float64() < 0.8 {
+			rating := h.faker.IntRange(55, 100)
+			upd.Rating = &rating
+		}
+	case player.PlayedGameStatusDropped:
+		if h.faker.Float64() < 0.4 {
+			rating := h.faker.IntRange(10, 60)
+			upd.Rating = &rating
+		}
+	case player.PlayedGameStatusRerolled:
+		noPoints := 0
+		upd.Points = &noPoints
+	}
+
+	if h.faker.Float64() < 0.3 {
+		comment := h.faker.Sentence(8)
+		upd.Comment = &comment
+	}
+}
+
+// statusPath lists statuses a new played game goes through to status
+func statusPath(status player.PlayedGameStatus) []player.PlayedGameStatus {
+	switch status {
+	case player.PlayedGameStatusAdded:
+		return nil
+	case player.PlayedGameStatusInProgress, player.PlayedGameStatusRerolled:
+		return []player.PlayedGameStatus{status}
+	default:
+		return []player.PlayedGameStatus{player.PlayedGameStatusInProgress, status}
+	}
+}
+
+// hoursToBeat is short for half of the games and long for a few
+func hoursToBeat(faker *gofakeit.Faker) int {
+	switch r := faker.Float64(); {
+	case r < 0.5:
+		return faker.IntRange(1, 12)
+	case r < 0.85:
+		return faker.IntRange(12, 40)
+	default:
+		return faker.IntRange(40, 120)
+	}
+}
+
+func slug(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}), "-")
+}
@@ -0,0 +1,138 @@
+package seed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/memory"
+	"github.com/lardira/playtrack/internal/pkg/password"
+)
+
+var (
+	rules = player.StreakRules{BonusAfter: 3, BonusPoints: 2, DecayAfter: 2}
+	now   = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type seeded struct {
+	store   *memory.Store
+	players *memory.PlayerRepository
+	played  *memory.PlayedRepository
+	streaks *memory.StreakRepository
+	ledger  *memory.LedgerRepository
+	result  *Result
+}
+
+func seed(t *testing.T, s uint64) seeded {
+	t.Helper()
+	store := memory.NewStore()
+	out := seeded{
+		store:   store,
+		players: memory.NewPlayerRepository(store),
+		played:  memory.NewPlayedRepository(store),
+		streaks: memory.NewStreakRepository(store),
+		ledger:  memory.NewLedgerRepository(store),
+	}
+	games := memory.NewGameRepository(store)
+	seeder := NewSeeder(
+		games,
+		out.players,
+		out.played,
+		out.streaks,
+		out.ledger,
+		memory.NewTxManager(store),
+		achievement.NewTracker(memory.NewAchievementRepository(store), out.played, games, nil),
+	)
+
+	res, err := seeder.Seed(t.Context(), Options{
+		Seed:     s,
+		Games:    20,
+		Players:  6,
+		Password: "secret-dev",
+		Streak:   rules,
+		Now:      now,
+	})
+	assert.NoError(t, err)
+	out.result = res
+	return out
+}
+
+// summary is the seeded data without generated ids
+func (s seeded) summary(t *testing.T) []string {
+	t.Helper()
+	players, err := s.players.FindAll(t.Context())
+	assert.NoError(t, err)
+
+	var out []string
+	for _, p := range players {
+		played, err := s.played.FindAll(t.Context(), p.ID)
+		assert.NoError(t, err)
+		out = append(out, p.Username)
+		for _, pg := range played {
+			out = append(out, string(pg.Status)+" "+pg.StartedAt.String())
+		}
+	}
+	return out
+}
+
+func TestSeed(t *testing.T) {
+	s := seed(t, 42)
+	assert.Equal(t, 20, s.result.Games)
+	assert.Equal(t, 6, s.result.Players)
+	assert.True(t, s.result.PlayedGames > 0)
+
+	players, err := s.players.FindAll(t.Context())
+	assert.NoError(t, err)
+	admins := 0
+	for _, p := range players {
+		if p.IsAdmin {
+			admins++
+			assert.Equal(t, AdminUsername, p.Username)
+		}
+		assert.True(t, password.CompareHash("secret-dev", p.Password))
+
+		played, err := s.played.FindAll(t.Context(), p.ID)
+		assert.NoError(t, err)
+		unfinished := 0
+		for _, pg := range played {
+			assert.True(t, pg.StartedAt.Before(now))
+			if pg.StatusTerminated() {
+				assert.True(t, pg.CompletedAt != nil && pg.CompletedAt.Before(now))
+			} else {
+				unfinished++
+			}
+		}
+		assert.True(t, unfinished <= 1)
+
+		entries, err := s.ledger.FindAll(t.Context(), p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(player.VerifyLedger(played, entries)))
+
+		expected := player.StreakFromHistory(p.ID, played, rules)
+		streak, err := s.streaks.FindOne(t.Context(), p.ID)
+		if expected == (player.Streak{PlayerID: p.ID}) {
+			assert.IsError(t, err, player.ErrStreakNotFound)
+			continue
+		}
+		assert.NoError(t, err)
+		streak.UpdatedAt = time.Time{}
+		assert.Equal(t, expected, *streak)
+	}
+	assert.Equal(t, 1, admins)
+}
+
+func TestSeedReproducible(t *testing.T) {
+	assert.Equal(t, seed(t, 7).summary(t), seed(t, 7).summary(t))
+	assert.NotEqual(t, seed(t, 7).summary(t), seed(t, 8).summary(t))
+}
+
+func TestSeedNotEmpty(t *testing.T) {
+	s := seed(t, 1)
+	games := memory.NewGameRepository(s.store)
+	seeder := NewSeeder(games, s.players, s.played, s.streaks, s.ledger, memory.NewTxManager(s.store), nil)
+
+	_, err := seeder.Seed(t.Context(), Options{Seed: 1, Games: 1, Players: 1, Password: "secret-dev"})
+	assert.IsError(t, err, ErrNotEmpty)
+}
@@ -2,46 +2,143 @@ package server
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/account"
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/admin"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
 	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/challenge"
+	"github.com/lardira/playtrack/internal/domain/discord"
 	"github.com/lardira/playtrack/internal/domain/game"
 	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/domain/stream"
+	"github.com/lardira/playtrack/internal/domain/webhook"
 	"github.com/lardira/playtrack/internal/middleware"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/lardira/playtrack/internal/pkg/keyset"
+	"github.com/lardira/playtrack/internal/pkg/mailer"
+	"github.com/lardira/playtrack/internal/pkg/oauth"
+	"github.com/lardira/playtrack/internal/pkg/password"
 	"github.com/lardira/playtrack/internal/tech"
 	"github.com/rs/cors"
 )
 
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+)
+
 type Options struct {
-	Host              string
-	Port              string
-	DatabaseURL       string
+	Host string
+	Port string
+	// Storage is StoragePostgres, StorageSQLite or StorageMemory, postgres when empty
+	Storage     string
+	DatabaseURL string
+	// SQLitePath is the database file of StorageSQLite
+	SQLitePath string
+	// JWTSecret verifies tokens signed with the shared secret before keys were used
 	JWTSecret         string
+	JWTKeysDir        string
+	JWTSigningKeyID   string
 	CheckPollInterval time.Duration
+
+	BcryptCost       int
+	AuthRateLimit    int
+	LoginMaxAttempts int
+	LoginLockout     time.Duration
+	// PlayerCacheTTL is how long role and suspension of a player are cached by the auth middleware
+	PlayerCacheTTL time.Duration
+	// TrustedProxies are addresses or cidrs of reverse proxies whose client ip headers are used
+	TrustedProxies []string
+
+	// AppURL is a public url of the web app, used in emails
+	AppURL     string
+	MailDriver string
+	MailDir    string
+	SMTP       mailer.SMTPOptions
+
+	// APIURL is a public url of the api, used in provider callbacks
+	APIURL         string
+	OAuthProviders map[string]oauth.Config
+
+	WebhookPollInterval time.Duration
+	WebhookMaxAttempts  int
+
+	// EventsNotify shares stream events between replicas with postgres LISTEN/NOTIFY
+	EventsNotify bool
+
+	// DiscordPublicKey enables the interactions endpoint of the discord bot
+	DiscordPublicKey string
+	DiscordAppID     string
+	DiscordBotToken  string
+
+	// Streak configures completion bonuses and drop penalties, defaults when empty
+	Streak player.StreakRules
 }
 
 type Server struct {
 	Options
 
-	server        *http.Server
-	db            *pgxpool.Pool
-	healthChecker *tech.HealthChecker
+	server            *http.Server
+	closeStorage      func()
+	healthChecker     *tech.HealthChecker
+	webhookDispatcher *webhook.Dispatcher
+	challengeResolver *challenge.Resolver
+	eventHub          *event.Hub
+	eventNotifier     *event.PGNotifier
 }
 
 func New(ctx context.Context, opts Options) (*Server, error) {
-	dbpool, err := db.NewPostgres(ctx, opts.DatabaseURL)
+	if opts.BcryptCost != 0 {
+		if err := password.SetCost(opts.BcryptCost); err != nil {
+			return nil, fmt.Errorf("bcrypt cost: %w", err)
+		}
+	}
+
+	trustedProxies, err := middleware.ParseTrustedProxies(opts.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newStorage(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	healthChecker := tech.NewHealthChecker(dbpool, opts.CheckPollInterval, "postgres db")
+	mail, err := newMailer(opts)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: %w", err)
+	}
+
+	keys, err := newKeySet(opts)
+	if err != nil {
+		return nil, fmt.Errorf("jwt keys: %w", err)
+	}
+
+	oauthProviders, err := newOAuthProviders(opts)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: %w", err)
+	}
+
+	var discordPublicKey ed25519.PublicKey
+	if opts.DiscordPublicKey != "" {
+		discordPublicKey, err = discord.ParsePublicKey(opts.DiscordPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("discord: %w", err)
+		}
+	}
+
+	healthChecker := tech.NewHealthChecker(store.pinger, opts.CheckPollInterval, store.name)
 
 	mux := http.NewServeMux()
 	servMux := cors.New(cors.Options{
@@ -61,49 +158,221 @@ func New(ctx context.Context, opts Options) (*Server, error) {
 	apiV1 := huma.NewGroup(api, "/v1")
 	unsecApi := huma.NewGroup(api, "/pub")
 
-	apiV1.UseMiddleware(
-		middleware.Authorize(opts.JWTSecret),
+	// TODO: use squirell for query building
+	gameRepository := store.games
+	playerRepository := store.players
+	playedGameRepository := store.played
+	tokenRepository := store.tokens
+	identityRepository := store.identities
+	oauthStateRepository := store.oauthStates
+	apiTokenRepository := store.apiTokens
+	webhookRepository := store.webhooks
+	discordLinkRepository := store.discordLinks
+	backlogRepository := store.backlog
+	challengeRepository := store.challenges
+	achievementRepository := store.achievements
+	streakRepository := store.streaks
+	adjustmentRepository := store.adjustments
+	ledgerRepository := store.ledger
+	accountRepository := store.accounts
+	txManager := store.tx
+
+	eventHub := event.NewHub(0)
+	var eventNotifier *event.PGNotifier
+	var streamPublisher event.Publisher = eventHub
+	// replicas share events through postgres, a memory store is never shared
+	if opts.EventsNotify && store.pool != nil {
+		eventNotifier = event.NewPGNotifier(store.pool)
+		streamPublisher = eventNotifier
+	}
+	// the resolver and the tracker react to played game events and publish their own
+	challengeResolver := challenge.NewResolver(
+		challengeRepository,
+		playedGameRepository,
+		ledgerRepository,
+		txManager,
+		event.Publishers{webhookRepository, streamPublisher},
+		0,
 	)
+	achievementTracker := achievement.NewTracker(
+		achievementRepository,
+		playedGameRepository,
+		gameRepository,
+		event.Publishers{webhookRepository, streamPublisher},
+	)
+	publisher := event.Publishers{webhookRepository, streamPublisher, challengeResolver, achievementTracker}
 
-	// TODO: use squirell for query building
-	gameRepository := game.NewPGRepository(dbpool)
-	playerRepository := player.NewPGRepository(dbpool)
-	playedGameRepository := player.NewPGPlayedRepository(dbpool)
+	playerCache := middleware.NewPlayerCache(playerRepository, opts.PlayerCacheTTL)
+	apiV1.UseMiddleware(
+		middleware.Authorize(keys, playerCache, apiTokenRepository),
+	)
 
 	techHandler := tech.NewHandler(healthChecker)
-	gameHandler := game.NewHandler(gameRepository)
-	playerHandler := player.NewHandler(playerRepository, gameRepository, playedGameRepository)
-	authHandler := auth.NewHandler(opts.JWTSecret, playerRepository)
+	gameHandler := game.NewHandler(gameRepository, txManager, publisher)
+	playerHandler := player.NewHandler(
+		playerRepository,
+		gameRepository,
+		playedGameRepository,
+		backlogRepository,
+		streakRepository,
+		adjustmentRepository,
+		ledgerRepository,
+		txManager,
+		publisher,
+		player.Options{Streak: opts.Streak},
+	)
+	backlogHandler := backlog.NewHandler(backlogRepository, gameRepository, txManager)
+	achievementHandler := achievement.NewHandler(achievementRepository)
+	challengeHandler := challenge.NewHandler(
+		challengeRepository,
+		playerRepository,
+		gameRepository,
+		playerHandler,
+		challengeResolver,
+		txManager,
+	)
+	webhookHandler := webhook.NewHandler(webhookRepository)
+	adminHandler := admin.NewHandler(playerRepository, playerCache)
+	accountHandler := account.NewHandler(accountRepository, playerCache, txManager, publisher)
+	streamHandler := stream.NewHandler(eventHub)
+	apiTokenHandler := apitoken.NewHandler(apiTokenRepository, playerRepository)
+	authHandler := auth.NewHandler(keys, playerRepository, tokenRepository, playerCache, mail, auth.Options{
+		LoginMaxAttempts: opts.LoginMaxAttempts,
+		LoginLockout:     opts.LoginLockout,
+		RateLimit:        opts.AuthRateLimit,
+		TrustedProxies:   trustedProxies,
+		AppURL:           opts.AppURL,
+	})
+	discordHandler := discord.NewHandler(
+		playerHandler,
+		ledgerRepository,
+		playedGameRepository,
+		gameRepository,
+		backlogRepository,
+		identityRepository,
+		discordLinkRepository,
+		playerCache,
+		discord.Options{
+			PublicKey: discordPublicKey,
+			AppURL:    opts.AppURL,
+		},
+	)
+	oauthHandler := auth.NewOAuthHandler(authHandler, identityRepository, oauthStateRepository, oauthProviders, auth.OAuthOptions{
+		RedirectURL: strings.TrimSuffix(opts.APIURL, "/") + "/pub/auth/oauth",
+	})
 
 	techHandler.Register(apiV1)
 	gameHandler.Register(apiV1)
 	playerHandler.Register(apiV1)
+	backlogHandler.Register(apiV1)
+	challengeHandler.Register(apiV1)
+	achievementHandler.Register(apiV1)
+	apiTokenHandler.Register(apiV1)
+	webhookHandler.Register(apiV1)
+	adminHandler.Register(apiV1)
+	accountHandler.Register(apiV1)
+	streamHandler.Register(apiV1)
+	discordHandler.Register(apiV1)
 	authHandler.Register(unsecApi)
+	oauthHandler.Register(unsecApi)
+	if discordPublicKey != nil {
+		discordHandler.RegisterInteractions(unsecApi)
+	}
+
+	webhookDispatcher := webhook.NewDispatcher(webhookRepository, webhook.DispatcherOptions{
+		PollInterval: opts.WebhookPollInterval,
+		MaxAttempts:  opts.WebhookMaxAttempts,
+	})
 
 	return &Server{
-		Options:       opts,
-		server:        &server,
-		db:            dbpool,
-		healthChecker: healthChecker,
+		Options:           opts,
+		server:            &server,
+		closeStorage:      store.close,
+		healthChecker:     healthChecker,
+		webhookDispatcher: webhookDispatcher,
+		challengeResolver: challengeResolver,
+		eventHub:          eventHub,
+		eventNotifier:     eventNotifier,
 	}, nil
 }
 
 func (s *Server) Run(ctx context.Context) error {
 	s.prompt()
 	go s.healthChecker.Check(ctx)
+	go s.webhookDispatcher.Run(ctx)
+	go s.challengeResolver.Run(ctx)
+	if s.eventNotifier != nil {
+		go s.eventNotifier.Listen(ctx, s.eventHub)
+	}
+	if s.DiscordAppID != "" && s.DiscordBotToken != "" {
+		go s.registerDiscordCommands(ctx)
+	}
 	return s.server.ListenAndServe()
 }
 
 func (s *Server) Shutdown(ctx context.Context) {
 	log.Println("shutting down...")
 
-	if s.db != nil {
-		s.db.Close()
+	if s.closeStorage != nil {
+		s.closeStorage()
 	}
 
 	s.server.Shutdown(ctx)
 }
 
+func newMailer(opts Options) (mailer.Mailer, error) {
+	switch opts.MailDriver {
+	case MailDriverSMTP:
+		return mailer.NewSMTP(opts.SMTP), nil
+	case MailDriverFile, "":
+		return mailer.NewFile(opts.MailDir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", opts.MailDriver)
+	}
+}
+
+// newKeySet loads jwt keys, without a keys dir an ephemeral key is generated
+// so tokens are invalidated on restart
+func newKeySet(opts Options) (*keyset.KeySet, error) {
+	var legacy []*keyset.Key
+	if opts.JWTSecret != "" {
+		legacy = append(legacy, keyset.NewHMAC("", []byte(opts.JWTSecret)))
+	}
+
+	if opts.JWTKeysDir != "" {
+		return keyset.LoadDir(opts.JWTKeysDir, opts.JWTSigningKeyID, legacy...)
+	}
+
+	log.Println("jwt keys dir is not set, using an ephemeral key")
+	key, err := keyset.GenerateEd25519("ephemeral")
+	if err != nil {
+		return nil, err
+	}
+	return keyset.New(key, legacy...)
+}
+
+func newOAuthProviders(opts Options) (map[string]auth.OAuthProvider, error) {
+	providers := make(map[string]auth.OAuthProvider, len(opts.OAuthProviders))
+	for name, cfg := range opts.OAuthProviders {
+		provider, err := oauth.New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: %w", name, err)
+		}
+		providers[name] = provider
+	}
+	return providers, nil
+}
+
+func (s *Server) registerDiscordCommands(ctx context.Context) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	err := discord.RegisterCommands(ctx, client, discord.DefaultAPIURL, s.DiscordAppID, s.DiscordBotToken)
+	if err != nil {
+		log.Printf("discord commands: %v", err)
+		return
+	}
+	log.Printf("discord commands registered: %d", len(discord.Commands))
+}
+
 func (s *Server) prompt() {
 	log.Println("server is running...")
 	log.Printf("api - http://%v\n", s.server.Addr)
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	// huma panics on conflicting schemas and routes while handlers are registered
+	s, err := New(t.Context(), Options{
+		Storage:          StorageMemory,
+		MailDir:          t.TempDir(),
+		DiscordPublicKey: hex.EncodeToString(publicKey),
+		BcryptCost:       4,
+	})
+	assert.NoError(t, err)
+	return s
+}
+
+func TestNew_RegistersRoutes(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var spec struct {
+		Paths map[string]any `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	for _, path := range []string{
+		"/v1/games/",
+		"/v1/players/{id}",
+		"/v1/admin/players/",
+		"/pub/auth/login",
+		"/pub/discord/interactions",
+	} {
+		_, ok := spec.Paths[path]
+		assert.True(t, ok, "path %s is not registered", path)
+	}
+}
+
+func serve(t *testing.T, s *Server, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestChangePassword_InvalidatesCachedPlayer(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := serve(t, s, http.MethodPost, "/pub/auth/register", "", map[string]string{
+		"username": "cached",
+		"password": "old-password",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var registered struct {
+		ID string `json:"id"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &registered))
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	rec = serve(t, s, http.MethodPost, "/pub/auth/login", "", map[string]string{
+		"username": "cached",
+		"password": "old-password",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+	oldToken := login.Token
+
+	// caches the player for the auth middleware
+	rec = serve(t, s, http.MethodGet, "/v1/players/"+registered.ID, oldToken, nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// tokens are revoked with a second precision
+	time.Sleep(time.Second)
+
+	rec = serve(t, s, http.MethodPatch, "/pub/auth/change-password", oldToken, map[string]string{
+		"old_password": "old-password",
+		"new_password": "new-password",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+
+	rec = serve(t, s, http.MethodGet, "/v1/players/"+registered.ID, oldToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+
+	rec = serve(t, s, http.MethodGet, "/v1/players/"+registered.ID, login.Token, nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lardira/playtrack/internal/db"
+	"github.com/lardira/playtrack/internal/domain/account"
+	"github.com/lardira/playtrack/internal/domain/achievement"
+	"github.com/lardira/playtrack/internal/domain/apitoken"
+	"github.com/lardira/playtrack/internal/domain/auth"
+	"github.com/lardira/playtrack/internal/domain/backlog"
+	"github.com/lardira/playtrack/internal/domain/challenge"
+	"github.com/lardira/playtrack/internal/domain/discord"
+	"github.com/lardira/playtrack/internal/domain/game"
+	"github.com/lardira/playtrack/internal/domain/player"
+	"github.com/lardira/playtrack/internal/domain/webhook"
+	"github.com/lardira/playtrack/internal/memory"
+	"github.com/lardira/playtrack/internal/pkg/event"
+	"github.com/lardira/playtrack/internal/tech"
+)
+
+const (
+	StoragePostgres = "postgres"
+	// StorageMemory keeps data in the process, it is meant for frontend development
+	StorageMemory = "memory"
+	// StorageSQLite keeps data in a file, it is meant for small deployments
+	StorageSQLite = "sqlite"
+)
+
+type gameStorage interface {
+	FindAll(ctx context.Context) ([]game.Game, error)
+	FindOne(ctx context.Context, id int) (*game.Game, error)
+	FindByTitle(ctx context.Context, title string) (*game.Game, error)
+	Insert(ctx context.Context, g *game.Game) (int, error)
+}
+
+type playerStorage interface {
+	FindAll(ctx context.Context) ([]player.Player, error)
+	FindByFilter(ctx context.Context, f player.PlayerFilter) ([]player.Player, error)
+	FindOne(ctx context.Context, id string) (*player.Player, error)
+	FindOneByUsername(ctx context.Context, username string) (*player.Player, error)
+	FindOneByEmail(ctx context.Context, email string) (*player.Player, error)
+	Insert(ctx context.Context, p *player.Player) (string, error)
+	Update(ctx context.Context, p *player.PlayerUpdate) (string, error)
+	Suspend(ctx context.Context, id string, s *player.Suspension) error
+	RegisterFailedLogin(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, id string) error
+}
+
+type playedStorage interface {
+	FindAll(ctx context.Context, playerID string) ([]player.PlayedGame, error)
+	FindStuck(ctx context.Context, startedBefore time.Time) ([]player.PlayedGame, error)
+	FindOne(ctx context.Context, playerID string, id int) (*player.PlayedGame, error)
+	FindNonterminated(ctx context.Context, playerID string) (*player.PlayedGame, error)
+	Insert(ctx context.Context, g *player.PlayedGame) (int, error)
+	Import(ctx context.Context, g *player.PlayedGame) (int, error)
+	Update(ctx context.Context, g *player.PlayedGameUpdate) (int, error)
+}
+
+type streakStorage interface {
+	FindOne(ctx context.Context, playerID string) (*player.Streak, error)
+	Save(ctx context.Context, s *player.Streak) error
+}
+
+type adjustmentStorage interface {
+	FindAll(ctx context.Context, playedGameID int) ([]player.Adjustment, error)
+	Insert(ctx context.Context, a *player.Adjustment) (int, error)
+}
+
+type ledgerStorage interface {
+	FindAll(ctx context.Context, playerID string) ([]player.LedgerEntry, error)
+	Insert(ctx context.Context, entries []player.LedgerEntry) error
+	Leaderboard(ctx context.Context) ([]player.LeaderboardPlayer, error)
+}
+
+type backlogStorage interface {
+	FindAll(ctx context.Context, playerID string) ([]backlog.Item, error)
+	FindOne(ctx context.Context, playerID string, id int) (*backlog.Item, error)
+	Insert(ctx context.Context, item *backlog.Item) (int, error)
+	UpdateNote(ctx context.Context, playerID string, id int, note *string) error
+	Move(ctx context.Context, playerID string, id int, position int) error
+	Delete(ctx context.Context, playerID string, id int) error
+	DeleteByGame(ctx context.Context, playerID string, gameID int) error
+}
+
+type challengeStorage interface {
+	FindAll(ctx context.Context, playerID string, all bool) ([]challenge.Challenge, error)
+	FindOne(ctx context.Context, id int) (*challenge.Challenge, error)
+	FindOpenByPlayedGame(ctx context.Context, playedGameID int) (*challenge.Challenge, error)
+	FindDue(ctx context.Context, now time.Time) ([]challenge.Challenge, error)
+	Insert(ctx context.Context, c *challenge.Challenge) (int, error)
+	Update(ctx context.Context, c *challenge.ChallengeUpdate) (int, error)
+	UpdateParticipant(ctx context.Context, challengeID int, p *challenge.Participant) error
+	ExpireInvitations(ctx context.Context, challengeID int) error
+}
+
+type achievementStorage interface {
+	FindAll(ctx context.Context, playerID string) ([]achievement.Unlock, error)
+	Insert(ctx context.Context, unlocks []achievement.Unlock) ([]achievement.Unlock, error)
+}
+
+type apiTokenStorage interface {
+	FindAll(ctx context.Context, playerID string) ([]apitoken.Token, error)
+	FindOneByHash(ctx context.Context, hash string) (*apitoken.Token, error)
+	Insert(ctx context.Context, token *apitoken.Token) (int, error)
+	Delete(ctx context.Context, playerID string, id int) error
+	Touch(ctx context.Context, id int, at time.Time) error
+}
+
+type tokenStorage interface {
+	Insert(ctx context.Context, token *auth.Token) (int, error)
+	Consume(ctx context.Context, purpose auth.TokenPurpose, hash string) (*auth.Token, error)
+}
+
+type identityStorage interface {
+	FindOne(ctx context.Context, provider, subject string) (*auth.Identity, error)
+	FindAllByPlayer(ctx context.Context, playerID string) ([]auth.Identity, error)
+	Insert(ctx context.Context, identity *auth.Identity) (int, error)
+	Delete(ctx context.Context, playerID, provider string) error
+}
+
+type oauthStateStorage interface {
+	Insert(ctx context.Context, state *auth.OAuthState) (int, error)
+	Consume(ctx context.Context, provider, hash string) (*auth.OAuthState, error)
+}
+
+type webhookStorage interface {
+	event.Publisher
+	FindAll(ctx context.Context) ([]webhook.Webhook, error)
+	FindOne(ctx context.Context, id int) (*webhook.Webhook, error)
+	Insert(ctx context.Context, w *webhook.Webhook) (int, error)
+	Update(ctx context.Context, w *webhook.WebhookUpdate) (int, error)
+	Delete(ctx context.Context, id int) error
+	FindDeliveries(ctx context.Context, webhookID int, limit int) ([]webhook.Delivery, error)
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error)
+	SaveAttempt(ctx context.Context, delivery *webhook.Delivery) error
+}
+
+type discordLinkStorage interface {
+	Insert(ctx context.Context, link *discord.Link) (int, error)
+	Consume(ctx context.Context, hash string) (*discord.Link, error)
+}
+
+type accountStorage interface {
+	Anonymize(ctx context.Context, playerID string) error
+	Erase(ctx context.Context, playerID string) error
+	Export(ctx context.Context, playerID string) ([]account.ExportTable, error)
+}
+
+type txStorage interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// storage holds repositories of the configured storage
+type storage struct {
+	// name is the storage watched by the health checker
+	name   string
+	pinger tech.Pinger
+	// pool is nil unless the storage is postgres
+	pool *pgxpool.Pool
+	// close releases the storage, it is nil when there is nothing to release
+	close func()
+
+	games        gameStorage
+	players      playerStorage
+	played       playedStorage
+	streaks      streakStorage
+	adjustments  adjustmentStorage
+	ledger       ledgerStorage
+	backlog      backlogStorage
+	challenges   challengeStorage
+	achievements achievementStorage
+	apiTokens    apiTokenStorage
+	tokens       tokenStorage
+	identities   identityStorage
+	oauthStates  oauthStateStorage
+	webhooks     webhookStorage
+	discordLinks discordLinkStorage
+	accounts     accountStorage
+	tx           txStorage
+}
+
+func newStorage(ctx context.Context, opts Options) (*storage, error) {
+	switch opts.Storage {
+	case StoragePostgres, "":
+		pool, err := db.NewPostgres(ctx, opts.DatabaseURL)
+		if err != nil {
+			return nil, err
+		}
+		return newPGStorage(pool), nil
+	case StorageMemory:
+		log.Println("memory storage is used, data is lost on restart")
+		return newMemoryStorage(memory.NewStore()), nil
+	case StorageSQLite:
+		sqlDB, err := db.NewSQLite(ctx, opts.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := migrateSQLite(ctx, sqlDB); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("migrate sqlite: %w", err)
+		}
+		return newSQLiteStorage(sqlDB), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q", opts.Storage)
+	}
+}
+
+func newPGStorage(pool *pgxpool.Pool) *storage {
+	return &storage{
+		name:         "postgres db",
+		pinger:       pool,
+		pool:         pool,
+		close:        pool.Close,
+		games:        game.NewPGRepository(pool),
+		players:      player.NewPGRepository(pool),
+		played:       player.NewPGPlayedRepository(pool),
+		streaks:      player.NewPGStreakRepository(pool),
+		adjustments:  player.NewPGAdjustmentRepository(pool),
+		ledger:       player.NewPGLedgerRepository(pool),
+		backlog:      backlog.NewPGRepository(pool),
+		challenges:   challenge.NewPGRepository(pool),
+		achievements: achievement.NewPGRepository(pool),
+		apiTokens:    apitoken.NewPGRepository(pool),
+		tokens:       auth.NewPGTokenRepository(pool),
+		identities:   auth.NewPGIdentityRepository(pool),
+		oauthStates:  auth.NewPGOAuthStateRepository(pool),
+		webhooks:     webhook.NewPGRepository(pool),
+		discordLinks: discord.NewPGLinkRepository(pool),
+		accounts:     account.NewPGRepository(pool),
+		tx:           db.NewTxManager(pool),
+	}
+}
+
+func newMemoryStorage(store *memory.Store) *storage {
+	return &storage{
+		name:         "memory store",
+		pinger:       store,
+		games:        memory.NewGameRepository(store),
+		players:      memory.NewPlayerRepository(store),
+		played:       memory.NewPlayedRepository(store),
+		streaks:      memory.NewStreakRepository(store),
+		adjustments:  memory.NewAdjustmentRepository(store),
+		ledger:       memory.NewLedgerRepository(store),
+		backlog:      memory.NewBacklogRepository(store),
+		challenges:   memory.NewChallengeRepository(store),
+		achievements: memory.NewAchievementRepository(store),
+		apiTokens:    memory.NewAPITokenRepository(store),
+		tokens:       memory.NewTokenRepository(store),
+		identities:   memory.NewIdentityRepository(store),
+		oauthStates:  memory.NewOAuthStateRepository(store),
+		webhooks:     memory.NewWebhookRepository(store),
+		discordLinks: memory.NewDiscordLinkRepository(store),
+		accounts:     memory.NewAccountRepository(store),
+		tx:           memory.NewTxManager(store),
+	}
+}
+
+func newSQLiteStorage(sqlDB *sql.DB) *storage {
+	return &storage{
+		name:         "sqlite db",
+		pinger:       sqlitePinger{db: sqlDB},
+		close:        func() { sqlDB.Close() },
+		games:        game.NewSQLiteRepository(sqlDB),
+		players:      player.NewSQLiteRepository(sqlDB),
+		played:       player.NewSQLitePlayedRepository(sqlDB),
+		streaks:      player.NewSQLiteStreakRepository(sqlDB),
+		adjustments:  player.NewSQLiteAdjustmentRepository(sqlDB),
+		ledger:       player.NewSQLiteLedgerRepository(sqlDB),
+		backlog:      backlog.NewSQLiteRepository(sqlDB),
+		challenges:   challenge.NewSQLiteRepository(sqlDB),
+		achievements: achievement.NewSQLiteRepository(sqlDB),
+		apiTokens:    apitoken.NewSQLiteRepository(sqlDB),
+		tokens:       auth.NewSQLiteTokenRepository(sqlDB),
+		identities:   auth.NewSQLiteIdentityRepository(sqlDB),
+		oauthStates:  auth.NewSQLiteOAuthStateRepository(sqlDB),
+		webhooks:     webhook.NewSQLiteRepository(sqlDB),
+		discordLinks: discord.NewSQLiteLinkRepository(sqlDB),
+		accounts:     account.NewSQLiteRepository(sqlDB),
+		tx:           db.NewSQLiteTxManager(sqlDB),
+	}
+}
+
+// migrateSQLite brings the database file up to date, there is no separate
+// migration step for sqlite deployments
+func migrateSQLite(ctx context.Context, sqlDB *sql.DB) error {
+	migrator, err := db.NewSQLiteMigrator(sqlDB, db.DefaultMigrationsTable)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	return migrator.Up(ctx)
+}
+
+type sqlitePinger struct {
+	db *sql.DB
+}
+
+func (p sqlitePinger) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
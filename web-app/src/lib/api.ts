@@ -1,4 +1,4 @@
-import type { Player, LeaderboardPlayer, PlayedGame, Game, AuthResponse } from './types';
+import type { Player, LeaderboardPlayer, PlayedGame, LedgerEntry, PointsAdjustment, Streak, AdminPlayer, SuspensionKind, Game, Achievement, AuthResponse, BacklogItem, Challenge, ChallengeMode, StreamEvent, StreamEventType } from './types';
 import { browser } from '$app/environment';
 import { getTokenFromCookie } from './cookies';
 import { getCurrentToken } from './tokenHolder';
@@ -63,13 +63,13 @@ export interface RegisterResponse {
     id: string;
 }
 
-export interface SetPasswordRequest {
-    password: string;
-    username: string;
+export interface ChangePasswordRequest {
+    old_password: string;
+    new_password: string;
 }
 
-export interface SetPasswordResponse {
-    id: string;
+export interface ChangePasswordResponse {
+    token: string;
 }
 
 export interface PlayerResponse {
@@ -100,14 +100,14 @@ export const register = async (data: RegisterRequest): Promise<RegisterResponse>
     return { id };
 };
 
-export const setPassword = async (data: SetPasswordRequest): Promise<SetPasswordResponse> => {
-    const response = await api<{ Body?: { id: string }; body?: { id: string }; id?: string }>('/pub/set-password', {
+export const changePassword = async (data: ChangePasswordRequest): Promise<ChangePasswordResponse> => {
+    const response = await api<{ token?: string; Token?: string }>('/pub/auth/change-password', {
         method: 'PATCH',
         body: JSON.stringify(data)
     });
-    const id = unwrapBody(response)?.id ?? response.id;
-    if (!id) throw new Error('No id in response');
-    return { id };
+    const token = response.token ?? response.Token;
+    if (!token || typeof token !== 'string') throw new Error('No token in response');
+    return { token };
 };
 
 function getItems<T>(r: { Body?: { items: T[] }; body?: { items: T[] }; items?: T[] }): T[] {
@@ -151,7 +151,29 @@ export const updatePlayer = async (playerId: string, data: UpdatePlayerRequest):
     return { id };
 };
 
-export const getLeaderboard = () => api<LeaderboardPlayer[]>('/v1/players/leaderboard');
+export type DeleteMode = 'anonymize' | 'erase';
+
+export const deletePlayer = (playerId: string, mode: DeleteMode = 'anonymize') =>
+    api<{ id?: string }>(`/v1/players/${playerId}?mode=${mode}`, { method: 'DELETE' });
+
+// the export is a zip archive, not json, so it is fetched without api()
+export const exportPlayerData = async (playerId: string): Promise<Blob> => {
+    const token = browser ? (getTokenFromCookie() ?? getCurrentToken()) : null;
+    const headers: Record<string, string> = {};
+    if (token) headers['Authorization'] = `Bearer ${token}`;
+
+    const res = await fetch(`${baseURL}/v1/players/${playerId}/export`, { headers });
+    if (!res.ok) throw new Error(`HTTP ${res.status}: ${res.statusText}`);
+    return res.blob();
+};
+
+export const getLeaderboard = () =>
+    api<{ Body?: { items: LeaderboardPlayer[] }; body?: { items: LeaderboardPlayer[] }; items?: LeaderboardPlayer[] }>(
+        '/v1/players/leaderboard'
+    ).then(getItems);
+
+export const getLedger = (playerId: string) =>
+    api<{ total: number; items: LedgerEntry[] }>(`/v1/players/${playerId}/ledger`);
 
 export const getGames = () =>
     api<{ Body?: { items: Game[] }; body?: { items: Game[] }; items?: Game[] }>('/v1/games/').then(getItems);
@@ -187,7 +209,6 @@ export const createPlayedGame = async (playerId: string, gameId: number): Promis
 };
 
 export interface UpdatePlayedGameRequest {
-    points?: number;
     comment?: string | null;
     rating?: number | null;
     status?: import('./types').PlayedGameStatus;
@@ -211,3 +232,140 @@ export const updatePlayedGame = async (
     if (id == null) throw new Error('No id in response');
     return { id };
 };
+
+export const getPointsAdjustments = (playerId: string, playedGameId: number) =>
+    api<{ Body?: { items: PointsAdjustment[] }; body?: { items: PointsAdjustment[] }; items?: PointsAdjustment[] }>(
+        `/v1/players/${playerId}/played-games/${playedGameId}/adjustments`
+    ).then(getItems);
+
+// adjustPoints sets points of a played game, only for admins
+export const adjustPoints = (playerId: string, playedGameId: number, points: number, reason: string) =>
+    api<{ id?: number }>(`/v1/players/${playerId}/played-games/${playedGameId}/adjustments`, {
+        method: 'POST',
+        body: JSON.stringify({ points, reason })
+    });
+
+export interface AdminPlayersFilter {
+    username?: string;
+    role?: 'admin' | 'player';
+    state?: 'active' | SuspensionKind;
+}
+
+export const getAdminPlayers = (filter: AdminPlayersFilter = {}) => {
+    const params = new URLSearchParams(
+        Object.entries(filter).filter((entry): entry is [string, string] => !!entry[1])
+    );
+    return api<{ Body?: { items: AdminPlayer[] }; body?: { items: AdminPlayer[] }; items?: AdminPlayer[] }>(
+        `/v1/admin/players/?${params}`
+    ).then(getItems);
+};
+
+export const setPlayerAdmin = (playerId: string, isAdmin: boolean) =>
+    api<{ Body?: { item: AdminPlayer }; body?: { item: AdminPlayer }; item?: AdminPlayer }>(
+        `/v1/admin/players/${playerId}/admin`,
+        { method: 'PUT', body: JSON.stringify({ is_admin: isAdmin }) }
+    ).then(getItem);
+
+// suspendPlayer deactivates or bans a player and ends the sessions, until is omitted for suspensions without expiry
+export const suspendPlayer = (playerId: string, kind: SuspensionKind, reason: string, until?: string) =>
+    api<{ Body?: { item: AdminPlayer }; body?: { item: AdminPlayer }; item?: AdminPlayer }>(
+        `/v1/admin/players/${playerId}/${kind === 'banned' ? 'ban' : 'deactivate'}`,
+        { method: 'POST', body: JSON.stringify({ reason, until }) }
+    ).then(getItem);
+
+export const liftSuspension = (playerId: string) =>
+    api<{ Body?: { item: AdminPlayer }; body?: { item: AdminPlayer }; item?: AdminPlayer }>(
+        `/v1/admin/players/${playerId}/suspension`,
+        { method: 'DELETE' }
+    ).then(getItem);
+
+export const logoutPlayer = (playerId: string) =>
+    api<void>(`/v1/admin/players/${playerId}/logout`, { method: 'POST' });
+
+export const getBacklog = (playerId: string) =>
+    api<{ Body?: { items: BacklogItem[] }; body?: { items: BacklogItem[] }; items?: BacklogItem[] }>(
+        `/v1/players/${playerId}/backlog`
+    ).then(getItems);
+
+export interface BacklogItemRequest {
+    note?: string | null;
+    position?: number;
+}
+
+export const addBacklogItem = async (
+    playerId: string,
+    gameId: number,
+    data: BacklogItemRequest = {}
+): Promise<{ id: number }> => {
+    const response = await api<{ id?: number }>(`/v1/players/${playerId}/backlog`, {
+        method: 'POST',
+        body: JSON.stringify({ game_id: gameId, ...data })
+    });
+    if (response.id == null) throw new Error('No id in response');
+    return { id: response.id };
+};
+
+export const updateBacklogItem = (playerId: string, itemId: number, data: BacklogItemRequest) =>
+    api<{ id?: number }>(`/v1/players/${playerId}/backlog/${itemId}`, {
+        method: 'PATCH',
+        body: JSON.stringify(data)
+    });
+
+export const deleteBacklogItem = (playerId: string, itemId: number) =>
+    api<{ id?: number }>(`/v1/players/${playerId}/backlog/${itemId}`, { method: 'DELETE' });
+
+export const getChallenges = (playerId: string, all = false) =>
+    api<{ Body?: { items: Challenge[] }; body?: { items: Challenge[] }; items?: Challenge[] }>(
+        `/v1/players/${playerId}/challenges${all ? '?all=true' : ''}`
+    ).then(getItems);
+
+export const createChallenge = async (
+    gameId: number,
+    opponentIds: string[],
+    mode: ChallengeMode
+): Promise<{ id: number }> => {
+    const response = await api<{ id?: number }>('/v1/challenges/', {
+        method: 'POST',
+        body: JSON.stringify({ game_id: gameId, opponent_ids: opponentIds, mode })
+    });
+    if (response.id == null) throw new Error('No id in response');
+    return { id: response.id };
+};
+
+export const acceptChallenge = (id: number) => api<{ id?: number }>(`/v1/challenges/${id}/accept`, { method: 'POST' });
+export const declineChallenge = (id: number) => api<{ id?: number }>(`/v1/challenges/${id}/decline`, { method: 'POST' });
+
+export const getStreak = (playerId: string) =>
+    api<{ Body?: { item: Streak }; body?: { item: Streak }; item?: Streak }>(`/v1/players/${playerId}/streak`).then(
+        getItem
+    );
+
+export const getAchievements = (playerId: string) =>
+    api<{ Body?: { items: Achievement[] }; body?: { items: Achievement[] }; items?: Achievement[] }>(
+        `/v1/players/${playerId}/achievements`
+    ).then(getItems);
+
+export const confirmDiscordLink = async (code: string): Promise<string> => {
+    const response = await api<{ message?: string }>('/v1/discord/link', {
+        method: 'POST',
+        body: JSON.stringify({ code })
+    });
+    return response.message ?? 'Discord привязан';
+};
+
+// subscribeEvents listens to the server-sent events of the api, EventSource reconnects
+// by itself and resumes from the last received event. Returns a function closing the stream.
+export function subscribeEvents(
+    types: StreamEventType[],
+    onEvent: (event: StreamEvent) => void
+): () => void {
+    const token = browser ? (getTokenFromCookie() ?? getCurrentToken()) : null;
+    if (!token) return () => {};
+
+    const params = new URLSearchParams({ access_token: token, types: types.join(',') });
+    const source = new EventSource(`${baseURL}/v1/events?${params}`);
+    const listener = (e: MessageEvent) => onEvent(JSON.parse(e.data) as StreamEvent);
+    types.forEach((type) => source.addEventListener(type, listener));
+
+    return () => source.close();
+}
@@ -9,8 +9,9 @@ export interface Player {
 
 export interface LeaderboardPlayer {
     player_id: string;
+    username: string;
     completed: number;
-    total: number;
+    total: number; // sum of the points ledger
     dropped: number;
     rerolled: number;
 }
@@ -24,6 +25,25 @@ export interface LeaderboardRow {
     rerolled: number;
 }
 
+export type StreamEventType =
+    | 'played_game.added'
+    | 'played_game.started'
+    | 'played_game.completed'
+    | 'played_game.dropped'
+    | 'played_game.rerolled'
+    | 'game.created'
+    | 'leaderboard.changed'
+    | 'challenge.finished'
+    | 'achievement.unlocked';
+
+export interface StreamEvent<T = unknown> {
+    id: string;
+    type: StreamEventType;
+    player_id?: string;
+    occurred_at: string; // ISO date string
+    data: T;
+}
+
 export interface Game {
     id: number;
     points: number;
@@ -46,6 +66,100 @@ export interface PlayedGame {
     play_time: string | null; // ISO duration string
 }
 
+export interface BacklogItem {
+    id: number;
+    player_id: string;
+    game_id: number;
+    position: number;
+    note: string | null;
+    created_at: string; // ISO date string
+}
+
+export type ChallengeMode = 'first_completed' | 'play_time';
+
+export type ChallengeStatus = 'pending' | 'active' | 'finished' | 'declined' | 'expired' | 'cancelled';
+
+export interface ChallengeParticipant {
+    player_id: string;
+    status: 'invited' | 'accepted' | 'declined' | 'expired';
+    played_game_id: number | null;
+}
+
+export interface Challenge {
+    id: number;
+    challenger_id: string;
+    game_id: number;
+    mode: ChallengeMode;
+    bonus: number;
+    status: ChallengeStatus;
+    winner_id: string | null;
+    expires_at: string; // ISO date string
+    created_at: string; // ISO date string
+    finished_at: string | null; // ISO date string
+    participants: ChallengeParticipant[];
+}
+
+export type LedgerReason =
+    | 'completion'
+    | 'streak_bonus'
+    | 'drop_penalty'
+    | 'drop_stacking'
+    | 'adjustment'
+    | 'challenge_bonus'
+    | 'import'
+    | 'history';
+
+export interface LedgerEntry {
+    id: number;
+    player_id: string;
+    played_game_id: number;
+    delta: number;
+    reason: LedgerReason;
+    created_at: string; // ISO date string
+}
+
+export interface PointsAdjustment {
+    id: number;
+    played_game_id: number;
+    player_id: string;
+    adjusted_by: string | null;
+    points_before: number;
+    points: number;
+    reason: string;
+    created_at: string; // ISO date string
+}
+
+export type SuspensionKind = 'deactivated' | 'banned';
+
+export interface Suspension {
+    kind: SuspensionKind;
+    reason: string;
+    at: string; // ISO date string
+    until: string | null; // ISO date string, null until lifted by an admin
+}
+
+// AdminPlayer is a player as seen by admins
+export interface AdminPlayer extends Player {
+    is_admin: boolean;
+    suspension: Suspension | null;
+}
+
+export interface Streak {
+    player_id: string;
+    completions: number;
+    drops: number;
+    penalty: number; // the next drop costs penalty + 1 points
+    best_completions: number;
+    updated_at: string; // ISO date string
+}
+
+export interface Achievement {
+    code: string;
+    title: string;
+    description: string;
+    unlocked_at: string | null; // ISO date string
+}
+
 export interface AuthResponse {
     token?: string;
     player?: Player;